@@ -2,6 +2,13 @@ package a2a
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,6 +26,16 @@ const (
 	TaskStateCancelled     TaskState = "cancelled" // Corrected spelling
 )
 
+// TaskStatusReason explains why a task was cancelled or failed.
+type TaskStatusReason string
+
+const (
+	TaskStatusReasonUser     TaskStatusReason = "user"     // Cancelled at the client's request
+	TaskStatusReasonTimeout  TaskStatusReason = "timeout"  // Ran longer than its timeout
+	TaskStatusReasonShutdown TaskStatusReason = "shutdown" // Stopped because the server shut down
+	TaskStatusReasonError    TaskStatusReason = "error"    // The agent reported an error
+)
+
 // Role represents the role of a message sender.
 type Role string
 
@@ -32,20 +49,29 @@ const (
 
 // Task represents an A2A task.
 type Task struct {
-	ID          string       `json:"id"`
-	SessionID   *string      `json:"sessionId,omitempty"` // Optional session ID
-	Status      TaskStatus   `json:"status"`
-	History     []Message    `json:"history"` // Chronological order
-	Artifacts   []Artifact   `json:"artifacts"`
-	InputSchema *interface{} `json:"inputSchema,omitempty"` // Optional JSON schema for input
+	ID          string                 `json:"id"`
+	SessionID   *string                `json:"sessionId,omitempty"` // Optional session ID
+	Status      TaskStatus             `json:"status"`
+	History     []Message              `json:"history"` // Chronological order
+	Artifacts   []Artifact             `json:"artifacts"`
+	InputSchema *interface{}           `json:"inputSchema,omitempty"` // Optional JSON schema for input
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`    // Optional server-populated metadata
 	// TODO: Add other potential fields if needed based on spec refinement
 }
 
+// Task metadata keys under which a server may record the request that created the task.
+const (
+	TaskMetadataRequestID     = "requestId"     // ID of the JSON-RPC request
+	TaskMetadataHTTPRequestID = "httpRequestId" // X-Request-ID header of the HTTP request
+)
+
 // TaskStatus represents the status details of a task.
 type TaskStatus struct {
-	State     TaskState `json:"state"`
-	Timestamp time.Time `json:"timestamp"`
-	Message   *Message  `json:"message,omitempty"` // Optional message associated with the status change
+	State     TaskState        `json:"state"`
+	Timestamp time.Time        `json:"timestamp"`
+	Message   *Message         `json:"message,omitempty"`   // Optional message associated with the status change
+	Reason    TaskStatusReason `json:"reason,omitempty"`    // Why the task was cancelled or failed
+	Retryable bool             `json:"retryable,omitempty"` // Whether sending the task again may succeed
 }
 
 // Artifact represents an artifact associated with a task.
@@ -65,14 +91,25 @@ type Message struct {
 	Metadata  interface{} `json:"metadata,omitempty"`
 }
 
-// Part represents a piece of content within a message or artifact.
-// Using an interface{} for now; might refine to a specific interface or tagged union struct later.
-// For marshalling/unmarshalling, custom logic might be needed depending on the final approach.
+// Part represents a piece of content within a message or artifact. Applications can define
+// their own part types by embedding BasePart and registering them with RegisterPartType.
 type Part interface {
 	// isPart is a marker method for the Part interface (or use type embedding)
 	isPart()
+
+	// PartType returns the part type identifier (e.g., "text", "file", "data").
+	PartType() string
+
+	// ContentSize returns the size of the part's content in bytes.
+	ContentSize() int
+
+	// Summary returns a short human-readable description of the part.
+	Summary() string
 }
 
+// maxSummaryTextLength is the maximum number of characters of text included in a part summary.
+const maxSummaryTextLength = 100
+
 // TextPart represents a plain text part.
 type TextPart struct {
 	Type string `json:"type"` // Should always be "text"
@@ -81,6 +118,21 @@ type TextPart struct {
 
 func (TextPart) isPart() {}
 
+// PartType returns "text".
+func (TextPart) PartType() string { return "text" }
+
+// ContentSize returns the length of the text in bytes.
+func (p TextPart) ContentSize() int { return len(p.Text) }
+
+// Summary returns the text, truncated if it is long.
+func (p TextPart) Summary() string {
+	text := []rune(p.Text)
+	if len(text) > maxSummaryTextLength {
+		return fmt.Sprintf("Text: %s...", string(text[:maxSummaryTextLength]))
+	}
+	return fmt.Sprintf("Text: %s", p.Text)
+}
+
 // FilePart represents a reference to a file, potentially with content.
 type FilePart struct {
 	Type        string       `json:"type"` // Should always be "file"
@@ -93,10 +145,43 @@ type FilePart struct {
 
 func (FilePart) isPart() {}
 
+// PartType returns "file".
+func (FilePart) PartType() string { return "file" }
+
+// ContentSize returns the decoded size of the inline file content in bytes.
+// It returns 0 if the file is only referenced by URI.
+func (p FilePart) ContentSize() int {
+	if p.Content == nil {
+		return 0
+	}
+	if p.Content.Encoding != "base64" {
+		return len(p.Content.Data)
+	}
+
+	// Calculate the decoded size without decoding, ignoring padding and whitespace
+	data := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, p.Content.Data)
+	data = strings.TrimRight(data, "=")
+	return len(data) * 3 / 4
+}
+
+// Summary returns the filename, MIME type, and content size.
+func (p FilePart) Summary() string {
+	if p.Content == nil {
+		return fmt.Sprintf("File: %s (%s)", p.Filename, p.MimeType)
+	}
+	return fmt.Sprintf("File: %s (%s, %d bytes)", p.Filename, p.MimeType, p.ContentSize())
+}
+
 // FileContent holds the actual file content, typically base64 encoded.
 type FileContent struct {
 	Encoding string `json:"encoding"` // e.g., "base64"
 	Data     string `json:"data"`
+	Size     int64  `json:"size,omitempty"` // Size of the decoded content in bytes (0 = not recorded)
 }
 
 // DataPart represents structured data.
@@ -108,6 +193,168 @@ type DataPart struct {
 
 func (DataPart) isPart() {}
 
+// PartType returns "data".
+func (DataPart) PartType() string { return "data" }
+
+// ContentSize returns the size of the JSON-encoded data in bytes.
+func (p DataPart) ContentSize() int {
+	data, err := json.Marshal(p.Data)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// Summary returns the MIME type and content size.
+func (p DataPart) Summary() string {
+	return fmt.Sprintf("Data: %s (%d bytes)", p.MimeType, p.ContentSize())
+}
+
+// BasePart can be embedded in an application-defined part type to implement the Part
+// interface. It carries the part's "type" field and provides defaults for the Part methods,
+// which the embedding type can override, e.g. to report the size of its content. See
+// RegisterPartType.
+type BasePart struct {
+	Type string `json:"type"`
+}
+
+func (BasePart) isPart() {}
+
+// PartType returns the part's type field.
+func (p BasePart) PartType() string { return p.Type }
+
+// ContentSize returns 0, as the content of the embedding part is unknown.
+func (BasePart) ContentSize() int { return 0 }
+
+// Summary returns the part's type.
+func (p BasePart) Summary() string { return fmt.Sprintf("Part: %s", p.Type) }
+
+var (
+	partTypesMu sync.RWMutex
+	partTypes   = map[string]func() Part{
+		"text": func() Part { return TextPart{} },
+		"file": func() Part { return FilePart{} },
+		"data": func() Part { return DataPart{} },
+	}
+)
+
+// isBuiltinPartType reports whether typeName is one of the part types defined by the protocol.
+func isBuiltinPartType(typeName string) bool {
+	return typeName == "text" || typeName == "file" || typeName == "data"
+}
+
+// RegisterPartType registers the factory used to decode parts with the given "type" field,
+// so messages and artifacts can carry application-defined parts (e.g., audio or embeddings).
+// The factory returns an empty part of the type, either a value or a pointer; parts are
+// decoded into a new one with encoding/json. Registering a type name again replaces its
+// factory. It panics if typeName is empty or factory is nil, or if typeName is one of the
+// built-in "text", "file" and "data" types, whose decoding cannot be replaced.
+func RegisterPartType(typeName string, factory func() Part) {
+	if typeName == "" || factory == nil {
+		panic("a2a: RegisterPartType requires a type name and factory")
+	}
+	if isBuiltinPartType(typeName) {
+		panic(fmt.Sprintf("a2a: RegisterPartType cannot replace the built-in %q part type", typeName))
+	}
+	partTypesMu.Lock()
+	defer partTypesMu.Unlock()
+	partTypes[typeName] = factory
+}
+
+// UnmarshalPart decodes a JSON-encoded part into its concrete type based on the "type" field,
+// using the factory registered for the type with RegisterPartType.
+func UnmarshalPart(data []byte) (Part, error) {
+	var probe struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to decode part: %w", err)
+	}
+
+	partTypesMu.RLock()
+	factory, ok := partTypes[probe.Type]
+	partTypesMu.RUnlock()
+	if !ok {
+		return nil, &FieldError{Field: "type", Reason: fmt.Sprintf("unknown part type %q", probe.Type)}
+	}
+
+	// Decode into a pointer, returning a value if the factory returned one
+	part := reflect.ValueOf(factory())
+	target := part
+	if part.Kind() != reflect.Pointer {
+		target = reflect.New(part.Type())
+		target.Elem().Set(part)
+	}
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return nil, fieldError("", fmt.Errorf("failed to decode %s part: %w", probe.Type, err))
+	}
+	if part.Kind() != reflect.Pointer {
+		return target.Elem().Interface().(Part), nil
+	}
+	return target.Interface().(Part), nil
+}
+
+// unmarshalParts decodes a list of raw JSON parts into their concrete types.
+func unmarshalParts(raw []json.RawMessage) ([]Part, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	parts := make([]Part, 0, len(raw))
+	for i, data := range raw {
+		part, err := UnmarshalPart(data)
+		if err != nil {
+			return nil, fieldError(fmt.Sprintf("parts[%d]", i), err)
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding each part into its concrete type.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message // Avoid recursion
+	var raw struct {
+		message
+		Parts []json.RawMessage `json:"parts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	parts, err := unmarshalParts(raw.Parts)
+	if err != nil {
+		return err
+	}
+
+	*m = Message(raw.message)
+	m.Parts = parts
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the part into its concrete type.
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	type artifact Artifact // Avoid recursion
+	var raw struct {
+		artifact
+		Part json.RawMessage `json:"part"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*a = Artifact(raw.artifact)
+	a.Part = nil
+	if len(raw.Part) > 0 && string(raw.Part) != "null" {
+		part, err := UnmarshalPart(raw.Part)
+		if err != nil {
+			return fieldError("part", err)
+		}
+		a.Part = part
+	}
+	return nil
+}
+
 // --- Agent Card ---
 
 // AgentCard describes an A2A agent.
@@ -127,6 +374,61 @@ type AgentCard struct {
 	DocumentationURI *string               `json:"documentationUri,omitempty"`
 }
 
+// a2aVersionPattern matches a version of the form "major.minor" or "major.minor.patch".
+var a2aVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// Validate checks that the agent card is well formed: the version, ID and name are set,
+// the version has the form "major.minor[.patch]", skill IDs are present and unique, and
+// URI fields are absolute URLs. All problems found are returned together.
+func (c *AgentCard) Validate() error {
+	var errs []error
+
+	if c.A2AVersion == "" {
+		errs = append(errs, errors.New("a2aVersion is required"))
+	} else if !a2aVersionPattern.MatchString(c.A2AVersion) {
+		errs = append(errs, fmt.Errorf("a2aVersion %q must have the form major.minor[.patch]", c.A2AVersion))
+	}
+	if c.ID == "" {
+		errs = append(errs, errors.New("id is required"))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	seen := make(map[string]bool, len(c.Skills))
+	for i, skill := range c.Skills {
+		switch {
+		case skill.ID == "":
+			errs = append(errs, fmt.Errorf("skills[%d]: id is required", i))
+		case seen[skill.ID]:
+			errs = append(errs, fmt.Errorf("skills[%d]: duplicate skill id %q", i, skill.ID))
+		}
+		seen[skill.ID] = true
+	}
+
+	uris := map[string]*string{
+		"iconUri":          c.IconURI,
+		"legalInfoUri":     c.LegalInfoURI,
+		"homepageUri":      c.HomepageURI,
+		"documentationUri": c.DocumentationURI,
+	}
+	if c.Provider != nil {
+		uris["provider.uri"] = c.Provider.URI
+	}
+	for _, name := range []string{"iconUri", "legalInfoUri", "homepageUri", "documentationUri", "provider.uri"} {
+		if uri := uris[name]; uri != nil && *uri != "" {
+			if u, err := url.Parse(*uri); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s %q is not a valid absolute URL", name, *uri))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid agent card: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
 // AgentProvider describes the provider of the agent.
 type AgentProvider struct {
 	Name string  `json:"name"`
@@ -135,11 +437,30 @@ type AgentProvider struct {
 
 // AgentSkill describes a skill the agent possesses.
 type AgentSkill struct {
-	ID             string      `json:"id"` // Unique ID for the skill within the agent
-	Name           string      `json:"name"`
-	Description    *string     `json:"description,omitempty"`
-	InputSchema    interface{} `json:"inputSchema,omitempty"`    // JSON Schema for task input
-	ArtifactSchema interface{} `json:"artifactSchema,omitempty"` // JSON Schema for artifacts produced
+	ID             string         `json:"id"` // Unique ID for the skill within the agent
+	Name           string         `json:"name"`
+	Description    *string        `json:"description,omitempty"`
+	InputSchema    interface{}    `json:"inputSchema,omitempty"`    // JSON Schema for task input
+	ArtifactSchema interface{}    `json:"artifactSchema,omitempty"` // JSON Schema for artifacts produced
+	Tags           []string       `json:"tags,omitempty"`           // Tags for discovering the skill (e.g., "search", "summarisation")
+	Examples       []SkillExample `json:"examples,omitempty"`       // Example inputs, to show clients how to invoke the skill
+}
+
+// SkillExample is an example input for a skill.
+type SkillExample struct {
+	Name  string      `json:"name"`  // Short description of the example
+	Input interface{} `json:"input"` // Input matching the skill's input schema, e.g. a string or a JSON object
+}
+
+// SkillExample returns the first example input of a skill, e.g. to prefill a client's input.
+// It returns false if the card has no such skill or the skill has no examples.
+func (c *AgentCard) SkillExample(skillID string) (SkillExample, bool) {
+	for _, skill := range c.Skills {
+		if skill.ID == skillID && len(skill.Examples) > 0 {
+			return skill.Examples[0], true
+		}
+	}
+	return SkillExample{}, false
 }
 
 // AgentCapabilities describes the capabilities of the agent.
@@ -147,9 +468,24 @@ type AgentCapabilities struct {
 	SupportsStreaming        bool `json:"supportsStreaming"`
 	SupportsSessions         bool `json:"supportsSessions"`
 	SupportsPushNotification bool `json:"supportsPushNotification"`
+	// MaxInputBytes is the largest request params, such as a task's message, the agent accepts (0 = unlimited)
+	MaxInputBytes int64 `json:"maxInputBytes,omitempty"`
+	// MaxOutputBytes is the most artifact content a single task may produce (0 = unlimited)
+	MaxOutputBytes int64 `json:"maxOutputBytes,omitempty"`
+	// StreamingEndpoint is where the streaming methods are served, as a path relative to the
+	// A2A endpoint or an absolute URL (empty = "sse")
+	StreamingEndpoint string `json:"streamingEndpoint,omitempty"`
+	// StreamingProtocol is the transport of the streaming endpoint (empty = StreamingProtocolSSE)
+	StreamingProtocol string `json:"streamingProtocol,omitempty"`
 	// Add other capabilities as defined in the spec
 }
 
+// Streaming protocols advertised in AgentCapabilities.StreamingProtocol.
+const (
+	StreamingProtocolSSE       = "sse" // Server-Sent Events
+	StreamingProtocolWebSocket = "ws"  // WebSocket
+)
+
 // AgentAuthentication describes an authentication method supported by the agent.
 type AgentAuthentication struct {
 	Type          string      `json:"type"` // e.g., "bearer", "oauth2"
@@ -166,6 +502,9 @@ type PushNotificationConfig struct {
 	Authentication   *AuthenticationInfo `json:"authentication,omitempty"`
 	IncludeTaskData  *bool               `json:"includeTaskData,omitempty"`  // Default true
 	IncludeArtifacts *bool               `json:"includeArtifacts,omitempty"` // Default false
+	Headers          map[string]string   `json:"headers,omitempty"`          // Extra HTTP headers sent with each notification
+	// ReplayOnSubscribe sends the task's current status and recent artifacts when the config is set (default false)
+	ReplayOnSubscribe *bool `json:"replayOnSubscribe,omitempty"`
 }
 
 // AuthenticationInfo provides details for authenticating push notification requests.
@@ -182,6 +521,46 @@ type JSONRPCRequest struct {
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"` // Use RawMessage to delay parsing
 	ID      interface{}     `json:"id,omitempty"`     // Request ID (string, number, or null)
+
+	hasID bool // Whether the id member was present when decoded
+}
+
+// UnmarshalJSON implements json.Unmarshaler, recording whether the id member was present
+// so that an explicit null id can be distinguished from a notification.
+func (r *JSONRPCRequest) UnmarshalJSON(data []byte) error {
+	type request JSONRPCRequest // Avoid recursion
+	var raw struct {
+		request
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*r = JSONRPCRequest(raw.request)
+	r.ID = nil
+	r.hasID = raw.ID != nil
+	if r.hasID {
+		if err := json.Unmarshal(raw.ID, &r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsNotification reports whether the request is a notification, i.e. it has no id member.
+func (r *JSONRPCRequest) IsNotification() bool {
+	return !r.hasID && r.ID == nil
+}
+
+// IsValidID reports whether id is a valid JSON-RPC request id: a string, a number, or null.
+func IsValidID(id interface{}) bool {
+	switch id.(type) {
+	case nil, string, float64, json.Number, int, int64:
+		return true
+	default:
+		return false
+	}
 }
 
 // JSONRPCResponse represents a JSON-RPC response object.
@@ -201,9 +580,73 @@ type TaskSendParams struct {
 	SkillID     *string     `json:"skillId,omitempty"`
 	Message     Message     `json:"message"`
 	InputSchema interface{} `json:"inputSchema,omitempty"` // Optional override/validation
+	DryRun      *bool       `json:"dryRun,omitempty"`      // Validate the request without running the task
+	Metadata    interface{} `json:"metadata,omitempty"`    // Arbitrary request metadata passed to the task handler
+	// IdempotencyKey identifies retries of the same request; a repeat returns the original task
+	IdempotencyKey *string `json:"idempotencyKey,omitempty"`
+	// AcceptedOutputModes are the output MIME types the client can handle, most preferred first
+	// (e.g. "text/plain", "image/*"). The server rejects the task if it supports none of them.
+	AcceptedOutputModes []string `json:"acceptedOutputModes,omitempty"`
+	// Timeout is the maximum time in seconds the task may run before it is failed. It cannot
+	// extend the server's own task timeout.
+	Timeout *float64 `json:"timeout,omitempty"`
+	// Mode controls whether a task is created or resumed (empty = resume if TaskID is set,
+	// otherwise create)
+	Mode TaskSendMode `json:"mode,omitempty"`
 	// Add other params like stream preference if needed
 }
 
+// TaskSendMode controls whether a tasks/send request creates a new task or resumes one.
+type TaskSendMode string
+
+const (
+	// TaskSendModeCreate always creates a new task. TaskID must not be set.
+	TaskSendModeCreate TaskSendMode = "create"
+	// TaskSendModeResume sends the message to the existing task given by TaskID.
+	TaskSendModeResume TaskSendMode = "resume"
+	// TaskSendModeCreateIfAbsent returns the session's active task, one that has not reached
+	// a final state, if it has one, and otherwise creates a new task. SessionID is required
+	// and TaskID must not be set. The message is not sent to an existing task.
+	TaskSendModeCreateIfAbsent TaskSendMode = "create-if-absent"
+)
+
+// UnmarshalJSON implements json.Unmarshaler. Errors in the message are reported with their
+// path from the params (e.g. "message.parts[0].text"), as FieldErrors.
+func (p *TaskSendParams) UnmarshalJSON(data []byte) error {
+	type taskSendParams TaskSendParams // Avoid recursion
+	var raw struct {
+		taskSendParams
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = TaskSendParams(raw.taskSendParams)
+	p.Message = Message{}
+	if len(raw.Message) > 0 && string(raw.Message) != "null" {
+		if err := json.Unmarshal(raw.Message, &p.Message); err != nil {
+			return fieldError("message", err)
+		}
+	}
+	return nil
+}
+
+// RequestedSkillID returns the skill a task is sent to: skillID if it is set, otherwise the
+// "skillId" metadata of the message, or an empty string if neither is. Servers use it
+// wherever tasks are routed or limited by skill, so both see the same skill.
+func RequestedSkillID(skillID string, message Message) string {
+	if skillID != "" {
+		return skillID
+	}
+	if metadata, ok := message.Metadata.(map[string]interface{}); ok {
+		if skillID, ok := metadata["skillId"].(string); ok {
+			return skillID
+		}
+	}
+	return ""
+}
+
 // TaskQueryParams represents the parameters for the tasks/get method.
 type TaskQueryParams struct {
 	TaskID string `json:"taskId"`
@@ -214,13 +657,113 @@ type TaskIdParams struct {
 	TaskID string `json:"taskId"`
 }
 
+// SessionIdParams represents parameters identifying a session.
+type SessionIdParams struct {
+	SessionID string `json:"sessionId"`
+}
+
+// CancelSessionResult represents the result of the sessions/cancel method.
+type CancelSessionResult struct {
+	Tasks []Task `json:"tasks"` // The tasks that were cancelled
+}
+
+// ListTasksParams represents the parameters of the tasks/list and tasks/listSubscribe methods.
+type ListTasksParams struct {
+	PageSize int    `json:"pageSize,omitempty"` // Maximum number of tasks per page (0 = server default)
+	Cursor   string `json:"cursor,omitempty"`   // Opaque cursor from a previous page; empty for the first page
+}
+
+// ListTasksResult represents a page of tasks, in the order they were created.
+type ListTasksResult struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"nextCursor,omitempty"` // Cursor for the next page; empty if this is the last page
+}
+
 // TaskPushNotificationConfigParams represents parameters for setting push config.
 type TaskPushNotificationConfigParams struct {
-	TaskID           string              `json:"taskId"`
-	URL              string              `json:"url"`
-	Authentication   *AuthenticationInfo `json:"authentication,omitempty"`
-	IncludeTaskData  *bool               `json:"includeTaskData,omitempty"`
-	IncludeArtifacts *bool               `json:"includeArtifacts,omitempty"`
+	TaskID            string              `json:"taskId"`
+	URL               string              `json:"url"`
+	Authentication    *AuthenticationInfo `json:"authentication,omitempty"`
+	IncludeTaskData   *bool               `json:"includeTaskData,omitempty"`
+	IncludeArtifacts  *bool               `json:"includeArtifacts,omitempty"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	ReplayOnSubscribe *bool               `json:"replayOnSubscribe,omitempty"`
+}
+
+// InitUploadParams represents the parameters for the files/initUpload method, which starts
+// a chunked upload of a file that tasks can then reference by URI.
+type InitUploadParams struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType,omitempty"`
+	Size     int64  `json:"size,omitempty"` // Total size in bytes, checked on completion if set
+}
+
+// UploadChunkParams represents the parameters for the files/uploadChunk method. Chunks are
+// numbered from 0 and must be sent in order; resending a chunk replaces it.
+type UploadChunkParams struct {
+	UploadID string `json:"uploadId"`
+	Index    int    `json:"index"`
+	Data     string `json:"data"` // Base64-encoded chunk content
+}
+
+// CompleteUploadParams represents the parameters for the files/completeUpload method.
+// Its result is a FilePart referencing the uploaded file by URI.
+type CompleteUploadParams struct {
+	UploadID string `json:"uploadId"`
+}
+
+// UploadStatus describes the progress of a chunked upload. It is the result of the
+// files/initUpload and files/uploadChunk methods.
+type UploadStatus struct {
+	UploadID      string `json:"uploadId"`
+	NextChunk     int    `json:"nextChunk"`     // Index of the next chunk the server expects
+	ReceivedBytes int64  `json:"receivedBytes"` // Total size of the chunks received so far
+}
+
+// SkillFilter represents the parameters for the skills/list method.
+// An empty filter matches all skills.
+type SkillFilter struct {
+	Tag  string `json:"tag,omitempty"`  // Only match skills with this tag (case-insensitive)
+	Name string `json:"name,omitempty"` // Only match skills whose name contains this substring (case-insensitive)
+}
+
+// Matches reports whether the skill satisfies the filter.
+func (f SkillFilter) Matches(skill AgentSkill) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(skill.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.Tag != "" {
+		for _, tag := range skill.Tags {
+			if strings.EqualFold(tag, f.Tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// FilterSkills returns the skills that satisfy the filter, preserving their order.
+func FilterSkills(skills []AgentSkill, filter SkillFilter) []AgentSkill {
+	matched := make([]AgentSkill, 0, len(skills))
+	for _, skill := range skills {
+		if filter.Matches(skill) {
+			matched = append(matched, skill)
+		}
+	}
+	return matched
+}
+
+// SkillsListResult represents the result of the skills/list method.
+type SkillsListResult struct {
+	Skills []AgentSkill `json:"skills"`
+}
+
+// AgentCapabilitiesResult represents the result of the agent/getCapabilities method.
+type AgentCapabilitiesResult struct {
+	A2AVersion   string            `json:"a2aVersion"`
+	AgentID      string            `json:"agentId"`
+	Capabilities AgentCapabilities `json:"capabilities"`
 }
 
 // --- SSE Event Structures ---
@@ -246,5 +789,3 @@ type TaskArtifactUpdateEvent struct {
 }
 
 // TODO: Add specific request/response structs for each A2A method (e.g., SendTaskRequest, SendTaskResponse).
-// TODO: Refine Part handling (e.g., custom marshalling/unmarshalling if needed).
-// TODO: Add error constants mapping to JSON-RPC codes (in errors.go).
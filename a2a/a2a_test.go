@@ -0,0 +1,239 @@
+package a2a
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRPCRequest_IsNotification(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{body: `{"jsonrpc":"2.0","method":"tasks/cancel"}`, want: true},
+		{body: `{"jsonrpc":"2.0","method":"tasks/cancel","id":null}`, want: false},
+		{body: `{"jsonrpc":"2.0","method":"tasks/cancel","id":"1"}`, want: false},
+		{body: `{"jsonrpc":"2.0","method":"tasks/cancel","id":0}`, want: false},
+	}
+
+	for _, tt := range tests {
+		var request JSONRPCRequest
+		if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+			t.Fatalf("Failed to decode %s: %v", tt.body, err)
+		}
+		if got := request.IsNotification(); got != tt.want {
+			t.Errorf("IsNotification() for %s = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestPart_Inspection(t *testing.T) {
+	longText := strings.Repeat("a", maxSummaryTextLength+10)
+
+	tests := []struct {
+		name        string
+		part        Part
+		wantType    string
+		wantSize    int
+		wantSummary string
+	}{
+		{
+			name:        "text",
+			part:        TextPart{Type: "text", Text: "hello"},
+			wantType:    "text",
+			wantSize:    5,
+			wantSummary: "Text: hello",
+		},
+		{
+			name:        "long text",
+			part:        TextPart{Type: "text", Text: longText},
+			wantType:    "text",
+			wantSize:    len(longText),
+			wantSummary: "Text: " + longText[:maxSummaryTextLength] + "...",
+		},
+		{
+			name: "base64 file",
+			part: FilePart{
+				Type:     "file",
+				Filename: "hello.txt",
+				MimeType: "text/plain",
+				Content: &FileContent{
+					Encoding: "base64",
+					Data:     base64.StdEncoding.EncodeToString([]byte("hello world")),
+				},
+			},
+			wantType:    "file",
+			wantSize:    11,
+			wantSummary: "File: hello.txt (text/plain, 11 bytes)",
+		},
+		{
+			name: "base64 file without padding",
+			part: FilePart{
+				Type:     "file",
+				Filename: "abc.bin",
+				MimeType: "application/octet-stream",
+				Content:  &FileContent{Encoding: "base64", Data: "YWJj"},
+			},
+			wantType:    "file",
+			wantSize:    3,
+			wantSummary: "File: abc.bin (application/octet-stream, 3 bytes)",
+		},
+		{
+			name: "file by URI",
+			part: FilePart{
+				Type:     "file",
+				Filename: "remote.pdf",
+				MimeType: "application/pdf",
+			},
+			wantType:    "file",
+			wantSize:    0,
+			wantSummary: "File: remote.pdf (application/pdf)",
+		},
+		{
+			name: "data",
+			part: DataPart{
+				Type:     "data",
+				MimeType: "application/json",
+				Data:     map[string]interface{}{"key": "value"},
+			},
+			wantType:    "data",
+			wantSize:    len(`{"key":"value"}`),
+			wantSummary: "Data: application/json (15 bytes)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.part.PartType(); got != tt.wantType {
+				t.Errorf("PartType() = %q, want %q", got, tt.wantType)
+			}
+			if got := tt.part.ContentSize(); got != tt.wantSize {
+				t.Errorf("ContentSize() = %d, want %d", got, tt.wantSize)
+			}
+			if got := tt.part.Summary(); got != tt.wantSummary {
+				t.Errorf("Summary() = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestAgentCard_Validate(t *testing.T) {
+	validCard := func() *AgentCard {
+		homepage := "https://example.com"
+		return &AgentCard{
+			A2AVersion:  "1.0",
+			ID:          "test-agent",
+			Name:        "Test Agent",
+			HomepageURI: &homepage,
+			Skills: []AgentSkill{
+				{ID: "echo", Name: "Echo"},
+				{ID: "reverse", Name: "Reverse"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(card *AgentCard)
+		wantErr string
+	}{
+		{
+			name:   "valid card",
+			modify: func(card *AgentCard) {},
+		},
+		{
+			name: "duplicate skill IDs",
+			modify: func(card *AgentCard) {
+				card.Skills = append(card.Skills, AgentSkill{ID: "echo", Name: "Echo again"})
+			},
+			wantErr: `skills[2]: duplicate skill id "echo"`,
+		},
+		{
+			name:    "missing version",
+			modify:  func(card *AgentCard) { card.A2AVersion = "" },
+			wantErr: "a2aVersion is required",
+		},
+		{
+			name:    "invalid version",
+			modify:  func(card *AgentCard) { card.A2AVersion = "v1" },
+			wantErr: `a2aVersion "v1" must have the form major.minor[.patch]`,
+		},
+		{
+			name: "relative URI",
+			modify: func(card *AgentCard) {
+				docs := "/docs"
+				card.DocumentationURI = &docs
+			},
+			wantErr: `documentationUri "/docs" is not a valid absolute URL`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := validCard()
+			tt.modify(card)
+			err := card.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAgentCard_SkillExample(t *testing.T) {
+	card := &AgentCard{
+		Skills: []AgentSkill{
+			{ID: "echo", Name: "Echo"},
+			{ID: "search", Name: "Search", Examples: []SkillExample{
+				{Name: "Recent news", Input: map[string]interface{}{"query": "go 1.24", "sort": "date"}},
+				{Name: "Plain text", Input: "golang generics"},
+			}},
+		},
+	}
+
+	example, ok := card.SkillExample("search")
+	if !ok || example.Name != "Recent news" {
+		t.Errorf("Expected the first example of search, got %+v, %v", example, ok)
+	}
+	if _, ok := card.SkillExample("echo"); ok {
+		t.Error("Expected no example for a skill without examples")
+	}
+	if _, ok := card.SkillExample("missing"); ok {
+		t.Error("Expected no example for an unknown skill")
+	}
+
+	// Examples round trip through JSON with the card
+	data, err := json.Marshal(card)
+	if err != nil {
+		t.Fatalf("Failed to marshal card: %v", err)
+	}
+	var decoded AgentCard
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal card: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Skills[1].Examples, card.Skills[1].Examples) {
+		t.Errorf("Expected examples %+v after a round trip, got %+v", card.Skills[1].Examples, decoded.Skills[1].Examples)
+	}
+}
+
+func TestRequestedSkillID(t *testing.T) {
+	message := Message{Role: RoleUser, Metadata: map[string]interface{}{"skillId": "from-metadata"}}
+	if got := RequestedSkillID("explicit", message); got != "explicit" {
+		t.Errorf("Expected the explicit skill ID, got %q", got)
+	}
+	if got := RequestedSkillID("", message); got != "from-metadata" {
+		t.Errorf("Expected the skill ID from the metadata, got %q", got)
+	}
+	if got := RequestedSkillID("", Message{Role: RoleUser}); got != "" {
+		t.Errorf("Expected no skill ID, got %q", got)
+	}
+}
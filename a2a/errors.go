@@ -1,7 +1,12 @@
 package a2a
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 )
 
 // JSONRPCError represents a JSON-RPC error object.
@@ -25,16 +30,19 @@ const (
 	// -32000 to -32099: Server error (Implementation-defined)
 
 	// A2A Specific Error Codes (within server error range)
-	CodeTaskNotFound           = -32000
-	CodeSkillNotFound          = -32001
-	CodeSessionNotFound        = -32002 // If sessions are strictly enforced
-	CodeAuthenticationRequired = -32010
-	CodeAuthenticationFailed   = -32011
-	CodeOperationNotSupported  = -32020 // e.g., streaming requested but not supported
-	CodeTaskCancelled          = -32030 // Explicitly cancelled by client
-	CodeTaskFailed             = -32031 // Task execution failed internally
-	CodePushNotificationFailed = -32040
-	CodeRateLimitExceeded      = -32050
+	CodeTaskNotFound            = -32000
+	CodeSkillNotFound           = -32001
+	CodeSessionNotFound         = -32002 // If sessions are strictly enforced
+	CodeContentTypeNotSupported = -32005 // None of the requested output modes are supported (the spec's "Incompatible content types")
+	CodeAuthenticationRequired  = -32010
+	CodeAuthenticationFailed    = -32011
+	CodeOperationNotSupported   = -32020 // e.g., streaming requested but not supported
+	CodeTaskCancelled           = -32030 // Explicitly cancelled by client
+	CodeTaskFailed              = -32031 // Task execution failed internally
+	CodePushNotificationFailed  = -32040
+	CodeRateLimitExceeded       = -32050
+	CodeUploadNotFound          = -32060
+	CodeUploadChunkOutOfOrder   = -32061 // The error data is the upload's UploadStatus
 	// Add more as needed
 )
 
@@ -59,6 +67,35 @@ func (e *Error) Unwrap() error {
 	return e.cause
 }
 
+// ToError converts a JSONRPCError, such as one received in a response, to an A2A Error.
+func (e *JSONRPCError) ToError() *Error {
+	return &Error{Code: e.Code, Message: e.Message, Data: e.Data}
+}
+
+// ValidationErrors returns the invalid fields reported by an error from ErrValidation, or
+// nil if it has none. The fields are found whether the data is a ValidationErrorData or
+// was decoded from a JSON-RPC response.
+func (e *Error) ValidationErrors() []FieldError {
+	switch data := e.Data.(type) {
+	case nil:
+		return nil
+	case ValidationErrorData:
+		return data.Fields
+	case *ValidationErrorData:
+		return data.Fields
+	default:
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil
+		}
+		var decoded ValidationErrorData
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			return nil
+		}
+		return decoded.Fields
+	}
+}
+
 // ToJSONRPCError converts an A2A Error to a JSONRPCError struct.
 func (e *Error) ToJSONRPCError() *JSONRPCError {
 	return &JSONRPCError{
@@ -68,6 +105,27 @@ func (e *Error) ToJSONRPCError() *JSONRPCError {
 	}
 }
 
+// HTTPStatus returns the HTTP status code used when the error is returned over HTTP.
+// Most JSON-RPC errors are returned with 200 OK; errors that map naturally to an
+// HTTP status (authentication, unknown methods, malformed requests, rate limiting,
+// unsupported capabilities) use it.
+func (e *Error) HTTPStatus() int {
+	switch e.Code {
+	case CodeAuthenticationRequired, CodeAuthenticationFailed:
+		return http.StatusUnauthorized
+	case CodeMethodNotFound:
+		return http.StatusNotFound
+	case CodeInvalidRequest, CodeInvalidParams:
+		return http.StatusBadRequest
+	case CodeRateLimitExceeded:
+		return http.StatusTooManyRequests
+	case CodeOperationNotSupported:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusOK
+	}
+}
+
 // NewError creates a new A2A Error.
 func NewError(code int, message string) *Error {
 	return &Error{Code: code, Message: message}
@@ -90,10 +148,12 @@ func WrapErrorf(cause error, code int, format string, args ...interface{}) *Erro
 
 // --- Predefined Errors ---
 
+// ErrParseError returns an error for a request body that is not valid JSON.
 func ErrParseError(cause error) *Error {
 	return WrapError(cause, CodeParseError, "Parse error")
 }
 
+// ErrInvalidRequest returns an error for a request that is not a valid JSON-RPC request.
 func ErrInvalidRequest(message string) *Error {
 	if message == "" {
 		message = "Invalid Request"
@@ -101,10 +161,12 @@ func ErrInvalidRequest(message string) *Error {
 	return NewError(CodeInvalidRequest, message)
 }
 
+// ErrMethodNotFound returns an error for an unknown method.
 func ErrMethodNotFound(method string) *Error {
 	return NewErrorf(CodeMethodNotFound, "Method not found: %s", method)
 }
 
+// ErrInvalidParams returns an error for invalid method parameters.
 func ErrInvalidParams(message string) *Error {
 	if message == "" {
 		message = "Invalid params"
@@ -112,22 +174,116 @@ func ErrInvalidParams(message string) *Error {
 	return NewError(CodeInvalidParams, message)
 }
 
+// FieldError describes why a field of a request's params is invalid.
+type FieldError struct {
+	Field  string `json:"field"`  // Path of the field, e.g. "message.parts[0].text" ("" = the params as a whole)
+	Reason string `json:"reason"` // Why the field is invalid
+}
+
+// Error implements the standard Go error interface.
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return e.Field + ": " + e.Reason
+}
+
+// ValidationErrorData is the Data of an error from ErrValidation.
+type ValidationErrorData struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// ErrValidation returns an invalid params error for params that failed validation. The
+// invalid fields are included as the error data (a ValidationErrorData), so clients can
+// tell which fields to fix.
+func ErrValidation(fields ...FieldError) *Error {
+	reasons := make([]string, len(fields))
+	for i := range fields {
+		reasons[i] = fields[i].Error()
+	}
+	err := ErrInvalidParams("Invalid params: " + strings.Join(reasons, "; "))
+	err.Data = ValidationErrorData{Fields: fields}
+	return err
+}
+
+// ErrInvalidParamsJSON returns a validation error for params that could not be decoded,
+// identifying the offending field where the decoding error does.
+func ErrInvalidParamsJSON(err error) *Error {
+	return ErrValidation(*fieldError("", err))
+}
+
+// fieldError converts an error decoding the field at path into a FieldError, joining path
+// with the path of a nested field the error identifies.
+func fieldError(path string, err error) *FieldError {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return &FieldError{Field: joinFieldPath(path, fe.Field), Reason: fe.Reason}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &FieldError{
+			Field:  joinFieldPath(path, typeErr.Field),
+			Reason: fmt.Sprintf("expected %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value),
+		}
+	}
+	return &FieldError{Field: path, Reason: err.Error()}
+}
+
+// jsonTypeName returns the name of the JSON type a Go type is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.String()
+	}
+}
+
+// joinFieldPath appends a nested field path to path. Elements starting with an index are
+// not separated by a dot.
+func joinFieldPath(path, field string) string {
+	if path == "" || field == "" || strings.HasPrefix(field, "[") {
+		return path + field
+	}
+	return path + "." + field
+}
+
+// ErrInternalError returns an error for an unexpected internal failure.
 func ErrInternalError(cause error) *Error {
 	return WrapError(cause, CodeInternalError, "Internal error")
 }
 
+// ErrTaskNotFound returns an error for an unknown task.
 func ErrTaskNotFound(taskId string) *Error {
 	return NewErrorf(CodeTaskNotFound, "Task not found: %s", taskId)
 }
 
+// ErrSkillNotFound returns an error for an unknown skill.
 func ErrSkillNotFound(skillId string) *Error {
 	return NewErrorf(CodeSkillNotFound, "Skill not found: %s", skillId)
 }
 
+// ErrSessionNotFound returns an error for an unknown session.
+func ErrSessionNotFound(sessionId string) *Error {
+	return NewErrorf(CodeSessionNotFound, "Session not found: %s", sessionId)
+}
+
+// ErrAuthenticationRequired returns an error for a request without credentials.
 func ErrAuthenticationRequired() *Error {
 	return NewError(CodeAuthenticationRequired, "Authentication required")
 }
 
+// ErrAuthenticationFailed returns an error for a request with invalid credentials.
 func ErrAuthenticationFailed(message string) *Error {
 	if message == "" {
 		message = "Authentication failed"
@@ -135,18 +291,51 @@ func ErrAuthenticationFailed(message string) *Error {
 	return NewError(CodeAuthenticationFailed, message)
 }
 
+// ErrOperationNotSupported returns an error for an operation the agent does not support,
+// such as one requiring a capability its agent card does not advertise (e.g., streaming or
+// push notifications).
 func ErrOperationNotSupported(operation string) *Error {
 	return NewErrorf(CodeOperationNotSupported, "Operation not supported: %s", operation)
 }
 
+// ErrContentTypeNotSupported returns an error for a request that accepts none of the
+// output modes the agent supports. The supported modes are included as the error data.
+func ErrContentTypeNotSupported(accepted, supported []string) *Error {
+	err := NewErrorf(CodeContentTypeNotSupported, "Content type not supported: the agent cannot produce any of %s", strings.Join(accepted, ", "))
+	err.Data = map[string]interface{}{"supportedOutputModes": supported}
+	return err
+}
+
+// ErrTaskCancelled returns an error for a task that was cancelled.
 func ErrTaskCancelled(taskId string) *Error {
 	return NewErrorf(CodeTaskCancelled, "Task cancelled: %s", taskId)
 }
 
+// ErrTaskFailed returns an error for a task whose execution failed.
 func ErrTaskFailed(taskId string, cause error) *Error {
 	return WrapErrorf(cause, CodeTaskFailed, "Task failed: %s", taskId)
 }
 
+// ErrPushNotificationFailed returns an error for a push notification that could not be delivered.
 func ErrPushNotificationFailed(taskId string, cause error) *Error {
 	return WrapErrorf(cause, CodePushNotificationFailed, "Push notification failed for task: %s", taskId)
 }
+
+// ErrRateLimitExceeded returns an error for a request rejected by rate limiting.
+func ErrRateLimitExceeded() *Error {
+	return NewError(CodeRateLimitExceeded, "Rate limit exceeded")
+}
+
+// ErrUploadNotFound returns an error for an unknown or expired upload.
+func ErrUploadNotFound(uploadID string) *Error {
+	return NewErrorf(CodeUploadNotFound, "Upload not found: %s", uploadID)
+}
+
+// ErrUploadChunkOutOfOrder returns an error for a chunk sent before an earlier one was
+// received. The upload's status is included as the error data, so the client can resume
+// from its NextChunk.
+func ErrUploadChunkOutOfOrder(index int, status UploadStatus) *Error {
+	err := NewErrorf(CodeUploadChunkOutOfOrder, "Upload chunk out of order: got chunk %d, expected %d", index, status.NextChunk)
+	err.Data = status
+	return err
+}
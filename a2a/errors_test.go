@@ -0,0 +1,131 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestErrorConstructors(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name       string
+		err        *Error
+		wantCode   int
+		wantStatus int
+	}{
+		{name: "parse error", err: ErrParseError(cause), wantCode: -32700, wantStatus: http.StatusOK},
+		{name: "invalid request", err: ErrInvalidRequest(""), wantCode: -32600, wantStatus: http.StatusBadRequest},
+		{name: "method not found", err: ErrMethodNotFound("foo"), wantCode: -32601, wantStatus: http.StatusNotFound},
+		{name: "invalid params", err: ErrInvalidParams(""), wantCode: -32602, wantStatus: http.StatusBadRequest},
+		{name: "internal error", err: ErrInternalError(cause), wantCode: -32603, wantStatus: http.StatusOK},
+		{name: "task not found", err: ErrTaskNotFound("task-1"), wantCode: CodeTaskNotFound, wantStatus: http.StatusOK},
+		{name: "skill not found", err: ErrSkillNotFound("skill-1"), wantCode: CodeSkillNotFound, wantStatus: http.StatusOK},
+		{name: "session not found", err: ErrSessionNotFound("session-1"), wantCode: CodeSessionNotFound, wantStatus: http.StatusOK},
+		{name: "authentication required", err: ErrAuthenticationRequired(), wantCode: CodeAuthenticationRequired, wantStatus: http.StatusUnauthorized},
+		{name: "authentication failed", err: ErrAuthenticationFailed(""), wantCode: CodeAuthenticationFailed, wantStatus: http.StatusUnauthorized},
+		{name: "operation not supported", err: ErrOperationNotSupported("streaming"), wantCode: CodeOperationNotSupported, wantStatus: http.StatusNotImplemented},
+		{name: "content type not supported", err: ErrContentTypeNotSupported([]string{"image/png"}, []string{"text/plain"}), wantCode: -32005, wantStatus: http.StatusOK},
+		{name: "task cancelled", err: ErrTaskCancelled("task-1"), wantCode: CodeTaskCancelled, wantStatus: http.StatusOK},
+		{name: "task failed", err: ErrTaskFailed("task-1", cause), wantCode: CodeTaskFailed, wantStatus: http.StatusOK},
+		{name: "push notification failed", err: ErrPushNotificationFailed("task-1", cause), wantCode: CodePushNotificationFailed, wantStatus: http.StatusOK},
+		{name: "rate limit exceeded", err: ErrRateLimitExceeded(), wantCode: CodeRateLimitExceeded, wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", tt.err.Code, tt.wantCode)
+			}
+			if tt.err.Message == "" {
+				t.Error("Expected a non-empty message")
+			}
+			if status := tt.err.HTTPStatus(); status != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", status, tt.wantStatus)
+			}
+
+			rpcErr := tt.err.ToJSONRPCError()
+			if rpcErr.Code != tt.err.Code || rpcErr.Message != tt.err.Message {
+				t.Errorf("ToJSONRPCError() = %+v, want code %d and message %q", rpcErr, tt.err.Code, tt.err.Message)
+			}
+		})
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	cause := errors.New("boom")
+	err := ErrTaskFailed("task-1", cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("Expected error to wrap its cause")
+	}
+
+	var a2aErr *Error
+	if !errors.As(error(err), &a2aErr) || a2aErr.Code != CodeTaskFailed {
+		t.Error("Expected errors.As to find the A2A error")
+	}
+}
+
+func TestErrInvalidParamsJSON_FieldPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    string
+		wantField string
+	}{
+		{name: "top-level field", params: `{"taskId":5,"message":{"role":"user","parts":[]}}`, wantField: "taskId"},
+		{name: "message field", params: `{"message":{"role":5,"parts":[]}}`, wantField: "message.role"},
+		{name: "part field", params: `{"message":{"role":"user","parts":[{"type":"text","text":5}]}}`, wantField: "message.parts[0].text"},
+		{name: "unknown part type", params: `{"message":{"role":"user","parts":[{"type":"text","text":"hi"},{"type":"bogus"}]}}`, wantField: "message.parts[1].type"},
+		{name: "malformed JSON", params: `{"message":`, wantField: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params TaskSendParams
+			decodeErr := json.Unmarshal([]byte(tt.params), &params)
+			if decodeErr == nil {
+				t.Fatal("Expected params to fail to decode")
+			}
+
+			err := ErrInvalidParamsJSON(decodeErr)
+			if err.Code != CodeInvalidParams {
+				t.Errorf("Code = %d, want %d", err.Code, CodeInvalidParams)
+			}
+			fields := err.ValidationErrors()
+			if len(fields) != 1 || fields[0].Field != tt.wantField || fields[0].Reason == "" {
+				t.Errorf("ValidationErrors() = %+v, want one error for field %q", fields, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestErrValidation_DataRoundTrip(t *testing.T) {
+	err := ErrValidation(
+		FieldError{Field: "message.parts", Reason: "must contain at least one part"},
+		FieldError{Field: "sessionId", Reason: "is required"},
+	)
+
+	// Send the error over JSON-RPC and convert it back, as a client does
+	encoded, marshalErr := json.Marshal(err.ToJSONRPCError())
+	if marshalErr != nil {
+		t.Fatalf("Failed to marshal error: %v", marshalErr)
+	}
+	var rpcErr JSONRPCError
+	if err := json.Unmarshal(encoded, &rpcErr); err != nil {
+		t.Fatalf("Failed to unmarshal error: %v", err)
+	}
+	received := rpcErr.ToError()
+
+	if received.Code != CodeInvalidParams || received.Message != err.Message {
+		t.Errorf("Expected code %d and message %q, got %+v", CodeInvalidParams, err.Message, received)
+	}
+	if got, want := received.ValidationErrors(), err.ValidationErrors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidationErrors() = %+v, want %+v", got, want)
+	}
+	if fields := ErrInvalidParams("").ValidationErrors(); fields != nil {
+		t.Errorf("Expected no validation errors for a plain invalid params error, got %+v", fields)
+	}
+}
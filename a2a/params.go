@@ -0,0 +1,135 @@
+package a2a
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalParams decodes the params of a JSON-RPC request into v, like json.Unmarshal,
+// but also rejects fields that v has no place for, so misspelt fields are reported instead
+// of being silently dropped. Errors identify the offending field as a FieldError, which
+// ErrInvalidParamsJSON includes in its error data.
+//
+// Fields are checked through nested structs, slices and maps, including those decoded by
+// custom unmarshalers. Values decoded into interfaces, such as message parts and metadata,
+// are not checked.
+func UnmarshalParams(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if fe := unknownField(data, reflect.TypeOf(v), ""); fe != nil {
+		return fe
+	}
+	return nil
+}
+
+// unknownField returns an error for the first field in data, a JSON value decoded into a
+// value of type t, that t has no place for, or nil if there is none.
+func unknownField(data []byte, t reflect.Type, path string) *FieldError {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil // Not an object, e.g. a time.Time
+		}
+
+		known := jsonFields(t)
+		for _, key := range sortedKeys(fields) {
+			field, ok := lookupJSONField(known, key)
+			if !ok {
+				return &FieldError{Field: joinFieldPath(path, key), Reason: "is not a known field"}
+			}
+			if fe := unknownField(fields[key], field.Type, joinFieldPath(path, key)); fe != nil {
+				return fe
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return nil
+		}
+		for i, elem := range elems {
+			if fe := unknownField(elem, t.Elem(), path+"["+strconv.Itoa(i)+"]"); fe != nil {
+				return fe
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		for _, key := range sortedKeys(values) {
+			if fe := unknownField(values[key], t.Elem(), joinFieldPath(path, key)); fe != nil {
+				return fe
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields returns the fields of a struct type by their JSON names, including the
+// fields of embedded structs without a JSON name.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for embeddedName, embedded := range jsonFields(ft) {
+				if _, shadowed := fields[embeddedName]; !shadowed {
+					fields[embeddedName] = embedded
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// lookupJSONField finds the field for a JSON key, matching names case-insensitively as
+// json.Unmarshal does.
+func lookupJSONField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if f, ok := fields[key]; ok {
+		return f, true
+	}
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// sortedKeys returns the keys of a decoded object in order, so the same field is reported
+// for the same params every time.
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package a2a
+
+import (
+	"testing"
+)
+
+func TestUnmarshalParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    string
+		into      interface{}
+		wantField string // "" = no error
+	}{
+		{
+			name:   "known fields",
+			params: `{"taskId":"t1","message":{"role":"user","parts":[{"type":"text","text":"hi"}]},"metadata":{"anything":1}}`,
+			into:   &TaskSendParams{},
+		},
+		{
+			name:   "field names match case-insensitively",
+			params: `{"TaskID":"t1"}`,
+			into:   &TaskIdParams{},
+		},
+		{
+			name:      "unknown top-level field",
+			params:    `{"taskId":"t1","callbackUrl":"http://example.com"}`,
+			into:      &TaskPushNotificationConfigParams{},
+			wantField: "callbackUrl",
+		},
+		{
+			name:      "unknown field in a custom-decoded message",
+			params:    `{"message":{"role":"user","parts":[],"sender":"me"}}`,
+			into:      &TaskSendParams{},
+			wantField: "message.sender",
+		},
+		{
+			name:      "unknown field in a nested struct",
+			params:    `{"taskId":"t1","url":"http://example.com","authentication":{"type":"bearer","token":"x"}}`,
+			into:      &TaskPushNotificationConfigParams{},
+			wantField: "authentication.token",
+		},
+		{
+			name:      "type errors take precedence",
+			params:    `{"taskId":1,"extra":true}`,
+			into:      &TaskIdParams{},
+			wantField: "taskId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UnmarshalParams([]byte(tt.params), tt.into)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("UnmarshalParams() error = %v", err)
+				}
+				return
+			}
+
+			fields := ErrInvalidParamsJSON(err).ValidationErrors()
+			if len(fields) != 1 || fields[0].Field != tt.wantField {
+				t.Errorf("Expected an error for %q, got %+v", tt.wantField, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package a2a_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// AudioPart is an application-defined part carrying an audio clip.
+type AudioPart struct {
+	a2a.BasePart
+	Type       string `json:"type"` // Always "audio"
+	MimeType   string `json:"mimeType"`
+	DurationMs int    `json:"durationMs"`
+	Data       string `json:"data"` // Base64-encoded audio
+}
+
+func (AudioPart) PartType() string   { return "audio" }
+func (p AudioPart) ContentSize() int { return len(p.Data) * 3 / 4 }
+func (p AudioPart) Summary() string {
+	return fmt.Sprintf("Audio: %s (%d ms)", p.MimeType, p.DurationMs)
+}
+
+// EmbeddingPart is an application-defined part with pointer receivers.
+type EmbeddingPart struct {
+	a2a.BasePart
+	Type   string    `json:"type"` // Always "embedding"
+	Vector []float64 `json:"vector"`
+}
+
+func (*EmbeddingPart) PartType() string   { return "embedding" }
+func (p *EmbeddingPart) ContentSize() int { return len(p.Vector) * 8 }
+func (p *EmbeddingPart) Summary() string {
+	return fmt.Sprintf("Embedding: %d dimensions", len(p.Vector))
+}
+
+func TestRegisterPartType(t *testing.T) {
+	a2a.RegisterPartType("audio", func() a2a.Part { return AudioPart{} })
+	a2a.RegisterPartType("embedding", func() a2a.Part { return &EmbeddingPart{} })
+
+	message := a2a.Message{
+		Role:      a2a.RoleUser,
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Parts: []a2a.Part{
+			a2a.TextPart{Type: "text", Text: "Transcribe this"},
+			AudioPart{Type: "audio", MimeType: "audio/ogg", DurationMs: 1500, Data: "T2dnUw=="},
+			&EmbeddingPart{Type: "embedding", Vector: []float64{0.1, 0.2, 0.3}},
+		},
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	var decoded a2a.Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, message) {
+		t.Errorf("Expected message to round-trip:\n got  %#v\n want %#v", decoded, message)
+	}
+	if summary := decoded.Parts[1].Summary(); summary != "Audio: audio/ogg (1500 ms)" {
+		t.Errorf("Unexpected summary for the custom part: %q", summary)
+	}
+}
+
+func TestUnmarshalPart_UnknownType(t *testing.T) {
+	if _, err := a2a.UnmarshalPart([]byte(`{"type":"hologram"}`)); err == nil {
+		t.Error("Expected an error for an unregistered part type")
+	}
+}
+
+// StickerPart is an application-defined part relying on BasePart for the Part methods.
+type StickerPart struct {
+	a2a.BasePart
+	Emoji string `json:"emoji"`
+}
+
+func TestBasePart_Defaults(t *testing.T) {
+	a2a.RegisterPartType("sticker", func() a2a.Part { return StickerPart{} })
+
+	part, err := a2a.UnmarshalPart([]byte(`{"type":"sticker","emoji":"🎉"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalPart failed: %v", err)
+	}
+	sticker, ok := part.(StickerPart)
+	if !ok {
+		t.Fatalf("Expected a StickerPart, got %T", part)
+	}
+	if sticker.Emoji != "🎉" || part.PartType() != "sticker" {
+		t.Errorf("Unexpected part: %+v", sticker)
+	}
+	if part.ContentSize() != 0 || part.Summary() != "Part: sticker" {
+		t.Errorf("Unexpected defaults: size %d, summary %q", part.ContentSize(), part.Summary())
+	}
+
+	data, err := json.Marshal(part)
+	if err != nil {
+		t.Fatalf("Failed to marshal part: %v", err)
+	}
+	if string(data) != `{"type":"sticker","emoji":"🎉"}` {
+		t.Errorf("Unexpected encoding: %s", data)
+	}
+}
+
+func TestRegisterPartType_BuiltinType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering the built-in text type to panic")
+		}
+	}()
+	a2a.RegisterPartType("text", func() a2a.Part { return StickerPart{} })
+}
@@ -0,0 +1,564 @@
+// Package a2atest provides a scriptable mock A2A server for testing A2A clients.
+//
+// The mock serves the JSON-RPC endpoint, the SSE streaming endpoint and the agent card
+// at the paths the client expects, so it can be used directly with client.WithBaseURL(mock.URL).
+// Every request is recorded and can be inspected or asserted on afterwards.
+package a2atest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// Handlers holds the scripted responses of a MockServer. Each field handles one
+// JSON-RPC method. Methods without a handler get a default response backed by the
+// mock's in-memory task store: sent tasks complete immediately and can be fetched,
+// listed, cancelled and given push notification configs.
+//
+// Returning an *a2a.Error from a handler sends that error to the client; any other
+// error is sent as an internal error.
+type Handlers struct {
+	AgentCard *a2a.AgentCard // Served at /.well-known/agent.json and used for agent/getCapabilities and skills/list
+
+	SendTask                   func(params *a2a.TaskSendParams) (*a2a.Task, error)
+	GetTask                    func(params *a2a.TaskQueryParams) (*a2a.Task, error)
+	CancelTask                 func(params *a2a.TaskIdParams) (*a2a.Task, error)
+	CancelSession              func(params *a2a.SessionIdParams) (*a2a.CancelSessionResult, error)
+	ListTasks                  func(params *a2a.ListTasksParams) (*a2a.ListTasksResult, error)
+	SetTaskPushNotification    func(params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error)
+	GetTaskPushNotification    func(params *a2a.TaskIdParams) (*a2a.PushNotificationConfig, error)
+	DeleteTaskPushNotification func(params *a2a.TaskIdParams) error
+
+	// SendTaskSubscribe and Resubscribe return the events streamed to the client.
+	// The stream is closed after the last event.
+	SendTaskSubscribe func(params *a2a.TaskSendParams) ([]Event, error)
+	Resubscribe       func(params *a2a.TaskIdParams) ([]Event, error)
+}
+
+// Event is a single event streamed by a MockServer. Exactly one of Status and Artifact is set.
+type Event struct {
+	Status   *a2a.TaskStatus
+	Artifact *a2a.Artifact
+}
+
+// StatusEvent returns a status update event with an optional agent text message.
+func StatusEvent(state a2a.TaskState, text string) Event {
+	status := &a2a.TaskStatus{
+		State:     state,
+		Timestamp: time.Now(),
+	}
+	if text != "" {
+		status.Message = &a2a.Message{
+			Role:      a2a.RoleAgent,
+			Timestamp: time.Now(),
+			Parts:     []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+		}
+	}
+	return Event{Status: status}
+}
+
+// ArtifactEvent returns an artifact update event carrying the given part.
+func ArtifactEvent(part a2a.Part) Event {
+	return Event{Artifact: &a2a.Artifact{
+		Timestamp: time.Now(),
+		Part:      part,
+	}}
+}
+
+// Request is a JSON-RPC request received by a MockServer.
+type Request struct {
+	Method string
+	Params json.RawMessage
+	Header http.Header
+}
+
+// DecodeParams unmarshals the request params into v.
+func (r Request) DecodeParams(v interface{}) error {
+	return json.Unmarshal(r.Params, v)
+}
+
+// MockServer is a mock A2A server. The embedded httptest.Server provides URL and Close.
+type MockServer struct {
+	*httptest.Server
+
+	handlers Handlers
+
+	mu          sync.Mutex
+	requests    []Request
+	tasks       map[string]*a2a.Task
+	pushConfigs map[string]*a2a.PushNotificationConfig
+	nextID      int
+}
+
+// NewMockServer starts a mock A2A server with the given handlers.
+// The caller should call Close when finished.
+func NewMockServer(handlers Handlers) *MockServer {
+	if handlers.AgentCard == nil {
+		handlers.AgentCard = &a2a.AgentCard{
+			A2AVersion:   "1.0",
+			ID:           "mock-agent",
+			Name:         "Mock Agent",
+			Capabilities: &a2a.AgentCapabilities{SupportsStreaming: true, SupportsPushNotification: true},
+		}
+	}
+
+	m := &MockServer{
+		handlers:    handlers,
+		tasks:       make(map[string]*a2a.Task),
+		pushConfigs: make(map[string]*a2a.PushNotificationConfig),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/agent.json", m.handleAgentCard)
+	mux.HandleFunc("/sse", m.handleStream)
+	mux.HandleFunc("/", m.handleJSONRPC)
+	m.Server = httptest.NewServer(mux)
+
+	return m
+}
+
+// Requests returns all JSON-RPC requests received so far, in order.
+func (m *MockServer) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := make([]Request, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
+
+// LastRequest returns the most recent request for the given method.
+func (m *MockServer) LastRequest(method string) (Request, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.requests) - 1; i >= 0; i-- {
+		if m.requests[i].Method == method {
+			return m.requests[i], true
+		}
+	}
+	return Request{}, false
+}
+
+// AssertLastParams fails the test unless the most recent request for the given method
+// had params equal to want. Params are compared by their JSON encoding, so want may be
+// a params struct (e.g., *a2a.TaskSendParams) or a map.
+func (m *MockServer) AssertLastParams(t testing.TB, method string, want interface{}) {
+	t.Helper()
+
+	request, ok := m.LastRequest(method)
+	if !ok {
+		t.Fatalf("No %s request was received", method)
+	}
+
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Failed to marshal expected params: %v", err)
+	}
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(request.Params, &gotValue); err != nil {
+		t.Fatalf("Failed to parse %s params: %v", method, err)
+	}
+	if err := json.Unmarshal(wantJSON, &wantValue); err != nil {
+		t.Fatalf("Failed to parse expected params: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("Unexpected %s params:\n got: %s\nwant: %s", method, request.Params, wantJSON)
+	}
+}
+
+// record reads a JSON-RPC request from r and records it.
+func (m *MockServer) record(r *http.Request) (*a2a.JSONRPCRequest, error) {
+	var request a2a.JSONRPCRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.requests = append(m.requests, Request{
+		Method: request.Method,
+		Params: request.Params,
+		Header: r.Header.Clone(),
+	})
+	m.mu.Unlock()
+
+	return &request, nil
+}
+
+// handleAgentCard serves the agent card.
+func (m *MockServer) handleAgentCard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.handlers.AgentCard)
+}
+
+// handleJSONRPC handles a non-streaming JSON-RPC request.
+func (m *MockServer) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
+	request, err := m.record(r)
+	if err != nil {
+		writeError(w, a2a.ErrParseError(err), nil)
+		return
+	}
+
+	result, err := m.dispatch(request)
+	if err != nil {
+		writeError(w, toA2AError(err), request.ID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  result,
+		ID:      request.ID,
+	})
+}
+
+// dispatch calls the handler for a non-streaming JSON-RPC method.
+func (m *MockServer) dispatch(request *a2a.JSONRPCRequest) (interface{}, error) {
+	switch request.Method {
+	case "tasks/send":
+		var params a2a.TaskSendParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.SendTask != nil {
+			return m.handlers.SendTask(&params)
+		}
+		return m.defaultSendTask(&params), nil
+	case "tasks/get":
+		var params a2a.TaskQueryParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.GetTask != nil {
+			return m.handlers.GetTask(&params)
+		}
+		return m.storedTask(params.TaskID)
+	case "tasks/cancel":
+		var params a2a.TaskIdParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.CancelTask != nil {
+			return m.handlers.CancelTask(&params)
+		}
+		return m.defaultCancelTask(params.TaskID)
+	case "sessions/cancel":
+		var params a2a.SessionIdParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.CancelSession != nil {
+			return m.handlers.CancelSession(&params)
+		}
+		return m.defaultCancelSession(params.SessionID)
+	case "tasks/list":
+		var params a2a.ListTasksParams
+		if len(request.Params) > 0 {
+			if err := decodeParams(request, &params); err != nil {
+				return nil, err
+			}
+		}
+		if m.handlers.ListTasks != nil {
+			return m.handlers.ListTasks(&params)
+		}
+		return m.defaultListTasks(), nil
+	case "tasks/pushNotification/set":
+		var params a2a.TaskPushNotificationConfigParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.SetTaskPushNotification != nil {
+			return m.handlers.SetTaskPushNotification(&params)
+		}
+		return m.defaultSetPushNotification(&params)
+	case "tasks/pushNotification/get":
+		var params a2a.TaskIdParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		if m.handlers.GetTaskPushNotification != nil {
+			return m.handlers.GetTaskPushNotification(&params)
+		}
+		return m.defaultGetPushNotification(params.TaskID)
+	case "tasks/pushNotification/delete":
+		var params a2a.TaskIdParams
+		if err := decodeParams(request, &params); err != nil {
+			return nil, err
+		}
+		var err error
+		if m.handlers.DeleteTaskPushNotification != nil {
+			err = m.handlers.DeleteTaskPushNotification(&params)
+		} else {
+			err = m.defaultDeletePushNotification(params.TaskID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return params, nil
+	case "agent/getCapabilities":
+		card := m.handlers.AgentCard
+		result := a2a.AgentCapabilitiesResult{A2AVersion: card.A2AVersion, AgentID: card.ID}
+		if card.Capabilities != nil {
+			result.Capabilities = *card.Capabilities
+		}
+		return result, nil
+	case "skills/list":
+		var filter a2a.SkillFilter
+		if len(request.Params) > 0 {
+			if err := decodeParams(request, &filter); err != nil {
+				return nil, err
+			}
+		}
+		return a2a.SkillsListResult{Skills: a2a.FilterSkills(m.handlers.AgentCard.Skills, filter)}, nil
+	default:
+		return nil, a2a.ErrMethodNotFound(request.Method)
+	}
+}
+
+// handleStream handles a tasks/sendSubscribe or tasks/resubscribe request by writing
+// the scripted events as SSE and closing the stream.
+func (m *MockServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	request, err := m.record(r)
+	if err != nil {
+		writeError(w, a2a.ErrParseError(err), nil)
+		return
+	}
+
+	var taskID string
+	var events []Event
+	switch request.Method {
+	case "tasks/sendSubscribe":
+		var params a2a.TaskSendParams
+		if err = decodeParams(request, &params); err != nil {
+			break
+		}
+		if m.handlers.SendTaskSubscribe != nil {
+			events, err = m.handlers.SendTaskSubscribe(&params)
+		} else {
+			events = []Event{StatusEvent(a2a.TaskStateWorking, ""), StatusEvent(a2a.TaskStateCompleted, "")}
+		}
+		taskID = m.defaultSendTask(&params).ID
+	case "tasks/resubscribe":
+		var params a2a.TaskIdParams
+		if err = decodeParams(request, &params); err != nil {
+			break
+		}
+		taskID = params.TaskID
+		if m.handlers.Resubscribe != nil {
+			events, err = m.handlers.Resubscribe(&params)
+		} else {
+			var task *a2a.Task
+			if task, err = m.storedTask(params.TaskID); err == nil {
+				events = []Event{{Status: &task.Status}}
+			}
+		}
+	default:
+		err = a2a.ErrMethodNotFound(request.Method)
+	}
+	if err != nil {
+		writeError(w, toA2AError(err), request.ID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	for i, event := range events {
+		var eventType string
+		var data interface{}
+		if event.Artifact != nil {
+			artifact := *event.Artifact
+			if artifact.TaskID == "" {
+				artifact.TaskID = taskID
+			}
+			eventType = "taskArtifactUpdate"
+			data = a2a.TaskArtifactUpdateEvent{TaskID: taskID, Artifact: artifact}
+		} else {
+			eventType = "taskStatusUpdate"
+			data = a2a.TaskStatusUpdateEvent{TaskID: taskID, Status: *event.Status}
+		}
+
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", eventType, i+1, jsonData)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// defaultSendTask stores a new task (or updates the one being resumed) and completes it.
+func (m *MockServer) defaultSendTask(params *a2a.TaskSendParams) *a2a.Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var task *a2a.Task
+	if params.TaskID != nil {
+		task = m.tasks[*params.TaskID]
+	}
+	if task == nil {
+		m.nextID++
+		id := fmt.Sprintf("task-%d", m.nextID)
+		if params.TaskID != nil {
+			id = *params.TaskID
+		}
+		task = &a2a.Task{ID: id, SessionID: params.SessionID, Artifacts: []a2a.Artifact{}}
+		m.tasks[id] = task
+	}
+	task.History = append(task.History, params.Message)
+	task.Status = a2a.TaskStatus{State: a2a.TaskStateCompleted, Timestamp: time.Now()}
+
+	taskCopy := *task
+	return &taskCopy
+}
+
+// storedTask returns a copy of a task in the store.
+func (m *MockServer) storedTask(taskID string) (*a2a.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return nil, a2a.ErrTaskNotFound(taskID)
+	}
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// defaultCancelTask marks a stored task as cancelled.
+func (m *MockServer) defaultCancelTask(taskID string) (*a2a.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return nil, a2a.ErrTaskNotFound(taskID)
+	}
+	task.Status = a2a.TaskStatus{State: a2a.TaskStateCancelled, Timestamp: time.Now()}
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// defaultCancelSession marks the stored tasks of a session that have not finished as cancelled.
+func (m *MockServer) defaultCancelSession(sessionID string) (*a2a.CancelSessionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &a2a.CancelSessionResult{Tasks: []a2a.Task{}}
+	for _, task := range m.tasks {
+		if task.SessionID == nil || *task.SessionID != sessionID {
+			continue
+		}
+		switch task.Status.State {
+		case a2a.TaskStateCompleted, a2a.TaskStateFailed, a2a.TaskStateCancelled:
+			continue
+		}
+		task.Status = a2a.TaskStatus{State: a2a.TaskStateCancelled, Timestamp: time.Now()}
+		result.Tasks = append(result.Tasks, *task)
+	}
+	return result, nil
+}
+
+// defaultListTasks returns every stored task in a single page, ordered by ID.
+func (m *MockServer) defaultListTasks() *a2a.ListTasksResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &a2a.ListTasksResult{Tasks: make([]a2a.Task, 0, len(m.tasks))}
+	for _, task := range m.tasks {
+		result.Tasks = append(result.Tasks, *task)
+	}
+	sort.Slice(result.Tasks, func(i, j int) bool { return result.Tasks[i].ID < result.Tasks[j].ID })
+	return result
+}
+
+// defaultSetPushNotification stores the push notification config of a stored task.
+func (m *MockServer) defaultSetPushNotification(params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tasks[params.TaskID]; !ok {
+		return nil, a2a.ErrTaskNotFound(params.TaskID)
+	}
+	config := &a2a.PushNotificationConfig{
+		TaskID:            params.TaskID,
+		URL:               params.URL,
+		Authentication:    params.Authentication,
+		IncludeTaskData:   params.IncludeTaskData,
+		IncludeArtifacts:  params.IncludeArtifacts,
+		Headers:           params.Headers,
+		ReplayOnSubscribe: params.ReplayOnSubscribe,
+	}
+	m.pushConfigs[params.TaskID] = config
+	return config, nil
+}
+
+// defaultGetPushNotification returns the push notification config of a stored task.
+func (m *MockServer) defaultGetPushNotification(taskID string) (*a2a.PushNotificationConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tasks[taskID]; !ok {
+		return nil, a2a.ErrTaskNotFound(taskID)
+	}
+	config, ok := m.pushConfigs[taskID]
+	if !ok {
+		return nil, a2a.NewErrorf(a2a.CodeInvalidParams, "No push notification configuration for task %s", taskID)
+	}
+	return config, nil
+}
+
+// defaultDeletePushNotification removes the push notification config of a stored task.
+func (m *MockServer) defaultDeletePushNotification(taskID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tasks[taskID]; !ok {
+		return a2a.ErrTaskNotFound(taskID)
+	}
+	delete(m.pushConfigs, taskID)
+	return nil
+}
+
+// decodeParams unmarshals the params of a request, returning an invalid params error on failure.
+func decodeParams(request *a2a.JSONRPCRequest, v interface{}) error {
+	if err := json.Unmarshal(request.Params, v); err != nil {
+		return a2a.ErrInvalidParamsJSON(err)
+	}
+	return nil
+}
+
+// toA2AError converts a handler error to an A2A error.
+func toA2AError(err error) *a2a.Error {
+	var a2aErr *a2a.Error
+	if errors.As(err, &a2aErr) {
+		return a2aErr
+	}
+	return a2a.ErrInternalError(err)
+}
+
+// writeError writes a JSON-RPC error response.
+func writeError(w http.ResponseWriter, err *a2a.Error, id interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.HTTPStatus())
+	json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error:   err.ToJSONRPCError(),
+		ID:      id,
+	})
+}
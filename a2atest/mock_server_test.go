@@ -0,0 +1,167 @@
+package a2atest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+)
+
+// newTestClient creates a client for the mock server.
+func newTestClient(t *testing.T, mock *MockServer) *client.Client {
+	t.Helper()
+
+	c, err := client.NewClient(client.WithBaseURL(mock.URL), client.WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+// newTextMessage creates a user message with a single text part.
+func newTextMessage(text string) a2a.Message {
+	return a2a.Message{
+		Role:  a2a.RoleUser,
+		Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+	}
+}
+
+func TestMockServer_DefaultTaskLifecycle(t *testing.T) {
+	mock := NewMockServer(Handlers{})
+	defer mock.Close()
+	c := newTestClient(t, mock)
+	ctx := context.Background()
+
+	params := &a2a.TaskSendParams{Message: newTextMessage("hello")}
+	created, err := c.SendTask(ctx, params)
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if created.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected completed task, got %s", created.Status.State)
+	}
+	mock.AssertLastParams(t, "tasks/send", params)
+
+	fetched, err := c.GetTask(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if fetched.ID != created.ID {
+		t.Errorf("Expected task %s, got %s", created.ID, fetched.ID)
+	}
+
+	if _, err := c.SetTaskPushNotification(ctx, &a2a.TaskPushNotificationConfigParams{
+		TaskID: created.ID,
+		URL:    "https://example.com/push",
+	}); err != nil {
+		t.Fatalf("SetTaskPushNotification failed: %v", err)
+	}
+	config, err := c.GetTaskPushNotification(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetTaskPushNotification failed: %v", err)
+	}
+	if config.URL != "https://example.com/push" {
+		t.Errorf("Expected push URL to be stored, got %q", config.URL)
+	}
+	if err := c.DeleteTaskPushNotification(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteTaskPushNotification failed: %v", err)
+	}
+
+	cancelled, err := c.CancelTask(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	if cancelled.Status.State != a2a.TaskStateCancelled {
+		t.Errorf("Expected cancelled task, got %s", cancelled.Status.State)
+	}
+
+	if _, err := c.GetTask(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "Task not found") {
+		t.Errorf("Expected task not found error, got %v", err)
+	}
+
+	// Every request is recorded with its headers
+	requests := mock.Requests()
+	if len(requests) != 7 {
+		t.Fatalf("Expected 7 recorded requests, got %d", len(requests))
+	}
+	if got := requests[0].Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Expected auth header to be recorded, got %q", got)
+	}
+}
+
+func TestMockServer_ScriptedResponses(t *testing.T) {
+	mock := NewMockServer(Handlers{
+		SendTask: func(params *a2a.TaskSendParams) (*a2a.Task, error) {
+			return &a2a.Task{ID: "scripted", Status: a2a.TaskStatus{State: a2a.TaskStateInputRequired}}, nil
+		},
+		GetTask: func(params *a2a.TaskQueryParams) (*a2a.Task, error) {
+			return nil, a2a.ErrTaskNotFound(params.TaskID)
+		},
+	})
+	defer mock.Close()
+	c := newTestClient(t, mock)
+
+	created, err := c.SendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage("hello")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if created.ID != "scripted" || created.Status.State != a2a.TaskStateInputRequired {
+		t.Errorf("Expected scripted task, got %+v", created)
+	}
+
+	_, err = c.GetTask(context.Background(), "scripted")
+	if err == nil || !strings.Contains(err.Error(), "Task not found: scripted") {
+		t.Errorf("Expected scripted error, got %v", err)
+	}
+	mock.AssertLastParams(t, "tasks/get", a2a.TaskQueryParams{TaskID: "scripted"})
+}
+
+func TestMockServer_Streaming(t *testing.T) {
+	mock := NewMockServer(Handlers{
+		SendTaskSubscribe: func(params *a2a.TaskSendParams) ([]Event, error) {
+			return []Event{
+				StatusEvent(a2a.TaskStateWorking, "thinking"),
+				ArtifactEvent(a2a.TextPart{Type: "text", Text: "result"}),
+				StatusEvent(a2a.TaskStateCompleted, ""),
+			}, nil
+		},
+	})
+	defer mock.Close()
+	c := newTestClient(t, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	params := &a2a.TaskSendParams{Message: newTextMessage("hello")}
+	updates, errs := c.SendSubscribe(ctx, params)
+
+	var received []client.TaskUpdate
+	for update := range updates {
+		received = append(received, update)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 updates, got %d", len(received))
+	}
+	if received[0].Type != "status" || received[0].Status.State != a2a.TaskStateWorking {
+		t.Errorf("Expected working status first, got %+v", received[0])
+	}
+	if text := received[0].Status.Message.Parts[0].(a2a.TextPart).Text; text != "thinking" {
+		t.Errorf("Expected status message %q, got %q", "thinking", text)
+	}
+	if received[1].Type != "artifact" || received[1].Artifact.Part.(a2a.TextPart).Text != "result" {
+		t.Errorf("Expected result artifact second, got %+v", received[1])
+	}
+	if received[1].Artifact.TaskID == "" {
+		t.Error("Expected artifact to carry the task ID")
+	}
+	if received[2].Type != "status" || received[2].Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected completed status last, got %+v", received[2])
+	}
+	mock.AssertLastParams(t, "tasks/sendSubscribe", params)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultMaxBackoff is the longest delay between attempts when Backoff.Max is not set.
+const DefaultMaxBackoff = 30 * time.Second
+
+// Backoff computes the growing delays between repeated attempts, such as polls of a task's
+// status or attempts to resume a dropped stream, so the client does not hammer the server
+// at a fixed interval. Zero fields take the defaults noted.
+type Backoff struct {
+	// Base is the delay before the first repeat. A poll interval passed to WaitForCompletion
+	// or Delegate takes its place when polling. (0 = the retry delay for stream reconnects,
+	// or the default poll interval when polling)
+	Base       time.Duration
+	Max        time.Duration // Longest delay (0 = DefaultMaxBackoff)
+	Multiplier float64       // Factor each delay grows by (0 = 2; 1 = a fixed interval)
+	// Jitter is the fraction of each delay that is randomized, from 0 (none) to 1 (anywhere
+	// between 0 and the delay), so clients started together do not repeat in lockstep
+	Jitter float64
+}
+
+// withBase returns the backoff with base as its base delay if none is set.
+func (b Backoff) withBase(base time.Duration) Backoff {
+	if b.Base <= 0 {
+		b.Base = base
+	}
+	return b
+}
+
+// Delay returns the delay before the given repeat, counted from 0: Base grown by Multiplier
+// for each earlier repeat, capped at Max, then reduced by up to the Jitter fraction.
+func (b Backoff) Delay(attempt int) time.Duration {
+	if b.Max <= 0 {
+		b.Max = DefaultMaxBackoff
+	}
+	if b.Multiplier == 0 {
+		b.Multiplier = 2
+	}
+	b.Multiplier = math.Max(b.Multiplier, 1)
+	jitter := math.Min(math.Max(b.Jitter, 0), 1)
+
+	delay := float64(b.Base) * math.Pow(b.Multiplier, float64(attempt))
+	if delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	delay -= delay * jitter * rand.Float64()
+	return time.Duration(delay)
+}
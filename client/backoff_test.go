@@ -0,0 +1,74 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{
+			name:    "doubles by default",
+			backoff: Backoff{Base: 100 * time.Millisecond},
+			want:    []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		{
+			name:    "capped at max",
+			backoff: Backoff{Base: 100 * time.Millisecond, Max: 300 * time.Millisecond, Multiplier: 3},
+			want:    []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond},
+		},
+		{
+			name:    "fixed interval",
+			backoff: Backoff{Base: time.Second, Multiplier: 1},
+			want:    []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:    "default max",
+			backoff: Backoff{Base: time.Second},
+			want:    []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, DefaultMaxBackoff, DefaultMaxBackoff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for attempt, want := range tt.want {
+				if got := tt.backoff.Delay(attempt); got != want {
+					t.Errorf("Delay(%d) = %s, want %s", attempt, got, want)
+				}
+			}
+		})
+	}
+
+	// Large attempt counts must not overflow past the cap
+	if got := (Backoff{Base: time.Second}).Delay(1000); got != DefaultMaxBackoff {
+		t.Errorf("Delay(1000) = %s, want %s", got, DefaultMaxBackoff)
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	const samples = 2000
+	backoff := Backoff{Base: time.Second, Multiplier: 1, Jitter: 0.5}
+
+	var sum time.Duration
+	lowest, highest := time.Duration(1<<62), time.Duration(0)
+	for i := 0; i < samples; i++ {
+		delay := backoff.Delay(0)
+		if delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("Delay %s outside [500ms, 1s]", delay)
+		}
+		sum += delay
+		lowest, highest = min(lowest, delay), max(highest, delay)
+	}
+
+	// Delays are spread uniformly over the jitter range, so they average its midpoint
+	if mean := sum / samples; mean < 700*time.Millisecond || mean > 800*time.Millisecond {
+		t.Errorf("Expected a mean delay around 750ms, got %s", mean)
+	}
+	if highest-lowest < 400*time.Millisecond {
+		t.Errorf("Expected delays spread across the jitter range, got %s to %s", lowest, highest)
+	}
+}
@@ -2,27 +2,53 @@ package client
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/redact"
+	"github.com/sammcj/go-a2a/pkg/trace"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrClosed is returned by calls made on a client after it has been closed.
+var ErrClosed = errors.New("client is closed")
+
 // Client is an A2A client for interacting with A2A servers.
 type Client struct {
 	config    Config
 	sseClient *SSEClient
+	balancer  *endpointBalancer // Selects the endpoint for each request
+	ownsHTTP  bool              // Whether the HTTP client was created by NewClient, and so is closed by Close
+
+	// closed is cancelled by Close to abort requests in flight
+	closed    context.Context
+	closeFunc context.CancelFunc
+	closeOnce sync.Once
+
+	// card is the cached agent card. cardMu is held for the whole fetch so that
+	// concurrent FetchAgentCard calls share a single HTTP request.
+	card   *a2a.AgentCard
+	cardMu sync.Mutex
 }
 
 // NewClient creates a new A2A client.
 func NewClient(opts ...Option) (*Client, error) {
 	cfg := DefaultConfig()
+	defaultHTTPClient := cfg.HTTPClient
 	for _, opt := range opts {
 		opt(&cfg)
 	}
@@ -32,37 +58,109 @@ func NewClient(opts ...Option) (*Client, error) {
 		return nil, fmt.Errorf("base URL is required")
 	}
 
-	// Ensure base URL ends with a slash
-	if !strings.HasSuffix(cfg.BaseURL, "/") {
-		cfg.BaseURL += "/"
-	}
-
 	// Validate base URL format
-	_, err := url.Parse(cfg.BaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
+	endpoints := cfg.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []string{cfg.BaseURL}
+	}
+	for _, endpoint := range endpoints {
+		if _, err := url.Parse(endpoint); err != nil {
+			return nil, fmt.Errorf("invalid base URL: %w", err)
+		}
 	}
+	balancer := newEndpointBalancer(endpoints, cfg.EndpointStrategy)
 
 	// Create SSE client
 	sseClient := NewSSEClient(cfg.HTTPClient, cfg.BaseURL, cfg.AuthHeaders)
+	sseClient.propagatedHeaders = cfg.PropagatedHeaders
+	sseClient.balancer = balancer
+	sseClient.maxReconnects = cfg.MaxReconnects
+	sseClient.backoff = cfg.Backoff.withBase(cfg.RetryDelay)
+	sseClient.logger = cfg.RequestLogger
+	if cfg.StreamBufferSize > 0 {
+		sseClient.bufferSize = cfg.StreamBufferSize
+	}
+	if cfg.SSEPath != "" {
+		sseClient.setStreamingEndpoint(cfg.SSEPath, a2a.StreamingProtocolSSE)
+	}
 
-	return &Client{
+	closed, closeFunc := context.WithCancel(context.Background())
+	c := &Client{
 		config:    cfg,
 		sseClient: sseClient,
-	}, nil
+		balancer:  balancer,
+		ownsHTTP:  cfg.HTTPClient == defaultHTTPClient,
+		closed:    closed,
+		closeFunc: closeFunc,
+		card:      cfg.AgentCard,
+	}
+	c.useCardStreamingEndpoint(cfg.AgentCard)
+	return c, nil
+}
+
+// useCardStreamingEndpoint makes streams use the streaming endpoint and protocol advertised
+// in an agent card, unless an SSE path was configured with WithSSEPath.
+func (c *Client) useCardStreamingEndpoint(card *a2a.AgentCard) {
+	if c.config.SSEPath != "" || card == nil || card.Capabilities == nil {
+		return
+	}
+	endpoint := card.Capabilities.StreamingEndpoint
+	if endpoint == "" {
+		endpoint = DefaultSSEPath
+	}
+	c.sseClient.setStreamingEndpoint(endpoint, card.Capabilities.StreamingProtocol)
+}
+
+// Close releases the client's resources. It aborts requests in flight, stops its streams,
+// including any waiting to reconnect, and closes idle connections unless the HTTP client
+// was set with WithHTTPClient, as it may be shared. Calls made after Close return ErrClosed.
+// It is safe to call more than once.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeFunc()
+		c.sseClient.Close()
+		if c.ownsHTTP {
+			c.config.HTTPClient.CloseIdleConnections()
+		}
+	})
+	return nil
 }
 
 // FetchAgentCard fetches the agent card from the server.
+// The configured auth headers are sent with the request, so cards served behind
+// authentication (see server.WithProtectedAgentCard) can be fetched too.
+// The card is cached after the first successful fetch. It is safe to call concurrently;
+// concurrent callers wait for a single fetch rather than each making a request.
 func (c *Client) FetchAgentCard(ctx context.Context) (*a2a.AgentCard, error) {
+	c.cardMu.Lock()
+	defer c.cardMu.Unlock()
+
 	// If we already have a cached agent card, return it
-	if c.config.AgentCard != nil {
-		return c.config.AgentCard, nil
+	if c.card != nil {
+		return c.card, nil
+	}
+
+	var card a2a.AgentCard
+	err := c.balancer.do(func(endpoint string) error {
+		return c.fetchAgentCard(ctx, endpoint, &card)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	// Cache the agent card
+	c.card = &card
+	c.useCardStreamingEndpoint(&card)
+
+	return &card, nil
+}
+
+// fetchAgentCard fetches the agent card from the given endpoint.
+func (c *Client) fetchAgentCard(ctx context.Context, endpoint string, card *a2a.AgentCard) error {
 	// Construct the URL for the agent card
-	baseURL, err := url.Parse(c.config.BaseURL)
+	baseURL, err := url.Parse(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
+		return fmt.Errorf("invalid base URL: %w", err)
 	}
 
 	// Default path for agent card
@@ -72,7 +170,7 @@ func (c *Client) FetchAgentCard(ctx context.Context) (*a2a.AgentCard, error) {
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
@@ -84,29 +182,36 @@ func (c *Client) FetchAgentCard(ctx context.Context) (*a2a.AgentCard, error) {
 	// Send request
 	resp, err := c.config.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to fetch agent card: %w", err)
+		}
+		return &connectionError{fmt.Errorf("failed to fetch agent card: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch agent card: status code %d", resp.StatusCode)
+		return fmt.Errorf("failed to fetch agent card: status code %d", resp.StatusCode)
 	}
 
 	// Parse response
-	var card a2a.AgentCard
-	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
-		return nil, fmt.Errorf("failed to parse agent card: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(card); err != nil {
+		return fmt.Errorf("failed to parse agent card: %w", err)
 	}
-
-	// Cache the agent card
-	c.config.AgentCard = &card
-
-	return &card, nil
+	return nil
 }
 
 // SendTask sends a task to the A2A server.
+// If retries are enabled and params has no idempotency key, one is generated so that a
+// retried request returns the original task rather than creating a duplicate.
 func (c *Client) SendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error) {
+	if c.config.MaxRetries > 0 && params.IdempotencyKey == nil {
+		key := generateIdempotencyKey()
+		withKey := *params
+		withKey.IdempotencyKey = &key
+		params = &withKey
+	}
+
 	// Create JSON-RPC request
 	request := a2a.JSONRPCRequest{
 		JSONRPC: "2.0",
@@ -190,6 +295,65 @@ func (c *Client) CancelTask(ctx context.Context, taskID string) (*a2a.Task, erro
 	return &task, nil
 }
 
+// CancelSession cancels every task in a session that has not finished, returning the cancelled tasks.
+func (c *Client) CancelSession(ctx context.Context, sessionID string) ([]a2a.Task, error) {
+	// Create params
+	params := a2a.SessionIdParams{
+		SessionID: sessionID,
+	}
+
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "sessions/cancel",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	request.Params = paramsJSON
+
+	// Send request
+	var result a2a.CancelSessionResult
+	if err := c.sendJSONRPCRequest(ctx, request, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tasks, nil
+}
+
+// ListTasks lists a page of the server's tasks, in the order they were created. Pass the
+// NextCursor of a result as params.Cursor to get the next page; it is empty on the last page.
+// params may be nil to get the first page with the server's default page size.
+func (c *Client) ListTasks(ctx context.Context, params *a2a.ListTasksParams) (*a2a.ListTasksResult, error) {
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "tasks/list",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	if params != nil {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		request.Params = paramsJSON
+	}
+
+	// Send request
+	var result a2a.ListTasksResult
+	if err := c.sendJSONRPCRequest(ctx, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // SetTaskPushNotification sets the push notification configuration for a task.
 func (c *Client) SetTaskPushNotification(ctx context.Context, params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error) {
 	// Create JSON-RPC request
@@ -245,6 +409,79 @@ func (c *Client) GetTaskPushNotification(ctx context.Context, taskID string) (*a
 	return &config, nil
 }
 
+// DeleteTaskPushNotification removes the push notification configuration for a task.
+// It succeeds if the task has no configuration.
+func (c *Client) DeleteTaskPushNotification(ctx context.Context, taskID string) error {
+	// Create params
+	params := a2a.TaskIdParams{
+		TaskID: taskID,
+	}
+
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "tasks/pushNotification/delete",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal params: %w", err)
+	}
+	request.Params = paramsJSON
+
+	// Send request
+	var result a2a.TaskIdParams
+	return c.sendJSONRPCRequest(ctx, request, &result)
+}
+
+// Ping checks that the A2A server is reachable and returns its advertised capabilities.
+// Unlike FetchAgentCard, it uses the JSON-RPC endpoint and so is subject to the same authentication.
+func (c *Client) Ping(ctx context.Context) (*a2a.AgentCapabilitiesResult, error) {
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "agent/getCapabilities",
+		ID:      generateRequestID(),
+	}
+
+	// Send request
+	var result a2a.AgentCapabilitiesResult
+	if err := c.sendJSONRPCRequest(ctx, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListSkills returns the agent's skills matching the filter. A nil filter lists all skills.
+func (c *Client) ListSkills(ctx context.Context, filter *a2a.SkillFilter) ([]a2a.AgentSkill, error) {
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "skills/list",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	if filter != nil {
+		paramsJSON, err := json.Marshal(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		request.Params = paramsJSON
+	}
+
+	// Send request
+	var result a2a.SkillsListResult
+	if err := c.sendJSONRPCRequest(ctx, request, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Skills, nil
+}
+
 // SendSubscribe sends a task to the A2A server and subscribes to updates via SSE.
 // It returns a channel for receiving task updates and an error channel.
 func (c *Client) SendSubscribe(ctx context.Context, params *a2a.TaskSendParams) (<-chan TaskUpdate, <-chan error) {
@@ -257,36 +494,215 @@ func (c *Client) Resubscribe(ctx context.Context, taskID string, lastEventID str
 	return c.sseClient.ResubscribeToTask(ctx, taskID, lastEventID)
 }
 
+// ListTasksSubscribe streams the server's task list via SSE: the existing tasks a page at a
+// time, followed by new tasks as they are created, until ctx is cancelled.
+// It returns a channel for receiving pages and an error channel.
+func (c *Client) ListTasksSubscribe(ctx context.Context, params *a2a.ListTasksParams) (<-chan a2a.ListTasksResult, <-chan error) {
+	return c.sseClient.SubscribeToTaskList(ctx, params)
+}
+
 // sendJSONRPCRequest sends a JSON-RPC request to the A2A server and unmarshals the result.
+// Requests that fail in a retryable way are retried if retries are enabled.
 func (c *Client) sendJSONRPCRequest(ctx context.Context, request a2a.JSONRPCRequest, result interface{}) error {
+	if c.closed.Err() != nil {
+		return ErrClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(c.closed, cancel)()
+
+	ctx, span := c.startSpan(ctx, request.Method)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		err := c.doJSONRPCRequest(ctx, request, result)
+		if err == nil || attempt >= c.config.MaxRetries || !isRetryable(err) {
+			if err != nil {
+				trace.RecordError(span, err)
+			}
+			return err
+		}
+
+		// Wait as long as the server asked, if it did, unless that outlasts the context
+		delay := c.config.RetryDelay
+		var retryable *retryableError
+		if errors.As(err, &retryable) && retryable.retryAfter > 0 {
+			delay = retryable.retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			trace.RecordError(span, err)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			trace.RecordError(span, err)
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryableError wraps an error response that may succeed if retried, such as a 503
+// or a 429, along with how long the server asked the client to wait, if it did.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// IsTransient reports whether a failed request may succeed if sent again, such as one that
+// could not connect or got a 502, 503, 504 or 429 response, so callers with their own
+// retry policy can tell transient failures from errors returned by the agent.
+func IsTransient(err error) bool {
+	return isRetryable(err)
+}
+
+// isRetryable reports whether a failed request may succeed if retried.
+func isRetryable(err error) bool {
+	var retryable *retryableError
+	var connErr *connectionError
+	return errors.As(err, &retryable) || errors.As(err, &connErr)
+}
+
+// isRetryableStatus reports whether an HTTP status indicates a transient server failure.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+// It returns 0 if the header is missing or invalid.
+func parseRetryAfter(header string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// startSpan starts a span for a client call, using the configured tracer provider or the
+// tracer in ctx.
+func (c *Client) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	ctx = trace.WithTracerProvider(ctx, c.config.TracerProvider)
+	return trace.StartSpan(ctx, "a2a.client", attribute.String("a2a.method", method))
+}
+
+// doJSONRPCRequest sends a JSON-RPC request to one of the client's endpoints, failing over
+// to the next endpoint if it cannot be reached, and unmarshals the result.
+func (c *Client) doJSONRPCRequest(ctx context.Context, request a2a.JSONRPCRequest, result interface{}) error {
+	return c.balancer.do(func(endpoint string) error {
+		start := time.Now()
+		err := c.postJSONRPCRequest(ctx, endpoint, request, result)
+		c.logRequest(request, endpoint, time.Since(start), err)
+		return err
+	})
+}
+
+// logRequest logs a JSON-RPC call if request logging is enabled.
+func (c *Client) logRequest(request a2a.JSONRPCRequest, endpoint string, duration time.Duration, err error) {
+	if c.config.RequestLogger == nil {
+		return
+	}
+
+	attrs := []any{
+		"method", request.Method,
+		"id", request.ID,
+		"endpoint", endpoint,
+		"duration", duration,
+	}
+	if len(request.Params) > 0 {
+		attrs = append(attrs, "params", string(redact.JSON(request.Params, c.config.RedactFields)))
+	}
+	if err != nil {
+		c.config.RequestLogger.Warn("a2a request", append(attrs, "outcome", "error", "error", err.Error())...)
+		return
+	}
+	c.config.RequestLogger.Info("a2a request", append(attrs, "outcome", "ok")...)
+}
+
+// postJSONRPCRequest performs the HTTP round trip for a JSON-RPC request to an endpoint and unmarshals the result.
+func (c *Client) postJSONRPCRequest(ctx context.Context, endpoint string, request a2a.JSONRPCRequest, result interface{}) error {
 	// Marshal request
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// Compress large request bodies if enabled
+	compressed := false
+	if c.config.Compression && len(requestJSON) >= compressionThreshold {
+		if requestJSON, err = gzipBytes(requestJSON); err != nil {
+			return fmt.Errorf("failed to compress request: %w", err)
+		}
+		compressed = true
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL, bytes.NewReader(requestJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(requestJSON))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 
 	// Add headers
 	for name, value := range c.config.AuthHeaders {
 		req.Header.Set(name, value)
 	}
+	trace.Inject(ctx, req, c.config.PropagatedHeaders)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
 
 	// Send request
 	resp, err := c.config.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+		return &connectionError{fmt.Errorf("failed to send request: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	if isRetryableStatus(resp.StatusCode) {
+		return &retryableError{
+			err:        fmt.Errorf("server unavailable: status code %d", resp.StatusCode),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	// Decompress the response body if needed
+	var bodyReader io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress response body: %w", err)
+		}
+		defer gz.Close()
+		bodyReader = gz
+	}
+
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -294,12 +710,22 @@ func (c *Client) sendJSONRPCRequest(ctx context.Context, request a2a.JSONRPCRequ
 	// Parse JSON-RPC response
 	var jsonRPCResponse a2a.JSONRPCResponse
 	if err := json.Unmarshal(body, &jsonRPCResponse); err != nil {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &retryableError{
+				err:        fmt.Errorf("rate limited: status code %d", resp.StatusCode),
+				retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
 		return fmt.Errorf("failed to parse JSON-RPC response: %w", err)
 	}
 
 	// Check for JSON-RPC error
 	if jsonRPCResponse.Error != nil {
-		return fmt.Errorf("JSON-RPC error: code=%d, message=%s", jsonRPCResponse.Error.Code, jsonRPCResponse.Error.Message)
+		err := jsonRPCResponse.Error.ToError()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return err
 	}
 
 	// Unmarshal result
@@ -315,6 +741,29 @@ func (c *Client) sendJSONRPCRequest(ctx context.Context, request a2a.JSONRPCRequ
 	return nil
 }
 
+// compressionThreshold is the minimum request body size in bytes that is compressed.
+const compressionThreshold = 1024
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateIdempotencyKey generates a random key identifying retries of a tasks/send request.
+func generateIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // generateRequestID generates a unique ID for a JSON-RPC request.
 func generateRequestID() string {
 	// For now, just use a simple string. In a real implementation, we might use a UUID.
@@ -0,0 +1,456 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// newJSONRPCServer starts a test server that answers every JSON-RPC request using the given function.
+func newJSONRPCServer(t *testing.T, respond func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(respond(t, r, request))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClient_Ping(t *testing.T) {
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		if request.Method != "agent/getCapabilities" {
+			t.Errorf("Expected method agent/getCapabilities, got %s", request.Method)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Expected auth header to be sent, got %q", got)
+		}
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result: a2a.AgentCapabilitiesResult{
+				A2AVersion:   "1.0",
+				AgentID:      "test-agent",
+				Capabilities: a2a.AgentCapabilities{SupportsStreaming: true},
+			},
+		}
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL), WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	result, err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if result.A2AVersion != "1.0" || result.AgentID != "test-agent" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !result.Capabilities.SupportsStreaming {
+		t.Error("Expected streaming capability to be reported")
+	}
+}
+
+func TestClient_PingError(t *testing.T) {
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Error:   a2a.ErrMethodNotFound(request.Method).ToJSONRPCError(),
+		}
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := c.Ping(context.Background()); err == nil {
+		t.Fatal("Expected Ping to fail when the server does not support the method")
+	}
+}
+
+func TestClient_ListSkills(t *testing.T) {
+	var receivedParams json.RawMessage
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		if request.Method != "skills/list" {
+			t.Errorf("Expected method skills/list, got %s", request.Method)
+		}
+		receivedParams = request.Params
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result: a2a.SkillsListResult{
+				Skills: []a2a.AgentSkill{{ID: "search", Name: "Web Search", Tags: []string{"web"}}},
+			},
+		}
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	skills, err := c.ListSkills(context.Background(), &a2a.SkillFilter{Tag: "web"})
+	if err != nil {
+		t.Fatalf("ListSkills failed: %v", err)
+	}
+	if len(skills) != 1 || skills[0].ID != "search" {
+		t.Errorf("Unexpected skills: %+v", skills)
+	}
+	if string(receivedParams) != `{"tag":"web"}` {
+		t.Errorf("Unexpected params: %s", receivedParams)
+	}
+
+	// A nil filter sends no params
+	if _, err := c.ListSkills(context.Background(), nil); err != nil {
+		t.Fatalf("ListSkills failed: %v", err)
+	}
+	if len(receivedParams) != 0 {
+		t.Errorf("Expected no params for nil filter, got %s", receivedParams)
+	}
+}
+
+func TestClient_DeleteTaskPushNotification(t *testing.T) {
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		if request.Method != "tasks/pushNotification/delete" {
+			t.Errorf("Expected method tasks/pushNotification/delete, got %s", request.Method)
+		}
+		var params a2a.TaskIdParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			t.Errorf("Failed to decode params: %v", err)
+		}
+		if params.TaskID != "task-1" {
+			t.Errorf("Expected task ID task-1, got %s", params.TaskID)
+		}
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  params,
+		}
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if err := c.DeleteTaskPushNotification(context.Background(), "task-1"); err != nil {
+		t.Fatalf("DeleteTaskPushNotification failed: %v", err)
+	}
+}
+
+func TestClient_FetchAgentCardConcurrent(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		// Give concurrent callers time to pile up behind the first fetch
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"})
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	cards := make([]*a2a.AgentCard, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			card, err := c.FetchAgentCard(context.Background())
+			if err != nil {
+				t.Errorf("FetchAgentCard failed: %v", err)
+				return
+			}
+			cards[i] = card
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("Expected a single agent card fetch, got %d", n)
+	}
+	for i, card := range cards {
+		if card == nil || card.ID != "test-agent" {
+			t.Errorf("Caller %d got unexpected card: %+v", i, card)
+		}
+	}
+}
+
+func TestClient_SendTaskRetriesWithIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		var params a2a.TaskSendParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			t.Errorf("Failed to decode params: %v", err)
+		}
+
+		mu.Lock()
+		key := ""
+		if params.IdempotencyKey != nil {
+			key = *params.IdempotencyKey
+		}
+		keys = append(keys, key)
+		attempt := len(keys)
+		mu.Unlock()
+
+		// The first attempt fails as if a proxy in front of the server was unavailable
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  a2a.Task{ID: "task-1"},
+		})
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetries(2), WithRetryDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	task, err := c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}}},
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if task.ID != "task-1" {
+		t.Errorf("Expected task-1, got %s", task.ID)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected both attempts to carry the same generated idempotency key, got %q", keys)
+	}
+}
+
+func TestClient_NoRetriesByDefault(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := c.GetTask(context.Background(), "task-1"); err == nil {
+		t.Fatal("Expected GetTask to fail")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("Expected 1 attempt, got %d", n)
+	}
+}
+
+// newRateLimitedServer starts a server that rejects the first request with a 429 rate-limit
+// error carrying the given Retry-After header, and records the time of each request.
+func newRateLimitedServer(t *testing.T, retryAfter string) (*httptest.Server, *[]time.Time) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		attempt := len(attempts)
+		mu.Unlock()
+
+		response := a2a.JSONRPCResponse{JSONRPC: "2.0", ID: request.ID, Result: a2a.Task{ID: "task-1"}}
+		w.Header().Set("Content-Type", "application/json")
+		if attempt == 1 {
+			response = a2a.JSONRPCResponse{JSONRPC: "2.0", ID: request.ID, Error: a2a.ErrRateLimitExceeded().ToJSONRPCError()}
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &attempts
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	server, attempts := newRateLimitedServer(t, "1")
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetries(1), WithRetryDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	task, err := c.GetTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.ID != "task-1" {
+		t.Errorf("Expected task-1, got %s", task.ID)
+	}
+
+	if len(*attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(*attempts))
+	}
+	if waited := (*attempts)[1].Sub((*attempts)[0]); waited < time.Second {
+		t.Errorf("Expected the client to wait for the Retry-After duration, waited %v", waited)
+	}
+}
+
+func TestClient_RetryAfterBeyondDeadline(t *testing.T) {
+	server, attempts := newRateLimitedServer(t, "60")
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetries(1), WithRetryDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.GetTask(ctx, "task-1"); err == nil || !strings.Contains(err.Error(), "Rate limit exceeded") {
+		t.Fatalf("Expected the rate limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the client to give up without waiting, took %v", elapsed)
+	}
+	if len(*attempts) != 1 {
+		t.Errorf("Expected 1 attempt, got %d", len(*attempts))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v, want about an hour", future, got)
+	}
+}
+
+func TestClient_RequestLoggingRedactsFields(t *testing.T) {
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  a2a.Task{ID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateSubmitted}},
+		}
+	})
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	c, err := NewClient(WithBaseURL(server.URL), WithRequestLogging(logger, []string{"metadata.apiKey"}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: a2a.Message{
+			Role:  a2a.RoleUser,
+			Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}},
+		},
+		Metadata: map[string]interface{}{"apiKey": "super-secret"},
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	logged := logs.String()
+	if strings.Contains(logged, "super-secret") {
+		t.Errorf("Expected apiKey to be redacted, got %s", logged)
+	}
+	for _, want := range []string{`"method":"tasks/send"`, `"outcome":"ok"`, "hello"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("Expected log to contain %s, got %s", want, logged)
+		}
+	}
+}
+
+// closeIdleTransport records calls to CloseIdleConnections.
+type closeIdleTransport struct {
+	closed atomic.Int32
+}
+
+func (t *closeIdleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *closeIdleTransport) CloseIdleConnections() {
+	t.closed.Add(1)
+}
+
+func TestClient_CloseKeepsSharedHTTPClient(t *testing.T) {
+	transport := &closeIdleTransport{}
+	c, err := NewClient(WithBaseURL("http://example.com"), WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	c.Close()
+	if n := transport.closed.Load(); n != 0 {
+		t.Errorf("Expected a shared HTTP client to keep its idle connections, got %d CloseIdleConnections calls", n)
+	}
+
+	c, err = NewClient(WithBaseURL("http://example.com"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if !c.ownsHTTP {
+		t.Error("Expected the client to own its default HTTP client")
+	}
+	if c.config.HTTPClient.Transport == http.DefaultTransport {
+		t.Error("Expected the default HTTP client not to share http.DefaultTransport")
+	}
+	c.Close()
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// DefaultDelegatePollInterval is the default interval between task status checks when delegating.
+const DefaultDelegatePollInterval = 500 * time.Millisecond
+
+// DelegateOptions configures a delegated task.
+type DelegateOptions struct {
+	SessionID    *string       // Optional session to run the task in
+	SkillID      *string       // Optional skill to invoke on the downstream agent
+	PollInterval time.Duration // Delay before the first task status check, then backing off (default DefaultDelegatePollInterval)
+}
+
+// DelegateResult holds the outcome of a delegated task.
+type DelegateResult struct {
+	Task      *a2a.Task      // The final state of the delegated task
+	Text      string         // Concatenated text of the final status message
+	Artifacts []a2a.Artifact // Artifacts produced by the delegated task
+}
+
+// Delegate sends a message to the agent as a new task and waits for it to finish.
+// It returns the final task along with its response text and artifacts.
+// The task is considered finished once it completes, fails, is cancelled, or requires input.
+func (c *Client) Delegate(ctx context.Context, message a2a.Message, opts *DelegateOptions) (*DelegateResult, error) {
+	if opts == nil {
+		opts = &DelegateOptions{}
+	}
+
+	// Send the task
+	task, err := c.SendTask(ctx, &a2a.TaskSendParams{
+		SessionID: opts.SessionID,
+		SkillID:   opts.SkillID,
+		Message:   message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delegate task: %w", err)
+	}
+
+	// Wait for the task to finish
+	backoff := c.pollBackoff(opts.PollInterval, DefaultDelegatePollInterval)
+	for attempt := 0; !isDelegateDone(task.Status.State); attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("delegated task %s did not finish: %w", task.ID, ctx.Err())
+		case <-time.After(backoff.Delay(attempt)):
+		}
+
+		task, err = c.GetTask(ctx, task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get delegated task: %w", err)
+		}
+	}
+
+	return &DelegateResult{
+		Task:      task,
+		Text:      messageText(task.Status.Message),
+		Artifacts: task.Artifacts,
+	}, nil
+}
+
+// DelegateStream sends a message to the agent as a new task and follows the task's updates
+// over SSE until it finishes, like Delegate. Agents that stream their answer send it across
+// many status updates and artifacts, so rather than only the final status message, the
+// result's Text joins the text of every agent status message and its Artifacts hold every
+// artifact streamed. The PollInterval option is not used.
+func (c *Client) DelegateStream(ctx context.Context, message a2a.Message, opts *DelegateOptions) (*DelegateResult, error) {
+	if opts == nil {
+		opts = &DelegateOptions{}
+	}
+
+	// Stop reading the stream once the task has finished
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{
+		SessionID: opts.SessionID,
+		SkillID:   opts.SkillID,
+		Message:   message,
+	})
+	result, err := AggregateUpdates(ctx, updates, errs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delegate task: %w", err)
+	}
+	return result, nil
+}
+
+// AggregateUpdates reads a task's updates, such as those from SendSubscribe or Resubscribe,
+// until the task completes, fails, is cancelled or requires input, and assembles them into
+// a DelegateResult: the text of every agent status message in order, every artifact, and a
+// task holding the final status and the artifacts (without history). It returns an error if
+// the stream fails, or ends or ctx is done before the task finishes, along with the result
+// so far; its task ID is empty if no update arrived, so the task may never have started.
+func AggregateUpdates(ctx context.Context, updates <-chan TaskUpdate, errs <-chan error) (*DelegateResult, error) {
+	result := &DelegateResult{Task: &a2a.Task{}}
+	var text strings.Builder
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				if errs == nil {
+					return result.withText(text.String()), fmt.Errorf("stream ended before task %s finished", result.Task.ID)
+				}
+				// Report why the stream ended, if it failed
+				updates = nil
+				continue
+			}
+			if update.TaskID != "" {
+				result.Task.ID = update.TaskID
+			}
+			if update.Artifact != nil {
+				result.Artifacts = append(result.Artifacts, *update.Artifact)
+			}
+			if update.Status == nil {
+				continue
+			}
+			result.Task.Status = *update.Status
+			if msg := update.Status.Message; msg != nil && msg.Role == a2a.RoleAgent {
+				text.WriteString(messageText(msg))
+			}
+			if isDelegateDone(update.Status.State) {
+				return result.withText(text.String()), nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				if updates == nil {
+					return result.withText(text.String()), fmt.Errorf("stream ended before task %s finished", result.Task.ID)
+				}
+				// The stream ended; any updates still buffered are read before updates closes
+				errs = nil
+				continue
+			}
+			return result.withText(text.String()), err
+		case <-ctx.Done():
+			return result.withText(text.String()), fmt.Errorf("task %s did not finish: %w", result.Task.ID, ctx.Err())
+		}
+	}
+}
+
+// withText completes a result being aggregated with its text and the task's artifacts.
+func (r *DelegateResult) withText(text string) *DelegateResult {
+	r.Text = text
+	r.Task.Artifacts = r.Artifacts
+	return r
+}
+
+// isDelegateDone reports whether a delegated task in the given state has finished.
+func isDelegateDone(state a2a.TaskState) bool {
+	switch state {
+	case a2a.TaskStateCompleted, a2a.TaskStateFailed, a2a.TaskStateCancelled, a2a.TaskStateInputRequired:
+		return true
+	default:
+		return false
+	}
+}
+
+// messageText returns the concatenated text parts of a message.
+func messageText(msg *a2a.Message) string {
+	if msg == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range msg.Parts {
+		if textPart, ok := part.(a2a.TextPart); ok {
+			sb.WriteString(textPart.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// writeEvent writes an SSE event with a JSON payload and flushes it to the client.
+func writeEvent(w http.ResponseWriter, event string, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	w.(http.Flusher).Flush()
+}
+
+// textStatus returns a status with a single text part message from role.
+func textStatus(state a2a.TaskState, role a2a.Role, text string) a2a.TaskStatus {
+	return a2a.TaskStatus{
+		State:   state,
+		Message: &a2a.Message{Role: role, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}}},
+	}
+}
+
+func TestClient_DelegateStream(t *testing.T) {
+	// The downstream agent streams its answer in chunks, with an artifact between them, and
+	// completes without a final message. Like the server, it keeps the stream open.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "taskStatusUpdate", a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: textStatus(a2a.TaskStateSubmitted, a2a.RoleUser, "Summarise the news.")})
+		writeEvent(w, "taskStatusUpdate", a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: textStatus(a2a.TaskStateWorking, a2a.RoleAgent, "Markets rose ")})
+		writeEvent(w, "taskArtifactUpdate", a2a.TaskArtifactUpdateEvent{TaskID: "task-1", Artifact: a2a.Artifact{ID: "artifact-1", TaskID: "task-1", Part: a2a.TextPart{Type: "text", Text: "Sources: example.com"}}})
+		writeEvent(w, "taskStatusUpdate", a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: textStatus(a2a.TaskStateWorking, a2a.RoleAgent, "and rain is ")})
+		writeEvent(w, "taskStatusUpdate", a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: textStatus(a2a.TaskStateWorking, a2a.RoleAgent, "forecast.")})
+		writeEvent(w, "taskStatusUpdate", a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}})
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := c.DelegateStream(ctx, a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "Summarise the news."}}}, nil)
+	if err != nil {
+		t.Fatalf("DelegateStream failed: %v", err)
+	}
+
+	if want := "Markets rose and rain is forecast."; result.Text != want {
+		t.Errorf("Expected text %q, got %q", want, result.Text)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].ID != "artifact-1" {
+		t.Errorf("Expected the streamed artifact, got %+v", result.Artifacts)
+	}
+	if result.Task.ID != "task-1" || result.Task.Status.State != a2a.TaskStateCompleted || len(result.Task.Artifacts) != 1 {
+		t.Errorf("Expected the completed task with its artifact, got %+v", result.Task)
+	}
+}
+
+func TestAggregateUpdates_StreamEndsEarly(t *testing.T) {
+	updates := make(chan TaskUpdate, 1)
+	errs := make(chan error)
+	updates <- TaskUpdate{Type: "status", TaskID: "task-1", Status: &a2a.TaskStatus{State: a2a.TaskStateWorking}}
+	close(updates)
+	close(errs)
+
+	if result, err := AggregateUpdates(context.Background(), updates, errs); err == nil {
+		t.Errorf("Expected an error for a stream that ends before the task finishes, got %+v", result)
+	}
+}
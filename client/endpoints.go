@@ -0,0 +1,135 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStrategy selects which endpoint a client with several endpoints sends each request to.
+type EndpointStrategy int
+
+const (
+	// RoundRobin sends successive requests to successive endpoints.
+	RoundRobin EndpointStrategy = iota
+	// Random sends each request to a randomly chosen endpoint.
+	Random
+)
+
+// endpointCooldown is how long an endpoint that could not be reached is tried only after the others.
+const endpointCooldown = 30 * time.Second
+
+// connectionError wraps an error from failing to reach an endpoint, as opposed to an
+// error response from it. Requests that fail this way are retried on the next endpoint.
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
+}
+
+// endpointBalancer spreads requests across the base URLs of one logical agent.
+// Endpoints that recently failed to connect are moved to the back of the order.
+type endpointBalancer struct {
+	endpoints []string
+	strategy  EndpointStrategy
+
+	mu        sync.Mutex
+	next      int
+	downUntil map[string]time.Time
+	rand      *rand.Rand
+}
+
+// newEndpointBalancer creates a balancer for the given endpoints. JSON-RPC requests are
+// sent to each endpoint exactly as given.
+func newEndpointBalancer(endpoints []string, strategy EndpointStrategy) *endpointBalancer {
+	return &endpointBalancer{
+		endpoints: endpoints,
+		strategy:  strategy,
+		downUntil: make(map[string]time.Time),
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// order returns the endpoints in the order a request should try them: starting from the
+// endpoint chosen by the strategy, with endpoints in their cooldown moved to the end.
+func (b *endpointBalancer) order() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.endpoints)
+	var start int
+	switch b.strategy {
+	case Random:
+		start = b.rand.Intn(n)
+	default:
+		start = b.next % n
+		b.next++
+	}
+
+	now := time.Now()
+	healthy := make([]string, 0, n)
+	var down []string
+	for i := 0; i < n; i++ {
+		endpoint := b.endpoints[(start+i)%n]
+		if now.Before(b.downUntil[endpoint]) {
+			down = append(down, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// markDown records that an endpoint could not be reached.
+func (b *endpointBalancer) markDown(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.downUntil[endpoint] = time.Now().Add(endpointCooldown)
+}
+
+// markUp records that an endpoint was reached.
+func (b *endpointBalancer) markUp(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.downUntil, endpoint)
+}
+
+// do calls fn with each endpoint in turn until it succeeds or fails with something other
+// than a connection error. When there are several endpoints, errors name the endpoint.
+func (b *endpointBalancer) do(fn func(endpoint string) error) error {
+	var err error
+	for _, endpoint := range b.order() {
+		err = fn(endpoint)
+
+		var connErr *connectionError
+		if !errors.As(err, &connErr) {
+			b.markUp(endpoint)
+			return b.annotate(endpoint, err)
+		}
+		b.markDown(endpoint)
+		err = b.annotate(endpoint, err)
+	}
+	return err
+}
+
+// annotate adds the endpoint to an error if the balancer has more than one endpoint.
+func (b *endpointBalancer) annotate(endpoint string, err error) error {
+	if err == nil || len(b.endpoints) == 1 {
+		return err
+	}
+	return fmt.Errorf("endpoint %s: %w", endpoint, err)
+}
+
+// joinPath joins a path below an endpoint, with exactly one slash between them.
+func joinPath(endpoint, path string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(path, "/")
+}
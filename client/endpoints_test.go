@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// newDeadEndpoint returns the URL of a server that has already been shut down.
+func newDeadEndpoint() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+// newCountingEndpoint starts a server that answers tasks/get and counts the requests it receives.
+func newCountingEndpoint(t *testing.T, count *int32) string {
+	t.Helper()
+
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		atomic.AddInt32(count, 1)
+		return a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  a2a.Task{ID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateWorking}},
+		}
+	})
+	return server.URL
+}
+
+// isDown reports whether an endpoint is in its cooldown.
+func (b *endpointBalancer) isDown(endpoint string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().Before(b.downUntil[endpoint])
+}
+
+func TestClient_EndpointFailover(t *testing.T) {
+	for _, strategy := range []EndpointStrategy{RoundRobin, Random} {
+		var live int32
+		dead := newDeadEndpoint()
+		c, err := NewClient(WithEndpoints([]string{dead, newCountingEndpoint(t, &live)}, strategy))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		// Send requests until the dead endpoint has been tried, which the random
+		// strategy may not do straight away
+		requests := 0
+		for requests < 4 || (!c.balancer.isDown(dead) && requests < 100) {
+			if _, err := c.GetTask(context.Background(), "task-1"); err != nil {
+				t.Fatalf("GetTask %d failed with strategy %d: %v", requests, strategy, err)
+			}
+			requests++
+		}
+		if n := atomic.LoadInt32(&live); n != int32(requests) {
+			t.Errorf("Expected every request to reach the live endpoint, got %d of %d", n, requests)
+		}
+
+		// The dead endpoint is only tried after the healthy one until its cooldown ends
+		if order := c.balancer.order(); order[len(order)-1] != dead {
+			t.Errorf("Expected dead endpoint to be tried last, got %v", order)
+		}
+	}
+}
+
+func TestClient_EndpointRoundRobin(t *testing.T) {
+	var first, second int32
+	c, err := NewClient(WithEndpoints([]string{newCountingEndpoint(t, &first), newCountingEndpoint(t, &second)}, RoundRobin))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := c.GetTask(context.Background(), "task-1"); err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+	}
+	if atomic.LoadInt32(&first) != 2 || atomic.LoadInt32(&second) != 2 {
+		t.Errorf("Expected requests to alternate between endpoints, got %d and %d", first, second)
+	}
+}
+
+func TestClient_EndpointErrorNamesEndpoint(t *testing.T) {
+	dead := newDeadEndpoint()
+	c, err := NewClient(WithEndpoints([]string{newDeadEndpoint(), dead}, RoundRobin))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = c.GetTask(context.Background(), "task-1")
+	if err == nil {
+		t.Fatal("Expected GetTask to fail when every endpoint is down")
+	}
+	if !strings.Contains(err.Error(), "endpoint "+dead) {
+		t.Errorf("Expected error to name the last endpoint tried, got %v", err)
+	}
+}
+
+func TestClient_EndpointPath(t *testing.T) {
+	// The server only serves the exact paths below its prefix, as server.Server does
+	var got []string
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		got = append(got, r.URL.Path)
+		return a2a.JSONRPCResponse{JSONRPC: "2.0", ID: request.ID, Result: a2a.Task{ID: "task-1"}}
+	})
+
+	for _, baseURL := range []string{server.URL + "/a2a", server.URL + "/a2a/"} {
+		c, err := NewClient(WithBaseURL(baseURL))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		if _, err := c.GetTask(context.Background(), "task-1"); err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+	}
+	if len(got) != 2 || got[0] != "/a2a" || got[1] != "/a2a/" {
+		t.Errorf("Expected requests to be sent to the base URL as configured, got %v", got)
+	}
+
+	if u := streamURL(server.URL+"/a2a", DefaultSSEPath); u != server.URL+"/a2a/sse" {
+		t.Errorf("Expected the SSE endpoint below the base URL, got %s", u)
+	}
+}
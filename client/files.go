@@ -0,0 +1,254 @@
+package client
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/trace"
+)
+
+// DefaultMaxFileSize is the default largest decoded file ResolveFile accepts.
+const DefaultMaxFileSize = 100 << 20 // 100 MiB
+
+// ErrFileTooLarge is returned by ResolveFile for files larger than the client's maximum file size.
+var ErrFileTooLarge = errors.New("file exceeds the maximum size")
+
+// ResolveFile returns a copy of a file part with its content inline, fetching it from the
+// part's URI if it only references the content (as artifacts offloaded to a server's
+// artifact store do). http(s) URIs are fetched with the client's HTTP client, sending its
+// auth headers only to the agent's own endpoints. Relative URIs, such as those of a
+// server's FileArtifactStore, are fetched from below the agent's endpoint. file:// URIs are
+// only read if they are under the directory set with WithLocalFileRoot.
+// Responses compressed with gzip or deflate, as given by their Content-Encoding, are
+// decoded, and the decoded size is recorded in the content. Files larger than the maximum
+// set by WithMaxFileSize once decoded fail with ErrFileTooLarge.
+// Parts that already have inline content are returned unchanged.
+func (c *Client) ResolveFile(ctx context.Context, part a2a.FilePart) (a2a.FilePart, error) {
+	if part.Content != nil {
+		return part, nil
+	}
+	if part.URI == nil || *part.URI == "" {
+		return part, fmt.Errorf("file part %q has neither content nor a URI", part.Filename)
+	}
+
+	u, err := url.Parse(*part.URI)
+	if err != nil {
+		return part, fmt.Errorf("invalid file URI: %w", err)
+	}
+
+	var data []byte
+	switch u.Scheme {
+	case "http", "https":
+		data, err = c.fetchFile(ctx, u)
+	case "":
+		err = c.balancer.do(func(endpoint string) error {
+			agentURL, parseErr := url.Parse(joinPath(endpoint, u.String()))
+			if parseErr != nil {
+				return parseErr
+			}
+			data, err = c.fetchFile(ctx, agentURL)
+			return err
+		})
+	case "file":
+		data, err = c.readFile(filepath.FromSlash(u.Path))
+	default:
+		err = fmt.Errorf("unsupported file URI scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return part, fmt.Errorf("failed to resolve file %s: %w", *part.URI, err)
+	}
+
+	part.Content = &a2a.FileContent{
+		Encoding: "base64",
+		Data:     base64.StdEncoding.EncodeToString(data),
+		Size:     int64(len(data)),
+	}
+	return part, nil
+}
+
+// readFile reads a local file under the client's local file root, up to the maximum file size.
+func (c *Client) readFile(path string) ([]byte, error) {
+	if c.config.LocalFileRoot == "" {
+		return nil, fmt.Errorf("file URIs are not allowed without a local file root")
+	}
+	root, err := filepath.Abs(c.config.LocalFileRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local file root: %w", err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, fmt.Errorf("invalid local file root: %w", err)
+	}
+	// Symlinks are resolved so they cannot point outside the root
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is outside the local file root", path)
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readLimited(f, c.maxFileSize())
+}
+
+// fetchFile downloads the content at an http(s) URI.
+func (c *Client) fetchFile(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.isAgentHost(u) {
+		for name, value := range c.config.AuthHeaders {
+			req.Header.Set(name, value)
+		}
+	}
+	// Setting the header stops the transport decoding gzip itself, so every encoding is
+	// decoded, and limited, in the same way
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	body, err := decodeContent(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return readLimited(body, c.maxFileSize())
+}
+
+// decodeContent returns a reader of the content of a body with the given Content-Encoding.
+// Encodings are listed in the order they were applied, so they are removed in reverse.
+func decodeContent(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	decoded := io.NopCloser(body)
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			decoded, err = gzip.NewReader(decoded)
+		case "deflate":
+			decoded, err = zlib.NewReader(decoded)
+		default:
+			return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode content: %w", err)
+		}
+	}
+	return decoded, nil
+}
+
+// readLimited reads r to the end, failing with ErrFileTooLarge if it has more than max bytes.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("%w of %d bytes", ErrFileTooLarge, max)
+	}
+	return data, nil
+}
+
+// maxFileSize returns the largest decoded file ResolveFile accepts.
+func (c *Client) maxFileSize() int64 {
+	if c.config.MaxFileSize > 0 {
+		return c.config.MaxFileSize
+	}
+	return DefaultMaxFileSize
+}
+
+// isAgentHost reports whether u is on the same host as one of the client's endpoints.
+func (c *Client) isAgentHost(u *url.URL) bool {
+	for _, endpoint := range c.balancer.endpoints {
+		if e, err := url.Parse(endpoint); err == nil && e.Scheme == u.Scheme && e.Host == u.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// ArtifactDownload is the raw content of an artifact downloaded with DownloadArtifact.
+type ArtifactDownload struct {
+	Body        io.ReadCloser // Content of the artifact; the caller must close it
+	ContentType string        // MIME type of the content
+	Filename    string        // Suggested filename (may be empty)
+	Size        int64         // Size of the content in bytes (-1 = unknown)
+}
+
+// DownloadArtifact downloads the raw content of a task's artifact, instead of fetching the
+// task with the content encoded in JSON. Text and data artifacts are downloaded as files of
+// their MIME type. The caller must close the returned body.
+func (c *Client) DownloadArtifact(ctx context.Context, taskID, artifactID string) (*ArtifactDownload, error) {
+	var download *ArtifactDownload
+	err := c.balancer.do(func(endpoint string) error {
+		var err error
+		download, err = c.downloadArtifact(ctx, endpoint, taskID, artifactID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+// downloadArtifact downloads an artifact from the given endpoint.
+func (c *Client) downloadArtifact(ctx context.Context, endpoint, taskID, artifactID string) (*ArtifactDownload, error) {
+	downloadURL := joinPath(endpoint, "tasks/") + url.PathEscape(taskID) + "/artifacts/" + url.PathEscape(artifactID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range c.config.AuthHeaders {
+		req.Header.Set(name, value)
+	}
+	trace.Inject(ctx, req, c.config.PropagatedHeaders)
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to download artifact: %w", err)
+		}
+		return nil, &connectionError{fmt.Errorf("failed to download artifact: %w", err)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download artifact: status code %d", resp.StatusCode)
+	}
+
+	download := &ArtifactDownload{
+		Body:        resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		download.Filename = params["filename"]
+	}
+	return download, nil
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+func TestResolveFile(t *testing.T) {
+	var gotAuth string
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte("remote content"))
+	}))
+	defer files.Close()
+
+	c, err := NewClient(WithBaseURL(files.URL), WithAuthHeader("Authorization", "Bearer token"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	uri := files.URL + "/artifacts/report.txt"
+	part, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", Filename: "report.txt", URI: &uri})
+	if err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	if part.Content == nil || part.Content.Encoding != "base64" {
+		t.Fatalf("Expected base64 inline content, got %+v", part.Content)
+	}
+	data, _ := base64.StdEncoding.DecodeString(part.Content.Data)
+	if string(data) != "remote content" {
+		t.Errorf("Expected %q, got %q", "remote content", data)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Expected auth header to be sent to the agent's host, got %q", gotAuth)
+	}
+
+	// Auth headers are not sent to other hosts
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer other.Close()
+	uri = other.URL + "/file"
+	if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: &uri}); err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	if gotAuth != "" {
+		t.Errorf("Expected no auth header for another host, got %q", gotAuth)
+	}
+
+	if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", Filename: "empty"}); err == nil {
+		t.Error("Expected an error for a part with neither content nor URI")
+	}
+}
+
+func TestResolveFile_ContentEncoding(t *testing.T) {
+	content := strings.Repeat("compressible content ", 100)
+	bomb := make([]byte, 1<<20) // Zeros compress to a tiny fraction of their size
+
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept-Encoding"); !strings.Contains(accept, "gzip") {
+			t.Errorf("Expected gzip to be accepted, got %q", accept)
+		}
+		var buf bytes.Buffer
+		switch r.URL.Path {
+		case "/gzip":
+			gz := gzip.NewWriter(&buf)
+			gz.Write([]byte(content))
+			gz.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/deflate":
+			zw := zlib.NewWriter(&buf)
+			zw.Write([]byte(content))
+			zw.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/bomb":
+			gz := gzip.NewWriter(&buf)
+			gz.Write(bomb)
+			gz.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/brotli":
+			buf.WriteString("not really brotli")
+			w.Header().Set("Content-Encoding", "br")
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer files.Close()
+
+	c, err := NewClient(WithBaseURL(files.URL), WithMaxFileSize(64<<10))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for _, path := range []string{"/gzip", "/deflate"} {
+		t.Run(path, func(t *testing.T) {
+			uri := files.URL + path
+			part, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: &uri})
+			if err != nil {
+				t.Fatalf("ResolveFile failed: %v", err)
+			}
+			data, _ := base64.StdEncoding.DecodeString(part.Content.Data)
+			if string(data) != content {
+				t.Errorf("Expected the decoded content, got %d bytes: %.40q", len(data), data)
+			}
+			if part.Content.Size != int64(len(content)) {
+				t.Errorf("Expected decoded size %d, got %d", len(content), part.Content.Size)
+			}
+		})
+	}
+
+	t.Run("over the maximum size once decoded", func(t *testing.T) {
+		uri := files.URL + "/bomb"
+		if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: &uri}); !errors.Is(err, ErrFileTooLarge) {
+			t.Errorf("Expected ErrFileTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		uri := files.URL + "/brotli"
+		if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: &uri}); err == nil || !strings.Contains(err.Error(), "unsupported content encoding") {
+			t.Errorf("Expected an unsupported encoding error, got %v", err)
+		}
+	})
+}
+
+func TestResolveFile_RelativeURI(t *testing.T) {
+	var gotPath, gotAuth string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte("stored content"))
+	}))
+	defer agent.Close()
+
+	c, err := NewClient(WithBaseURL(agent.URL+"/a2a"), WithAuthHeader("Authorization", "Bearer token"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	uri := "tasks/task-1/artifacts/artifact-1"
+	part, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: &uri})
+	if err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(part.Content.Data)
+	if string(data) != "stored content" {
+		t.Errorf("Expected %q, got %q", "stored content", data)
+	}
+	if gotPath != "/a2a/tasks/task-1/artifacts/artifact-1" {
+		t.Errorf("Expected the URI to be resolved against the endpoint, got %q", gotPath)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Expected auth header to be sent to the agent, got %q", gotAuth)
+	}
+}
+
+func TestResolveFile_LocalFile(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "report.txt")
+	if err := os.WriteFile(inside, []byte("local content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link.txt")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatal(err)
+	}
+	fileURI := func(path string) *string {
+		uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+		return &uri
+	}
+
+	c, err := NewClient(WithBaseURL("http://localhost:8080"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: fileURI(inside)}); err == nil {
+		t.Error("Expected file URIs to be rejected without a local file root")
+	}
+
+	c, err = NewClient(WithBaseURL("http://localhost:8080"), WithLocalFileRoot(root))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	part, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: fileURI(inside)})
+	if err != nil {
+		t.Fatalf("ResolveFile failed: %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(part.Content.Data)
+	if string(data) != "local content" {
+		t.Errorf("Expected %q, got %q", "local content", data)
+	}
+
+	for name, path := range map[string]string{
+		"outside the root":                  outside,
+		"escaping the root":                 filepath.Join(root, "..", filepath.Base(filepath.Dir(outside)), "secret.txt"),
+		"symlink pointing outside the root": link,
+	} {
+		if _, err := c.ResolveFile(context.Background(), a2a.FilePart{Type: "file", URI: fileURI(path)}); err == nil {
+			t.Errorf("Expected a file %s to be rejected", name)
+		}
+	}
+}
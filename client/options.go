@@ -1,10 +1,12 @@
 package client
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/trace"
 )
 
 // Config holds the configuration for the A2A client.
@@ -14,6 +16,26 @@ type Config struct {
 	Timeout     time.Duration     // Timeout for requests
 	AgentCard   *a2a.AgentCard    // Cached agent card (if already fetched)
 	AuthHeaders map[string]string // Authentication headers to include in requests
+	// Endpoints are the base URLs of several servers for the same agent; requests are spread across them
+	Endpoints        []string
+	EndpointStrategy EndpointStrategy // How requests are spread across Endpoints
+	// PropagatedHeaders are the trace/correlation headers copied from the request context onto outgoing requests
+	PropagatedHeaders []string
+	TracerProvider    trace.TracerProvider // Optional OpenTelemetry tracer provider for client call spans (defaults to the tracer in the request context)
+	Compression       bool                 // Whether to gzip large request bodies
+	MaxRetries        int                  // Maximum number of retries of a failed request (0 = no retries)
+	RetryDelay        time.Duration        // Delay between retries
+	MaxReconnects     int                  // Maximum number of attempts to resume a dropped stream (0 = no reconnect)
+	StreamBufferSize  int                  // Number of task updates buffered per stream (0 = DefaultStreamBufferSize)
+	SSEPath           string               // Path of the SSE endpoint relative to the base URL ("" = from the agent card, or DefaultSSEPath)
+	MaxFileSize       int64                // Largest decoded file ResolveFile accepts, in bytes (0 = DefaultMaxFileSize)
+	LocalFileRoot     string               // Directory ResolveFile may read file:// URIs from (empty = none)
+	UploadChunkSize   int                  // Size of the chunks UploadFile sends, in bytes (0 = DefaultUploadChunkSize)
+	MaxWait           time.Duration        // Longest WaitForCompletion waits for a task (0 = until the context is done)
+	Backoff           Backoff              // Growth of the delays between polls of a task and stream reconnects
+	RequestLogger     *slog.Logger         // Logs each JSON-RPC call, and ignored stream events at debug level, when set
+	// RedactFields are the JSON paths in params replaced before requests are logged
+	RedactFields []string
 }
 
 // Option is a function that modifies the client configuration.
@@ -23,10 +45,14 @@ type Option func(*Config)
 func DefaultConfig() Config {
 	return Config{
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			// Use a transport of our own, so Close does not close the idle connections of http.DefaultTransport
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+			Timeout:   30 * time.Second,
 		},
-		Timeout:     30 * time.Second,
-		AuthHeaders: make(map[string]string),
+		Timeout:           30 * time.Second,
+		AuthHeaders:       make(map[string]string),
+		PropagatedHeaders: trace.DefaultHeaders,
+		RetryDelay:        500 * time.Millisecond,
 	}
 }
 
@@ -37,6 +63,31 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
+// WithEndpoints sets several base URLs for the same logical agent. Requests are spread
+// across them using the given strategy, and a request that cannot connect to one endpoint
+// is retried on the next. Endpoints that fail to connect are tried last for a short while.
+// The first endpoint is used as the base URL if none is set.
+func WithEndpoints(endpoints []string, strategy EndpointStrategy) Option {
+	return func(c *Config) {
+		c.Endpoints = endpoints
+		c.EndpointStrategy = strategy
+		if c.BaseURL == "" && len(endpoints) > 0 {
+			c.BaseURL = endpoints[0]
+		}
+	}
+}
+
+// WithRequestLogging logs each JSON-RPC call's method, ID, endpoint, duration, and outcome
+// to logger, along with its params. The value at each of the redactFields JSON paths in params
+// (e.g. "pushNotification.authentication.credentials") is replaced before logging.
+// Stream events of unknown types, which are ignored, are logged at debug level.
+func WithRequestLogging(logger *slog.Logger, redactFields []string) Option {
+	return func(c *Config) {
+		c.RequestLogger = logger
+		c.RedactFields = redactFields
+	}
+}
+
 // WithHTTPClient sets the HTTP client for the client.
 func WithHTTPClient(httpClient *http.Client) Option {
 	return func(c *Config) {
@@ -74,3 +125,120 @@ func WithBearerToken(token string) Option {
 		c.AuthHeaders["Authorization"] = "Bearer " + token
 	}
 }
+
+// WithPropagatedHeaders sets the trace/correlation headers copied from the request context
+// onto outgoing requests, replacing the defaults (X-Request-ID and traceparent).
+func WithPropagatedHeaders(headers ...string) Option {
+	return func(c *Config) {
+		c.PropagatedHeaders = headers
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used to create a span for each
+// client call. The span's context is sent with the request, written by the global propagator.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = provider
+	}
+}
+
+// WithCompression enables or disables gzip compression of large request bodies.
+// Compressed responses are always accepted and decompressed transparently.
+func WithCompression(enabled bool) Option {
+	return func(c *Config) {
+		c.Compression = enabled
+	}
+}
+
+// WithRetries enables automatic retries of requests that fail with a connection error,
+// a 502, 503 or 504 response, or a 429 rate-limit response, up to maxRetries times. If the
+// response has a Retry-After header, the client waits that long instead of the retry delay,
+// giving up early if the wait would outlast the context's deadline. Retried tasks/send
+// requests carry an idempotency key (generated if not set) so the server does not create
+// duplicate tasks.
+func WithRetries(maxRetries int) Option {
+	return func(c *Config) {
+		c.MaxRetries = maxRetries
+	}
+}
+
+// WithRetryDelay sets the delay between retries. It is also the delay before the first
+// attempt to resume a dropped stream (see WithAutoReconnect).
+func WithRetryDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.RetryDelay = delay
+	}
+}
+
+// WithStreamBufferSize sets the number of task updates buffered per stream, so the stream
+// keeps being read while the consumer catches up. Values below 1 use DefaultStreamBufferSize.
+func WithStreamBufferSize(n int) Option {
+	return func(c *Config) {
+		c.StreamBufferSize = n
+	}
+}
+
+// WithMaxFileSize sets the largest file, in bytes once decoded, that ResolveFile accepts.
+// It guards against compressed files that expand to far more than they were sent as.
+// Values below 1 use DefaultMaxFileSize.
+func WithMaxFileSize(n int64) Option {
+	return func(c *Config) {
+		c.MaxFileSize = n
+	}
+}
+
+// WithLocalFileRoot lets ResolveFile read file:// URIs of files under dir, such as those
+// of an agent sharing the client's filesystem. Without it, file:// URIs are rejected, as
+// any agent could otherwise make the client read its local files.
+func WithLocalFileRoot(dir string) Option {
+	return func(c *Config) {
+		c.LocalFileRoot = dir
+	}
+}
+
+// WithUploadChunkSize sets the size of the chunks UploadFile sends a file in, in bytes.
+// Each chunk is sent base64-encoded in one request, so it must fit within the server's
+// request size limit. Values below 1 use DefaultUploadChunkSize.
+func WithUploadChunkSize(n int) Option {
+	return func(c *Config) {
+		c.UploadChunkSize = n
+	}
+}
+
+// WithBackoff sets how the delays between polls of a task's status (by WaitForCompletion and
+// Delegate) and attempts to resume a dropped stream grow. By default each delay doubles, up
+// to DefaultMaxBackoff, without jitter.
+func WithBackoff(backoff Backoff) Option {
+	return func(c *Config) {
+		c.Backoff = backoff
+	}
+}
+
+// WithMaxWait sets the longest WaitForCompletion waits for a task to complete before giving
+// up. A value of 0 waits until the context is done.
+func WithMaxWait(d time.Duration) Option {
+	return func(c *Config) {
+		c.MaxWait = d
+	}
+}
+
+// WithSSEPath sets the path of the server's SSE endpoint, relative to the base URL, that
+// streaming methods are sent to. By default the streaming endpoint advertised in the agent
+// card's capabilities is used once the card is fetched (or set with WithAgentCard), falling
+// back to DefaultSSEPath.
+func WithSSEPath(path string) Option {
+	return func(c *Config) {
+		c.SSEPath = path
+	}
+}
+
+// WithAutoReconnect makes streams resume automatically if the connection drops before the task
+// reaches a final state. The client resubscribes with tasks/resubscribe and the last event ID
+// received, up to maxAttempts times in a row, backing off between attempts (see WithBackoff)
+// starting from the retry delay, or from the delay the server sent in an SSE retry field. The
+// attempt count resets once a resumed stream delivers an event.
+func WithAutoReconnect(maxAttempts int) Option {
+	return func(c *Config) {
+		c.MaxReconnects = maxAttempts
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// ErrUnknownAgent is returned when a pool has no agent registered under the requested name.
+var ErrUnknownAgent = errors.New("unknown agent")
+
+// Pool holds clients for named downstream agents and routes delegated tasks to them by name.
+type Pool struct {
+	agents map[string]*Client
+	mu     sync.RWMutex
+}
+
+// NewPool creates a new empty agent pool.
+func NewPool() *Pool {
+	return &Pool{
+		agents: make(map[string]*Client),
+	}
+}
+
+// Register adds or replaces the client for the named agent.
+func (p *Pool) Register(name string, client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.agents[name] = client
+}
+
+// Get returns the client for the named agent.
+func (p *Pool) Get(name string) (*Client, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	client, ok := p.agents[name]
+	return client, ok
+}
+
+// Names returns the names of all registered agents in sorted order.
+func (p *Pool) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.agents))
+	for name := range p.agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Delegate sends a message to the named agent and waits for its response.
+// It returns an error wrapping ErrUnknownAgent if no agent is registered under the name.
+func (p *Pool) Delegate(ctx context.Context, name string, message a2a.Message, opts *DelegateOptions) (*DelegateResult, error) {
+	client, ok := p.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAgent, name)
+	}
+	return client.Delegate(ctx, message, opts)
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// newMockAgent starts a mock agent that completes every task with the given reply
+// after reporting it as working for a couple of polls.
+func newMockAgent(t *testing.T, reply string) *Client {
+	t.Helper()
+
+	var polls int32
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		task := a2a.Task{
+			ID:     "task-1",
+			Status: a2a.TaskStatus{State: a2a.TaskStateSubmitted},
+		}
+
+		if request.Method == "tasks/get" {
+			if atomic.AddInt32(&polls, 1) < 3 {
+				task.Status.State = a2a.TaskStateWorking
+			} else {
+				task.Status = a2a.TaskStatus{
+					State: a2a.TaskStateCompleted,
+					Message: &a2a.Message{
+						Role:  a2a.RoleAgent,
+						Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: reply}},
+					},
+				}
+				task.Artifacts = []a2a.Artifact{
+					{ID: "artifact-1", TaskID: task.ID, Part: a2a.TextPart{Type: "text", Text: "artifact"}},
+				}
+			}
+		}
+
+		return a2a.JSONRPCResponse{JSONRPC: "2.0", ID: request.ID, Result: task}
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+func newUserMessage(text string) a2a.Message {
+	return a2a.Message{
+		Role:  a2a.RoleUser,
+		Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+	}
+}
+
+func TestPool_Register(t *testing.T) {
+	pool := NewPool()
+	web := newMockAgent(t, "web")
+	reasoner := newMockAgent(t, "reasoner")
+
+	pool.Register("web", web)
+	pool.Register("reasoner", reasoner)
+
+	if got, ok := pool.Get("web"); !ok || got != web {
+		t.Error("Expected web agent to be registered")
+	}
+	if _, ok := pool.Get("missing"); ok {
+		t.Error("Expected missing agent not to be registered")
+	}
+	if names := pool.Names(); !reflect.DeepEqual(names, []string{"reasoner", "web"}) {
+		t.Errorf("Unexpected names: %v", names)
+	}
+}
+
+func TestPool_Delegate(t *testing.T) {
+	pool := NewPool()
+	pool.Register("web", newMockAgent(t, "web answer"))
+	pool.Register("reasoner", newMockAgent(t, "reasoner answer"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result, err := pool.Delegate(ctx, "reasoner", newUserMessage("why?"), &DelegateOptions{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Delegate failed: %v", err)
+	}
+
+	if result.Task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected completed task, got %s", result.Task.Status.State)
+	}
+	if result.Text != "reasoner answer" {
+		t.Errorf("Expected reasoner answer, got %q", result.Text)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].ID != "artifact-1" {
+		t.Errorf("Unexpected artifacts: %+v", result.Artifacts)
+	}
+}
+
+func TestPool_DelegateUnknownAgent(t *testing.T) {
+	pool := NewPool()
+
+	_, err := pool.Delegate(context.Background(), "missing", newUserMessage("hello"), nil)
+	if !errors.Is(err, ErrUnknownAgent) {
+		t.Fatalf("Expected ErrUnknownAgent, got %v", err)
+	}
+}
+
+func TestClient_DelegateContextCancelled(t *testing.T) {
+	c := newMockAgent(t, "never")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.Delegate(ctx, newUserMessage("hello"), &DelegateOptions{PollInterval: time.Hour}); err == nil {
+		t.Fatal("Expected Delegate to fail when the context is cancelled")
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/schema"
+)
+
+// SkillClient is a client that invokes an agent's skills by ID, as described by its agent
+// card. Input is checked against each skill's input schema before it is sent, so unknown
+// skills and invalid input are reported without a round trip to the agent.
+type SkillClient struct {
+	*Client
+	card   *a2a.AgentCard
+	skills map[string]a2a.AgentSkill
+}
+
+// NewSkillClient creates a client for the agent at baseURL and fetches its agent card.
+// The options are applied as for NewClient.
+func NewSkillClient(ctx context.Context, baseURL string, opts ...Option) (*SkillClient, error) {
+	c, err := NewClient(append([]Option{WithBaseURL(baseURL)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := c.FetchAgentCard(ctx)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+
+	skills := make(map[string]a2a.AgentSkill, len(card.Skills))
+	for _, skill := range card.Skills {
+		skills[skill.ID] = skill
+	}
+	return &SkillClient{Client: c, card: card, skills: skills}, nil
+}
+
+// Skills returns the skills the agent advertises, in the order of its agent card.
+func (c *SkillClient) Skills() []a2a.AgentSkill {
+	return append([]a2a.AgentSkill(nil), c.card.Skills...)
+}
+
+// Skill returns the skill with the given ID, if the agent has one.
+func (c *SkillClient) Skill(skillID string) (a2a.AgentSkill, bool) {
+	skill, ok := c.skills[skillID]
+	return skill, ok
+}
+
+// ValidateInput checks input against the input schema of a skill. It returns an
+// a2a.ErrSkillNotFound error if the agent has no such skill, and an a2a.ErrValidation
+// error listing the invalid fields if the input does not match the schema.
+func (c *SkillClient) ValidateInput(skillID string, input interface{}) error {
+	return schema.ValidateSkillInput(c.card, skillID, input)
+}
+
+// Invoke sends a task invoking a skill with the given input, once it has passed
+// ValidateInput. String input is sent as a text part; any other input is sent as a JSON
+// data part.
+func (c *SkillClient) Invoke(ctx context.Context, skillID string, input interface{}) (*a2a.Task, error) {
+	if err := c.ValidateInput(skillID, input); err != nil {
+		return nil, err
+	}
+
+	var part a2a.Part
+	if text, ok := input.(string); ok {
+		part = a2a.TextPart{Type: "text", Text: text}
+	} else {
+		part = a2a.DataPart{Type: "data", MimeType: "application/json", Data: input}
+	}
+
+	return c.SendTask(ctx, &a2a.TaskSendParams{
+		SkillID: &skillID,
+		Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{part}},
+	})
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// newSkillServer starts a server with a search skill and a free-form chat skill, recording
+// the params of each tasks/send request it receives.
+func newSkillServer(t *testing.T) (*httptest.Server, func() []a2a.TaskSendParams) {
+	t.Helper()
+
+	card := a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "skilled-agent",
+		Name:       "Skilled Agent",
+		Skills: []a2a.AgentSkill{
+			{
+				ID:   "search",
+				Name: "Search",
+				InputSchema: map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"query"},
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{"type": "string"},
+						"limit": map[string]interface{}{"type": "integer", "maximum": 10},
+					},
+				},
+			},
+			{ID: "chat", Name: "Chat"},
+		},
+	}
+
+	var mu sync.Mutex
+	var sent []a2a.TaskSendParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/agent.json" {
+			json.NewEncoder(w).Encode(card)
+			return
+		}
+
+		var request a2a.JSONRPCRequest
+		var params a2a.TaskSendParams
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || json.Unmarshal(request.Params, &params) != nil {
+			t.Errorf("Failed to decode request")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		sent = append(sent, params)
+		mu.Unlock()
+
+		json.NewEncoder(w).Encode(a2a.JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  a2a.Task{ID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateSubmitted}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []a2a.TaskSendParams {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]a2a.TaskSendParams(nil), sent...)
+	}
+}
+
+func TestSkillClient_Invoke(t *testing.T) {
+	server, sent := newSkillServer(t)
+
+	c, err := NewSkillClient(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create skill client: %v", err)
+	}
+	defer c.Close()
+
+	if skills := c.Skills(); len(skills) != 2 || skills[0].ID != "search" {
+		t.Errorf("Expected the card's skills, got %+v", skills)
+	}
+
+	task, err := c.Invoke(context.Background(), "search", map[string]interface{}{"query": "a2a", "limit": 5})
+	if err != nil {
+		t.Fatalf("Failed to invoke skill: %v", err)
+	}
+	if task.ID != "task-1" {
+		t.Errorf("Expected task-1, got %q", task.ID)
+	}
+	if _, err := c.Invoke(context.Background(), "chat", "hello"); err != nil {
+		t.Fatalf("Failed to invoke skill without a schema: %v", err)
+	}
+
+	requests := sent()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 tasks to be sent, got %d", len(requests))
+	}
+	if requests[0].SkillID == nil || *requests[0].SkillID != "search" {
+		t.Errorf("Expected the search skill to be requested, got %v", requests[0].SkillID)
+	}
+	data, ok := requests[0].Message.Parts[0].(a2a.DataPart)
+	if !ok || data.Data.(map[string]interface{})["query"] != "a2a" {
+		t.Errorf("Expected the input as a data part, got %+v", requests[0].Message.Parts)
+	}
+	if text, ok := requests[1].Message.Parts[0].(a2a.TextPart); !ok || text.Text != "hello" {
+		t.Errorf("Expected string input as a text part, got %+v", requests[1].Message.Parts)
+	}
+}
+
+func TestSkillClient_InvokeRejectedLocally(t *testing.T) {
+	server, sent := newSkillServer(t)
+
+	c, err := NewSkillClient(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create skill client: %v", err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		name      string
+		skillID   string
+		input     interface{}
+		wantCode  int
+		wantField string
+	}{
+		{name: "unknown skill", skillID: "translate", input: "hello", wantCode: a2a.CodeSkillNotFound},
+		{name: "missing field", skillID: "search", input: map[string]interface{}{"limit": 5}, wantCode: a2a.CodeInvalidParams, wantField: "query"},
+		{name: "out of range", skillID: "search", input: map[string]interface{}{"query": "a2a", "limit": 50}, wantCode: a2a.CodeInvalidParams, wantField: "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Invoke(context.Background(), tt.skillID, tt.input)
+
+			var a2aErr *a2a.Error
+			if !errors.As(err, &a2aErr) || a2aErr.Code != tt.wantCode {
+				t.Fatalf("Expected error code %d, got %v", tt.wantCode, err)
+			}
+			if tt.wantField != "" {
+				if fields := a2aErr.ValidationErrors(); len(fields) != 1 || fields[0].Field != tt.wantField {
+					t.Errorf("Expected a validation error for %q, got %+v", tt.wantField, fields)
+				}
+			}
+		})
+	}
+
+	if requests := sent(); len(requests) != 0 {
+		t.Errorf("Expected no tasks to be sent, got %d", len(requests))
+	}
+}
@@ -2,15 +2,28 @@ package client
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/trace"
 )
 
+// DefaultSSEPath is the default path of the SSE endpoint, relative to the base URL.
+const DefaultSSEPath = "sse"
+
+// DefaultStreamBufferSize is the default number of task updates buffered per stream.
+const DefaultStreamBufferSize = 64
+
 // SSEEvent represents an event received from an SSE stream.
 type SSEEvent struct {
 	ID    string
@@ -21,6 +34,7 @@ type SSEEvent struct {
 // TaskUpdate represents an update to a task (either status or artifact).
 type TaskUpdate struct {
 	Type     string // "status" or "artifact"
+	TaskID   string // The task the update belongs to
 	Status   *a2a.TaskStatus
 	Artifact *a2a.Artifact
 }
@@ -30,80 +44,221 @@ type SSEClient struct {
 	httpClient  *http.Client
 	baseURL     string
 	authHeaders map[string]string
+
+	propagatedHeaders []string          // Trace headers copied from the request context
+	balancer          *endpointBalancer // Selects the endpoint for each stream
+	maxReconnects     int               // Maximum reconnection attempts after a stream drops (0 = none)
+	backoff           Backoff           // Delays between reconnection attempts
+	logger            *slog.Logger      // Optional logger for ignored events
+	bufferSize        int               // Number of updates buffered for a slow consumer
+
+	// closed is cancelled by Close to stop the client's streams, which are tracked by streams
+	closed    context.Context
+	closeFunc context.CancelFunc
+	mu        sync.Mutex
+	isClosed  bool
+	streams   sync.WaitGroup
+	// streamEndpoint is a path relative to the base URL or an absolute URL, and protocol is
+	// its transport ("" = SSE). Both are guarded by mu.
+	streamEndpoint string
+	protocol       string
 }
 
 // NewSSEClient creates a new SSE client.
 func NewSSEClient(httpClient *http.Client, baseURL string, authHeaders map[string]string) *SSEClient {
+	closed, closeFunc := context.WithCancel(context.Background())
 	return &SSEClient{
-		httpClient:  httpClient,
-		baseURL:     baseURL,
-		authHeaders: authHeaders,
+		httpClient:        httpClient,
+		baseURL:           baseURL,
+		authHeaders:       authHeaders,
+		propagatedHeaders: trace.DefaultHeaders,
+		balancer:          newEndpointBalancer([]string{baseURL}, RoundRobin),
+		bufferSize:        DefaultStreamBufferSize,
+		streamEndpoint:    DefaultSSEPath,
+		closed:            closed,
+		closeFunc:         closeFunc,
 	}
 }
 
+// Close stops all of the client's streams, including any waiting to reconnect, and waits
+// for them to finish. Streams cannot be opened after the client is closed. It is safe to
+// call more than once.
+func (c *SSEClient) Close() error {
+	c.mu.Lock()
+	if c.isClosed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.isClosed = true
+	c.closeFunc()
+	c.mu.Unlock()
+
+	c.streams.Wait()
+	return nil
+}
+
+// setStreamingEndpoint sets the endpoint used by streams opened afterwards, either a path
+// relative to the base URL or an absolute URL, and the protocol it speaks.
+func (c *SSEClient) setStreamingEndpoint(endpoint, protocol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streamEndpoint = endpoint
+	c.protocol = protocol
+}
+
+// streamURL returns the URL of the streaming endpoint for a base URL.
+func streamURL(baseURL, endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.IsAbs() {
+		return endpoint
+	}
+	return joinPath(baseURL, endpoint)
+}
+
+// startStream registers a new stream, returning a context that is also cancelled when the
+// client is closed and a function to call once the stream has finished.
+func (c *SSEClient) startStream(ctx context.Context) (context.Context, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isClosed {
+		return nil, nil, ErrClosed
+	}
+
+	c.streams.Add(1)
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.closed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		c.streams.Done()
+	}, nil
+}
+
+// openStream posts a streaming JSON-RPC request, failing over to the next endpoint if one
+// cannot be reached, and returns the response once the server has accepted the stream.
+func (c *SSEClient) openStream(ctx context.Context, requestJSON []byte, lastEventID string) (*http.Response, error) {
+	c.mu.Lock()
+	streamEndpoint, protocol := c.streamEndpoint, c.protocol
+	c.mu.Unlock()
+
+	// Only SSE is implemented
+	if protocol != "" && protocol != a2a.StreamingProtocolSSE {
+		return nil, fmt.Errorf("unsupported streaming protocol %q", protocol)
+	}
+
+	var resp *http.Response
+	err := c.balancer.do(func(endpoint string) error {
+		// Create HTTP request
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, streamURL(endpoint, streamEndpoint), bytes.NewReader(requestJSON))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Add headers
+		for name, value := range c.authHeaders {
+			req.Header.Set(name, value)
+		}
+		trace.Inject(ctx, req, c.propagatedHeaders)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+		req.Header.Set("Connection", "keep-alive")
+
+		// Add Last-Event-ID header if provided
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		// Send request
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to send request: %w", err)
+			}
+			return &connectionError{fmt.Errorf("failed to send request: %w", err)}
+		}
+
+		// Check status code
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			if isRetryableStatus(resp.StatusCode) || resp.StatusCode == http.StatusTooManyRequests {
+				return &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // SubscribeToTask subscribes to task updates via SSE.
 // It returns a channel for receiving task updates and an error channel.
 func (c *SSEClient) SubscribeToTask(ctx context.Context, params *a2a.TaskSendParams) (<-chan TaskUpdate, <-chan error) {
-	updateChan := make(chan TaskUpdate)
+	updateChan := make(chan TaskUpdate, c.bufferSize)
 	errChan := make(chan error, 1)
 
-	// Create JSON-RPC request
-	request := a2a.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "tasks/sendSubscribe",
-		ID:      generateRequestID(),
-	}
-
-	// Marshal params
-	paramsJSON, err := json.Marshal(params)
+	ctx, done, err := c.startStream(ctx)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to marshal params: %w", err)
+		errChan <- err
 		close(updateChan)
 		close(errChan)
 		return updateChan, errChan
 	}
-	request.Params = paramsJSON
 
-	// Marshal request
-	requestJSON, err := json.Marshal(request)
+	// Create JSON-RPC request
+	requestJSON, err := newStreamRequest("tasks/sendSubscribe", params)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to marshal request: %w", err)
+		done()
+		errChan <- err
 		close(updateChan)
 		close(errChan)
 		return updateChan, errChan
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sse", strings.NewReader(string(requestJSON)))
+	// Open the stream on the first reachable endpoint
+	resp, err := c.openStream(ctx, requestJSON, "")
 	if err != nil {
-		errChan <- fmt.Errorf("failed to create request: %w", err)
+		done()
+		errChan <- err
 		close(updateChan)
 		close(errChan)
 		return updateChan, errChan
 	}
 
-	// Add headers
-	for name, value := range c.authHeaders {
-		req.Header.Set(name, value)
+	// Start a goroutine to read the SSE stream
+	var taskID string
+	if params.TaskID != nil {
+		taskID = *params.TaskID
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
+	go func() {
+		defer done()
+		c.readStream(ctx, resp, &streamState{taskID: taskID}, updateChan, errChan)
+	}()
+
+	return updateChan, errChan
+}
 
-	// Send request
-	resp, err := c.httpClient.Do(req)
+// ResubscribeToTask resubscribes to task updates via SSE.
+// It returns a channel for receiving task updates and an error channel.
+func (c *SSEClient) ResubscribeToTask(ctx context.Context, taskID string, lastEventID string) (<-chan TaskUpdate, <-chan error) {
+	updateChan := make(chan TaskUpdate, c.bufferSize)
+	errChan := make(chan error, 1)
+
+	ctx, done, err := c.startStream(ctx)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to send request: %w", err)
+		errChan <- err
 		close(updateChan)
 		close(errChan)
 		return updateChan, errChan
 	}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		errChan <- fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	resp, err := c.resubscribe(ctx, taskID, lastEventID)
+	if err != nil {
+		done()
+		errChan <- err
 		close(updateChan)
 		close(errChan)
 		return updateChan, errChan
@@ -111,186 +266,327 @@ func (c *SSEClient) SubscribeToTask(ctx context.Context, params *a2a.TaskSendPar
 
 	// Start a goroutine to read the SSE stream
 	go func() {
-		defer resp.Body.Close()
-		defer close(updateChan)
+		defer done()
+		c.readStream(ctx, resp, &streamState{taskID: taskID, lastEventID: lastEventID}, updateChan, errChan)
+	}()
+
+	return updateChan, errChan
+}
+
+// SubscribeToTaskList streams the server's task list via SSE. Unlike task streams, the stream
+// is not reconnected if it drops; subscribe again with the NextCursor of the last page received.
+// It returns a channel for receiving pages and an error channel.
+func (c *SSEClient) SubscribeToTaskList(ctx context.Context, params *a2a.ListTasksParams) (<-chan a2a.ListTasksResult, <-chan error) {
+	pageChan := make(chan a2a.ListTasksResult, c.bufferSize)
+	errChan := make(chan error, 1)
+
+	ctx, done, err := c.startStream(ctx)
+	if err != nil {
+		errChan <- err
+		close(pageChan)
+		close(errChan)
+		return pageChan, errChan
+	}
+
+	if params == nil {
+		params = &a2a.ListTasksParams{}
+	}
+	requestJSON, err := newStreamRequest("tasks/listSubscribe", params)
+	if err != nil {
+		done()
+		errChan <- err
+		close(pageChan)
+		close(errChan)
+		return pageChan, errChan
+	}
+
+	resp, err := c.openStream(ctx, requestJSON, "")
+	if err != nil {
+		done()
+		errChan <- err
+		close(pageChan)
+		close(errChan)
+		return pageChan, errChan
+	}
+
+	// Start a goroutine to read the SSE stream
+	go func() {
+		defer done()
+		defer close(pageChan)
 		defer close(errChan)
+		defer resp.Body.Close()
 
 		scanner := bufio.NewScanner(resp.Body)
+		// A page of tasks can be much longer than the scanner's default line limit
+		scanner.Buffer(nil, maxTaskListEventSize)
 		var event SSEEvent
-
+		var data []string
 		for scanner.Scan() {
 			line := scanner.Text()
-
-			// Skip empty lines
-			if line == "" {
-				// End of event, process it
-				if event.Event != "" && event.Data != "" {
-					c.processEvent(event, updateChan, errChan)
-					event = SSEEvent{} // Reset event
+			if line != "" {
+				if strings.HasPrefix(line, ":") {
+					continue
+				}
+				field, value := parseSSEField(line)
+				switch field {
+				case "event":
+					event.Event = value
+				case "data":
+					data = append(data, value)
 				}
 				continue
 			}
 
-			// Parse the line
-			if strings.HasPrefix(line, "id:") {
-				event.ID = strings.TrimSpace(line[3:])
-			} else if strings.HasPrefix(line, "event:") {
-				event.Event = strings.TrimSpace(line[6:])
-			} else if strings.HasPrefix(line, "data:") {
-				event.Data = strings.TrimSpace(line[5:])
+			// An empty line ends the event
+			if event.Event == "taskList" && len(data) > 0 {
+				var page a2a.ListTasksResult
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &page); err != nil {
+					sendError(ctx, errChan, fmt.Errorf("failed to unmarshal task list: %w", err))
+				} else {
+					select {
+					case pageChan <- page:
+					case <-ctx.Done():
+						return
+					}
+				}
 			}
+			event = SSEEvent{}
+			data = nil
 		}
-
-		if err := scanner.Err(); err != nil {
-			errChan <- fmt.Errorf("error reading SSE stream: %w", err)
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			sendError(ctx, errChan, fmt.Errorf("error reading SSE stream: %w", err))
 		}
 	}()
 
-	return updateChan, errChan
+	return pageChan, errChan
 }
 
-// ResubscribeToTask resubscribes to task updates via SSE.
-// It returns a channel for receiving task updates and an error channel.
-func (c *SSEClient) ResubscribeToTask(ctx context.Context, taskID string, lastEventID string) (<-chan TaskUpdate, <-chan error) {
-	updateChan := make(chan TaskUpdate)
-	errChan := make(chan error, 1)
+// maxTaskListEventSize is the largest task list event SubscribeToTaskList reads.
+const maxTaskListEventSize = 64 << 20
 
+// newStreamRequest marshals a JSON-RPC request for a streaming method.
+func newStreamRequest(method string, params interface{}) ([]byte, error) {
 	// Create JSON-RPC request
 	request := a2a.JSONRPCRequest{
 		JSONRPC: "2.0",
-		Method:  "tasks/resubscribe",
+		Method:  method,
 		ID:      generateRequestID(),
 	}
 
-	// Create params
-	params := a2a.TaskIdParams{
-		TaskID: taskID,
-	}
-
 	// Marshal params
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to marshal params: %w", err)
-		close(updateChan)
-		close(errChan)
-		return updateChan, errChan
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
 	}
 	request.Params = paramsJSON
 
 	// Marshal request
 	requestJSON, err := json.Marshal(request)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to marshal request: %w", err)
-		close(updateChan)
-		close(errChan)
-		return updateChan, errChan
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	return requestJSON, nil
+}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sse", strings.NewReader(string(requestJSON)))
+// resubscribe opens a tasks/resubscribe stream for a task, resuming after lastEventID if set.
+func (c *SSEClient) resubscribe(ctx context.Context, taskID, lastEventID string) (*http.Response, error) {
+	requestJSON, err := newStreamRequest("tasks/resubscribe", a2a.TaskIdParams{TaskID: taskID})
 	if err != nil {
-		errChan <- fmt.Errorf("failed to create request: %w", err)
-		close(updateChan)
-		close(errChan)
-		return updateChan, errChan
+		return nil, err
 	}
+	return c.openStream(ctx, requestJSON, lastEventID)
+}
 
-	// Add headers
-	for name, value := range c.authHeaders {
-		req.Header.Set(name, value)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-
-	// Add Last-Event-ID header if provided
-	if lastEventID != "" {
-		req.Header.Set("Last-Event-ID", lastEventID)
-	}
+// streamState tracks how far a client has read a task's event stream.
+type streamState struct {
+	taskID      string // Learned from the first event if not known when subscribing
+	lastEventID string
+	received    bool          // Whether an event was received on the current connection
+	final       bool          // Whether the task has reached a final state
+	retryDelay  time.Duration // Reconnection delay requested by the server with a retry field (0 = not set)
+}
 
-	// Send request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		errChan <- fmt.Errorf("failed to send request: %w", err)
-		close(updateChan)
-		close(errChan)
-		return updateChan, errChan
-	}
+// readStream reads task updates from an SSE response until the stream ends. If the stream
+// ends before the task reaches a final state and auto-reconnect is enabled, it resubscribes
+// with the last event ID received, backing off exponentially between attempts.
+func (c *SSEClient) readStream(ctx context.Context, resp *http.Response, state *streamState, updateChan chan<- TaskUpdate, errChan chan<- error) {
+	defer close(updateChan)
+	defer close(errChan)
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
+	attempts := 0
+	for {
+		err := c.readEvents(ctx, resp, state, updateChan, errChan)
 		resp.Body.Close()
-		errChan <- fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-		close(updateChan)
-		close(errChan)
-		return updateChan, errChan
-	}
-
-	// Start a goroutine to read the SSE stream
-	go func() {
-		defer resp.Body.Close()
-		defer close(updateChan)
-		defer close(errChan)
+		if state.final {
+			return
+		}
+		if state.received {
+			// The connection made progress, so start backing off afresh
+			attempts = 0
+		}
 
-		scanner := bufio.NewScanner(resp.Body)
-		var event SSEEvent
+		// Reconnect until a stream is opened or the attempts run out
+		for {
+			if attempts >= c.maxReconnects || state.taskID == "" || ctx.Err() != nil {
+				if err != nil {
+					sendError(ctx, errChan, fmt.Errorf("error reading SSE stream: %w", err))
+				}
+				return
+			}
 
-		for scanner.Scan() {
-			line := scanner.Text()
+			backoff := c.backoff
+			if state.retryDelay > 0 {
+				backoff.Base = state.retryDelay
+			}
+			delay := backoff.Delay(attempts)
+			attempts++
 
-			// Skip empty lines
-			if line == "" {
-				// End of event, process it
-				if event.Event != "" && event.Data != "" {
-					c.processEvent(event, updateChan, errChan)
-					event = SSEEvent{} // Reset event
-				}
+			select {
+			case <-ctx.Done():
 				continue
+			case <-time.After(delay):
 			}
 
-			// Parse the line
-			if strings.HasPrefix(line, "id:") {
-				event.ID = strings.TrimSpace(line[3:])
-			} else if strings.HasPrefix(line, "event:") {
-				event.Event = strings.TrimSpace(line[6:])
-			} else if strings.HasPrefix(line, "data:") {
-				event.Data = strings.TrimSpace(line[5:])
+			var openErr error
+			if resp, openErr = c.resubscribe(ctx, state.taskID, state.lastEventID); openErr == nil {
+				break
 			}
+			err = openErr
 		}
+	}
+}
 
-		if err := scanner.Err(); err != nil {
-			errChan <- fmt.Errorf("error reading SSE stream: %w", err)
+// readEvents reads events from an SSE response until the stream ends, recording progress in state.
+// It returns nil if the stream ended cleanly.
+// Sends give up if ctx is cancelled, so a consumer that stops reading does not leak the reader.
+func (c *SSEClient) readEvents(ctx context.Context, resp *http.Response, state *streamState, updateChan chan<- TaskUpdate, errChan chan<- error) error {
+	state.received = false
+
+	scanner := bufio.NewScanner(resp.Body)
+	var event SSEEvent
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// An empty line ends the event
+		if line == "" {
+			event.Data = strings.Join(data, "\n")
+			if event.Event != "" && event.Data != "" {
+				update, taskID, err := c.processEvent(event)
+				if err != nil {
+					if !sendError(ctx, errChan, err) {
+						return ctx.Err()
+					}
+				} else if update != nil {
+					select {
+					case updateChan <- *update:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				if event.ID != "" {
+					state.lastEventID = event.ID
+				}
+				if taskID != "" && state.taskID == "" {
+					state.taskID = taskID
+				}
+				state.received = true
+				state.final = state.final || isFinalUpdate(update)
+			}
+			event = SSEEvent{} // Reset event
+			data = nil
+			continue
 		}
-	}()
 
-	return updateChan, errChan
+		// Lines starting with a colon are comments, such as heartbeats
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		// Parse the field
+		field, value := parseSSEField(line)
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			// Multiple data lines are joined with newlines
+			data = append(data, value)
+		case "retry":
+			// The server's preferred reconnection delay, in milliseconds
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				state.retryDelay = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	return scanner.Err()
 }
 
-// processEvent processes an SSE event and sends it to the appropriate channel.
-func (c *SSEClient) processEvent(event SSEEvent, updateChan chan<- TaskUpdate, errChan chan<- error) {
+// parseSSEField splits an SSE line into its field name and value. A single space after
+// the colon is not part of the value, and a line without a colon is a field with no value.
+func parseSSEField(line string) (string, string) {
+	field, value, found := strings.Cut(line, ":")
+	if !found {
+		return line, ""
+	}
+	return field, strings.TrimPrefix(value, " ")
+}
+
+// processEvent decodes an SSE event into a task update. It returns a nil update for
+// event types this client does not know, and the ID of the task the event belongs to.
+func (c *SSEClient) processEvent(event SSEEvent) (*TaskUpdate, string, error) {
 	switch event.Event {
 	case "taskStatusUpdate":
 		var statusEvent a2a.TaskStatusUpdateEvent
 		if err := json.Unmarshal([]byte(event.Data), &statusEvent); err != nil {
-			errChan <- fmt.Errorf("failed to unmarshal status update: %w", err)
-			return
+			return nil, "", fmt.Errorf("failed to unmarshal status update: %w", err)
 		}
-		updateChan <- TaskUpdate{
+		return &TaskUpdate{
 			Type:   "status",
+			TaskID: statusEvent.TaskID,
 			Status: &statusEvent.Status,
-		}
+		}, statusEvent.TaskID, nil
 	case "taskArtifactUpdate":
 		var artifactEvent a2a.TaskArtifactUpdateEvent
 		if err := json.Unmarshal([]byte(event.Data), &artifactEvent); err != nil {
-			errChan <- fmt.Errorf("failed to unmarshal artifact update: %w", err)
-			return
+			return nil, "", fmt.Errorf("failed to unmarshal artifact update: %w", err)
 		}
-		updateChan <- TaskUpdate{
+		return &TaskUpdate{
 			Type:     "artifact",
+			TaskID:   artifactEvent.TaskID,
 			Artifact: &artifactEvent.Artifact,
-		}
+		}, artifactEvent.TaskID, nil
 	default:
-		errChan <- fmt.Errorf("unknown event type: %s", event.Event)
+		// Ignore event types this client does not know, such as ones added in later protocol versions
+		if c.logger != nil {
+			c.logger.Debug("ignoring unknown SSE event", "event", event.Event, "id", event.ID)
+		}
+		return nil, "", nil
+	}
+}
+
+// isFinalUpdate reports whether an update leaves the task in a state after which no
+// further events are streamed.
+func isFinalUpdate(update *TaskUpdate) bool {
+	if update == nil || update.Status == nil {
+		return false
+	}
+	switch update.Status.State {
+	case a2a.TaskStateCompleted, a2a.TaskStateFailed, a2a.TaskStateCancelled, a2a.TaskStateInputRequired:
+		return true
+	}
+	return false
+}
+
+// sendError sends err to errChan, giving up if ctx is cancelled first. It reports whether err was sent.
+func sendError(ctx context.Context, errChan chan<- error, err error) bool {
+	select {
+	case errChan <- err:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
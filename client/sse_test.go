@@ -0,0 +1,435 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// writeStatusEvent writes a task status SSE event and flushes it to the client.
+func writeStatusEvent(w http.ResponseWriter, id, taskID string, state a2a.TaskState) {
+	data, _ := json.Marshal(a2a.TaskStatusUpdateEvent{TaskID: taskID, Status: a2a.TaskStatus{State: state}})
+	fmt.Fprintf(w, "event: taskStatusUpdate\nid: %s\ndata: %s\n\n", id, data)
+	w.(http.Flusher).Flush()
+}
+
+// droppingStreamServer starts a server whose first stream drops after one event and whose
+// resubscribe stream completes the task. It records each stream request's method and Last-Event-ID.
+func droppingStreamServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, request.Method+" "+r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch request.Method {
+		case "tasks/sendSubscribe":
+			// Drop the connection before the task finishes
+			writeStatusEvent(w, "1", "task-1", a2a.TaskStateWorking)
+		case "tasks/resubscribe":
+			var params a2a.TaskIdParams
+			json.Unmarshal(request.Params, &params)
+			if params.TaskID != "task-1" {
+				t.Errorf("Expected resubscribe for task-1, got %q", params.TaskID)
+			}
+			writeStatusEvent(w, "2", "task-1", a2a.TaskStateCompleted)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestSSEClient_AutoReconnect(t *testing.T) {
+	server, requests := droppingStreamServer(t)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithAutoReconnect(3), WithRetryDelay(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{
+		Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}}},
+	})
+
+	var states []a2a.TaskState
+	for update := range updates {
+		states = append(states, update.Status.State)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(states) != 2 || states[0] != a2a.TaskStateWorking || states[1] != a2a.TaskStateCompleted {
+		t.Errorf("Expected working then completed, got %v", states)
+	}
+	got := requests()
+	if len(got) != 2 || got[0] != "tasks/sendSubscribe " || got[1] != "tasks/resubscribe 1" {
+		t.Errorf("Expected the stream to resume from event 1, got %q", got)
+	}
+}
+
+func TestSSEClient_NoReconnectByDefault(t *testing.T) {
+	server, requests := droppingStreamServer(t)
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	updates, errs := c.SendSubscribe(context.Background(), &a2a.TaskSendParams{
+		Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}}},
+	})
+	count := 0
+	for range updates {
+		count++
+	}
+	<-errs
+
+	if count != 1 {
+		t.Errorf("Expected 1 update before the stream dropped, got %d", count)
+	}
+	if got := requests(); len(got) != 1 {
+		t.Errorf("Expected no reconnection, got %q", got)
+	}
+}
+
+func TestSSEClient_ReadEventsMultiLineDataAndComments(t *testing.T) {
+	stream := strings.Join([]string{
+		": connected",
+		"retry: 250",
+		"",
+		"event: taskStatusUpdate",
+		"id: 1",
+		`data: {"taskId":"task-1",`,
+		`data: "status":{"state":"working","message":{"role":"agent","parts":[{"type":"text","text":"line one\nline two"}]}}}`,
+		"",
+		": heartbeat",
+		"",
+		"event: taskArtifactUpdate",
+		"id: 2",
+		`data:{"taskId":"task-1","artifact":{"id":"a1","taskId":"task-1","part":{"type":"text","text":"result"}}}`,
+	}, "\n") + "\n\n"
+
+	c := NewSSEClient(http.DefaultClient, "http://localhost", nil)
+	resp := &http.Response{Body: io.NopCloser(strings.NewReader(stream))}
+	updates := make(chan TaskUpdate, 10)
+	errs := make(chan error, 10)
+	state := &streamState{}
+
+	if err := c.readEvents(context.Background(), resp, state, updates, errs); err != nil {
+		t.Fatalf("readEvents failed: %v", err)
+	}
+	close(updates)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Unexpected stream error: %v", err)
+	}
+	var received []TaskUpdate
+	for update := range updates {
+		received = append(received, update)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 updates, got %d: %+v", len(received), received)
+	}
+	if received[0].Type != "status" || received[0].Status.State != a2a.TaskStateWorking {
+		t.Errorf("Expected working status built from multiple data lines, got %+v", received[0])
+	}
+	if text := received[0].Status.Message.Parts[0].(a2a.TextPart).Text; text != "line one\nline two" {
+		t.Errorf("Expected message text to survive, got %q", text)
+	}
+	if received[1].Type != "artifact" || received[1].Artifact.ID != "a1" {
+		t.Errorf("Expected artifact without a space after the colon, got %+v", received[1])
+	}
+	if state.lastEventID != "2" || state.taskID != "task-1" {
+		t.Errorf("Expected last event 2 for task-1, got %q for %q", state.lastEventID, state.taskID)
+	}
+	if state.retryDelay != 250*time.Millisecond {
+		t.Errorf("Expected retry delay of 250ms, got %v", state.retryDelay)
+	}
+}
+
+func TestParseSSEField(t *testing.T) {
+	tests := []struct {
+		line, field, value string
+	}{
+		{"data: hello", "data", "hello"},
+		{"data:hello", "data", "hello"},
+		{"data:  indented", "data", " indented"},
+		{"data: a: b", "data", "a: b"},
+		{"data", "data", ""},
+	}
+	for _, tt := range tests {
+		field, value := parseSSEField(tt.line)
+		if field != tt.field || value != tt.value {
+			t.Errorf("parseSSEField(%q) = %q, %q; want %q, %q", tt.line, field, value, tt.field, tt.value)
+		}
+	}
+}
+
+func TestSSEClient_IgnoresUnknownEventTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeStatusEvent(w, "1", "task-1", a2a.TaskStateWorking)
+		fmt.Fprint(w, "event: taskProgress\nid: 2\ndata: {\"taskId\":\"task-1\",\"percent\":50}\n\n")
+		writeStatusEvent(w, "3", "task-1", a2a.TaskStateCompleted)
+	}))
+	defer server.Close()
+
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := NewClient(WithBaseURL(server.URL), WithRequestLogging(logger, nil))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	updates, errs := c.Resubscribe(context.Background(), "task-1", "")
+	var states []a2a.TaskState
+	for update := range updates {
+		states = append(states, update.Status.State)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Expected unknown event to be ignored, got %v", err)
+	}
+	if len(states) != 2 || states[1] != a2a.TaskStateCompleted {
+		t.Errorf("Expected the subscription to continue to completion, got %v", states)
+	}
+	if !strings.Contains(logs.String(), "event=taskProgress") {
+		t.Errorf("Expected the ignored event to be logged, got %q", logs.String())
+	}
+}
+
+func TestSSEClient_SlowConsumerCancelled(t *testing.T) {
+	const bufferSize = 4
+	disconnected := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 100; i++ {
+			writeStatusEvent(w, fmt.Sprint(i), "task-1", a2a.TaskStateWorking)
+		}
+		// Keep the stream open until the client goes away
+		<-r.Context().Done()
+		close(disconnected)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL), WithStreamBufferSize(bufferSize))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, errs := c.Resubscribe(ctx, "task-1", "")
+	if cap(updates) != bufferSize {
+		t.Errorf("Expected update buffer of %d, got %d", bufferSize, cap(updates))
+	}
+
+	// Read one update, let the reader fill the buffer, then stop reading and cancel
+	<-updates
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	// The reader must stop without anyone draining its channels
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the connection to be closed after cancellation")
+	}
+	deadline := time.After(2 * time.Second)
+	remaining := 0
+	for open := true; open; {
+		select {
+		case _, open = <-updates:
+			if open {
+				remaining++
+			}
+		case <-deadline:
+			t.Fatal("Expected the update channel to be closed after cancellation")
+		}
+	}
+	if remaining > bufferSize {
+		t.Errorf("Expected at most %d buffered updates, got %d", bufferSize, remaining)
+	}
+	select {
+	case <-errs:
+	case <-deadline:
+		t.Fatal("Expected the error channel to be closed after cancellation")
+	}
+}
+
+func TestClient_CloseStopsStreams(t *testing.T) {
+	// Every stream drops after one event, so the client waits to reconnect
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeStatusEvent(w, "1", "task-1", a2a.TaskStateWorking)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL), WithAutoReconnect(5), WithRetryDelay(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	updates, errs := c.SendSubscribe(context.Background(), &a2a.TaskSendParams{
+		Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}}},
+	})
+
+	select {
+	case <-updates:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the first update")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		c.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for Close to stop the stream")
+	}
+
+	// Close waits for the stream's goroutine, so its channels are already closed
+	for range updates {
+	}
+	for range errs {
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected closing again to succeed, got %v", err)
+	}
+	if _, err := c.GetTask(context.Background(), "task-1"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+	_, errs = c.Resubscribe(context.Background(), "task-1", "")
+	if err := <-errs; !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed for a stream after Close, got %v", err)
+	}
+}
+
+func TestClient_StreamingEndpoint(t *testing.T) {
+	// The server records which path each stream is opened at and serves the card set by each case
+	var mu sync.Mutex
+	var paths []string
+	var capabilities a2a.AgentCapabilities
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/agent/.well-known/agent.json":
+			json.NewEncoder(w).Encode(a2a.AgentCard{A2AVersion: "1.0", ID: "agent", Name: "Agent", Capabilities: &capabilities})
+		case "/agent/sse", "/agent/events", "/agent/custom", "/streams/agent":
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeStatusEvent(w, "1", "task-1", a2a.TaskStateCompleted)
+		default:
+			paths = append(paths, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		capabilities *a2a.AgentCapabilities // Card fetched before streaming, if set
+		wantPath     string                 // Empty if no stream should be opened
+		wantErr      string
+	}{
+		{name: "default", wantPath: "/agent/" + DefaultSSEPath},
+		{
+			name:         "custom path in agent card",
+			capabilities: &a2a.AgentCapabilities{StreamingEndpoint: "events", StreamingProtocol: a2a.StreamingProtocolSSE},
+			wantPath:     "/agent/events",
+		},
+		{
+			name:         "absolute URL in agent card",
+			capabilities: &a2a.AgentCapabilities{StreamingEndpoint: server.URL + "/streams/agent"},
+			wantPath:     "/streams/agent",
+		},
+		{
+			name:         "configured path overrides agent card",
+			opts:         []Option{WithSSEPath("/custom")},
+			capabilities: &a2a.AgentCapabilities{StreamingEndpoint: "events"},
+			wantPath:     "/agent/custom",
+		},
+		{
+			name:         "unsupported protocol",
+			capabilities: &a2a.AgentCapabilities{StreamingEndpoint: "ws", StreamingProtocol: a2a.StreamingProtocolWebSocket},
+			wantErr:      "unsupported streaming protocol",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(append([]Option{WithBaseURL(server.URL + "/agent")}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			defer c.Close()
+			if tt.capabilities != nil {
+				mu.Lock()
+				capabilities = *tt.capabilities
+				mu.Unlock()
+				if _, err := c.FetchAgentCard(context.Background()); err != nil {
+					t.Fatalf("Failed to fetch agent card: %v", err)
+				}
+			}
+
+			mu.Lock()
+			paths = nil
+			mu.Unlock()
+
+			updates, errs := c.SendSubscribe(context.Background(), &a2a.TaskSendParams{})
+			for range updates {
+			}
+			streamErr := <-errs
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.wantErr != "" {
+				if streamErr == nil || !strings.Contains(streamErr.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, streamErr)
+				}
+				if len(paths) != 0 {
+					t.Errorf("Expected no stream to be opened, got requests to %v", paths)
+				}
+				return
+			}
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Fatalf("Expected the stream to be opened at %s, got requests to %v", tt.wantPath, paths)
+			}
+			if streamErr != nil {
+				t.Errorf("Stream failed: %v", streamErr)
+			}
+		})
+	}
+}
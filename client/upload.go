@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// DefaultUploadChunkSize is the default size of the chunks UploadFile sends.
+const DefaultUploadChunkSize = 1 << 20 // 1 MiB
+
+// maxUploadResumes is the number of times UploadFile resumes an upload from the server's
+// status before giving up.
+const maxUploadResumes = 3
+
+// InitUpload starts a chunked upload of a file to the agent. The server must have an
+// artifact store to accept uploads.
+func (c *Client) InitUpload(ctx context.Context, params *a2a.InitUploadParams) (*a2a.UploadStatus, error) {
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "files/initUpload",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	request.Params = paramsJSON
+
+	// Send request
+	var status a2a.UploadStatus
+	if err := c.sendJSONRPCRequest(ctx, request, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// UploadChunk sends a chunk of an upload. Chunks are numbered from 0 and must be sent in
+// order; a chunk sent early fails with an a2a.CodeUploadChunkOutOfOrder error whose data is
+// the upload's status (see UploadStatusFromError). Resending a chunk replaces it.
+func (c *Client) UploadChunk(ctx context.Context, uploadID string, index int, data []byte) (*a2a.UploadStatus, error) {
+	params := a2a.UploadChunkParams{
+		UploadID: uploadID,
+		Index:    index,
+		Data:     base64.StdEncoding.EncodeToString(data),
+	}
+
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "files/uploadChunk",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	request.Params = paramsJSON
+
+	// Send request
+	var status a2a.UploadStatus
+	if err := c.sendJSONRPCRequest(ctx, request, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CompleteUpload finishes an upload and returns a FilePart referencing the uploaded file,
+// which can be sent in a task's message.
+func (c *Client) CompleteUpload(ctx context.Context, uploadID string) (*a2a.FilePart, error) {
+	params := a2a.CompleteUploadParams{UploadID: uploadID}
+
+	// Create JSON-RPC request
+	request := a2a.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "files/completeUpload",
+		ID:      generateRequestID(),
+	}
+
+	// Marshal params
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	request.Params = paramsJSON
+
+	// Send request
+	var part a2a.FilePart
+	if err := c.sendJSONRPCRequest(ctx, request, &part); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+// UploadFile uploads the content of r in chunks of the size set by WithUploadChunkSize
+// and returns a FilePart referencing the uploaded file. If the server reports that a chunk
+// is missing, the upload resumes from the server's status, so r must be able to seek back.
+func (c *Client) UploadFile(ctx context.Context, r io.ReadSeeker, params a2a.InitUploadParams) (*a2a.FilePart, error) {
+	status, err := c.InitUpload(ctx, &params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	chunkSize := c.config.UploadChunkSize
+	if chunkSize < 1 {
+		chunkSize = DefaultUploadChunkSize
+	}
+	buf := make([]byte, chunkSize)
+
+	index, resumes := 0, 0
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("failed to read upload: %w", readErr)
+		}
+		if n == 0 {
+			break
+		}
+
+		if _, err := c.UploadChunk(ctx, status.UploadID, index, buf[:n]); err != nil {
+			// Go back to the first chunk the server is missing
+			missing, ok := UploadStatusFromError(err)
+			if !ok || resumes >= maxUploadResumes {
+				return nil, fmt.Errorf("failed to upload chunk %d: %w", index, err)
+			}
+			resumes++
+			if _, err := r.Seek(missing.ReceivedBytes-int64(index*chunkSize+n), io.SeekCurrent); err != nil {
+				return nil, fmt.Errorf("failed to resume upload: %w", err)
+			}
+			index = missing.NextChunk
+			continue
+		}
+		index++
+
+		if n < chunkSize {
+			break
+		}
+	}
+
+	part, err := c.CompleteUpload(ctx, status.UploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	return part, nil
+}
+
+// UploadStatusFromError returns the upload status carried by an error for a chunk sent out
+// of order, reporting whether err is such an error.
+func UploadStatusFromError(err error) (a2a.UploadStatus, bool) {
+	var a2aErr *a2a.Error
+	if !errors.As(err, &a2aErr) || a2aErr.Code != a2a.CodeUploadChunkOutOfOrder {
+		return a2a.UploadStatus{}, false
+	}
+	if status, ok := a2aErr.Data.(a2a.UploadStatus); ok {
+		return status, true
+	}
+
+	// Data decoded from a response is generic JSON
+	encoded, err := json.Marshal(a2aErr.Data)
+	if err != nil {
+		return a2a.UploadStatus{}, false
+	}
+	var status a2a.UploadStatus
+	if err := json.Unmarshal(encoded, &status); err != nil {
+		return a2a.UploadStatus{}, false
+	}
+	return status, true
+}
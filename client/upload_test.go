@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+func TestClient_UploadFileResumes(t *testing.T) {
+	// The server loses chunk 1 the first time it is sent, so chunk 2 arrives out of order
+	var received [][]byte
+	lost := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		response := a2a.JSONRPCResponse{JSONRPC: "2.0", ID: request.ID}
+		switch request.Method {
+		case "files/initUpload":
+			response.Result = a2a.UploadStatus{UploadID: "upload-1"}
+		case "files/uploadChunk":
+			var params a2a.UploadChunkParams
+			json.Unmarshal(request.Params, &params)
+			data, _ := base64.StdEncoding.DecodeString(params.Data)
+
+			status := a2a.UploadStatus{UploadID: "upload-1", NextChunk: len(received)}
+			for _, chunk := range received {
+				status.ReceivedBytes += int64(len(chunk))
+			}
+			switch {
+			case params.Index == 1 && !lost:
+				lost = true
+				response.Result = a2a.UploadStatus{UploadID: "upload-1", NextChunk: 2}
+			case params.Index > len(received):
+				response.Error = a2a.ErrUploadChunkOutOfOrder(params.Index, status).ToJSONRPCError()
+			default:
+				received = append(received[:params.Index], data)
+				response.Result = a2a.UploadStatus{UploadID: "upload-1", NextChunk: len(received)}
+			}
+		case "files/completeUpload":
+			uri := "file:///uploads/upload-1"
+			response.Result = a2a.FilePart{Type: "file", Filename: "notes.txt", URI: &uri}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL), WithUploadChunkSize(4))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	part, err := c.UploadFile(context.Background(), bytes.NewReader([]byte("abcdefghij")), a2a.InitUploadParams{Filename: "notes.txt"})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if part.URI == nil || *part.URI != "file:///uploads/upload-1" {
+		t.Errorf("Expected the completed upload's file part, got %+v", part)
+	}
+
+	if got := string(bytes.Join(received, nil)); got != "abcdefghij" {
+		t.Errorf("Expected the whole file to be received in order, got %q", got)
+	}
+	if !lost {
+		t.Error("Expected a chunk to be lost")
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// DefaultWaitPollInterval is the default interval between task status checks when waiting
+// for a task to complete.
+const DefaultWaitPollInterval = 500 * time.Millisecond
+
+// WaitForCompletion waits for a task to complete, fail or be cancelled, and returns the task
+// in its final state. If the agent card is cached and the agent supports streaming, the
+// client resubscribes to the task's updates, falling back to polling if the stream fails.
+// Otherwise it polls the task, first after pollInterval (DefaultWaitPollInterval if 0) and
+// then backing off as set by WithBackoff. It gives up when ctx is done or the client's
+// MaxWait passes.
+func (c *Client) WaitForCompletion(ctx context.Context, taskID string, pollInterval time.Duration) (*a2a.Task, error) {
+	if c.config.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.MaxWait)
+		defer cancel()
+	}
+
+	task, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if isComplete(task.Status.State) {
+		return task, nil
+	}
+
+	if c.supportsStreaming() && c.awaitFinalUpdate(ctx, taskID) {
+		if task, err = c.GetTask(ctx, taskID); err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+	}
+
+	backoff := c.pollBackoff(pollInterval, DefaultWaitPollInterval)
+	for attempt := 0; !isComplete(task.Status.State); attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("task %s did not complete: %w", taskID, ctx.Err())
+		case <-time.After(backoff.Delay(attempt)):
+		}
+
+		task, err = c.GetTask(ctx, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+	}
+	return task, nil
+}
+
+// pollBackoff returns the backoff between polls of a task, starting from pollInterval if it
+// is set, or else from the configured backoff's base or defaultInterval.
+func (c *Client) pollBackoff(pollInterval, defaultInterval time.Duration) Backoff {
+	backoff := c.config.Backoff
+	if pollInterval > 0 {
+		backoff.Base = pollInterval
+	}
+	return backoff.withBase(defaultInterval)
+}
+
+// supportsStreaming reports whether the cached agent card advertises streaming. The card is
+// not fetched if it is not cached.
+func (c *Client) supportsStreaming() bool {
+	c.cardMu.Lock()
+	defer c.cardMu.Unlock()
+	return c.card != nil && c.card.Capabilities != nil && c.card.Capabilities.SupportsStreaming
+}
+
+// awaitFinalUpdate resubscribes to a task and waits for it to complete, fail or be cancelled.
+// It reports whether it saw the task do so; if the stream ends first, e.g. because the task
+// requires input or the stream failed, the caller should poll the task instead.
+func (c *Client) awaitFinalUpdate(ctx context.Context, taskID string) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, errs := c.Resubscribe(ctx, taskID, "")
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			if update.Status != nil && isComplete(update.Status.State) {
+				return true
+			}
+		case _, ok := <-errs:
+			if !ok {
+				// The stream ended; any updates still buffered are read before updates closes
+				errs = nil
+				continue
+			}
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// isComplete reports whether a task in the given state will not change state again.
+func isComplete(state a2a.TaskState) bool {
+	switch state {
+	case a2a.TaskStateCompleted, a2a.TaskStateFailed, a2a.TaskStateCancelled:
+		return true
+	default:
+		return false
+	}
+}
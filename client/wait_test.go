@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// taskResponse answers a tasks/get request with a task in the given state.
+func taskResponse(request a2a.JSONRPCRequest, state a2a.TaskState) a2a.JSONRPCResponse {
+	return a2a.JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result:  a2a.Task{ID: "task-1", Status: a2a.TaskStatus{State: state}},
+	}
+}
+
+func TestClient_WaitForCompletion(t *testing.T) {
+	var polls atomic.Int32
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		if request.Method != "tasks/get" {
+			t.Errorf("Expected method tasks/get, got %s", request.Method)
+		}
+		if polls.Add(1) < 3 {
+			return taskResponse(request, a2a.TaskStateWorking)
+		}
+		return taskResponse(request, a2a.TaskStateCompleted)
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	task, err := c.WaitForCompletion(context.Background(), "task-1", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForCompletion failed: %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected a completed task, got %s", task.Status.State)
+	}
+	if n := polls.Load(); n != 3 {
+		t.Errorf("Expected 3 polls, got %d", n)
+	}
+}
+
+func TestClient_WaitForCompletionMaxWait(t *testing.T) {
+	server := newJSONRPCServer(t, func(t *testing.T, r *http.Request, request a2a.JSONRPCRequest) a2a.JSONRPCResponse {
+		return taskResponse(request, a2a.TaskStateWorking)
+	})
+
+	c, err := NewClient(WithBaseURL(server.URL), WithMaxWait(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = c.WaitForCompletion(context.Background(), "task-1", 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+}
+
+func TestClient_WaitForCompletionStreaming(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request a2a.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		switch request.Method {
+		case "tasks/get":
+			state := a2a.TaskStateWorking
+			if polls.Add(1) > 1 {
+				state = a2a.TaskStateCompleted
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(taskResponse(request, state))
+		case "tasks/resubscribe":
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeStatusEvent(w, "1", "task-1", a2a.TaskStateWorking)
+			writeStatusEvent(w, "2", "task-1", a2a.TaskStateCompleted)
+		default:
+			t.Errorf("Unexpected method %s", request.Method)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	card := &a2a.AgentCard{Capabilities: &a2a.AgentCapabilities{SupportsStreaming: true}}
+	c, err := NewClient(WithBaseURL(server.URL), WithAgentCard(card))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// The poll interval is longer than the test, so the task can only complete through the stream
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := c.WaitForCompletion(ctx, "task-1", time.Hour)
+	if err != nil {
+		t.Fatalf("WaitForCompletion failed: %v", err)
+	}
+	if task.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected a completed task, got %s", task.Status.State)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -21,7 +22,7 @@ import (
 var (
 	configFile   = flag.String("config", "", "Path to configuration file (JSON or YAML)")
 	agentURL     = flag.String("url", "", "URL of the A2A agent")
-	outputFormat = flag.String("output", "pretty", "Output format (json, pretty)")
+	outputFormat = flag.String("output", "pretty", "Output format (json, ndjson, pretty)")
 	authHeader   = flag.String("auth", "", "Authentication header (format: 'Name: Value')")
 	timeout      = flag.Duration("timeout", 30*time.Second, "Request timeout")
 	interactive  = flag.Bool("interactive", false, "Interactive mode")
@@ -35,12 +36,18 @@ func main() {
 	sendSkill := sendCmd.String("skill", "", "Skill ID to use")
 	sendTaskID := sendCmd.String("task", "", "Task ID to resume")
 	sendStream := sendCmd.Bool("stream", false, "Stream task updates")
+	sendDryRun := sendCmd.Bool("dry-run", false, "Validate the task without running it")
 
 	getCmd := flag.NewFlagSet("get", flag.ExitOnError)
 	getTaskID := getCmd.String("task", "", "Task ID to get")
 
 	cancelCmd := flag.NewFlagSet("cancel", flag.ExitOnError)
 	cancelTaskID := cancelCmd.String("task", "", "Task ID to cancel")
+	cancelSessionID := cancelCmd.String("session", "", "Session ID to cancel all unfinished tasks in")
+
+	listCmd := flag.NewFlagSet("list", flag.ExitOnError)
+	listPageSize := listCmd.Int("page-size", 0, "Number of tasks to fetch per request (0 = server default)")
+	listWatch := listCmd.Bool("watch", false, "Keep streaming new tasks as they are created")
 
 	subscribeCmd := flag.NewFlagSet("subscribe", flag.ExitOnError)
 	subscribeTaskID := subscribeCmd.String("task", "", "Task ID to subscribe to")
@@ -53,8 +60,12 @@ func main() {
 	pushIncludeTask := pushCmd.Bool("include-task", true, "Include task data in push notifications")
 	pushIncludeArtifacts := pushCmd.Bool("include-artifacts", false, "Include artifacts in push notifications")
 	pushGet := pushCmd.Bool("get", false, "Get push notification configuration instead of setting it")
+	pushDelete := pushCmd.Bool("delete", false, "Delete push notification configuration instead of setting it")
+	var pushHeaders headerFlags
+	pushCmd.Var(&pushHeaders, "header", "Custom header for push notification requests (format: 'name:value', repeatable)")
 
 	cardCmd := flag.NewFlagSet("card", flag.ExitOnError)
+	cardSkillFilter := cardCmd.String("skill-filter", "", "Only show skills whose name contains this text (use 'tag:<tag>' to filter by tag)")
 
 	// Parse command line flags
 	flag.Parse()
@@ -91,6 +102,11 @@ func main() {
 		}
 	}
 
+	// Keep stdout for one JSON object per line when streaming JSON Lines
+	if config.OutputFormat == "ndjson" {
+		logger = common.NewLogger(os.Stderr, "info")
+	}
+
 	// Check if a subcommand was provided
 	if flag.NArg() == 0 {
 		if *interactive {
@@ -132,29 +148,32 @@ func main() {
 	switch subcommand {
 	case "send":
 		sendCmd.Parse(flag.Args()[1:])
-		handleSendCommand(a2aClient, *sendMessage, *sendFile, *sendSkill, *sendTaskID, *sendStream, config, logger)
+		handleSendCommand(a2aClient, *sendMessage, *sendFile, *sendSkill, *sendTaskID, *sendStream, *sendDryRun, config, logger)
 	case "get":
 		getCmd.Parse(flag.Args()[1:])
 		handleGetCommand(a2aClient, *getTaskID, config, logger)
 	case "cancel":
 		cancelCmd.Parse(flag.Args()[1:])
-		handleCancelCommand(a2aClient, *cancelTaskID, config, logger)
+		handleCancelCommand(a2aClient, *cancelTaskID, *cancelSessionID, config, logger)
+	case "list":
+		listCmd.Parse(flag.Args()[1:])
+		handleListCommand(a2aClient, *listPageSize, *listWatch, config, logger)
 	case "subscribe":
 		subscribeCmd.Parse(flag.Args()[1:])
 		handleSubscribeCommand(a2aClient, *subscribeTaskID, *subscribeLastEventID, config, logger)
 	case "push":
 		pushCmd.Parse(flag.Args()[1:])
-		handlePushCommand(a2aClient, *pushTaskID, *pushURL, *pushAuth, *pushIncludeTask, *pushIncludeArtifacts, *pushGet, config, logger)
+		handlePushCommand(a2aClient, *pushTaskID, *pushURL, *pushAuth, *pushIncludeTask, *pushIncludeArtifacts, *pushGet, *pushDelete, pushHeaders, config, logger)
 	case "card":
 		cardCmd.Parse(flag.Args()[1:])
-		handleCardCommand(a2aClient, config, logger)
+		handleCardCommand(a2aClient, *cardSkillFilter, config, logger)
 	default:
 		logger.Fatal("Unknown subcommand: %s", subcommand)
 	}
 }
 
 // handleSendCommand handles the 'send' subcommand.
-func handleSendCommand(a2aClient *client.Client, message, file, skillID, taskID string, stream bool, config common.ClientConfig, logger *common.Logger) {
+func handleSendCommand(a2aClient *client.Client, message, file, skillID, taskID string, stream, dryRun bool, config common.ClientConfig, logger *common.Logger) {
 	// Get message content
 	var messageContent string
 	if message != "" {
@@ -193,6 +212,12 @@ func handleSendCommand(a2aClient *client.Client, message, file, skillID, taskID
 	if taskID != "" {
 		params.TaskID = &taskID
 	}
+	if dryRun {
+		if stream {
+			logger.Fatal("-dry-run cannot be combined with -stream")
+		}
+		params.DryRun = &dryRun
+	}
 
 	// Send task
 	if stream {
@@ -262,9 +287,28 @@ func handleGetCommand(a2aClient *client.Client, taskID string, config common.Cli
 }
 
 // handleCancelCommand handles the 'cancel' subcommand.
-func handleCancelCommand(a2aClient *client.Client, taskID string, config common.ClientConfig, logger *common.Logger) {
+func handleCancelCommand(a2aClient *client.Client, taskID, sessionID string, config common.ClientConfig, logger *common.Logger) {
+	if sessionID != "" {
+		if taskID != "" {
+			logger.Fatal("-task cannot be combined with -session")
+		}
+
+		// Cancel every unfinished task in the session
+		tasks, err := a2aClient.CancelSession(context.Background(), sessionID)
+		if err != nil {
+			logger.Fatal("Failed to cancel session: %v", err)
+		}
+		logger.Info("Cancelled %d task(s) in session %s", len(tasks), sessionID)
+
+		// Print tasks
+		for i := range tasks {
+			printTask(&tasks[i], config.OutputFormat, logger)
+		}
+		return
+	}
+
 	if taskID == "" {
-		logger.Fatal("Task ID must be specified")
+		logger.Fatal("Task ID or session ID must be specified")
 	}
 
 	// Cancel task
@@ -277,6 +321,85 @@ func handleCancelCommand(a2aClient *client.Client, taskID string, config common.
 	printTask(task, config.OutputFormat, logger)
 }
 
+// handleListCommand handles the 'list' subcommand.
+func handleListCommand(a2aClient *client.Client, pageSize int, watch bool, config common.ClientConfig, logger *common.Logger) {
+	if pageSize < 0 {
+		logger.Fatal("-page-size must not be negative")
+	}
+
+	if !watch {
+		// Follow the pages until the last one
+		count := 0
+		err := listAllTasks(context.Background(), a2aClient, pageSize, func(task *a2a.Task) {
+			printTask(task, config.OutputFormat, logger)
+			count++
+		})
+		if err != nil {
+			logger.Fatal("Failed to list tasks: %v", err)
+		}
+		logger.Info("Listed %d task(s)", count)
+		return
+	}
+
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Set up signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("Cancelling task list stream...")
+		cancel()
+	}()
+
+	// Stream the task list
+	pageChan, errChan := a2aClient.ListTasksSubscribe(ctx, &a2a.ListTasksParams{PageSize: pageSize})
+
+	// Process pages
+	for {
+		select {
+		case page, ok := <-pageChan:
+			if !ok {
+				// Channel closed, we're done
+				return
+			}
+			for i := range page.Tasks {
+				printTask(&page.Tasks[i], config.OutputFormat, logger)
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				// Channel closed
+				continue
+			}
+			logger.Error("Error: %v", err)
+			return
+		case <-ctx.Done():
+			logger.Info("Task list stream cancelled")
+			return
+		}
+	}
+}
+
+// listAllTasks lists every task, following the pages of tasks/list, and calls fn for each task in order.
+func listAllTasks(ctx context.Context, a2aClient *client.Client, pageSize int, fn func(task *a2a.Task)) error {
+	params := &a2a.ListTasksParams{PageSize: pageSize}
+	for {
+		page, err := a2aClient.ListTasks(ctx, params)
+		if err != nil {
+			return err
+		}
+		for i := range page.Tasks {
+			fn(&page.Tasks[i])
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		params.Cursor = page.NextCursor
+	}
+}
+
 // handleSubscribeCommand handles the 'subscribe' subcommand.
 func handleSubscribeCommand(a2aClient *client.Client, taskID, lastEventID string, config common.ClientConfig, logger *common.Logger) {
 	if taskID == "" {
@@ -323,7 +446,7 @@ func handleSubscribeCommand(a2aClient *client.Client, taskID, lastEventID string
 }
 
 // handlePushCommand handles the 'push' subcommand.
-func handlePushCommand(a2aClient *client.Client, taskID, url, auth string, includeTask, includeArtifacts, get bool, config common.ClientConfig, logger *common.Logger) {
+func handlePushCommand(a2aClient *client.Client, taskID, url, auth string, includeTask, includeArtifacts, get, del bool, headers headerFlags, config common.ClientConfig, logger *common.Logger) {
 	if taskID == "" {
 		logger.Fatal("Task ID must be specified")
 	}
@@ -340,6 +463,15 @@ func handlePushCommand(a2aClient *client.Client, taskID, url, auth string, inclu
 		return
 	}
 
+	if del {
+		// Delete push notification configuration
+		if err := a2aClient.DeleteTaskPushNotification(context.Background(), taskID); err != nil {
+			logger.Fatal("Failed to delete push notification configuration: %v", err)
+		}
+		logger.Info("Push notification configuration deleted for task %s", taskID)
+		return
+	}
+
 	if url == "" {
 		logger.Fatal("URL must be specified")
 	}
@@ -388,6 +520,17 @@ func handlePushCommand(a2aClient *client.Client, taskID, url, auth string, inclu
 		}
 	}
 
+	if len(headers) > 0 {
+		params.Headers = make(map[string]string, len(headers))
+		for _, header := range headers {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				logger.Fatal("Invalid header format %q. Use 'name:value'", header)
+			}
+			params.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
 	params.IncludeTaskData = &includeTask
 	params.IncludeArtifacts = &includeArtifacts
 
@@ -401,18 +544,48 @@ func handlePushCommand(a2aClient *client.Client, taskID, url, auth string, inclu
 	printPushConfig(pushConfig, config.OutputFormat, logger)
 }
 
+// headerFlags collects the values of a repeatable header flag.
+type headerFlags []string
+
+// String implements flag.Value.
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+// Set implements flag.Value.
+func (h *headerFlags) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
 // handleCardCommand handles the 'card' subcommand.
-func handleCardCommand(a2aClient *client.Client, config common.ClientConfig, logger *common.Logger) {
+func handleCardCommand(a2aClient *client.Client, skillFilter string, config common.ClientConfig, logger *common.Logger) {
 	// Fetch agent card
 	card, err := a2aClient.FetchAgentCard(context.Background())
 	if err != nil {
 		logger.Fatal("Failed to fetch agent card: %v", err)
 	}
 
+	// Filter skills if requested
+	if skillFilter != "" {
+		filtered := *card
+		filtered.Skills = a2a.FilterSkills(card.Skills, parseSkillFilter(skillFilter))
+		card = &filtered
+	}
+
 	// Print agent card
 	printAgentCard(card, config.OutputFormat, logger)
 }
 
+// parseSkillFilter parses a -skill-filter value. A "tag:" prefix filters by tag,
+// otherwise the value is matched against skill names.
+func parseSkillFilter(value string) a2a.SkillFilter {
+	if tag, ok := strings.CutPrefix(value, "tag:"); ok {
+		return a2a.SkillFilter{Tag: tag}
+	}
+	return a2a.SkillFilter{Name: value}
+}
+
 // runInteractiveMode runs the client in interactive mode.
 func runInteractiveMode(config common.ClientConfig, logger *common.Logger) {
 	logger.Info("Interactive mode not implemented yet")
@@ -430,13 +603,27 @@ func printTask(task *a2a.Task, format string, logger *common.Logger) {
 			return
 		}
 		fmt.Println(string(jsonData))
+	case "ndjson":
+		// Print as a single line of JSON
+		if err := writeNDJSON(os.Stdout, task); err != nil {
+			logger.Error("Failed to write task: %v", err)
+		}
 	case "pretty":
 		// Print in a human-readable format
 		fmt.Printf("Task ID: %s\n", task.ID)
 		if task.SessionID != nil {
 			fmt.Printf("Session ID: %s\n", *task.SessionID)
 		}
+		if id, ok := task.Metadata[a2a.TaskMetadataRequestID]; ok {
+			fmt.Printf("Request ID: %v\n", id)
+		}
+		if id, ok := task.Metadata[a2a.TaskMetadataHTTPRequestID]; ok {
+			fmt.Printf("HTTP Request ID: %v\n", id)
+		}
 		fmt.Printf("Status: %s (%s)\n", task.Status.State, task.Status.Timestamp.Format(time.RFC3339))
+		if task.Status.Reason != "" {
+			fmt.Printf("Reason: %s\n", describeStatusReason(task.Status))
+		}
 		if task.Status.Message != nil {
 			fmt.Printf("Status Message: %s\n", getMessageText(task.Status.Message))
 		}
@@ -464,11 +651,19 @@ func printTaskUpdate(update client.TaskUpdate, format string, logger *common.Log
 			return
 		}
 		fmt.Println(string(jsonData))
+	case "ndjson":
+		// Print as a single line of JSON
+		if err := writeNDJSON(os.Stdout, update); err != nil {
+			logger.Error("Failed to write task update: %v", err)
+		}
 	case "pretty":
 		// Print in a human-readable format
 		switch update.Type {
 		case "status":
 			fmt.Printf("Status Update: %s\n", update.Status.State)
+			if update.Status.Reason != "" {
+				fmt.Printf("  Reason: %s\n", describeStatusReason(*update.Status))
+			}
 			if update.Status.Message != nil {
 				fmt.Printf("  Message: %s\n", getMessageText(update.Status.Message))
 			}
@@ -494,6 +689,11 @@ func printPushConfig(config *a2a.PushNotificationConfig, format string, logger *
 			return
 		}
 		fmt.Println(string(jsonData))
+	case "ndjson":
+		// Print as a single line of JSON
+		if err := writeNDJSON(os.Stdout, config); err != nil {
+			logger.Error("Failed to write push notification configuration: %v", err)
+		}
 	case "pretty":
 		// Print in a human-readable format
 		fmt.Printf("Task ID: %s\n", config.TaskID)
@@ -511,6 +711,9 @@ func printPushConfig(config *a2a.PushNotificationConfig, format string, logger *
 		} else {
 			fmt.Printf("Include Artifacts: false (default)\n")
 		}
+		for name, value := range config.Headers {
+			fmt.Printf("Header: %s: %s\n", name, value)
+		}
 	default:
 		logger.Error("Unknown output format: %s", format)
 	}
@@ -527,6 +730,11 @@ func printAgentCard(card *a2a.AgentCard, format string, logger *common.Logger) {
 			return
 		}
 		fmt.Println(string(jsonData))
+	case "ndjson":
+		// Print as a single line of JSON
+		if err := writeNDJSON(os.Stdout, card); err != nil {
+			logger.Error("Failed to write agent card: %v", err)
+		}
 	case "pretty":
 		// Print in a human-readable format
 		fmt.Printf("Agent ID: %s\n", card.ID)
@@ -546,6 +754,15 @@ func printAgentCard(card *a2a.AgentCard, format string, logger *common.Logger) {
 			if skill.Description != nil {
 				fmt.Printf("      %s\n", *skill.Description)
 			}
+			if len(skill.Tags) > 0 {
+				fmt.Printf("      Tags: %s\n", strings.Join(skill.Tags, ", "))
+			}
+			if len(skill.Examples) > 0 {
+				fmt.Printf("      Examples:\n")
+				for _, example := range skill.Examples {
+					fmt.Printf("        %s: %s\n", example.Name, describeExampleInput(example.Input))
+				}
+			}
 		}
 		if card.Capabilities != nil {
 			fmt.Printf("Capabilities:\n")
@@ -565,6 +782,35 @@ func printAgentCard(card *a2a.AgentCard, format string, logger *common.Logger) {
 	}
 }
 
+// describeExampleInput returns a skill example's input as it would be typed: text as is,
+// and anything else as compact JSON.
+func describeExampleInput(input interface{}) string {
+	if text, ok := input.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("%v", input)
+	}
+	return string(data)
+}
+
+// writeNDJSON writes v to w as compact JSON followed by a newline, so each value is
+// exactly one line (JSON Lines), and flushes w if it is buffered.
+func writeNDJSON(w io.Writer, v interface{}) error {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(jsonData, '\n')); err != nil {
+		return err
+	}
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 // getMessageText returns the text content of a message.
 func getMessageText(msg *a2a.Message) string {
 	for _, part := range msg.Parts {
@@ -575,18 +821,20 @@ func getMessageText(msg *a2a.Message) string {
 	return "[No text content]"
 }
 
+// describeStatusReason describes why a task was cancelled or failed, and whether it can be retried.
+func describeStatusReason(status a2a.TaskStatus) string {
+	if status.Retryable {
+		return string(status.Reason) + " (retryable)"
+	}
+	return string(status.Reason)
+}
+
 // getPartDescription returns a description of a part.
 func getPartDescription(part a2a.Part) string {
-	switch p := part.(type) {
-	case a2a.TextPart:
-		return fmt.Sprintf("Text: %s", p.Text)
-	case a2a.FilePart:
-		return fmt.Sprintf("File: %s (%s)", p.Filename, p.MimeType)
-	case a2a.DataPart:
-		return fmt.Sprintf("Data: %s", p.MimeType)
-	default:
-		return fmt.Sprintf("Unknown part type: %T", part)
+	if part == nil {
+		return "Empty part"
 	}
+	return part.Summary()
 }
 
 // printUsage prints usage information.
@@ -597,7 +845,8 @@ func printUsage() {
 	fmt.Println("\nCommands:")
 	fmt.Println("  send        Send a task to an agent")
 	fmt.Println("  get         Get a task from an agent")
-	fmt.Println("  cancel      Cancel a task")
+	fmt.Println("  cancel      Cancel a task, or every unfinished task in a session")
+	fmt.Println("  list        List tasks, following pages (-page-size), or stream them (-watch)")
 	fmt.Println("  subscribe   Subscribe to task updates")
 	fmt.Println("  push        Configure push notifications")
 	fmt.Println("  card        Get agent card information")
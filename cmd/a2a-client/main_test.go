@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/a2atest"
+	"github.com/sammcj/go-a2a/client"
+)
+
+func TestWriteNDJSON_OneLinePerUpdate(t *testing.T) {
+	message := &a2a.Message{
+		Role:  a2a.RoleAgent,
+		Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "first line\nsecond line"}},
+	}
+	updates := []client.TaskUpdate{
+		{Type: "status", Status: &a2a.TaskStatus{State: a2a.TaskStateWorking, Message: message, Timestamp: time.Now()}},
+		{Type: "artifact", Artifact: &a2a.Artifact{ID: "artifact-1", TaskID: "task-1", Part: a2a.TextPart{Type: "text", Text: "multi\nline\nresult"}}},
+		{Type: "status", Status: &a2a.TaskStatus{State: a2a.TaskStateCompleted, Timestamp: time.Now()}},
+	}
+
+	var buf bytes.Buffer
+	for _, update := range updates {
+		if err := writeNDJSON(&buf, update); err != nil {
+			t.Fatalf("writeNDJSON failed: %v", err)
+		}
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != len(updates) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(updates), len(lines), lines)
+	}
+	for i, line := range lines {
+		var decoded client.TaskUpdate
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Errorf("Line %d is not valid JSON: %v: %s", i, err, line)
+			continue
+		}
+		if decoded.Type != updates[i].Type {
+			t.Errorf("Line %d: expected update type %s, got %s", i, updates[i].Type, decoded.Type)
+		}
+	}
+}
+
+// flushRecorder records whether it was flushed.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (f *flushRecorder) Flush() error {
+	f.flushed++
+	return nil
+}
+
+func TestWriteNDJSON_FlushesEachLine(t *testing.T) {
+	var w flushRecorder
+	for i := 0; i < 2; i++ {
+		if err := writeNDJSON(&w, &a2a.Task{ID: "task-1"}); err != nil {
+			t.Fatalf("writeNDJSON failed: %v", err)
+		}
+	}
+	if w.flushed != 2 {
+		t.Errorf("Expected a flush per line, got %d", w.flushed)
+	}
+}
+
+func TestListAllTasks_FollowsPages(t *testing.T) {
+	var want []string
+	for i := 0; i < 7; i++ {
+		want = append(want, fmt.Sprintf("task-%d", i))
+	}
+
+	// Serve the tasks a page at a time, using the offset as the cursor
+	mock := a2atest.NewMockServer(a2atest.Handlers{
+		ListTasks: func(params *a2a.ListTasksParams) (*a2a.ListTasksResult, error) {
+			offset, _ := strconv.Atoi(params.Cursor)
+			end := min(offset+params.PageSize, len(want))
+			result := &a2a.ListTasksResult{}
+			for _, id := range want[offset:end] {
+				result.Tasks = append(result.Tasks, a2a.Task{ID: id})
+			}
+			if end < len(want) {
+				result.NextCursor = strconv.Itoa(end)
+			}
+			return result, nil
+		},
+	})
+	defer mock.Close()
+
+	a2aClient, err := client.NewClient(client.WithBaseURL(mock.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var listed []string
+	err = listAllTasks(context.Background(), a2aClient, 3, func(task *a2a.Task) {
+		listed = append(listed, task.ID)
+	})
+	if err != nil {
+		t.Fatalf("listAllTasks failed: %v", err)
+	}
+	if !reflect.DeepEqual(listed, want) {
+		t.Errorf("Expected tasks %v, got %v", want, listed)
+	}
+
+	var requests int
+	for _, r := range mock.Requests() {
+		if r.Method == "tasks/list" {
+			requests++
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 tasks/list requests, got %d", requests)
+	}
+}
@@ -77,51 +77,14 @@ func main() {
 		logger.Fatal("Failed to create gollm options: %v", err)
 	}
 
-	// Load and convert agent card configuration
-	var a2aAgentCard *a2a.AgentCard
-	if *agentCardFile != "" {
-		logger.Info("Loading agent card from %s", *agentCardFile)
-		loadedCard, err := common.LoadConfig[config.AgentCardConfig](*agentCardFile)
-		if err != nil {
-			logger.Fatal("Failed to load agent card: %v", err)
-		}
-		// Convert config.AgentCardConfig to a2a.AgentCard
-		a2aAgentCard = common.ConvertToAgentCard(&common.AgentCardConfig{
-			A2AVersion:       loadedCard.A2AVersion,
-			ID:               loadedCard.ID,
-			Name:             loadedCard.Name,
-			Description:      loadedCard.Description,
-			IconURI:          loadedCard.IconURI,
-			ContactEmail:     loadedCard.ContactEmail,
-			LegalInfoURI:     loadedCard.LegalInfoURI,
-			HomepageURI:      loadedCard.HomepageURI,
-			DocumentationURI: loadedCard.DocumentationURI,
-		})
-	} else {
-		// If no agent card file is specified, use the agent card from the server configuration
-		a2aAgentCard = common.ConvertToAgentCard(&cfg.AgentCard)
+	// Load agent card and task handler
+	a2aAgentCard, err := loadAgentCard(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to load agent card: %v", err)
 	}
-
-	// Load plugins
-	var taskHandler task.Handler
-	if cfg.PluginPath != "" {
-		logger.Info("Loading plugins from %s", cfg.PluginPath)
-		plugins, err := common.LoadPlugins(cfg.PluginPath)
-		if err != nil {
-			logger.Fatal("Failed to load plugins: %v", err)
-		}
-
-		if len(plugins) == 0 {
-			logger.Warn("No plugins found, using built-in echo plugin")
-			taskHandler = common.NewEchoPlugin().GetTaskHandler()
-		} else {
-			logger.Info("Loaded %d plugins", len(plugins))
-			taskHandler = common.MergeTaskHandlers(plugins)
-		}
-	} else {
-		// Use built-in echo plugin
-		logger.Info("No plugin path specified, using built-in echo plugin")
-		taskHandler = common.NewEchoPlugin().GetTaskHandler()
+	taskHandler, err := loadTaskHandler(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to load plugins: %v", err)
 	}
 
 	// Create server options
@@ -148,6 +111,20 @@ func main() {
 		}
 	}()
 
+	// Reload the agent card and plugins on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			logger.Info("Reloading agent card and plugins")
+			if err := reloadServer(srv, cfg, logger); err != nil {
+				logger.Error("Reload failed, keeping previous configuration: %v", err)
+				continue
+			}
+			logger.Info("Reload complete")
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -166,6 +143,100 @@ func main() {
 	logger.Info("Server exited properly")
 }
 
+// loadAgentCard loads the agent card from the agent card file, or from the server
+// configuration if no file is specified.
+func loadAgentCard(cfg common.ServerConfig, logger *common.Logger) (*a2a.AgentCard, error) {
+	if *agentCardFile == "" {
+		// If no agent card file is specified, use the agent card from the server configuration
+		return common.ConvertToAgentCard(&cfg.AgentCard), nil
+	}
+
+	logger.Info("Loading agent card from %s", *agentCardFile)
+	loadedCard, err := common.LoadConfig[config.AgentCardConfig](*agentCardFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert config.AgentCardConfig to a2a.AgentCard
+	return common.ConvertToAgentCard(&common.AgentCardConfig{
+		A2AVersion:       loadedCard.A2AVersion,
+		ID:               loadedCard.ID,
+		Name:             loadedCard.Name,
+		Description:      loadedCard.Description,
+		IconURI:          loadedCard.IconURI,
+		ContactEmail:     loadedCard.ContactEmail,
+		LegalInfoURI:     loadedCard.LegalInfoURI,
+		HomepageURI:      loadedCard.HomepageURI,
+		DocumentationURI: loadedCard.DocumentationURI,
+	}), nil
+}
+
+// loadTaskHandler loads the plugins and script skills from the configuration and merges
+// them into a single task handler. The built-in echo plugin is used if there are none.
+func loadTaskHandler(cfg common.ServerConfig, logger *common.Logger) (task.Handler, error) {
+	// Load plugins
+	var plugins []common.TaskHandlerPlugin
+	if cfg.PluginPath != "" {
+		logger.Info("Loading plugins from %s", cfg.PluginPath)
+		loaded, err := common.LoadPlugins(cfg.PluginPath)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, loaded...)
+	}
+
+	// Load script skills
+	if len(cfg.ScriptSkills) > 0 {
+		scriptPlugin, err := common.NewScriptPlugin(cfg.ScriptSkills)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create script plugin: %w", err)
+		}
+		logger.Info("Loaded %d script skills", len(cfg.ScriptSkills))
+		plugins = append(plugins, scriptPlugin)
+	}
+
+	if len(plugins) == 0 {
+		// Use built-in echo plugin
+		logger.Info("No plugins found, using built-in echo plugin")
+		return common.NewEchoPlugin().GetTaskHandler(), nil
+	}
+	logger.Info("Loaded %d plugins", len(plugins))
+
+	// Find the handler for the default skill, if configured
+	var fallback task.Handler
+	if cfg.DefaultSkill != "" {
+		for _, p := range plugins {
+			for _, skill := range p.GetSkills() {
+				if skill.ID == cfg.DefaultSkill {
+					fallback = p.GetTaskHandler()
+				}
+			}
+		}
+		if fallback == nil {
+			return nil, fmt.Errorf("default skill %s is not provided by any plugin", cfg.DefaultSkill)
+		}
+	}
+
+	return common.MergeTaskHandlers(plugins, fallback)
+}
+
+// reloadServer reloads the agent card and plugins and applies them to the running server.
+// Nothing is changed if either fails to load.
+func reloadServer(srv *server.Server, cfg common.ServerConfig, logger *common.Logger) error {
+	card, err := loadAgentCard(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load agent card: %w", err)
+	}
+	taskHandler, err := loadTaskHandler(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+	return srv.Reload(server.ReloadConfig{
+		AgentCard:   card,
+		TaskHandler: taskHandler,
+	})
+}
+
 // saveDefaultConfig saves a default configuration file.
 func saveDefaultConfig(path string) error {
 	cfg := common.DefaultServerConfig()
@@ -13,13 +13,15 @@ import (
 
 // ServerConfig represents the configuration for a server.
 type ServerConfig struct {
-	ListenAddress string          `json:"listenAddress" yaml:"listenAddress"`
-	LogLevel      string          `json:"logLevel" yaml:"logLevel"`
-	AgentCardPath string          `json:"agentCardPath" yaml:"agentCardPath"`
-	A2APathPrefix string          `json:"a2aPathPrefix" yaml:"a2aPathPrefix"`
-	PluginPath    string          `json:"pluginPath" yaml:"pluginPath"`
-	LLMConfig     *LLMConfig      `json:"llmConfig,omitempty" yaml:"llmConfig,omitempty"`
-	AgentCard     AgentCardConfig `json:"agentCard" yaml:"agentCard"`
+	ListenAddress string              `json:"listenAddress" yaml:"listenAddress"`
+	LogLevel      string              `json:"logLevel" yaml:"logLevel"`
+	AgentCardPath string              `json:"agentCardPath" yaml:"agentCardPath"`
+	A2APathPrefix string              `json:"a2aPathPrefix" yaml:"a2aPathPrefix"`
+	PluginPath    string              `json:"pluginPath" yaml:"pluginPath"`
+	ScriptSkills  []ScriptSkillConfig `json:"scriptSkills,omitempty" yaml:"scriptSkills,omitempty"`
+	DefaultSkill  string              `json:"defaultSkill,omitempty" yaml:"defaultSkill,omitempty"` // Skill that handles tasks for unknown skills (default: built-in echo)
+	LLMConfig     *LLMConfig          `json:"llmConfig,omitempty" yaml:"llmConfig,omitempty"`
+	AgentCard     AgentCardConfig     `json:"agentCard" yaml:"agentCard"`
 }
 
 // ClientConfig represents the configuration for a client.
@@ -57,9 +59,22 @@ type ProviderConfig struct {
 
 // SkillConfig represents the configuration for a skill.
 type SkillConfig struct {
-	ID          string `json:"id" yaml:"id"`
-	Name        string `json:"name" yaml:"name"`
-	Description string `json:"description" yaml:"description"`
+	ID          string   `json:"id" yaml:"id"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// ScriptSkillConfig represents the configuration for a skill handled by an external command.
+// See ScriptPlugin for the protocol the command must implement.
+type ScriptSkillConfig struct {
+	Skill          SkillConfig `json:"skill" yaml:"skill"`
+	Command        string      `json:"command" yaml:"command"`
+	Args           []string    `json:"args,omitempty" yaml:"args,omitempty"`
+	Dir            string      `json:"dir,omitempty" yaml:"dir,omitempty"`                       // Working directory for the command
+	Env            []string    `json:"env,omitempty" yaml:"env,omitempty"`                       // Extra environment variables (KEY=VALUE)
+	TimeoutSeconds int         `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"` // Default 60
+	MaxOutputBytes int         `json:"maxOutputBytes,omitempty" yaml:"maxOutputBytes,omitempty"` // Default 1 MiB
 }
 
 // CapabilitiesConfig represents the capabilities configuration.
@@ -181,6 +196,7 @@ func ConvertToAgentCard(cfg *AgentCardConfig) *a2a.AgentCard {
 			ID:          skill.ID,
 			Name:        skill.Name,
 			Description: &desc,
+			Tags:        skill.Tags,
 		}
 	}
 
@@ -2,7 +2,6 @@ package common
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"path/filepath"
 	"plugin"
@@ -61,49 +60,36 @@ func LoadPlugins(dir string) ([]TaskHandlerPlugin, error) {
 	return plugins, nil
 }
 
-// MergeTaskHandlers merges multiple task handlers into a single task handler.
-// The resulting task handler will delegate to the appropriate plugin based on the skill ID.
-func MergeTaskHandlers(plugins []TaskHandlerPlugin) task.Handler {
+// MergeTaskHandlers merges the task handlers of multiple plugins into a single task handler
+// that routes each task to the plugin registered for its skill. The skill is read from the
+// request's skill ID, or the "skillId" message metadata if none is set. Tasks for skills no plugin handles go to the fallback handler,
+// or to the built-in echo plugin if fallback is nil.
+// It returns an error if more than one plugin registers the same skill ID.
+func MergeTaskHandlers(plugins []TaskHandlerPlugin, fallback task.Handler) (task.Handler, error) {
+	if fallback == nil {
+		fallback = NewEchoPlugin().GetTaskHandler()
+	}
+
 	// Create a map of skill ID to task handler
 	handlers := make(map[string]task.Handler)
-	for _, p := range plugins {
+	owners := make(map[string]int) // Skill ID to index of the plugin that registered it
+	for i, p := range plugins {
 		handler := p.GetTaskHandler()
 		for _, skill := range p.GetSkills() {
+			if owner, exists := owners[skill.ID]; exists {
+				return nil, fmt.Errorf("skill %q is registered by both plugin %d and plugin %d", skill.ID, owner, i)
+			}
+			owners[skill.ID] = i
 			handlers[skill.ID] = handler
 		}
 	}
 
 	// Return a task handler that delegates to the appropriate plugin
 	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
-		// Get the skill ID from the message or use a default
-		skillID := ""
-		// Try to extract skill ID from the message metadata if available
-		if taskCtx.UserMessage.Metadata != nil {
-			if metadata, ok := taskCtx.UserMessage.Metadata.(map[string]interface{}); ok {
-				if skillIDValue, ok := metadata["skillId"]; ok {
-					if skillIDStr, ok := skillIDValue.(string); ok {
-						skillID = skillIDStr
-					}
-				}
-			}
-		}
-
-		// Find the handler for the skill
-		handler, ok := handlers[skillID]
+		handler, ok := handlers[taskCtx.RequestedSkillID()]
 		if !ok {
-			// If no handler is found, use the first handler as a fallback
-			for _, h := range handlers {
-				handler = h
-				break
-			}
-		}
-
-		// If no handler is found, return an error
-		if handler == nil {
-			return nil, errors.New("no handler found")
+			handler = fallback
 		}
-
-		// Delegate to the handler
 		return handler(ctx, taskCtx)
-	}
+	}, nil
 }
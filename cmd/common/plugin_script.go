@@ -0,0 +1,303 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+const (
+	// DefaultScriptTimeout is the maximum run time of a script when none is configured.
+	DefaultScriptTimeout = 60 * time.Second
+
+	// DefaultScriptMaxOutputBytes is the maximum amount of stdout a script may produce when none is configured.
+	DefaultScriptMaxOutputBytes = 1 << 20
+
+	// maxScriptStderrBytes is the amount of stderr kept for error messages.
+	maxScriptStderrBytes = 4096
+)
+
+// ScriptInput is the JSON document written to a script's stdin.
+type ScriptInput struct {
+	TaskID  string      `json:"taskId"`
+	SkillID string      `json:"skillId"`
+	Message a2a.Message `json:"message"`
+}
+
+// ScriptOutputLine is a single newline-delimited JSON update read from a script's stdout.
+//
+// A status line looks like {"type":"status","state":"working","text":"Thinking..."}
+// and may carry a full "message" instead of "text". An artifact line looks like
+// {"type":"artifact","part":{"type":"text","text":"..."}} or uses "text" as shorthand
+// for a text part.
+type ScriptOutputLine struct {
+	Type     string          `json:"type"`            // "status" or "artifact"
+	State    a2a.TaskState   `json:"state,omitempty"` // For status lines
+	Text     string          `json:"text,omitempty"`
+	Message  *a2a.Message    `json:"message,omitempty"`
+	Part     json.RawMessage `json:"part,omitempty"`
+	Metadata interface{}     `json:"metadata,omitempty"`
+}
+
+// ScriptPlugin is a plugin that handles each of its skills by running an external command.
+// This lets task handlers be written in any language.
+//
+// For each task the command receives a ScriptInput as JSON on stdin and writes updates to
+// stdout as newline-delimited JSON (see ScriptOutputLine). If the command exits without
+// reporting a final state, the task is completed when it exits successfully and failed
+// otherwise. Commands run with a minimal environment, a timeout and a cap on output size.
+type ScriptPlugin struct {
+	skills map[string]ScriptSkillConfig
+	order  []string // Skill IDs in configuration order
+}
+
+// NewScriptPlugin creates a new script plugin for the given skills.
+func NewScriptPlugin(skills []ScriptSkillConfig) (*ScriptPlugin, error) {
+	p := &ScriptPlugin{
+		skills: make(map[string]ScriptSkillConfig, len(skills)),
+	}
+	for _, skill := range skills {
+		if skill.Skill.ID == "" {
+			return nil, errors.New("script skill must have an ID")
+		}
+		if skill.Command == "" {
+			return nil, fmt.Errorf("script skill %s must have a command", skill.Skill.ID)
+		}
+		if _, exists := p.skills[skill.Skill.ID]; exists {
+			return nil, fmt.Errorf("duplicate script skill ID: %s", skill.Skill.ID)
+		}
+		p.skills[skill.Skill.ID] = skill
+		p.order = append(p.order, skill.Skill.ID)
+	}
+	return p, nil
+}
+
+// GetSkills returns the skills provided by the script plugin.
+func (p *ScriptPlugin) GetSkills() []a2a.AgentSkill {
+	skills := make([]a2a.AgentSkill, 0, len(p.order))
+	for _, id := range p.order {
+		skill := p.skills[id].Skill
+		desc := skill.Description
+		skills = append(skills, a2a.AgentSkill{
+			ID:          skill.ID,
+			Name:        skill.Name,
+			Description: &desc,
+			Tags:        skill.Tags,
+		})
+	}
+	return skills
+}
+
+// GetTaskHandler returns the task handler function for the script plugin.
+// The skill is selected from the request's skill ID or the "skillId" message metadata;
+// a plugin with a single skill uses it for every task.
+func (p *ScriptPlugin) GetTaskHandler() task.Handler {
+	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		skillID := taskCtx.RequestedSkillID()
+		skill, ok := p.skills[skillID]
+		if !ok {
+			if len(p.order) != 1 {
+				return nil, a2a.ErrSkillNotFound(skillID)
+			}
+			skill = p.skills[p.order[0]]
+		}
+
+		// Create a channel for updates
+		updateChan := make(chan task.YieldUpdate)
+
+		// Start a goroutine to run the script
+		go func() {
+			defer close(updateChan)
+			runScript(ctx, skill, taskCtx, updateChan)
+		}()
+
+		return updateChan, nil
+	}
+}
+
+// runScript runs the command for a skill and forwards its updates.
+func runScript(ctx context.Context, skill ScriptSkillConfig, taskCtx task.Context, updateChan chan<- task.YieldUpdate) {
+	timeout := DefaultScriptTimeout
+	if skill.TimeoutSeconds > 0 {
+		timeout = time.Duration(skill.TimeoutSeconds) * time.Second
+	}
+	maxOutput := DefaultScriptMaxOutputBytes
+	if skill.MaxOutputBytes > 0 {
+		maxOutput = skill.MaxOutputBytes
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	input, err := json.Marshal(ScriptInput{
+		TaskID:  taskCtx.TaskID,
+		SkillID: skill.Skill.ID,
+		Message: taskCtx.UserMessage,
+	})
+	if err != nil {
+		updateChan <- scriptFailure(fmt.Sprintf("failed to encode script input: %v", err))
+		return
+	}
+
+	cmd := exec.CommandContext(ctx, skill.Command, skill.Args...)
+	cmd.Dir = skill.Dir
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")}, skill.Env...)
+	cmd.Stdin = bytes.NewReader(input)
+	stderr := &limitedBuffer{limit: maxScriptStderrBytes}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		updateChan <- scriptFailure(fmt.Sprintf("failed to create stdout pipe: %v", err))
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		updateChan <- scriptFailure(fmt.Sprintf("failed to start script: %v", err))
+		return
+	}
+
+	// Read updates until the script exits or exceeds its output limit
+	finished := false
+	var readErr error
+	reader := bufio.NewReader(io.LimitReader(stdout, int64(maxOutput)+1))
+	read := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		read += len(line)
+		if read > maxOutput {
+			readErr = fmt.Errorf("script output exceeded %d bytes", maxOutput)
+			break
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			update, final, parseErr := parseScriptLine(line)
+			if parseErr != nil {
+				readErr = parseErr
+				break
+			}
+			updateChan <- update
+			if final {
+				finished = true
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if readErr != nil {
+		cancel()
+	}
+	// Drain the pipe so the script is not blocked writing to it
+	io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+
+	switch {
+	case readErr != nil:
+		updateChan <- scriptFailure(readErr.Error())
+	case ctx.Err() == context.DeadlineExceeded:
+		updateChan <- scriptFailure(fmt.Sprintf("script timed out after %s", timeout))
+	case waitErr != nil:
+		msg := fmt.Sprintf("script failed: %v", waitErr)
+		if out := strings.TrimSpace(stderr.String()); out != "" {
+			msg += ": " + out
+		}
+		updateChan <- scriptFailure(msg)
+	case !finished:
+		updateChan <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+	}
+}
+
+// parseScriptLine converts a line of script output into a task update.
+// It reports whether the update puts the task into a final state.
+func parseScriptLine(line []byte) (task.YieldUpdate, bool, error) {
+	var out ScriptOutputLine
+	if err := json.Unmarshal(line, &out); err != nil {
+		return nil, false, fmt.Errorf("invalid script output line: %w", err)
+	}
+
+	switch out.Type {
+	case "status":
+		if out.State == "" {
+			return nil, false, errors.New("script status update is missing a state")
+		}
+		msg := out.Message
+		if msg == nil && out.Text != "" {
+			msg = newAgentTextMessage(out.Text)
+		}
+		final := out.State == a2a.TaskStateCompleted ||
+			out.State == a2a.TaskStateFailed ||
+			out.State == a2a.TaskStateCancelled
+		return task.StatusUpdate{State: out.State, Message: msg}, final, nil
+	case "artifact":
+		var part a2a.Part
+		if len(out.Part) > 0 {
+			var err error
+			part, err = a2a.UnmarshalPart(out.Part)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid script artifact: %w", err)
+			}
+		} else {
+			part = a2a.TextPart{Type: "text", Text: out.Text}
+		}
+		return task.ArtifactUpdate{Part: part, Metadata: out.Metadata}, false, nil
+	default:
+		return nil, false, fmt.Errorf("unknown script update type: %q", out.Type)
+	}
+}
+
+// scriptFailure returns a failed status update carrying the given reason.
+func scriptFailure(reason string) task.StatusUpdate {
+	return task.StatusUpdate{
+		State:   a2a.TaskStateFailed,
+		Message: newAgentTextMessage(reason),
+	}
+}
+
+// newAgentTextMessage creates an agent message with a single text part.
+func newAgentTextMessage(text string) *a2a.Message {
+	return &a2a.Message{
+		Role:      a2a.RoleAgent,
+		Timestamp: time.Now(),
+		Parts: []a2a.Part{
+			a2a.TextPart{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// limitedBuffer is an io.Writer that keeps at most limit bytes and discards the rest.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+// Write implements io.Writer.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// String returns the buffered content.
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
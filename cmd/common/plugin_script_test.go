@@ -0,0 +1,182 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// runScriptSkill runs a single-skill script plugin and collects its updates.
+func runScriptSkill(t *testing.T, skill ScriptSkillConfig, text string) []task.YieldUpdate {
+	t.Helper()
+
+	plugin, err := NewScriptPlugin([]ScriptSkillConfig{skill})
+	if err != nil {
+		t.Fatalf("Failed to create script plugin: %v", err)
+	}
+
+	updates, err := plugin.GetTaskHandler()(context.Background(), task.Context{
+		TaskID: "task-1",
+		UserMessage: a2a.Message{
+			Role:      a2a.RoleUser,
+			Timestamp: time.Now(),
+			Parts:     []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Task handler failed: %v", err)
+	}
+
+	var collected []task.YieldUpdate
+	for update := range updates {
+		collected = append(collected, update)
+	}
+	return collected
+}
+
+// lastStatus returns the last status update, failing the test if there is none.
+func lastStatus(t *testing.T, updates []task.YieldUpdate) task.StatusUpdate {
+	t.Helper()
+	for i := len(updates) - 1; i >= 0; i-- {
+		if status, ok := updates[i].(task.StatusUpdate); ok {
+			return status
+		}
+	}
+	t.Fatalf("Expected a status update, got %+v", updates)
+	return task.StatusUpdate{}
+}
+
+func TestScriptPlugin_SampleScript(t *testing.T) {
+	updates := runScriptSkill(t, ScriptSkillConfig{
+		Skill:   SkillConfig{ID: "wordcount", Name: "Word Count"},
+		Command: "./testdata/wordcount.sh",
+	}, "the quick brown fox")
+
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %d: %+v", len(updates), updates)
+	}
+	if status := updates[0].(task.StatusUpdate); status.State != a2a.TaskStateWorking {
+		t.Errorf("Expected first update to be working, got %s", status.State)
+	}
+
+	artifact, ok := updates[1].(task.ArtifactUpdate)
+	if !ok {
+		t.Fatalf("Expected an artifact update, got %T", updates[1])
+	}
+	data, ok := artifact.Part.(a2a.DataPart)
+	if !ok {
+		t.Fatalf("Expected a data part, got %T", artifact.Part)
+	}
+	if words := data.Data.(map[string]interface{})["words"]; words != float64(4) {
+		t.Errorf("Expected 4 words, got %v", words)
+	}
+
+	status := lastStatus(t, updates)
+	if status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected completed state, got %s", status.State)
+	}
+	if text := status.Message.Parts[0].(a2a.TextPart).Text; text != "Found 4 words" {
+		t.Errorf("Unexpected status message: %q", text)
+	}
+}
+
+func TestScriptPlugin_ExitWithoutFinalState(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		wantState a2a.TaskState
+		wantText  string
+	}{
+		{
+			name:      "success",
+			script:    `echo '{"type":"artifact","text":"done"}'`,
+			wantState: a2a.TaskStateCompleted,
+		},
+		{
+			name:      "failure",
+			script:    `echo "something broke" >&2; exit 3`,
+			wantState: a2a.TaskStateFailed,
+			wantText:  "something broke",
+		},
+		{
+			name:      "invalid output",
+			script:    `echo 'not json'`,
+			wantState: a2a.TaskStateFailed,
+			wantText:  "invalid script output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := runScriptSkill(t, ScriptSkillConfig{
+				Skill:   SkillConfig{ID: "script"},
+				Command: "sh",
+				Args:    []string{"-c", tt.script},
+			}, "hello")
+
+			status := lastStatus(t, updates)
+			if status.State != tt.wantState {
+				t.Fatalf("Expected state %s, got %s", tt.wantState, status.State)
+			}
+			if tt.wantText != "" {
+				if text := status.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, tt.wantText) {
+					t.Errorf("Expected status message to contain %q, got %q", tt.wantText, text)
+				}
+			}
+		})
+	}
+}
+
+func TestScriptPlugin_Limits(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		updates := runScriptSkill(t, ScriptSkillConfig{
+			Skill:          SkillConfig{ID: "slow"},
+			Command:        "sleep",
+			Args:           []string{"10"},
+			TimeoutSeconds: 1,
+		}, "hello")
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected script to be killed after its timeout, took %s", elapsed)
+		}
+		status := lastStatus(t, updates)
+		if status.State != a2a.TaskStateFailed {
+			t.Fatalf("Expected failed state, got %s", status.State)
+		}
+		if text := status.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, "timed out") {
+			t.Errorf("Expected timeout message, got %q", text)
+		}
+	})
+
+	t.Run("max output", func(t *testing.T) {
+		updates := runScriptSkill(t, ScriptSkillConfig{
+			Skill:          SkillConfig{ID: "noisy"},
+			Command:        "sh",
+			Args:           []string{"-c", `while true; do echo '{"type":"status","state":"working"}'; done`},
+			MaxOutputBytes: 1024,
+		}, "hello")
+
+		status := lastStatus(t, updates)
+		if status.State != a2a.TaskStateFailed {
+			t.Fatalf("Expected failed state, got %s", status.State)
+		}
+		if text := status.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, "exceeded") {
+			t.Errorf("Expected output limit message, got %q", text)
+		}
+	})
+}
+
+func TestNewScriptPlugin_DuplicateSkill(t *testing.T) {
+	_, err := NewScriptPlugin([]ScriptSkillConfig{
+		{Skill: SkillConfig{ID: "a"}, Command: "true"},
+		{Skill: SkillConfig{ID: "a"}, Command: "true"},
+	})
+	if err == nil {
+		t.Fatal("Expected duplicate skill IDs to be rejected")
+	}
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// namedPlugin is a test plugin whose handler completes with its name.
+type namedPlugin struct {
+	name   string
+	skills []string
+}
+
+func (p namedPlugin) GetTaskHandler() task.Handler {
+	return namedHandler(p.name)
+}
+
+func (p namedPlugin) GetSkills() []a2a.AgentSkill {
+	skills := make([]a2a.AgentSkill, len(p.skills))
+	for i, id := range p.skills {
+		skills[i] = a2a.AgentSkill{ID: id, Name: id}
+	}
+	return skills
+}
+
+// namedHandler returns a task handler that completes with the given name as its message.
+func namedHandler(name string) task.Handler {
+	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: newAgentTextMessage(name)}
+		close(updates)
+		return updates, nil
+	}
+}
+
+// handledBy runs the handler for a task with the given skill ID and returns the text of its reply.
+func handledBy(t *testing.T, handler task.Handler, skillID string) string {
+	t.Helper()
+
+	msg := a2a.Message{
+		Role:  a2a.RoleUser,
+		Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}},
+	}
+	if skillID != "" {
+		msg.Metadata = map[string]interface{}{"skillId": skillID}
+	}
+
+	updates, err := handler(context.Background(), task.Context{TaskID: "task-1", UserMessage: msg})
+	if err != nil {
+		t.Fatalf("Task handler failed: %v", err)
+	}
+	status := (<-updates).(task.StatusUpdate)
+	return status.Message.Parts[0].(a2a.TextPart).Text
+}
+
+func TestMergeTaskHandlers_RoutesBySkill(t *testing.T) {
+	handler, err := MergeTaskHandlers([]TaskHandlerPlugin{
+		namedPlugin{name: "search", skills: []string{"web-search", "news"}},
+		namedPlugin{name: "math", skills: []string{"calculate"}},
+	}, namedHandler("default"))
+	if err != nil {
+		t.Fatalf("MergeTaskHandlers failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"web-search": "search",
+		"news":       "search",
+		"calculate":  "math",
+		"unknown":    "default",
+		"":           "default",
+	}
+	for skillID, want := range tests {
+		if got := handledBy(t, handler, skillID); got != want {
+			t.Errorf("Skill %q: expected handler %q, got %q", skillID, want, got)
+		}
+	}
+}
+
+func TestMergeTaskHandlers_DefaultsToEcho(t *testing.T) {
+	handler, err := MergeTaskHandlers([]TaskHandlerPlugin{
+		namedPlugin{name: "math", skills: []string{"calculate"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("MergeTaskHandlers failed: %v", err)
+	}
+
+	if got := handledBy(t, handler, "unknown"); got != "Echo: hello" {
+		t.Errorf("Expected unknown skills to be echoed, got %q", got)
+	}
+}
+
+func TestMergeTaskHandlers_SkillCollision(t *testing.T) {
+	_, err := MergeTaskHandlers([]TaskHandlerPlugin{
+		namedPlugin{name: "a", skills: []string{"shared"}},
+		namedPlugin{name: "b", skills: []string{"shared"}},
+	}, nil)
+	if err == nil {
+		t.Fatal("Expected duplicate skill IDs to be rejected")
+	}
+}
+
+func TestMergeTaskHandlers_PrefersRequestSkillID(t *testing.T) {
+	handler, err := MergeTaskHandlers([]TaskHandlerPlugin{
+		namedPlugin{name: "search", skills: []string{"web-search"}},
+		namedPlugin{name: "math", skills: []string{"calculate"}},
+	}, namedHandler("default"))
+	if err != nil {
+		t.Fatalf("MergeTaskHandlers failed: %v", err)
+	}
+
+	msg := a2a.Message{
+		Role:     a2a.RoleUser,
+		Parts:    []a2a.Part{a2a.TextPart{Type: "text", Text: "hello"}},
+		Metadata: map[string]interface{}{"skillId": "web-search"},
+	}
+	updates, err := handler(context.Background(), task.Context{TaskID: "task-1", SkillID: "calculate", UserMessage: msg})
+	if err != nil {
+		t.Fatalf("Task handler failed: %v", err)
+	}
+	status := (<-updates).(task.StatusUpdate)
+	if got := status.Message.Parts[0].(a2a.TextPart).Text; got != "math" {
+		t.Errorf("Expected the request skill ID to route to %q, got %q", "math", got)
+	}
+}
@@ -0,0 +1,9 @@
+#!/bin/sh
+# Sample script skill: counts the words in the first text part of the message.
+input=$(cat)
+text=$(printf '%s' "$input" | sed -n 's/.*"text":"\([^"]*\)".*/\1/p')
+count=$(printf '%s' "$text" | wc -w | tr -d ' ')
+
+echo '{"type":"status","state":"working","text":"Counting words"}'
+echo "{\"type\":\"artifact\",\"part\":{\"type\":\"data\",\"data\":{\"words\":$count}}}"
+echo "{\"type\":\"status\",\"state\":\"completed\",\"text\":\"Found $count words\"}"
@@ -56,8 +56,7 @@ func main() {
 
 			// Send a working status update
 			updateChan <- server.StatusUpdate{
-				State:   a2a.TaskStateWorking,
-				Message: &responseMessage,
+				State: a2a.TaskStateWorking,
 			}
 
 			// Simulate more processing time
@@ -77,9 +76,10 @@ func main() {
 			// Simulate final processing time
 			time.Sleep(1 * time.Second)
 
-			// Send a completed status update
+			// Complete the task with the response as its final message
 			updateChan <- server.StatusUpdate{
-				State: a2a.TaskStateCompleted,
+				State:   a2a.TaskStateCompleted,
+				Message: &responseMessage,
 			}
 		}()
 
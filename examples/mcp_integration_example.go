@@ -288,8 +288,12 @@ func runExample(a2aClient *client.Client, query string) {
 			} else if update.Type == "artifact" {
 				// Print artifact content
 				if update.Artifact != nil && update.Artifact.Part != nil {
-					if textPart, ok := update.Artifact.Part.(a2a.TextPart); ok {
-						fmt.Print(textPart.Text)
+					switch part := update.Artifact.Part.(type) {
+					case a2a.TextPart:
+						fmt.Print(part.Text)
+					case a2a.DataPart:
+						// Tool results are structured data
+						fmt.Printf("\n[tool result] %v\n", part.Data)
 					}
 				}
 			}
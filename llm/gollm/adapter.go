@@ -4,6 +4,7 @@ package gollm
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/sammcj/go-a2a/llm"
 	"github.com/teilomillet/gollm"
@@ -34,6 +35,11 @@ func NewAdapter(opts ...Option) (*Adapter, error) {
 		gollmOpts = append(gollmOpts, gollm.SetAPIKey(options.APIKey))
 	}
 
+	// Add the Ollama endpoint if specified
+	if options.Endpoint != "" {
+		gollmOpts = append(gollmOpts, gollm.SetOllamaEndpoint(options.Endpoint))
+	}
+
 	// Add memory if specified
 	if options.Memory > 0 {
 		gollmOpts = append(gollmOpts, gollm.SetMemory(options.Memory))
@@ -47,12 +53,13 @@ func NewAdapter(opts ...Option) (*Adapter, error) {
 
 	// Create model info
 	modelInfo := llm.LLMModelInfo{
-		Name:             options.Model,
-		Provider:         options.Provider,
-		MaxContextSize:   options.MaxContextSize,
-		Capabilities:     options.Capabilities,
-		InputModalities:  options.InputModalities,
-		OutputModalities: options.OutputModalities,
+		Name:              options.Model,
+		Provider:          options.Provider,
+		MaxContextSize:    options.MaxContextSize,
+		Capabilities:      options.Capabilities,
+		InputModalities:   options.InputModalities,
+		OutputModalities:  options.OutputModalities,
+		SupportsStreaming: llmClient.SupportsStreaming(),
 	}
 
 	return &Adapter{
@@ -61,7 +68,8 @@ func NewAdapter(opts ...Option) (*Adapter, error) {
 	}, nil
 }
 
-// Generate implements the LLM interface Generate method.
+// Generate implements the LLM interface Generate method. The model request is made with
+// ctx, so cancelling ctx (e.g. by cancelling the task) aborts it.
 func (a *Adapter) Generate(ctx context.Context, promptText string, options ...llm.LLMOption) (string, error) {
 	// Apply options
 	opts := llm.DefaultLLMOptions()
@@ -77,6 +85,9 @@ func (a *Adapter) Generate(ctx context.Context, promptText string, options ...ll
 		promptOpts = append(promptOpts, gollm.WithDirectives(opts.SystemPrompt))
 	}
 
+	// Apply conversation history if provided
+	promptText = historyPrompt(opts.History, promptText)
+
 	// Apply structured output if requested
 	if opts.StructuredOutput != nil {
 		promptOpts = append(promptOpts, gollm.WithOutput(opts.StructuredOutput.Format))
@@ -103,7 +114,8 @@ func (a *Adapter) Generate(ctx context.Context, promptText string, options ...ll
 	return response, nil
 }
 
-// GenerateStream implements the LLM interface GenerateStream method.
+// GenerateStream implements the LLM interface GenerateStream method. Cancelling ctx aborts
+// the model request and closes the channels without waiting for the chunks to be read.
 func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options ...llm.LLMOption) (<-chan llm.LLMChunk, <-chan error) {
 	// Apply options
 	opts := llm.DefaultLLMOptions()
@@ -119,6 +131,9 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 		promptOpts = append(promptOpts, gollm.WithDirectives(opts.SystemPrompt))
 	}
 
+	// Apply conversation history if provided
+	promptText = historyPrompt(opts.History, promptText)
+
 	// Create the prompt
 	prompt := gollm.NewPrompt(promptText, promptOpts...)
 
@@ -131,6 +146,17 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 		defer close(chunkChan)
 		defer close(errChan)
 
+		// send sends a chunk, reporting false if ctx is done first
+		send := func(chunk llm.LLMChunk) bool {
+			select {
+			case chunkChan <- chunk:
+				return true
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return false
+			}
+		}
+
 		// Check if streaming is supported
 		if !a.llmClient.SupportsStreaming() {
 			// Fall back to non-streaming if not supported
@@ -140,16 +166,9 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 				return
 			}
 
-			// Send the entire response as a single chunk
-			chunkChan <- llm.LLMChunk{
-				Text:      response,
-				Completed: false,
-			}
-
-			// Send completion signal
-			chunkChan <- llm.LLMChunk{
-				Text:      "",
-				Completed: true,
+			// Send the entire response as a single chunk, then the completion signal
+			if send(llm.LLMChunk{Text: response, Completed: false}) {
+				send(llm.LLMChunk{Text: "", Completed: true})
 			}
 			return
 		}
@@ -160,6 +179,7 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 			errChan <- fmt.Errorf("failed to start gollm stream: %w", err)
 			return
 		}
+		defer stream.Close()
 
 		// Process the stream
 		for {
@@ -167,10 +187,7 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 			if err != nil {
 				if err.Error() == "EOF" || err.Error() == "stream closed" {
 					// Stream completed successfully
-					chunkChan <- llm.LLMChunk{
-						Text:      "",
-						Completed: true,
-					}
+					send(llm.LLMChunk{Text: "", Completed: true})
 					return
 				}
 
@@ -180,9 +197,8 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 			}
 
 			// Send the chunk
-			chunkChan <- llm.LLMChunk{
-				Text:      token.Text,
-				Completed: false,
+			if !send(llm.LLMChunk{Text: token.Text, Completed: false}) {
+				return
 			}
 		}
 	}()
@@ -190,6 +206,28 @@ func (a *Adapter) GenerateStream(ctx context.Context, promptText string, options
 	return chunkChan, errChan
 }
 
+// historyPrompt returns the prompt text preceded by the conversation history, one message
+// per line labelled with its role. gollm sends a prompt to the provider as a single user
+// message, so the labels are what let the model tell tool output apart from user input.
+func historyPrompt(history []llm.Message, promptText string) string {
+	if len(history) == 0 {
+		return promptText
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Conversation so far:\n")
+	for _, msg := range history {
+		role := msg.Role
+		if msg.Role == llm.RoleTool && msg.Name != "" {
+			role = fmt.Sprintf("%s (%s)", msg.Role, msg.Name)
+		}
+		fmt.Fprintf(&builder, "%s: %s\n", role, msg.Content)
+	}
+	builder.WriteString("\n")
+	builder.WriteString(promptText)
+	return builder.String()
+}
+
 // GetModelInfo implements the LLM interface GetModelInfo method.
 func (a *Adapter) GetModelInfo() llm.LLMModelInfo {
 	return a.modelInfo
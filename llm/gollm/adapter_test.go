@@ -0,0 +1,30 @@
+package gollm
+
+import (
+	"testing"
+
+	"github.com/sammcj/go-a2a/llm"
+)
+
+func TestHistoryPrompt(t *testing.T) {
+	history := []llm.Message{
+		{Role: llm.RoleUser, Content: "What's the weather?"},
+		{Role: llm.RoleAssistant, Content: `{"tool": "weather"}`},
+		{Role: llm.RoleTool, Content: `{"temperature": 21.5}`, Name: "weather"},
+	}
+
+	got := historyPrompt(history, "Continue.")
+	want := "Conversation so far:\n" +
+		"user: What's the weather?\n" +
+		"assistant: {\"tool\": \"weather\"}\n" +
+		"tool (weather): {\"temperature\": 21.5}\n" +
+		"\n" +
+		"Continue."
+	if got != want {
+		t.Errorf("historyPrompt() = %q, want %q", got, want)
+	}
+
+	if got := historyPrompt(nil, "Continue."); got != "Continue." {
+		t.Errorf("Expected the prompt unchanged without history, got %q", got)
+	}
+}
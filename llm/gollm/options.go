@@ -12,6 +12,9 @@ type options struct {
 	// APIKey is the API key to use for authentication (not needed for Ollama).
 	APIKey string
 
+	// Endpoint is the base URL of the Ollama API (empty for the gollm default).
+	Endpoint string
+
 	// MaxTokens is the maximum number of tokens to generate.
 	MaxTokens int
 
@@ -68,6 +71,13 @@ func WithAPIKey(apiKey string) Option {
 	}
 }
 
+// WithEndpoint sets the base URL of the Ollama API, e.g. "http://localhost:11434".
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.Endpoint = endpoint
+	}
+}
+
 // WithMaxTokens sets the maximum number of tokens to generate.
 func WithMaxTokens(maxTokens int) Option {
 	return func(o *options) {
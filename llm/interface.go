@@ -35,6 +35,26 @@ type LLMChunk struct {
 	Completed bool
 }
 
+// Roles of the messages in a conversation history.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message is a message in the conversation history that precedes a prompt.
+type Message struct {
+	// Role is the role of the message sender: RoleSystem, RoleUser, RoleAssistant, or RoleTool.
+	Role string
+
+	// Content is the text of the message.
+	Content string
+
+	// Name is the name of the tool whose output a RoleTool message holds.
+	Name string
+}
+
 // LLMModelInfo contains information about an LLM model.
 type LLMModelInfo struct {
 	// Name is the name of the model (e.g., "gpt-4o", "llama3").
@@ -54,6 +74,11 @@ type LLMModelInfo struct {
 
 	// OutputModalities is a list of output modalities the model supports (e.g., "text/plain", "image/png").
 	OutputModalities []string
+
+	// SupportsStreaming indicates whether GenerateStream streams the response as it is generated.
+	// It is informational: providers that do not stream must be wrapped with
+	// WithStreamingFallback by the caller.
+	SupportsStreaming bool
 }
 
 // LLMOption defines options for LLM generation.
@@ -79,6 +104,11 @@ type LLMOptions struct {
 
 	// StructuredOutput contains options for generating structured output.
 	StructuredOutput *StructuredOutputOptions
+
+	// History is the conversation that precedes the prompt, oldest first.
+	// Implementations should give the model the role of each message, as separate
+	// messages or as labels in the prompt, so it can tell tool output apart from user input.
+	History []Message
 }
 
 // StructuredOutputOptions contains options for generating structured output.
@@ -135,3 +165,10 @@ func WithStructuredOutput(format string, schema map[string]interface{}) LLMOptio
 		}
 	}
 }
+
+// WithHistory sets the conversation history that precedes the prompt.
+func WithHistory(messages ...Message) LLMOption {
+	return func(o *LLMOptions) {
+		o.History = messages
+	}
+}
@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// WithStreamingFallback returns an LLMInterface for a provider that cannot stream, whose
+// GenerateStream calls Generate and emits the complete response in word-sized chunks, so
+// agents that consume streams work the same with every provider. The fallback is always
+// applied, whatever the model info reports, so only wrap providers that do not stream.
+func WithStreamingFallback(l LLMInterface) LLMInterface {
+	return &streamingFallback{LLMInterface: l}
+}
+
+// streamingFallback emulates streaming for an LLM that only supports blocking generation.
+type streamingFallback struct {
+	LLMInterface
+}
+
+// GenerateStream implements LLMInterface.GenerateStream by chunking the result of Generate.
+// The last chunk is marked Completed.
+func (s *streamingFallback) GenerateStream(ctx context.Context, prompt string, options ...LLMOption) (<-chan LLMChunk, <-chan error) {
+	chunkChan := make(chan LLMChunk)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+
+		response, err := s.Generate(ctx, prompt, options...)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		chunks := strings.SplitAfter(response, " ")
+		for i, text := range chunks {
+			select {
+			case chunkChan <- LLMChunk{Text: text, Completed: i == len(chunks)-1}:
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return chunkChan, errChan
+}
+
+// GetModelInfo implements LLMInterface.GetModelInfo, reporting streaming support.
+func (s *streamingFallback) GetModelInfo() LLMModelInfo {
+	info := s.LLMInterface.GetModelInfo()
+	info.SupportsStreaming = true
+	return info
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// streamingLLM streams its response in the given chunks.
+type streamingLLM struct {
+	chunks []string
+}
+
+func (s *streamingLLM) Generate(ctx context.Context, prompt string, options ...LLMOption) (string, error) {
+	return strings.Join(s.chunks, ""), nil
+}
+
+func (s *streamingLLM) GenerateStream(ctx context.Context, prompt string, options ...LLMOption) (<-chan LLMChunk, <-chan error) {
+	chunks := make(chan LLMChunk, len(s.chunks))
+	for i, text := range s.chunks {
+		chunks <- LLMChunk{Text: text, Completed: i == len(s.chunks)-1}
+	}
+	close(chunks)
+	return chunks, make(chan error)
+}
+
+func (s *streamingLLM) GetModelInfo() LLMModelInfo {
+	return LLMModelInfo{Name: "streaming", SupportsStreaming: true}
+}
+
+// blockingLLM only supports blocking generation; its GenerateStream never sends anything.
+type blockingLLM struct {
+	response string
+	err      error
+}
+
+func (b *blockingLLM) Generate(ctx context.Context, prompt string, options ...LLMOption) (string, error) {
+	return b.response, b.err
+}
+
+func (b *blockingLLM) GenerateStream(ctx context.Context, prompt string, options ...LLMOption) (<-chan LLMChunk, <-chan error) {
+	return make(chan LLMChunk), make(chan error)
+}
+
+func (b *blockingLLM) GetModelInfo() LLMModelInfo {
+	return LLMModelInfo{Name: "blocking"}
+}
+
+// collect reads a stream to the end, returning its chunks and the first error.
+func collect(chunkChan <-chan LLMChunk, errChan <-chan error) ([]LLMChunk, error) {
+	var chunks []LLMChunk
+	for chunk := range chunkChan {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, <-errChan
+}
+
+func TestWithStreamingFallback_AlwaysApplied(t *testing.T) {
+	// The fallback is opt-in, so it applies even if the model info claims streaming support
+	l := WithStreamingFallback(&streamingLLM{chunks: []string{"Hello", ", world"}})
+	chunks, err := collect(l.GenerateStream(context.Background(), "prompt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0].Text != "Hello, " || chunks[1].Text != "world" {
+		t.Errorf("Expected the Generate response in word-sized chunks, got %+v", chunks)
+	}
+}
+
+func TestWithStreamingFallback_Blocking(t *testing.T) {
+	l := WithStreamingFallback(&blockingLLM{response: "The quick brown fox"})
+	if !l.GetModelInfo().SupportsStreaming {
+		t.Error("Expected the wrapped LLM to report streaming support")
+	}
+
+	chunks, err := collect(l.GenerateStream(context.Background(), "prompt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("Expected 4 chunks, got %d: %+v", len(chunks), chunks)
+	}
+
+	var text strings.Builder
+	for i, chunk := range chunks {
+		text.WriteString(chunk.Text)
+		if chunk.Completed != (i == len(chunks)-1) {
+			t.Errorf("Expected only the last chunk to be completed, chunk %d is %v", i, chunk.Completed)
+		}
+	}
+	if text.String() != "The quick brown fox" {
+		t.Errorf("Expected the chunks to make up the response, got %q", text.String())
+	}
+}
+
+func TestWithStreamingFallback_BlockingError(t *testing.T) {
+	generateErr := errors.New("provider unavailable")
+	l := WithStreamingFallback(&blockingLLM{err: generateErr})
+
+	chunks, err := collect(l.GenerateStream(context.Background(), "prompt"))
+	if len(chunks) != 0 {
+		t.Errorf("Expected no chunks, got %+v", chunks)
+	}
+	if !errors.Is(err, generateErr) {
+		t.Errorf("Expected the Generate error, got %v", err)
+	}
+}
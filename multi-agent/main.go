@@ -16,6 +16,7 @@ import (
 	"github.com/sammcj/go-a2a/client"
 	"github.com/sammcj/go-a2a/llm"
 	"github.com/sammcj/go-a2a/llm/gollm"
+	"github.com/sammcj/go-a2a/pkg/task"
 	"github.com/sammcj/go-a2a/server"
 )
 
@@ -49,18 +50,37 @@ type Agent struct {
 	TaskRouter *TaskRouter
 }
 
+// Names of the agents a TaskRouter delegates to, as passed to Broadcast.
+const (
+	WebAgentName      = "web"
+	ReasonerAgentName = "reasoner"
+)
+
+// DefaultBroadcastTimeout is how long Broadcast waits for the agents to respond.
+const DefaultBroadcastTimeout = 2 * time.Minute
+
 // TaskRouter routes tasks between agents.
 type TaskRouter struct {
-	WebAgent      *client.Client
-	CustomerAgent *client.Client
-	ReasonerAgent *client.Client
-	mu            sync.Mutex
+	WebAgent         *client.Client
+	CustomerAgent    *client.Client
+	ReasonerAgent    *client.Client
+	retry            client.Backoff          // Delay before retrying a delegation that failed transiently
+	broadcastTimeout time.Duration           // How long Broadcast waits for the agents to respond
+	skills           map[string]indexedSkill // Skills the agents advertise, keyed by skill ID
+	cardRetry        map[string]time.Time    // When to fetch an unreachable agent's card again, keyed by agent name
+	skillRetry       time.Duration           // How long to wait before fetching an unreachable agent's card again
+	mu               sync.Mutex              // Guards the agent clients and skill index, not the delegations made with them
 }
 
 // NewTaskRouter creates a new TaskRouter.
 func NewTaskRouter() *TaskRouter {
 	return &TaskRouter{
-		mu: sync.Mutex{},
+		retry:            client.Backoff{Base: 500 * time.Millisecond, Jitter: 0.5},
+		broadcastTimeout: DefaultBroadcastTimeout,
+		skills:           make(map[string]indexedSkill),
+		cardRetry:        make(map[string]time.Time),
+		skillRetry:       DefaultSkillRetryInterval,
+		mu:               sync.Mutex{},
 	}
 }
 
@@ -86,27 +106,103 @@ func (r *TaskRouter) SetReasonerAgent(client *client.Client) {
 }
 
 // RouteToWebAgent routes a task to the web agent.
-func (r *TaskRouter) RouteToWebAgent(ctx context.Context, message a2a.Message) (*a2a.Task, error) {
+func (r *TaskRouter) RouteToWebAgent(ctx context.Context, message a2a.Message) (*client.DelegateResult, error) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-	if r.WebAgent == nil {
+	agent := r.WebAgent
+	r.mu.Unlock()
+	if agent == nil {
 		return nil, fmt.Errorf("web agent not set")
 	}
-	return r.WebAgent.SendTask(ctx, &a2a.TaskSendParams{
-		Message: message,
-	})
+	return r.delegate(ctx, agent, &a2a.TaskSendParams{Message: message})
 }
 
 // RouteToReasonerAgent routes a task to the reasoner agent.
-func (r *TaskRouter) RouteToReasonerAgent(ctx context.Context, message a2a.Message) (*a2a.Task, error) {
+func (r *TaskRouter) RouteToReasonerAgent(ctx context.Context, message a2a.Message) (*client.DelegateResult, error) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-	if r.ReasonerAgent == nil {
+	agent := r.ReasonerAgent
+	r.mu.Unlock()
+	if agent == nil {
 		return nil, fmt.Errorf("reasoner agent not set")
 	}
-	return r.ReasonerAgent.SendTask(ctx, &a2a.TaskSendParams{
-		Message: message,
-	})
+	return r.delegate(ctx, agent, &a2a.TaskSendParams{Message: message})
+}
+
+// BroadcastResult is an agent's response to a broadcast message.
+type BroadcastResult struct {
+	Result *client.DelegateResult // The agent's response (nil if it did not respond)
+	Err    error                  // Why the agent did not respond, e.g. it timed out
+}
+
+// Broadcast sends a message to several agents at once, named as in WebAgentName, and waits
+// up to the broadcast timeout for their responses. It returns each agent's response keyed by
+// name; agents that fail or do not respond in time have an error instead, so the responses
+// that did arrive can still be used.
+func (r *TaskRouter) Broadcast(ctx context.Context, message a2a.Message, agents ...string) map[string]BroadcastResult {
+	ctx, cancel := context.WithTimeout(ctx, r.broadcastTimeout)
+	defer cancel()
+
+	results := make(map[string]BroadcastResult, len(agents))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := r.route(ctx, name, message)
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = BroadcastResult{Result: result, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// route routes a task to the named agent.
+func (r *TaskRouter) route(ctx context.Context, name string, message a2a.Message) (*client.DelegateResult, error) {
+	switch name {
+	case WebAgentName:
+		return r.RouteToWebAgent(ctx, message)
+	case ReasonerAgentName:
+		return r.RouteToReasonerAgent(ctx, message)
+	default:
+		return nil, fmt.Errorf("unknown agent %q", name)
+	}
+}
+
+// delegate sends a task to an agent and follows its stream until it finishes, aggregating
+// every response chunk and artifact the agent streams. If the stream fails transiently
+// before the task starts, the task is sent once more after a short backoff; once the task
+// has started, sending it again would run it twice.
+func (r *TaskRouter) delegate(ctx context.Context, agent *client.Client, params *a2a.TaskSendParams) (*client.DelegateResult, error) {
+	result, err := streamTask(ctx, agent, params)
+	if err == nil {
+		return result, nil
+	}
+	if result.Task.ID != "" || !client.IsTransient(err) {
+		return nil, fmt.Errorf("failed to delegate task: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to delegate task: %w", err)
+	case <-time.After(r.retry.Delay(0)):
+	}
+	result, err = streamTask(ctx, agent, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delegate task after retrying: %w", err)
+	}
+	return result, nil
+}
+
+// streamTask sends a task to an agent and aggregates its updates until it finishes.
+func streamTask(ctx context.Context, agent *client.Client, params *a2a.TaskSendParams) (*client.DelegateResult, error) {
+	// Stop reading the stream once the task has finished
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, errs := agent.SendSubscribe(ctx, params)
+	return client.AggregateUpdates(ctx, updates, errs)
 }
 
 // LoadAgentConfig loads an agent configuration from a file.
@@ -429,6 +525,9 @@ func createWebAgentHandler(systemPrompt string, gollmOptions []gollm.Option) fun
 	}
 }
 
+// customerRoutes are the routes the customer agent's model chooses between.
+var customerRoutes = []string{"web", "reasoner", "both", "direct"}
+
 // createCustomerAgentHandler creates a task handler for the customer agent.
 func createCustomerAgentHandler(systemPrompt string, gollmOptions []gollm.Option, taskRouter *TaskRouter) func(ctx context.Context, taskCtx server.TaskContext) (<-chan server.TaskYieldUpdate, error) {
 	return func(ctx context.Context, taskCtx server.TaskContext) (<-chan server.TaskYieldUpdate, error) {
@@ -470,38 +569,36 @@ func createCustomerAgentHandler(systemPrompt string, gollmOptions []gollm.Option
 				return
 			}
 
-			// Determine if we need to route to another agent
-			routePrompt := fmt.Sprintf(`
-User message: %s
-
-Based on this message, determine if it requires:
-1. Web search or information retrieval (route to web agent)
-2. Complex reasoning or analysis (route to reasoner agent)
-3. Direct response (handle directly)
-
-Respond with one of: "web", "reasoner", or "direct"
-`, userText)
-
-			routeDecision, err := adapter.Generate(ctx, routePrompt, llm.WithSystemPrompt("You are a routing agent that determines which agent should handle a user request."))
+			// Route by the skills the downstream agents advertise, then ask the model
+			skillResponse, routedBySkill, err := routeBySkill(ctx, adapter, taskRouter, userText)
 			if err != nil {
-				updateChan <- server.StatusUpdate{
-					State: a2a.TaskStateFailed,
-					Message: &a2a.Message{
-						Role: a2a.RoleSystem,
-						Parts: []a2a.Part{
-							a2a.TextPart{
-								Type: "text",
-								Text: fmt.Sprintf("Failed to determine routing: %v", err),
+				log.Printf("Failed to route by skill, asking the model instead: %v", err)
+			}
+			var routeDecision task.RouteDecision
+			if !routedBySkill {
+				routeDecision, err = decideRoute(ctx, adapter, userText)
+				if err != nil {
+					updateChan <- server.StatusUpdate{
+						State: a2a.TaskStateFailed,
+						Message: &a2a.Message{
+							Role: a2a.RoleSystem,
+							Parts: []a2a.Part{
+								a2a.TextPart{
+									Type: "text",
+									Text: fmt.Sprintf("Failed to determine routing: %v", err),
+								},
 							},
 						},
-					},
+					}
+					return
 				}
-				return
 			}
 
 			// Process based on routing decision
 			var finalResponse string
-			if routeDecision == "web" || routeDecision == "WEB" {
+			if routedBySkill {
+				finalResponse = skillResponse
+			} else if routeDecision.Route == "web" {
 				// Route to web agent
 				webMessage := a2a.Message{
 					Role: a2a.RoleUser,
@@ -513,7 +610,7 @@ Respond with one of: "web", "reasoner", or "direct"
 					},
 				}
 
-				webTask, err := taskRouter.RouteToWebAgent(ctx, webMessage)
+				webResult, err := taskRouter.RouteToWebAgent(ctx, webMessage)
 				if err != nil {
 					updateChan <- server.StatusUpdate{
 						State: a2a.TaskStateFailed,
@@ -531,8 +628,8 @@ Respond with one of: "web", "reasoner", or "direct"
 				}
 
 				// Get the web agent's response
-				finalResponse = fmt.Sprintf("I've consulted our web agent for this query. Here's what I found:\n\n%s", getTaskResponse(webTask))
-			} else if routeDecision == "reasoner" || routeDecision == "REASONER" {
+				finalResponse = fmt.Sprintf("I've consulted our web agent for this query. Here's what I found:\n\n%s", getDelegateResponse(webResult))
+			} else if routeDecision.Route == "reasoner" {
 				// Route to reasoner agent
 				reasonerMessage := a2a.Message{
 					Role: a2a.RoleUser,
@@ -544,7 +641,7 @@ Respond with one of: "web", "reasoner", or "direct"
 					},
 				}
 
-				reasonerTask, err := taskRouter.RouteToReasonerAgent(ctx, reasonerMessage)
+				reasonerResult, err := taskRouter.RouteToReasonerAgent(ctx, reasonerMessage)
 				if err != nil {
 					updateChan <- server.StatusUpdate{
 						State: a2a.TaskStateFailed,
@@ -562,7 +659,42 @@ Respond with one of: "web", "reasoner", or "direct"
 				}
 
 				// Get the reasoner agent's response
-				finalResponse = fmt.Sprintf("I've consulted our reasoning specialist for this query. Here's the analysis:\n\n%s", getTaskResponse(reasonerTask))
+				finalResponse = fmt.Sprintf("I've consulted our reasoning specialist for this query. Here's the analysis:\n\n%s", getDelegateResponse(reasonerResult))
+			} else if routeDecision.Route == "both" {
+				// Ask the web and reasoner agents at once
+				agents := []string{WebAgentName, ReasonerAgentName}
+				results := taskRouter.Broadcast(ctx, a2a.Message{
+					Role: a2a.RoleUser,
+					Parts: []a2a.Part{
+						a2a.TextPart{
+							Type: "text",
+							Text: userText,
+						},
+					},
+				}, agents...)
+
+				// Fail only if no agent responded
+				responded := false
+				for _, result := range results {
+					responded = responded || result.Err == nil
+				}
+				if !responded {
+					updateChan <- server.StatusUpdate{
+						State: a2a.TaskStateFailed,
+						Message: &a2a.Message{
+							Role: a2a.RoleSystem,
+							Parts: []a2a.Part{
+								a2a.TextPart{
+									Type: "text",
+									Text: fmt.Sprintf("Failed to route to the web and reasoner agents: %s", mergeResponses(results, agents...)),
+								},
+							},
+						},
+					}
+					return
+				}
+
+				finalResponse = fmt.Sprintf("I've consulted our web agent and reasoning specialist for this query. Here's what they found:\n\n%s", mergeResponses(results, agents...))
 			} else {
 				// Handle directly
 				directResponse, err := adapter.Generate(ctx, userText, llm.WithSystemPrompt(systemPrompt))
@@ -611,6 +743,36 @@ Respond with one of: "web", "reasoner", or "direct"
 	}
 }
 
+// decideRoute asks the model which of customerRoutes should handle a message, handling it
+// directly if the model's decision cannot be parsed.
+func decideRoute(ctx context.Context, model llm.LLMInterface, userText string) (task.RouteDecision, error) {
+	routeSchema, _ := json.Marshal(task.RouteSchema(customerRoutes...))
+	routePrompt := fmt.Sprintf(`
+User message: %s
+
+Based on this message, determine if it requires:
+1. Web search or information retrieval (route "web")
+2. Complex reasoning or analysis (route "reasoner")
+3. Both information retrieval and reasoning (route "both")
+4. Direct response (route "direct")
+
+Respond with a JSON object matching this schema, e.g. {"route": "web", "reason": "..."}:
+%s
+`, userText, routeSchema)
+
+	routeReply, err := model.Generate(ctx, routePrompt, llm.WithSystemPrompt("You are a routing agent that determines which agent should handle a user request."))
+	if err != nil {
+		return task.RouteDecision{}, err
+	}
+
+	routeDecision, err := task.ParseRouteDecision(routeReply, customerRoutes...)
+	if err != nil {
+		log.Printf("Failed to parse routing decision %q, handling directly: %v", routeReply, err)
+		return task.RouteDecision{Route: "direct"}, nil
+	}
+	return routeDecision, nil
+}
+
 // createReasonerAgentHandler creates a task handler for the reasoner agent.
 func createReasonerAgentHandler(systemPrompt string, gollmOptions []gollm.Option) func(ctx context.Context, taskCtx server.TaskContext) (<-chan server.TaskYieldUpdate, error) {
 	return func(ctx context.Context, taskCtx server.TaskContext) (<-chan server.TaskYieldUpdate, error) {
@@ -697,19 +859,50 @@ func createReasonerAgentHandler(systemPrompt string, gollmOptions []gollm.Option
 	}
 }
 
-// getTaskResponse extracts the response text from a task.
-func getTaskResponse(task *a2a.Task) string {
-	if task == nil || task.Status.Message == nil {
-		return "No response available"
+// mergeResponses combines the responses to a broadcast into one reply, with a section for
+// each agent in the order given. Agents that did not respond are noted, so a partial reply
+// says what is missing.
+func mergeResponses(results map[string]BroadcastResult, agents ...string) string {
+	sections := make([]string, 0, len(agents))
+	for _, name := range agents {
+		result, ok := results[name]
+		switch {
+		case !ok:
+			continue
+		case result.Err != nil:
+			sections = append(sections, fmt.Sprintf("The %s agent did not respond: %v", name, result.Err))
+		default:
+			sections = append(sections, fmt.Sprintf("From the %s agent:\n\n%s", name, getDelegateResponse(result.Result)))
+		}
 	}
+	return strings.Join(sections, "\n\n")
+}
 
-	var responseText string
-	for _, part := range task.Status.Message.Parts {
-		if textPart, ok := part.(a2a.TextPart); ok {
+// getDelegateResponse assembles the response of a delegated task: the text the agent
+// streamed, followed by the text of its artifacts. If the agent sent no text, as when its
+// task fails, the final status message is used.
+func getDelegateResponse(result *client.DelegateResult) string {
+	responseText := result.Text
+	if responseText == "" && result.Task.Status.Message != nil {
+		for _, part := range result.Task.Status.Message.Parts {
+			if textPart, ok := part.(a2a.TextPart); ok {
+				responseText += textPart.Text
+			}
+		}
+	}
+
+	for _, artifact := range result.Artifacts {
+		if textPart, ok := artifact.Part.(a2a.TextPart); ok && textPart.Text != "" {
+			if responseText != "" {
+				responseText += "\n\n"
+			}
 			responseText += textPart.Text
 		}
 	}
 
+	if responseText == "" {
+		return "No response available"
+	}
 	return responseText
 }
 
@@ -789,6 +982,11 @@ func main() {
 	log.Printf("Customer agent: http://localhost%s", customerAgentConfig.ListenAddress)
 	log.Printf("Reasoner agent: http://localhost%s", reasonerAgentConfig.ListenAddress)
 
+	// Index the skills the downstream agents advertise; agents not yet reachable are retried when routing
+	if err := taskRouter.LoadSkills(context.Background()); err != nil {
+		log.Printf("Failed to load agent skills: %v", err)
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
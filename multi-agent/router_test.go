@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+)
+
+// newAgentServer starts an agent that answers tasks/sendSubscribe with respond, which is given
+// the request's context and params and returns the HTTP status, or 0 to stream a task that
+// answers "Hello, world!" in two chunks with an artifact between them.
+func newAgentServer(t *testing.T, respond func(ctx context.Context, params a2a.TaskSendParams) int) *client.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Params a2a.TaskSendParams `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// The request's context is only cancelled when the client goes away once the body is read
+		io.Copy(io.Discard, r.Body)
+		if status := respond(r.Context(), request.Params); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []interface{}{
+			a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateSubmitted}},
+			a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: agentStatus(a2a.TaskStateWorking, "Hello, ")},
+			a2a.TaskArtifactUpdateEvent{TaskID: "task-1", Artifact: a2a.Artifact{ID: "artifact-1", Part: a2a.TextPart{Type: "text", Text: "Sources: example.com"}}},
+			a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: agentStatus(a2a.TaskStateWorking, "world!")},
+			a2a.TaskStatusUpdateEvent{TaskID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}},
+		} {
+			name := "taskStatusUpdate"
+			if _, ok := event.(a2a.TaskArtifactUpdateEvent); ok {
+				name = "taskArtifactUpdate"
+			}
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+			w.(http.Flusher).Flush()
+		}
+
+		// Like an A2A server, keep the stream open after the task finishes
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient(client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+// agentStatus returns a status with an agent message holding text.
+func agentStatus(state a2a.TaskState, text string) a2a.TaskStatus {
+	return a2a.TaskStatus{
+		State:   state,
+		Message: &a2a.Message{Role: a2a.RoleAgent, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}}},
+	}
+}
+
+// newRouterMessage creates a user message to route.
+func newRouterMessage(text string) a2a.Message {
+	return a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}}}
+}
+
+func TestTaskRouter_ConcurrentDelegations(t *testing.T) {
+	const delegations = 8
+
+	// Each request waits until every delegation has arrived, so they only finish quickly if
+	// they all overlap; delegations made one at a time would each wait out the timeout
+	var inFlight, maxInFlight atomic.Int32
+	allArrived := make(chan struct{})
+	var once sync.Once
+	wait := func(context.Context, a2a.TaskSendParams) int {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if n == delegations {
+			once.Do(func() { close(allArrived) })
+		}
+		select {
+		case <-allArrived:
+		case <-time.After(2 * time.Second):
+		}
+		return 0
+	}
+	router := NewTaskRouter()
+	router.SetWebAgent(newAgentServer(t, wait))
+	router.SetReasonerAgent(newAgentServer(t, wait))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < delegations; i++ {
+		route := router.RouteToWebAgent
+		if i%2 == 1 {
+			route = router.RouteToReasonerAgent
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := route(context.Background(), newRouterMessage("What's new?")); err != nil {
+				t.Errorf("Delegation failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got != delegations {
+		t.Errorf("Expected all %d delegations to be in flight at once, got at most %d", delegations, got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the delegations to overlap, but they took %s", elapsed)
+	}
+}
+
+func TestTaskRouter_AggregatesStreamedResponse(t *testing.T) {
+	router := NewTaskRouter()
+	router.SetWebAgent(newAgentServer(t, func(context.Context, a2a.TaskSendParams) int { return 0 }))
+
+	result, err := router.RouteToWebAgent(context.Background(), newRouterMessage("Say hello."))
+	if err != nil {
+		t.Fatalf("RouteToWebAgent failed: %v", err)
+	}
+	if want := "Hello, world!\n\nSources: example.com"; getDelegateResponse(result) != want {
+		t.Errorf("Expected response %q, got %q", want, getDelegateResponse(result))
+	}
+}
+
+func TestTaskRouter_RetriesTransientFailure(t *testing.T) {
+	var attempts atomic.Int32
+	router := NewTaskRouter()
+	router.retry = client.Backoff{Base: 10 * time.Millisecond}
+	router.SetReasonerAgent(newAgentServer(t, func(context.Context, a2a.TaskSendParams) int {
+		if attempts.Add(1) == 1 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	}))
+
+	result, err := router.RouteToReasonerAgent(context.Background(), newRouterMessage("Prove it."))
+	if err != nil {
+		t.Fatalf("RouteToReasonerAgent failed: %v", err)
+	}
+	if result.Task.ID != "task-1" || result.Text != "Hello, world!" {
+		t.Errorf("Expected the downstream task's response, got %+v", result)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestTaskRouter_RetriesOnlyOnce(t *testing.T) {
+	var attempts atomic.Int32
+	router := NewTaskRouter()
+	router.retry = client.Backoff{Base: 10 * time.Millisecond}
+	router.SetWebAgent(newAgentServer(t, func(context.Context, a2a.TaskSendParams) int {
+		attempts.Add(1)
+		return http.StatusServiceUnavailable
+	}))
+
+	if _, err := router.RouteToWebAgent(context.Background(), newRouterMessage("What's new?")); err == nil {
+		t.Fatal("Expected an error when the agent stays unavailable")
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+
+	// Errors the agent returns are not transient
+	attempts.Store(0)
+	router.SetWebAgent(newAgentServer(t, func(context.Context, a2a.TaskSendParams) int {
+		attempts.Add(1)
+		return http.StatusBadRequest
+	}))
+	if _, err := router.RouteToWebAgent(context.Background(), newRouterMessage("What's new?")); err == nil {
+		t.Fatal("Expected an error for a rejected task")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected a rejected task not to be retried, got %d attempts", got)
+	}
+}
+
+func TestTaskRouter_Broadcast(t *testing.T) {
+	// Each agent waits for the other, so they only respond quickly if dispatched at once
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	bothArrived := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(bothArrived)
+	}()
+	wait := func(context.Context, a2a.TaskSendParams) int {
+		arrived.Done()
+		select {
+		case <-bothArrived:
+		case <-time.After(2 * time.Second):
+			t.Error("Expected the agents to be sent the message at once")
+		}
+		return 0
+	}
+	router := NewTaskRouter()
+	router.SetWebAgent(newAgentServer(t, wait))
+	router.SetReasonerAgent(newAgentServer(t, wait))
+
+	results := router.Broadcast(context.Background(), newRouterMessage("Research and analyse."), WebAgentName, ReasonerAgentName)
+	for _, name := range []string{WebAgentName, ReasonerAgentName} {
+		if result := results[name]; result.Err != nil || result.Result == nil || result.Result.Text != "Hello, world!" {
+			t.Errorf("Expected the %s agent's response, got %+v", name, result)
+		}
+	}
+
+	merged := mergeResponses(results, WebAgentName, ReasonerAgentName)
+	want := "From the web agent:\n\nHello, world!\n\nSources: example.com\n\nFrom the reasoner agent:\n\nHello, world!\n\nSources: example.com"
+	if merged != want {
+		t.Errorf("Expected merged response %q, got %q", want, merged)
+	}
+}
+
+func TestTaskRouter_BroadcastPartialResults(t *testing.T) {
+	router := NewTaskRouter()
+	router.broadcastTimeout = 200 * time.Millisecond
+	router.SetWebAgent(newAgentServer(t, func(context.Context, a2a.TaskSendParams) int { return 0 }))
+	router.SetReasonerAgent(newAgentServer(t, func(ctx context.Context, params a2a.TaskSendParams) int {
+		// Never respond
+		<-ctx.Done()
+		return http.StatusServiceUnavailable
+	}))
+
+	start := time.Now()
+	results := router.Broadcast(context.Background(), newRouterMessage("Research and analyse."), WebAgentName, ReasonerAgentName)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Broadcast to give up after its timeout, took %s", elapsed)
+	}
+
+	if result := results[WebAgentName]; result.Err != nil || result.Result.Text != "Hello, world!" {
+		t.Errorf("Expected the web agent's response, got %+v", result)
+	}
+	if result := results[ReasonerAgentName]; !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the reasoner agent to time out, got %+v", result)
+	}
+
+	merged := mergeResponses(results, WebAgentName, ReasonerAgentName)
+	if !strings.Contains(merged, "From the web agent:\n\nHello, world!") || !strings.Contains(merged, "The reasoner agent did not respond") {
+		t.Errorf("Expected the web agent's response and a note about the reasoner agent, got %q", merged)
+	}
+}
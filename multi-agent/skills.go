@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/llm"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// DefaultSkillRetryInterval is how long a TaskRouter waits before fetching the card of an
+// agent that could not be reached again.
+const DefaultSkillRetryInterval = 30 * time.Second
+
+// SkillMatch is a skill advertised by a downstream agent that matches a request.
+type SkillMatch struct {
+	Agent string         // Name of the agent advertising the skill, e.g. WebAgentName
+	Skill a2a.AgentSkill // The matching skill
+	Score int            // How many of the skill's terms the request mentions
+}
+
+// indexedSkill is a skill in a TaskRouter's skill index, with the agent advertising it.
+type indexedSkill struct {
+	agent string
+	skill a2a.AgentSkill
+}
+
+// agents returns the downstream agents that are set, keyed by name.
+func (r *TaskRouter) agents() map[string]*client.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agents := make(map[string]*client.Client, 2)
+	if r.WebAgent != nil {
+		agents[WebAgentName] = r.WebAgent
+	}
+	if r.ReasonerAgent != nil {
+		agents[ReasonerAgentName] = r.ReasonerAgent
+	}
+	return agents
+}
+
+// LoadSkills fetches the agent card of each downstream agent and indexes the skills the
+// agents advertise, so requests can be routed by skill. Agents that cannot be reached are
+// skipped, and MatchSkills tries them again once the retry interval has passed, so an agent
+// that starts late is still picked up. It returns an error for each agent not reached.
+func (r *TaskRouter) LoadSkills(ctx context.Context) error {
+	var errs []error
+	for name, agent := range r.agents() {
+		if err := r.loadAgentSkills(ctx, name, agent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadAgentSkills fetches an agent's card, which the client caches once fetched, and
+// replaces the agent's skills in the index with the ones on the card.
+func (r *TaskRouter) loadAgentSkills(ctx context.Context, name string, agent *client.Client) error {
+	card, err := agent.FetchAgentCard(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.cardRetry[name] = time.Now().Add(r.skillRetry)
+		return fmt.Errorf("failed to fetch the %s agent's card: %w", name, err)
+	}
+	delete(r.cardRetry, name)
+
+	for id, indexed := range r.skills {
+		if indexed.agent == name {
+			delete(r.skills, id)
+		}
+	}
+	for _, skill := range card.Skills {
+		if other, ok := r.skills[skill.ID]; ok {
+			log.Printf("Skill %s is advertised by both the %s and %s agents; routing it to the %s agent", skill.ID, other.agent, name, other.agent)
+			continue
+		}
+		r.skills[skill.ID] = indexedSkill{agent: name, skill: skill}
+	}
+	return nil
+}
+
+// MatchSkills returns the downstream agents' skills that match a request, best first. A
+// skill matches if the request mentions any of its terms: its tags and the words of its ID
+// and name. Agents whose cards have not been fetched, or could not be fetched before the
+// retry interval, are tried first.
+func (r *TaskRouter) MatchSkills(ctx context.Context, text string) []SkillMatch {
+	now := time.Now()
+	for name, agent := range r.agents() {
+		r.mu.Lock()
+		retry, failed := r.cardRetry[name]
+		r.mu.Unlock()
+		if failed && now.Before(retry) {
+			continue
+		}
+		if err := r.loadAgentSkills(ctx, name, agent); err != nil {
+			log.Printf("Routing without the %s agent's skills: %v", name, err)
+		}
+	}
+
+	words := make(map[string]bool)
+	for _, word := range splitWords(text) {
+		words[word] = true
+	}
+
+	r.mu.Lock()
+	var matches []SkillMatch
+	for _, indexed := range r.skills {
+		score := 0
+		for _, term := range skillTerms(indexed.skill) {
+			if words[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, SkillMatch{Agent: indexed.agent, Skill: indexed.skill, Score: score})
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Skill.ID < matches[j].Skill.ID
+	})
+	return matches
+}
+
+// RouteToSkill routes a task to the agent advertising a skill, invoking the skill.
+func (r *TaskRouter) RouteToSkill(ctx context.Context, match SkillMatch, message a2a.Message) (*client.DelegateResult, error) {
+	agent, ok := r.agents()[match.Agent]
+	if !ok {
+		return nil, fmt.Errorf("%s agent not set", match.Agent)
+	}
+	skillID := match.Skill.ID
+	return r.delegate(ctx, agent, &a2a.TaskSendParams{
+		Message: message,
+		SkillID: &skillID,
+	})
+}
+
+// skillTerms returns the lower-case terms a request can mention to match a skill.
+func skillTerms(skill a2a.AgentSkill) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range append(append(splitWords(skill.ID), splitWords(skill.Name)...), skill.Tags...) {
+		term = strings.ToLower(term)
+		if len(term) >= 3 && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// splitWords splits text into lower-case words of letters and digits.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// routeBySkill routes a request to the downstream agent advertising the skill that best
+// matches it, and returns the agent's response. If skills of more than one agent match, the
+// model picks among them, falling back to the best match if its reply cannot be parsed. It
+// reports false if no skill matches.
+func routeBySkill(ctx context.Context, model llm.LLMInterface, router *TaskRouter, userText string) (string, bool, error) {
+	matches := router.MatchSkills(ctx, userText)
+	if len(matches) == 0 {
+		return "", false, nil
+	}
+
+	match := matches[0]
+	if spansAgents(matches) {
+		match = pickSkill(ctx, model, userText, matches)
+	}
+
+	result, err := router.RouteToSkill(ctx, match, a2a.Message{
+		Role: a2a.RoleUser,
+		Parts: []a2a.Part{
+			a2a.TextPart{
+				Type: "text",
+				Text: userText,
+			},
+		},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to route to the %s agent's %s skill: %w", match.Agent, match.Skill.ID, err)
+	}
+	return fmt.Sprintf("I've consulted our %s agent (%s) for this query. Here's what it found:\n\n%s", match.Agent, match.Skill.Name, getDelegateResponse(result)), true, nil
+}
+
+// spansAgents reports whether skill matches come from more than one agent.
+func spansAgents(matches []SkillMatch) bool {
+	for _, match := range matches[1:] {
+		if match.Agent != matches[0].Agent {
+			return true
+		}
+	}
+	return false
+}
+
+// pickSkill asks the model which of the matching skills should handle a request. It returns
+// the best match if the model fails or its choice cannot be parsed.
+func pickSkill(ctx context.Context, model llm.LLMInterface, userText string, matches []SkillMatch) SkillMatch {
+	skillIDs := make([]string, len(matches))
+	var skills strings.Builder
+	for i, match := range matches {
+		skillIDs[i] = match.Skill.ID
+		fmt.Fprintf(&skills, "- %s (%s agent): %s", match.Skill.ID, match.Agent, match.Skill.Name)
+		if match.Skill.Description != nil {
+			fmt.Fprintf(&skills, ", %s", *match.Skill.Description)
+		}
+		skills.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`
+User message: %s
+
+Which of these skills should handle the message?
+%s
+Respond with a JSON object naming the skill's ID as the route, e.g. {"route": "%s"}.
+`, userText, skills.String(), skillIDs[0])
+
+	reply, err := model.Generate(ctx, prompt, llm.WithSystemPrompt("You are a routing agent that determines which agent should handle a user request."))
+	if err != nil {
+		log.Printf("Failed to pick a skill, using %s: %v", matches[0].Skill.ID, err)
+		return matches[0]
+	}
+	decision, err := task.ParseRouteDecision(reply, skillIDs...)
+	if err != nil {
+		log.Printf("Failed to parse skill choice %q, using %s: %v", reply, matches[0].Skill.ID, err)
+		return matches[0]
+	}
+	for _, match := range matches {
+		if strings.EqualFold(match.Skill.ID, decision.Route) {
+			return match
+		}
+	}
+	return matches[0]
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/llm"
+)
+
+// newSkillAgentServer starts an agent whose card advertises skills, and which answers
+// tasks/sendSubscribe with the ID of the skill it was asked to invoke. If reachable is set,
+// the agent answers every request with 503 Service Unavailable while it is false.
+func newSkillAgentServer(t *testing.T, reachable *atomic.Bool, skills ...a2a.AgentSkill) *client.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reachable != nil && !reachable.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(a2a.AgentCard{Name: "Mock Agent", Skills: skills})
+			return
+		}
+
+		var request struct {
+			Params a2a.TaskSendParams `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		skillID := "none"
+		if request.Params.SkillID != nil {
+			skillID = *request.Params.SkillID
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []a2a.TaskStatusUpdateEvent{
+			{TaskID: "task-1", Status: agentStatus(a2a.TaskStateWorking, "Handled by "+skillID)},
+			{TaskID: "task-1", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}},
+		} {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: taskStatusUpdate\ndata: %s\n\n", data)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient(client.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+// pickingLLM is an LLM that answers every prompt with reply, counting the calls.
+type pickingLLM struct {
+	reply string
+	calls atomic.Int32
+}
+
+func (m *pickingLLM) Generate(ctx context.Context, prompt string, options ...llm.LLMOption) (string, error) {
+	m.calls.Add(1)
+	return m.reply, nil
+}
+
+func (m *pickingLLM) GenerateStream(ctx context.Context, prompt string, options ...llm.LLMOption) (<-chan llm.LLMChunk, <-chan error) {
+	chunks := make(chan llm.LLMChunk)
+	errs := make(chan error)
+	close(chunks)
+	close(errs)
+	return chunks, errs
+}
+
+func (m *pickingLLM) GetModelInfo() llm.LLMModelInfo {
+	return llm.LLMModelInfo{Name: "picking"}
+}
+
+var (
+	webSearchSkill = a2a.AgentSkill{ID: "web-search", Name: "Web Search", Tags: []string{"news", "search"}}
+	mathsSkill     = a2a.AgentSkill{ID: "maths", Name: "Mathematical Reasoning", Tags: []string{"calculate", "proof"}}
+	summariseSkill = a2a.AgentSkill{ID: "summarise", Name: "Summarise", Tags: []string{"news", "summary"}}
+)
+
+func TestTaskRouter_MatchSkills(t *testing.T) {
+	router := NewTaskRouter()
+	router.SetWebAgent(newSkillAgentServer(t, nil, webSearchSkill))
+	router.SetReasonerAgent(newSkillAgentServer(t, nil, mathsSkill, summariseSkill))
+	if err := router.LoadSkills(context.Background()); err != nil {
+		t.Fatalf("LoadSkills failed: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want []string // Matching skill IDs, best first
+	}{
+		{text: "Search the news for today's headlines", want: []string{"web-search", "summarise"}},
+		{text: "Please calculate 12 * 7.", want: []string{"maths"}},
+		{text: "Give me a summary of the news", want: []string{"summarise", "web-search"}},
+		{text: "Hello there!", want: nil},
+	}
+	for _, tt := range tests {
+		matches := router.MatchSkills(context.Background(), tt.text)
+		var got []string
+		for _, match := range matches {
+			got = append(got, match.Skill.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("MatchSkills(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	if matches := router.MatchSkills(context.Background(), "calculate it"); len(matches) != 1 || matches[0].Agent != ReasonerAgentName {
+		t.Errorf("Expected the maths skill to be indexed under the reasoner agent, got %+v", matches)
+	}
+}
+
+func TestRouteBySkill(t *testing.T) {
+	router := NewTaskRouter()
+	router.SetWebAgent(newSkillAgentServer(t, nil, webSearchSkill))
+	router.SetReasonerAgent(newSkillAgentServer(t, nil, mathsSkill, summariseSkill))
+
+	// A single agent matches, so the model is not asked
+	model := &pickingLLM{reply: `{"route": "web-search"}`}
+	response, routed, err := routeBySkill(context.Background(), model, router, "Please calculate 12 * 7.")
+	if err != nil || !routed {
+		t.Fatalf("Expected the request to be routed by skill, got %v, %v", routed, err)
+	}
+	if !strings.Contains(response, "Handled by maths") {
+		t.Errorf("Expected the maths skill to handle the request, got %q", response)
+	}
+	if calls := model.calls.Load(); calls != 0 {
+		t.Errorf("Expected the model not to be asked, got %d calls", calls)
+	}
+
+	// Skills of both agents match, so the model picks one
+	response, routed, err = routeBySkill(context.Background(), model, router, "Give me a summary of the news")
+	if err != nil || !routed {
+		t.Fatalf("Expected the request to be routed by skill, got %v, %v", routed, err)
+	}
+	if !strings.Contains(response, "Handled by web-search") {
+		t.Errorf("Expected the model's choice to handle the request, got %q", response)
+	}
+	if calls := model.calls.Load(); calls != 1 {
+		t.Errorf("Expected the model to be asked once, got %d calls", calls)
+	}
+
+	// The best match is used if the model's choice cannot be parsed
+	model.reply = "I'm not sure."
+	response, _, _ = routeBySkill(context.Background(), model, router, "Give me a summary of the news")
+	if !strings.Contains(response, "Handled by summarise") {
+		t.Errorf("Expected the best match to handle the request, got %q", response)
+	}
+
+	// Requests matching no skill are left to other routing
+	if _, routed, err := routeBySkill(context.Background(), model, router, "Hello there!"); routed || err != nil {
+		t.Errorf("Expected no skill to match, got %v, %v", routed, err)
+	}
+}
+
+func TestTaskRouter_UnreachableAgent(t *testing.T) {
+	webReachable := &atomic.Bool{}
+	router := NewTaskRouter()
+	router.skillRetry = 100 * time.Millisecond
+	router.SetWebAgent(newSkillAgentServer(t, webReachable, webSearchSkill))
+	router.SetReasonerAgent(newSkillAgentServer(t, nil, mathsSkill))
+
+	// The reasoner agent's skills are still indexed while the web agent is down
+	if err := router.LoadSkills(context.Background()); err == nil || !strings.Contains(err.Error(), "web agent") {
+		t.Errorf("Expected an error for the unreachable web agent, got %v", err)
+	}
+	if matches := router.MatchSkills(context.Background(), "calculate and search"); len(matches) != 1 || matches[0].Skill.ID != "maths" {
+		t.Errorf("Expected only the reasoner agent's skill to match, got %+v", matches)
+	}
+
+	// The web agent's card is not fetched again until the retry interval has passed
+	webReachable.Store(true)
+	if matches := router.MatchSkills(context.Background(), "search"); len(matches) != 0 {
+		t.Errorf("Expected the web agent's card not to be fetched yet, got %+v", matches)
+	}
+	time.Sleep(150 * time.Millisecond)
+	matches := router.MatchSkills(context.Background(), "search")
+	if len(matches) != 1 || matches[0].Agent != WebAgentName || matches[0].Skill.ID != "web-search" {
+		t.Errorf("Expected the web agent's skill once it is reachable, got %+v", matches)
+	}
+}
@@ -47,6 +47,7 @@ type SkillConfig struct {
 	Description    string      `json:"description,omitempty" yaml:"description,omitempty"`
 	InputSchema    interface{} `json:"inputSchema,omitempty" yaml:"inputSchema,omitempty"`
 	ArtifactSchema interface{} `json:"artifactSchema,omitempty" yaml:"artifactSchema,omitempty"`
+	Tags           []string    `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 // CapabilitiesConfig represents the configuration for agent capabilities.
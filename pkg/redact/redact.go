@@ -0,0 +1,61 @@
+// Package redact removes sensitive fields from JSON documents before they are logged.
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Placeholder replaces the value of every redacted field.
+const Placeholder = "[REDACTED]"
+
+// JSON returns a copy of data with the value at each of the given paths replaced by Placeholder.
+// Paths are dot-separated object keys (e.g. "pushNotification.authentication.credentials");
+// arrays are traversed element by element, so "message.parts.data.apiKey" redacts the field in
+// every part. A "*" segment matches any key. Paths that do not exist are ignored, and data that
+// is not valid JSON is returned unchanged.
+func JSON(data []byte, paths []string) []byte {
+	if len(paths) == 0 || len(data) == 0 {
+		return data
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		doc = redact(doc, strings.Split(path, "."))
+	}
+
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// redact replaces the value at path within v.
+func redact(v interface{}, path []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		key, rest := path[0], path[1:]
+		for k, child := range v {
+			if key != "*" && k != key {
+				continue
+			}
+			if len(rest) == 0 {
+				v[k] = Placeholder
+			} else {
+				v[k] = redact(child, rest)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redact(child, path)
+		}
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	data := []byte(`{"message":{"parts":[{"type":"data","data":{"apiKey":"k1"}},{"type":"text","text":"hi"}]},"auth":{"token":"t1","user":"u1"}}`)
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{
+			name:  "nested field",
+			paths: []string{"auth.token"},
+			want:  `{"auth":{"token":"[REDACTED]","user":"u1"},"message":{"parts":[{"data":{"apiKey":"k1"},"type":"data"},{"text":"hi","type":"text"}]}}`,
+		},
+		{
+			name:  "field in array elements",
+			paths: []string{"message.parts.data.apiKey"},
+			want:  `{"auth":{"token":"t1","user":"u1"},"message":{"parts":[{"data":{"apiKey":"[REDACTED]"},"type":"data"},{"text":"hi","type":"text"}]}}`,
+		},
+		{
+			name:  "wildcard",
+			paths: []string{"*.token"},
+			want:  `{"auth":{"token":"[REDACTED]","user":"u1"},"message":{"parts":[{"data":{"apiKey":"k1"},"type":"data"},{"text":"hi","type":"text"}]}}`,
+		},
+		{
+			name:  "missing path",
+			paths: []string{"auth.password"},
+			want:  `{"auth":{"token":"t1","user":"u1"},"message":{"parts":[{"data":{"apiKey":"k1"},"type":"data"},{"text":"hi","type":"text"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JSON(data, tt.paths)
+			var gotDoc, wantDoc interface{}
+			json.Unmarshal(got, &gotDoc)
+			json.Unmarshal([]byte(tt.want), &wantDoc)
+			gotJSON, _ := json.Marshal(gotDoc)
+			wantJSON, _ := json.Marshal(wantDoc)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Expected %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+
+	if got := JSON([]byte("not json"), []string{"a"}); string(got) != "not json" {
+		t.Errorf("Expected invalid JSON to be returned unchanged, got %s", got)
+	}
+}
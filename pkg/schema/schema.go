@@ -0,0 +1,341 @@
+// Package schema validates values against the JSON Schemas in agent cards, such as a
+// skill's input and artifact schemas.
+//
+// Only the commonly used subset of JSON Schema is supported: type, enum, const, properties,
+// required, additionalProperties, items, minItems/maxItems, minLength/maxLength, pattern,
+// minimum/maximum and exclusiveMinimum/exclusiveMaximum, and allOf/anyOf/oneOf. Other
+// keywords, including $ref, are ignored, so unsupported constraints are not enforced.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// Validate checks value against schema and returns a FieldError for each violation, or nil
+// if the value is valid. Both may be decoded JSON or any value that marshals to JSON. Field
+// paths are relative to value (e.g. "items[0].name"; "" is value itself). A nil schema
+// accepts every value.
+func Validate(schema, value interface{}) ([]a2a.FieldError, error) {
+	if schema == nil {
+		return nil, nil
+	}
+
+	s, err := normalize(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	v, err := normalize(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	var errs []a2a.FieldError
+	validate(s, v, "", &errs)
+	return errs, nil
+}
+
+// ValidateSkillInput checks input against the input schema of a skill in an agent card,
+// so clients can reject invalid input without a round trip to the agent, and servers can
+// check dry-run tasks. It returns an a2a.ErrSkillNotFound error if the card has no such
+// skill, and an a2a.ErrValidation error listing the invalid fields if the input does not
+// match the schema. It is not an AgentCard method because this package imports a2a for
+// FieldError, so a2a cannot import it back.
+func ValidateSkillInput(card *a2a.AgentCard, skillID string, input interface{}) error {
+	for _, skill := range card.Skills {
+		if skill.ID != skillID {
+			continue
+		}
+		fields, err := Validate(skill.InputSchema, input)
+		if err != nil {
+			return fmt.Errorf("failed to validate input for skill %s: %w", skillID, err)
+		}
+		if len(fields) > 0 {
+			return a2a.ErrValidation(fields...)
+		}
+		return nil
+	}
+	return a2a.ErrSkillNotFound(skillID)
+}
+
+// normalize converts v into the generic form produced by decoding JSON, with numbers
+// as float64, so Go values and decoded documents are validated alike.
+func normalize(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case nil, bool, float64, string:
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// validate appends the violations of schema by value, found at path, to errs.
+func validate(schema, value interface{}, path string, errs *[]a2a.FieldError) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		// A false schema rejects everything; true (or anything else) accepts everything
+		if b, isBool := schema.(bool); isBool && !b {
+			addError(errs, path, "is not allowed")
+		}
+		return
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		addError(errs, path, fmt.Sprintf("expected %s, got %s", typeNames(t), typeOf(value)))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		addError(errs, path, fmt.Sprintf("must be one of %s", formatValues(enum)))
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		addError(errs, path, fmt.Sprintf("must be %s", formatValue(c)))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(s, v, path, errs)
+	case []interface{}:
+		validateArray(s, v, path, errs)
+	case string:
+		validateString(s, v, path, errs)
+	case float64:
+		validateNumber(s, v, path, errs)
+	}
+
+	validateCombinators(s, value, path, errs)
+}
+
+// validateObject checks the object keywords of a schema.
+func validateObject(s map[string]interface{}, v map[string]interface{}, path string, errs *[]a2a.FieldError) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := v[key]; !present {
+					addError(errs, joinPath(path, key), "is required")
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // Report errors in a stable order
+
+	for _, key := range keys {
+		if propSchema, ok := properties[key]; ok {
+			validate(propSchema, v[key], joinPath(path, key), errs)
+			continue
+		}
+		if additional, ok := s["additionalProperties"]; ok {
+			if b, isBool := additional.(bool); isBool && !b {
+				addError(errs, joinPath(path, key), "is not an allowed property")
+				continue
+			}
+			validate(additional, v[key], joinPath(path, key), errs)
+		}
+	}
+}
+
+// validateArray checks the array keywords of a schema.
+func validateArray(s map[string]interface{}, v []interface{}, path string, errs *[]a2a.FieldError) {
+	if n, ok := number(s["minItems"]); ok && float64(len(v)) < n {
+		addError(errs, path, fmt.Sprintf("must have at least %v items", n))
+	}
+	if n, ok := number(s["maxItems"]); ok && float64(len(v)) > n {
+		addError(errs, path, fmt.Sprintf("must have at most %v items", n))
+	}
+	if items, ok := s["items"]; ok {
+		for i, item := range v {
+			validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// validateString checks the string keywords of a schema.
+func validateString(s map[string]interface{}, v string, path string, errs *[]a2a.FieldError) {
+	length := float64(utf8.RuneCountInString(v))
+	if n, ok := number(s["minLength"]); ok && length < n {
+		addError(errs, path, fmt.Sprintf("must have a length of at least %v", n))
+	}
+	if n, ok := number(s["maxLength"]); ok && length > n {
+		addError(errs, path, fmt.Sprintf("must have a length of at most %v", n))
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		// Invalid patterns are ignored, like other keywords that cannot be enforced
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+			addError(errs, path, fmt.Sprintf("must match pattern %q", pattern))
+		}
+	}
+}
+
+// validateNumber checks the numeric keywords of a schema.
+func validateNumber(s map[string]interface{}, v float64, path string, errs *[]a2a.FieldError) {
+	if n, ok := number(s["minimum"]); ok && v < n {
+		addError(errs, path, fmt.Sprintf("must be at least %v", n))
+	}
+	if n, ok := number(s["maximum"]); ok && v > n {
+		addError(errs, path, fmt.Sprintf("must be at most %v", n))
+	}
+	if n, ok := number(s["exclusiveMinimum"]); ok && v <= n {
+		addError(errs, path, fmt.Sprintf("must be greater than %v", n))
+	}
+	if n, ok := number(s["exclusiveMaximum"]); ok && v >= n {
+		addError(errs, path, fmt.Sprintf("must be less than %v", n))
+	}
+}
+
+// validateCombinators checks the allOf, anyOf and oneOf keywords of a schema.
+func validateCombinators(s map[string]interface{}, value interface{}, path string, errs *[]a2a.FieldError) {
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			validate(sub, value, path, errs)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && countMatches(anyOf, value) == 0 {
+		addError(errs, path, "does not match any of the allowed schemas")
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok && countMatches(oneOf, value) != 1 {
+		addError(errs, path, "must match exactly one of the allowed schemas")
+	}
+}
+
+// countMatches returns the number of schemas that value is valid against.
+func countMatches(schemas []interface{}, value interface{}) int {
+	matches := 0
+	for _, sub := range schemas {
+		var subErrs []a2a.FieldError
+		validate(sub, value, "", &subErrs)
+		if len(subErrs) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// matchesType reports whether value has the type, or one of the types, t names.
+func matchesType(t, value interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && isType(s, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// isType reports whether value is of the named JSON Schema type.
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return typeOf(value) == name
+	}
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// typeNames formats the type keyword of a schema for an error message.
+func typeNames(t interface{}) string {
+	if names, ok := t.([]interface{}); ok {
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprint(name)
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// number returns a numeric keyword's value, if it is set.
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+// containsValue reports whether values contains value.
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValues formats the allowed values of an enum for an error message.
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatValue formats a value as JSON for an error message.
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// joinPath appends an object key to a field path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// addError appends a violation to errs.
+func addError(errs *[]a2a.FieldError, path, reason string) {
+	*errs = append(*errs, a2a.FieldError{Field: path, Reason: reason})
+}
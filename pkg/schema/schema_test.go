@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+func TestValidate(t *testing.T) {
+	querySchema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"query"},
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "minLength": 1},
+			"limit": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 50},
+			"sort":  map[string]interface{}{"enum": []interface{}{"relevance", "date"}},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "pattern": "^[a-z]+$"},
+			},
+		},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name   string
+		schema interface{}
+		value  interface{}
+		want   []a2a.FieldError
+	}{
+		{name: "nil schema", schema: nil, value: 42},
+		{name: "valid object", schema: querySchema, value: map[string]interface{}{"query": "go", "limit": 10, "tags": []string{"lang"}}},
+		{
+			name:   "missing required property",
+			schema: querySchema,
+			value:  map[string]interface{}{"limit": 10},
+			want:   []a2a.FieldError{{Field: "query", Reason: "is required"}},
+		},
+		{
+			name:   "nested violations",
+			schema: querySchema,
+			value:  map[string]interface{}{"query": "", "limit": 2.5, "sort": "name", "tags": []interface{}{"ok", "NOT"}, "extra": true},
+			want: []a2a.FieldError{
+				{Field: "extra", Reason: "is not an allowed property"},
+				{Field: "limit", Reason: "expected integer, got number"},
+				{Field: "query", Reason: "must have a length of at least 1"},
+				{Field: "sort", Reason: `must be one of "relevance", "date"`},
+				{Field: "tags[1]", Reason: `must match pattern "^[a-z]+$"`},
+			},
+		},
+		{
+			name:   "wrong root type",
+			schema: querySchema,
+			value:  "go",
+			want:   []a2a.FieldError{{Field: "", Reason: "expected object, got string"}},
+		},
+		{
+			name:   "struct value",
+			schema: querySchema,
+			value: struct {
+				Query string `json:"query"`
+				Limit int    `json:"limit"`
+			}{Query: "go", Limit: 100},
+			want: []a2a.FieldError{{Field: "limit", Reason: "must be at most 50"}},
+		},
+		{
+			name:   "multiple types",
+			schema: map[string]interface{}{"type": []interface{}{"string", "null"}},
+			value:  nil,
+		},
+		{
+			name:   "anyOf",
+			schema: map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}, map[string]interface{}{"type": "number"}}},
+			value:  true,
+			want:   []a2a.FieldError{{Field: "", Reason: "does not match any of the allowed schemas"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.schema, tt.value)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidValue(t *testing.T) {
+	if _, err := Validate(map[string]interface{}{"type": "object"}, make(chan int)); err == nil {
+		t.Error("Expected an error for a value that cannot be encoded as JSON")
+	}
+}
+
+func TestValidateSkillInput(t *testing.T) {
+	card := &a2a.AgentCard{
+		Skills: []a2a.AgentSkill{
+			{ID: "echo", Name: "Echo"},
+			{ID: "search", Name: "Search", InputSchema: map[string]interface{}{
+				"type":       "object",
+				"required":   []interface{}{"query"},
+				"properties": map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		skillID  string
+		input    interface{}
+		wantCode int // 0 = valid
+	}{
+		{"valid input", "search", map[string]interface{}{"query": "go"}, 0},
+		{"skill without a schema", "echo", "anything", 0},
+		{"invalid input", "search", map[string]interface{}{"limit": 10}, a2a.CodeInvalidParams},
+		{"unknown skill", "missing", "go", a2a.CodeSkillNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSkillInput(card, tt.skillID, tt.input)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("ValidateSkillInput() error = %v", err)
+				}
+				return
+			}
+			a2aErr, ok := err.(*a2a.Error)
+			if !ok || a2aErr.Code != tt.wantCode {
+				t.Errorf("Expected an error with code %d, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// EchoHandler is a Handler that replies with the text of the user's message, prefixed
+// with "Echo: ". Like the other ready-made handlers, it reports the task as working and
+// then completes it with the reply.
+func EchoHandler(ctx context.Context, taskCtx Context) (<-chan YieldUpdate, error) {
+	return respond(ctx, []a2a.Part{a2a.TextPart{Type: "text", Text: "Echo: " + taskCtx.AllText()}}, nil), nil
+}
+
+// StaticHandler returns a Handler that replies to every task with the given parts.
+func StaticHandler(parts ...a2a.Part) Handler {
+	return func(ctx context.Context, taskCtx Context) (<-chan YieldUpdate, error) {
+		return respond(ctx, parts, nil), nil
+	}
+}
+
+// FuncHandler returns a Handler that replies with the result of calling fn with the text
+// of the user's message. If fn returns an error, the task fails with the error as its message.
+func FuncHandler(fn func(text string) (string, error)) Handler {
+	return func(ctx context.Context, taskCtx Context) (<-chan YieldUpdate, error) {
+		reply, err := fn(taskCtx.AllText())
+		return respond(ctx, []a2a.Part{a2a.TextPart{Type: "text", Text: reply}}, err), nil
+	}
+}
+
+// respond emits the standard status sequence for a task with a single reply: working,
+// then completed with an agent message carrying parts, or failed if err is set.
+func respond(ctx context.Context, parts []a2a.Part, err error) <-chan YieldUpdate {
+	updates := make(chan YieldUpdate)
+
+	go func() {
+		defer close(updates)
+
+		final := StatusUpdate{
+			State:   a2a.TaskStateCompleted,
+			Message: &a2a.Message{Role: a2a.RoleAgent, Timestamp: time.Now(), Parts: parts},
+		}
+		if err != nil {
+			final = StatusUpdate{
+				State: a2a.TaskStateFailed,
+				Message: &a2a.Message{
+					Role:      a2a.RoleAgent,
+					Timestamp: time.Now(),
+					Parts:     []a2a.Part{a2a.TextPart{Type: "text", Text: err.Error()}},
+				},
+			}
+		}
+
+		for _, update := range []YieldUpdate{StatusUpdate{State: a2a.TaskStateWorking}, final} {
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// collect runs a handler for a text message and returns every update it yields.
+func collect(t *testing.T, handler Handler, text string) []YieldUpdate {
+	t.Helper()
+
+	updates, err := handler(context.Background(), Context{
+		TaskID: "task-1",
+		UserMessage: a2a.Message{
+			Role:  a2a.RoleUser,
+			Parts: []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	var yielded []YieldUpdate
+	for update := range updates {
+		yielded = append(yielded, update)
+	}
+	return yielded
+}
+
+// assertSequence checks that updates are a working status followed by a final status
+// with the given state and message text.
+func assertSequence(t *testing.T, updates []YieldUpdate, state a2a.TaskState, text string) {
+	t.Helper()
+
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %d", len(updates))
+	}
+	if first, ok := updates[0].(StatusUpdate); !ok || first.State != a2a.TaskStateWorking {
+		t.Errorf("Expected working status first, got %+v", updates[0])
+	}
+	last, ok := updates[1].(StatusUpdate)
+	if !ok || last.State != state {
+		t.Fatalf("Expected %s status last, got %+v", state, updates[1])
+	}
+	if last.Message == nil || last.Message.Role != a2a.RoleAgent {
+		t.Fatalf("Expected an agent message, got %+v", last.Message)
+	}
+	if got := last.Message.Parts[0].(a2a.TextPart).Text; got != text {
+		t.Errorf("Expected message %q, got %q", text, got)
+	}
+}
+
+func TestEchoHandler(t *testing.T) {
+	assertSequence(t, collect(t, EchoHandler, "hello"), a2a.TaskStateCompleted, "Echo: hello")
+}
+
+func TestStaticHandler(t *testing.T) {
+	handler := StaticHandler(a2a.TextPart{Type: "text", Text: "fixed reply"})
+	assertSequence(t, collect(t, handler, "anything"), a2a.TaskStateCompleted, "fixed reply")
+}
+
+func TestFuncHandler(t *testing.T) {
+	handler := FuncHandler(func(text string) (string, error) {
+		if text == "" {
+			return "", errors.New("empty input")
+		}
+		return strings.ToUpper(text), nil
+	})
+
+	assertSequence(t, collect(t, handler, "hello"), a2a.TaskStateCompleted, "HELLO")
+	assertSequence(t, collect(t, handler, ""), a2a.TaskStateFailed, "empty input")
+}
@@ -0,0 +1,27 @@
+package task
+
+import "context"
+
+// Identity is the authenticated caller of a request, as established by the server's
+// authentication validator.
+type Identity struct {
+	Scheme  string                 // Authentication type the caller used (e.g., "bearer", "header")
+	Subject string                 // Authenticated user or client (empty if the validator cannot tell)
+	Tenant  string                 // Tenant the caller belongs to (empty if none)
+	Claims  map[string]interface{} // Claims of the caller's token, for token-based schemes
+}
+
+// identityContextKey is the context key for the authenticated identity.
+type identityContextKey struct{}
+
+// WithIdentity returns a context carrying the authenticated identity of a request.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the authenticated identity carried by ctx, or nil if the
+// request was not authenticated.
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityContextKey{}).(*Identity)
+	return identity
+}
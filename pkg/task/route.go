@@ -0,0 +1,97 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sammcj/go-a2a/pkg/schema"
+)
+
+// RouteDecision is a model's choice of where a task should be handled, such as by another
+// agent or directly.
+type RouteDecision struct {
+	Route  string `json:"route"`            // One of the offered routes, in lower case
+	Reason string `json:"reason,omitempty"` // Why the model chose the route, if it said
+}
+
+// RouteSchema returns the JSON Schema of a RouteDecision choosing one of routes, for
+// describing the expected reply in a routing prompt. Routes are matched in lower case.
+func RouteSchema(routes ...string) map[string]interface{} {
+	enum := make([]interface{}, len(routes))
+	for i, route := range routes {
+		enum[i] = strings.ToLower(route)
+	}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"route"},
+		"properties": map[string]interface{}{
+			"route":  map[string]interface{}{"type": "string", "enum": enum},
+			"reason": map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// ParseRouteDecision extracts a RouteDecision from a model's reply to a routing prompt. The
+// reply should contain a JSON object such as {"route": "web"}, which may be surrounded by
+// prose or a code fence; the first object with a route is used, and its keys and route are
+// matched regardless of case and surrounding whitespace. A reply that is just one of the
+// routes is also accepted. It returns an error if the reply holds no decision or the route
+// is not one of routes.
+func ParseRouteDecision(reply string, routes ...string) (RouteDecision, error) {
+	decision, ok := findRouteObject(reply)
+	if !ok {
+		// Models sometimes answer with the bare route
+		word := strings.ToLower(strings.Trim(reply, " \t\r\n\"'`.!"))
+		for _, route := range routes {
+			if word == strings.ToLower(route) {
+				return RouteDecision{Route: word}, nil
+			}
+		}
+		return RouteDecision{}, errors.New("reply does not contain a route decision")
+	}
+
+	if route, ok := decision["route"].(string); ok {
+		decision["route"] = strings.ToLower(strings.TrimSpace(route))
+	}
+	fields, err := schema.Validate(RouteSchema(routes...), decision)
+	if err != nil {
+		return RouteDecision{}, fmt.Errorf("failed to validate route decision: %w", err)
+	}
+	if len(fields) > 0 {
+		reasons := make([]string, len(fields))
+		for i := range fields {
+			reasons[i] = fields[i].Error()
+		}
+		return RouteDecision{}, fmt.Errorf("invalid route decision: %s", strings.Join(reasons, "; "))
+	}
+
+	route, _ := decision["route"].(string)
+	reason, _ := decision["reason"].(string)
+	return RouteDecision{Route: route, Reason: strings.TrimSpace(reason)}, nil
+}
+
+// findRouteObject returns the first JSON object in text with a "route" key, with its keys
+// in lower case.
+func findRouteObject(text string) (map[string]interface{}, bool) {
+	for i := strings.IndexByte(text, '{'); i >= 0; {
+		var object map[string]interface{}
+		if err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&object); err == nil {
+			decision := make(map[string]interface{}, len(object))
+			for key, value := range object {
+				decision[strings.ToLower(strings.TrimSpace(key))] = value
+			}
+			if _, ok := decision["route"]; ok {
+				return decision, true
+			}
+		}
+
+		next := strings.IndexByte(text[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil, false
+}
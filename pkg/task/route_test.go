@@ -0,0 +1,66 @@
+package task
+
+import "testing"
+
+func TestParseRouteDecision(t *testing.T) {
+	routes := []string{"web", "reasoner", "direct"}
+	tests := []struct {
+		name  string
+		reply string
+		want  RouteDecision
+	}{
+		{
+			name:  "bare object",
+			reply: `{"route":"web"}`,
+			want:  RouteDecision{Route: "web"},
+		},
+		{
+			name:  "surrounded by prose",
+			reply: "Sure! This needs current information, so {\"route\":\"web\"} is best.\nLet me know if you need anything else.",
+			want:  RouteDecision{Route: "web"},
+		},
+		{
+			name:  "code fence with reason",
+			reply: "```json\n{\n  \"route\": \"reasoner\",\n  \"reason\": \" Multi-step maths \"\n}\n```",
+			want:  RouteDecision{Route: "reasoner", Reason: "Multi-step maths"},
+		},
+		{
+			name:  "casing and whitespace",
+			reply: `{"Route": "  WEB "}`,
+			want:  RouteDecision{Route: "web"},
+		},
+		{
+			name:  "braces before the decision",
+			reply: `Considering {the options} and {"other": 1}, I pick {"route": "direct"}`,
+			want:  RouteDecision{Route: "direct"},
+		},
+		{
+			name:  "bare route",
+			reply: " \"Reasoner\".\n",
+			want:  RouteDecision{Route: "reasoner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRouteDecision(tt.reply, routes...)
+			if err != nil {
+				t.Fatalf("ParseRouteDecision failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRouteDecision() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, reply := range []string{
+		`{"route": "email"}`,
+		`{"route": 3}`,
+		`I would send this to the web agent.`,
+		``,
+	} {
+		if got, err := ParseRouteDecision(reply, routes...); err == nil {
+			t.Errorf("Expected an error for %q, got %+v", reply, got)
+		}
+	}
+}
@@ -2,14 +2,65 @@ package task
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
 )
 
 // Context represents the context for a task execution.
 type Context struct {
-	TaskID      string
-	UserMessage a2a.Message
+	TaskID       string
+	SessionID    string // Session the task belongs to (empty if none)
+	SkillID      string // Skill requested by the client (empty if none)
+	UserMessage  a2a.Message
+	Metadata     interface{}       // Metadata sent with the task request
+	TraceHeaders map[string]string // Trace/correlation headers from the originating request (e.g., X-Request-ID)
+	// OutputModes are the output MIME types the client accepts that the agent supports, most
+	// preferred first (empty if the client accepts anything)
+	OutputModes []string
+	// Deadline is when the originating request times out (zero if it has no deadline).
+	// Long-running handlers should wrap up or report progress before it passes.
+	Deadline time.Time
+	// Timeout is the maximum time the task may run, as requested by the client (0 = no limit).
+	// The task is failed and its context cancelled when it is exceeded.
+	Timeout time.Duration
+	// Identity is the authenticated caller that sent the task (nil if the request was not
+	// authenticated).
+	Identity *Identity
+}
+
+// HasDeadline reports whether the task has a deadline.
+func (c Context) HasDeadline() bool {
+	return !c.Deadline.IsZero()
+}
+
+// RequestedSkillID returns the skill requested for the task: SkillID if set, otherwise the
+// "skillId" metadata of the user message (see a2a.RequestedSkillID).
+func (c Context) RequestedSkillID() string {
+	return a2a.RequestedSkillID(c.SkillID, c.UserMessage)
+}
+
+// AllText returns the text of every text part of the user message, in order, joined by newlines.
+func (c Context) AllText() string {
+	var texts []string
+	for _, part := range c.UserMessage.Parts {
+		if textPart, ok := part.(a2a.TextPart); ok {
+			texts = append(texts, textPart.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Files returns the file parts of the user message, in order.
+func (c Context) Files() []a2a.FilePart {
+	var files []a2a.FilePart
+	for _, part := range c.UserMessage.Parts {
+		if filePart, ok := part.(a2a.FilePart); ok {
+			files = append(files, filePart)
+		}
+	}
+	return files
 }
 
 // YieldUpdate represents an update from a task execution.
@@ -18,17 +69,57 @@ type YieldUpdate interface {
 }
 
 // StatusUpdate represents a status update from a task.
+//
+// A final state may carry the task's final message, e.g.,
+// StatusUpdate{State: a2a.TaskStateCompleted, Message: &response}. The message is added to
+// the task's history and the state set in one step, so clients that only read the final
+// status still see the response.
+//
+// A failed or cancelled state may say why with Reason, and whether the task is worth
+// retrying with Retryable. Failures without a reason are reported as errors.
 type StatusUpdate struct {
-	State   a2a.TaskState
-	Message *a2a.Message
+	State     a2a.TaskState
+	Message   *a2a.Message
+	Reason    a2a.TaskStatusReason
+	Retryable bool
 }
 
 func (StatusUpdate) isYieldUpdate() {}
 
+// WithMetadata returns a copy of the status update whose message carries the given
+// metadata entry. The original message is not modified. If the update has no message,
+// an empty agent message is created to carry the metadata.
+func (u StatusUpdate) WithMetadata(key string, value interface{}) StatusUpdate {
+	msg := a2a.Message{
+		Role:      a2a.RoleAgent,
+		Timestamp: time.Now(),
+	}
+	if u.Message != nil {
+		msg = *u.Message
+	}
+
+	metadata := make(map[string]interface{})
+	if existing, ok := msg.Metadata.(map[string]interface{}); ok {
+		for k, v := range existing {
+			metadata[k] = v
+		}
+	}
+	metadata[key] = value
+
+	msg.Metadata = metadata
+	u.Message = &msg
+	return u
+}
+
 // ArtifactUpdate represents an artifact update from a task.
 type ArtifactUpdate struct {
 	Part     a2a.Part
 	Metadata interface{}
+
+	// ID and Timestamp identify the artifact stored for the update. Task managers set them
+	// on the updates they stream to subscribers; handlers leave them empty.
+	ID        string
+	Timestamp time.Time
 }
 
 func (ArtifactUpdate) isYieldUpdate() {}
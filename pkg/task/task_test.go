@@ -0,0 +1,38 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+func TestContext_AllTextAndFiles(t *testing.T) {
+	taskCtx := Context{
+		UserMessage: a2a.Message{
+			Role: a2a.RoleUser,
+			Parts: []a2a.Part{
+				a2a.TextPart{Type: "text", Text: "first"},
+				a2a.FilePart{Type: "file", Filename: "a.png", MimeType: "image/png"},
+				a2a.TextPart{Type: "text", Text: "second"},
+				a2a.DataPart{Type: "data", MimeType: "application/json", Data: map[string]interface{}{"k": "v"}},
+				a2a.FilePart{Type: "file", Filename: "b.pdf", MimeType: "application/pdf"},
+			},
+		},
+	}
+
+	if got, want := taskCtx.AllText(), "first\nsecond"; got != want {
+		t.Errorf("AllText() = %q, want %q", got, want)
+	}
+
+	files := taskCtx.Files()
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Filename != "a.png" || files[1].Filename != "b.pdf" {
+		t.Errorf("Expected files in message order, got %s and %s", files[0].Filename, files[1].Filename)
+	}
+
+	if got := (Context{}).AllText(); got != "" {
+		t.Errorf("Expected empty text for an empty message, got %q", got)
+	}
+}
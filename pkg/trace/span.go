@@ -0,0 +1,59 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the instrumentation scope used for spans when no tracer is configured.
+const InstrumentationName = "github.com/sammcj/go-a2a"
+
+// Tracer is an OpenTelemetry tracer.
+type Tracer = oteltrace.Tracer
+
+// Span is an OpenTelemetry span.
+type Span = oteltrace.Span
+
+// TracerProvider is an OpenTelemetry tracer provider.
+type TracerProvider = oteltrace.TracerProvider
+
+type tracerContextKey struct{}
+
+// WithTracer returns a copy of ctx carrying the given tracer.
+// Spans started with StartSpan on the returned context, or any derived from it, use this tracer.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerContextKey{}, tracer)
+}
+
+// WithTracerProvider returns a copy of ctx carrying the tracer for this module from the
+// given provider. A nil provider leaves ctx unchanged.
+func WithTracerProvider(ctx context.Context, provider TracerProvider) context.Context {
+	if provider == nil {
+		return ctx
+	}
+	return WithTracer(ctx, provider.Tracer(InstrumentationName))
+}
+
+// TracerFromContext returns the tracer carried by ctx, or a tracer from the global
+// OpenTelemetry tracer provider if there is none.
+func TracerFromContext(ctx context.Context) Tracer {
+	if tracer, ok := ctx.Value(tracerContextKey{}).(Tracer); ok && tracer != nil {
+		return tracer
+	}
+	return otel.GetTracerProvider().Tracer(InstrumentationName)
+}
+
+// StartSpan starts a span using the tracer carried by ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, Span) {
+	return TracerFromContext(ctx).Start(ctx, name, oteltrace.WithAttributes(attrs...))
+}
+
+// RecordError records err on span and marks the span as failed.
+func RecordError(span Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
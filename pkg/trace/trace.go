@@ -0,0 +1,86 @@
+// Package trace propagates request and trace correlation headers between agents.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RequestIDHeader is the header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// DefaultHeaders are the headers propagated by default.
+var DefaultHeaders = []string{RequestIDHeader, "traceparent"}
+
+type contextKey struct{}
+
+// WithHeaders returns a copy of ctx carrying the given trace headers.
+// Header names must be in canonical form (see http.CanonicalHeaderKey).
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, contextKey{}, headers)
+}
+
+// HeadersFromContext returns the trace headers carried by ctx, or nil if there are none.
+func HeadersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(contextKey{}).(map[string]string)
+	return headers
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	return HeadersFromContext(ctx)[http.CanonicalHeaderKey(RequestIDHeader)]
+}
+
+// FromRequest extracts the named headers from an incoming request.
+// A request ID is generated if the request does not carry one.
+func FromRequest(r *http.Request, names []string) map[string]string {
+	headers := make(map[string]string, len(names)+1)
+	for _, name := range names {
+		if value := r.Header.Get(name); value != "" {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+
+	if key := http.CanonicalHeaderKey(RequestIDHeader); headers[key] == "" {
+		headers[key] = NewRequestID()
+	}
+
+	return headers
+}
+
+// Extract returns a copy of ctx carrying the remote span context of an incoming request, read
+// with the global OpenTelemetry propagator, so spans started from it continue the caller's trace.
+func Extract(ctx context.Context, r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// Inject sets the named headers carried by ctx on an outgoing request, followed by the span
+// context of the span in ctx, written with the global OpenTelemetry propagator, so the
+// receiving agent's spans are children of it. The span context replaces a propagated
+// traceparent header.
+func Inject(ctx context.Context, req *http.Request, names []string) {
+	headers := HeadersFromContext(ctx)
+	for _, name := range names {
+		if value := headers[http.CanonicalHeaderKey(name)]; value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// NewRequestID generates a new random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to a time-based ID if the random source fails
+		return fmt.Sprintf("req_%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
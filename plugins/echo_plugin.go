@@ -1,10 +1,6 @@
 package main
 
 import (
-	"context"
-	"fmt"
-	"time"
-
 	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/cmd/common"
 	"github.com/sammcj/go-a2a/pkg/task"
@@ -15,45 +11,7 @@ type EchoPlugin struct{}
 
 // GetTaskHandler returns the task handler function for the echo plugin.
 func (p *EchoPlugin) GetTaskHandler() task.Handler {
-	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
-		// Create a channel for updates
-		updateChan := make(chan task.YieldUpdate)
-
-		// Start a goroutine to handle the task
-		go func() {
-			defer close(updateChan)
-
-			// Extract the user's message
-			userMessage := taskCtx.UserMessage
-			var userText string
-			for _, part := range userMessage.Parts {
-				if textPart, ok := part.(a2a.TextPart); ok {
-					userText = textPart.Text
-					break
-				}
-			}
-
-			// Create an agent message
-			agentMessage := &a2a.Message{
-				Role:      a2a.RoleAgent,
-				Timestamp: time.Now(),
-				Parts: []a2a.Part{
-					a2a.TextPart{
-						Type: "text",
-						Text: fmt.Sprintf("Echo: %s", userText),
-					},
-				},
-			}
-
-			// Send a status update with the agent message
-			updateChan <- task.StatusUpdate{
-				State:   a2a.TaskStateCompleted,
-				Message: agentMessage,
-			}
-		}()
-
-		return updateChan, nil
-	}
+	return task.EchoHandler
 }
 
 // GetSkills returns the skills provided by the echo plugin.
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// adminStatsPath is the path of the admin stats endpoint, relative to the A2A path prefix.
+const adminStatsPath = "admin/stats"
+
+// errAdminAPIUnauthenticated is returned by NewServer when the admin API is enabled without
+// an auth validator, or with an agent card that declares no authentication scheme.
+var errAdminAPIUnauthenticated = errors.New("the admin API requires an auth validator and an agent card that declares an authentication scheme (use NoAuthValidator to serve it without authentication)")
+
+// AdminStats is the runtime state of a server, returned by the admin stats endpoint. The
+// counts include the server's virtual agents.
+type AdminStats struct {
+	StartedAt time.Time `json:"startedAt"` // When the server was created
+	// UptimeSeconds is how long the server has been running
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+	// Tasks counts the stored tasks by state (omitted if the task manager cannot count them)
+	Tasks map[a2a.TaskState]int `json:"tasks,omitempty"`
+	// PushConfigs is the number of tasks with a push notification config
+	PushConfigs    int `json:"pushConfigs"`
+	SSEConnections int `json:"sseConnections"` // Open SSE connections for task updates
+}
+
+// TaskStats are the counts a task manager reports for the admin stats endpoint.
+type TaskStats struct {
+	Tasks       map[a2a.TaskState]int // Stored tasks by state
+	PushConfigs int                   // Tasks with a push notification config
+}
+
+// WithAdminAPI serves runtime stats as JSON at GET {prefix}/admin/stats (see AdminStats), for
+// operators who need visibility without scraping metrics. The endpoint is authenticated by
+// the AuthValidator like the A2A endpoints, against the schemes the agent card declares.
+// NewServer fails if no validator is set or the card declares no scheme, as the endpoint
+// would then be open; pass NoAuthValidator to serve it without authentication.
+func WithAdminAPI() Option {
+	return func(c *Config) {
+		c.AdminAPI = true
+	}
+}
+
+// handleAdminStats serves the server's AdminStats.
+func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
+	stats := AdminStats{
+		StartedAt:     s.started,
+		UptimeSeconds: time.Since(s.started).Seconds(),
+	}
+	agents := []*Server{s}
+	for _, agent := range s.virtual {
+		agents = append(agents, agent)
+	}
+	for _, agent := range agents {
+		if tm, ok := agent.taskManager.(interface{ Stats() TaskStats }); ok {
+			taskStats := tm.Stats()
+			if stats.Tasks == nil {
+				stats.Tasks = make(map[a2a.TaskState]int)
+			}
+			for state, n := range taskStats.Tasks {
+				stats.Tasks[state] += n
+			}
+			stats.PushConfigs += taskStats.PushConfigs
+		}
+		stats.SSEConnections += agent.sseManager.ConnectionCount()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
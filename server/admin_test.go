@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// getAdminStats fetches the admin stats from a test server.
+func getAdminStats(t *testing.T, baseURL string) AdminStats {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "admin/stats")
+	if err != nil {
+		t.Fatalf("Failed to get admin stats: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var stats AdminStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode admin stats: %v", err)
+	}
+	return stats
+}
+
+func TestServer_AdminStats(t *testing.T) {
+	// Tasks keep working until they are cancelled
+	hang := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-ctx.Done()
+		}()
+		return updates, nil
+	}
+	s, baseURL := newTestServer(t, hang, WithAdminAPI(), WithAuthValidator(NoAuthValidator()))
+
+	var taskIDs []string
+	for i := 0; i < 2; i++ {
+		taskObj, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		waitForState(t, s.taskManager.(*InMemoryTaskManager), taskObj.ID, a2a.TaskStateWorking)
+		taskIDs = append(taskIDs, taskObj.ID)
+	}
+	if _, err := s.taskManager.OnCancelTask(context.Background(), &a2a.TaskIdParams{TaskID: taskIDs[0]}); err != nil {
+		t.Fatalf("OnCancelTask failed: %v", err)
+	}
+	if _, err := s.taskManager.OnSetTaskPushNotification(context.Background(), &a2a.TaskPushNotificationConfigParams{
+		TaskID: taskIDs[1],
+		URL:    "https://example.com/webhook",
+	}); err != nil {
+		t.Fatalf("OnSetTaskPushNotification failed: %v", err)
+	}
+
+	stats := getAdminStats(t, baseURL)
+	if stats.Tasks[a2a.TaskStateWorking] != 1 || stats.Tasks[a2a.TaskStateCancelled] != 1 {
+		t.Errorf("Expected one working and one cancelled task, got %v", stats.Tasks)
+	}
+	if stats.PushConfigs != 1 {
+		t.Errorf("Expected 1 push config, got %d", stats.PushConfigs)
+	}
+	if stats.SSEConnections != 0 {
+		t.Errorf("Expected no SSE connections, got %d", stats.SSEConnections)
+	}
+	if stats.UptimeSeconds <= 0 || stats.StartedAt.IsZero() {
+		t.Errorf("Expected the uptime to be reported, got %+v", stats)
+	}
+
+	// Open a stream of the working task's updates
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	body := `{"jsonrpc":"2.0","method":"tasks/resubscribe","id":"1","params":{"taskId":"` + taskIDs[1] + `"}}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"sse", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	waitForConnections(t, s.sseManager, taskIDs[1], 1)
+
+	if stats := getAdminStats(t, baseURL); stats.SSEConnections != 1 {
+		t.Errorf("Expected 1 SSE connection, got %d", stats.SSEConnections)
+	}
+}
+
+func TestServer_AdminAPIAuthentication(t *testing.T) {
+	card := &a2a.AgentCard{
+		A2AVersion:     "1.0",
+		ID:             "test-agent",
+		Name:           "Test Agent",
+		Authentication: []a2a.AgentAuthentication{{Type: "bearer"}},
+	}
+
+	_, err := NewServer(WithAgentCard(card), WithAgentEngine(stubAgentEngine{}), WithAdminAPI())
+	if !errors.Is(err, errAdminAPIUnauthenticated) {
+		t.Errorf("Expected the admin API to require an auth validator, got %v", err)
+	}
+
+	// A validator has nothing to check if the card declares no authentication scheme
+	openCard := *card
+	openCard.Authentication = nil
+	_, err = NewServer(WithAgentCard(&openCard), WithAgentEngine(stubAgentEngine{}), WithAdminAPI(), WithAuthValidator(SimpleTokenValidator("secret")))
+	if !errors.Is(err, errAdminAPIUnauthenticated) {
+		t.Errorf("Expected the admin API to require a declared authentication scheme, got %v", err)
+	}
+	if _, err := NewServer(WithAgentCard(&openCard), WithAgentEngine(stubAgentEngine{}), WithAdminAPI(), WithAuthValidator(NoAuthValidator())); err != nil {
+		t.Errorf("Expected NoAuthValidator to serve the admin API without authentication, got %v", err)
+	}
+
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(card), WithAdminAPI(), WithAuthValidator(SimpleTokenValidator("secret")))
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"secret", http.StatusOK},
+	} {
+		req, err := http.NewRequest(http.MethodGet, baseURL+"admin/stats", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Token %q: expected status %d, got %d", tt.token, tt.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_AdminAPIDisabledByDefault(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	resp, err := http.Get(baseURL + "admin/stats")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		t.Error("Expected no admin stats without WithAdminAPI")
+	}
+}
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
 )
@@ -10,9 +13,20 @@ import (
 // DefaultAgentCardPath is the default path for serving the agent card.
 const DefaultAgentCardPath = "/.well-known/agent.json"
 
+// agentCardFetchTimeout bounds how long AgentCardFromURL waits for the card.
+const agentCardFetchTimeout = 30 * time.Second
+
 // AgentCardHandler returns an HTTP handler that serves the agent card.
 func AgentCardHandler(card *a2a.AgentCard) http.HandlerFunc {
+	return agentCardHandlerFunc(func() *a2a.AgentCard { return card })
+}
+
+// agentCardHandlerFunc returns an HTTP handler that serves the agent card returned by getCard,
+// which is called for every request.
+func agentCardHandlerFunc(getCard func() *a2a.AgentCard) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		card := getCard()
+
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 			return
@@ -34,6 +48,11 @@ func AgentCardHandler(card *a2a.AgentCard) http.HandlerFunc {
 
 // RegisterAgentCardHandler registers the agent card handler with the provided ServeMux.
 func RegisterAgentCardHandler(mux *http.ServeMux, card *a2a.AgentCard, cardPath string) {
+	registerAgentCardHandlerFunc(mux, func() *a2a.AgentCard { return card }, cardPath)
+}
+
+// registerAgentCardHandlerFunc registers a handler serving the agent card returned by getCard.
+func registerAgentCardHandlerFunc(mux *http.ServeMux, getCard func() *a2a.AgentCard, cardPath string) {
 	if cardPath == "" {
 		cardPath = DefaultAgentCardPath
 	}
@@ -43,7 +62,7 @@ func RegisterAgentCardHandler(mux *http.ServeMux, card *a2a.AgentCard, cardPath
 		cardPath = "/" + cardPath
 	}
 
-	mux.HandleFunc(cardPath, AgentCardHandler(card))
+	mux.HandleFunc(cardPath, agentCardHandlerFunc(getCard))
 }
 
 // WithAgentCardPath returns an Option that sets the path for serving the agent card.
@@ -52,3 +71,34 @@ func WithAgentCardPath(cardPath string) Option {
 		c.AgentCardPath = cardPath
 	}
 }
+
+// AgentCardFromURL returns an AgentCardLoader that fetches the agent card as JSON from url,
+// for use with WithAgentCardLoader.
+func AgentCardFromURL(url string) AgentCardLoader {
+	return func(ctx context.Context) (*a2a.AgentCard, error) {
+		ctx, cancel := context.WithTimeout(ctx, agentCardFetchTimeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch agent card: unexpected status code %d", resp.StatusCode)
+		}
+
+		var card a2a.AgentCard
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			return nil, fmt.Errorf("failed to decode agent card: %w", err)
+		}
+		return &card, nil
+	}
+}
@@ -3,6 +3,10 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/llm"
@@ -36,10 +40,11 @@ type AgentCapabilities struct {
 
 // BasicLLMAgent implements AgentEngine using an LLM.
 type BasicLLMAgent struct {
-	llm          llm.LLMInterface
-	systemPrompt string
-	skills       []a2a.AgentSkill
-	capabilities AgentCapabilities
+	llm            llm.LLMInterface
+	systemPrompt   string
+	promptTemplate *PromptTemplate // Rendered for each task instead of systemPrompt if set
+	skills         []a2a.AgentSkill
+	capabilities   AgentCapabilities
 }
 
 // NewBasicLLMAgent creates a new BasicLLMAgent.
@@ -58,6 +63,28 @@ func NewBasicLLMAgent(llmInterface llm.LLMInterface, systemPrompt string) *Basic
 	}
 }
 
+// NewBasicLLMAgentWithPromptTemplate creates a BasicLLMAgent whose system prompt is a
+// text/template rendered for each task with PromptVars, e.g., "You help with {{.SkillID}}.".
+// It returns an error if the template is invalid.
+func NewBasicLLMAgentWithPromptTemplate(llmInterface llm.LLMInterface, promptTemplate string) (*BasicLLMAgent, error) {
+	tmpl, err := ParsePromptTemplate(promptTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := NewBasicLLMAgent(llmInterface, promptTemplate)
+	agent.promptTemplate = tmpl
+	return agent, nil
+}
+
+// taskSystemPrompt returns the system prompt for a task.
+func (a *BasicLLMAgent) taskSystemPrompt(taskCtx task.Context) (string, error) {
+	if a.promptTemplate == nil {
+		return a.systemPrompt, nil
+	}
+	return a.promptTemplate.Render(taskCtx)
+}
+
 // ProcessTask implements AgentEngine.ProcessTask.
 func (a *BasicLLMAgent) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
 	updateChan := make(chan task.YieldUpdate)
@@ -65,23 +92,20 @@ func (a *BasicLLMAgent) ProcessTask(ctx context.Context, taskCtx task.Context) (
 	go func() {
 		defer close(updateChan)
 
-		// Extract the user's message
-		userMessage := taskCtx.UserMessage
-		var userText string
-		for _, part := range userMessage.Parts {
-			if textPart, ok := part.(a2a.TextPart); ok {
-				userText = textPart.Text
-				break
-			}
-		}
+		// Build the prompt from all parts of the user's message
+		userText := taskPrompt(taskCtx)
 
 		// Send a working status update
 		updateChan <- task.StatusUpdate{
 			State: a2a.TaskStateWorking,
 		}
 
-		// Process the message with the LLM
-		response, err := a.llm.Generate(ctx, userText, llm.WithSystemPrompt(a.systemPrompt))
+		// Process the message with the LLM, using the system prompt for this task
+		systemPrompt, err := a.taskSystemPrompt(taskCtx)
+		var response string
+		if err == nil {
+			response, err = a.llm.Generate(ctx, userText, llm.WithSystemPrompt(systemPrompt))
+		}
 		if err != nil {
 			// Send a failed status update
 			updateChan <- task.StatusUpdate{
@@ -110,16 +134,11 @@ func (a *BasicLLMAgent) ProcessTask(ctx context.Context, taskCtx task.Context) (
 			},
 		}
 
-		// Send a working status update with the response
+		// Complete the task with the response as its final message
 		updateChan <- task.StatusUpdate{
-			State:   a2a.TaskStateWorking,
+			State:   a2a.TaskStateCompleted,
 			Message: &responseMessage,
 		}
-
-		// Send a completed status update
-		updateChan <- task.StatusUpdate{
-			State: a2a.TaskStateCompleted,
-		}
 	}()
 
 	return updateChan, nil
@@ -132,10 +151,11 @@ func (a *BasicLLMAgent) GetCapabilities() AgentCapabilities {
 
 // ToolAugmentedAgent implements AgentEngine using an LLM with tools.
 type ToolAugmentedAgent struct {
-	llm          llm.LLMInterface
-	tools        []Tool
-	systemPrompt string
-	capabilities AgentCapabilities
+	llm            llm.LLMInterface
+	tools          []Tool
+	systemPrompt   string
+	promptTemplate *PromptTemplate // Rendered for each task and followed by the tool descriptions if set
+	capabilities   AgentCapabilities
 }
 
 // Tool defines a tool that can be used by an agent.
@@ -156,11 +176,7 @@ func NewToolAugmentedAgent(llmInterface llm.LLMInterface, tools []Tool) *ToolAug
 	modelInfo := llmInterface.GetModelInfo()
 
 	// Create a system prompt that includes tool descriptions
-	systemPrompt := "You are a helpful assistant with access to the following tools:\n\n"
-	for _, tool := range tools {
-		systemPrompt += "- " + tool.Name() + ": " + tool.Description() + "\n"
-	}
-	systemPrompt += "\nWhen you need to use a tool, specify the tool name and parameters in your response."
+	systemPrompt := "You are a helpful assistant with access to the following tools:\n\n" + describeTools(tools)
 
 	return &ToolAugmentedAgent{
 		llm:          llmInterface,
@@ -174,6 +190,42 @@ func NewToolAugmentedAgent(llmInterface llm.LLMInterface, tools []Tool) *ToolAug
 	}
 }
 
+// NewToolAugmentedAgentWithPromptTemplate creates a ToolAugmentedAgent whose system prompt
+// is a text/template rendered for each task with PromptVars, followed by the descriptions
+// of the tools. It returns an error if the template is invalid.
+func NewToolAugmentedAgentWithPromptTemplate(llmInterface llm.LLMInterface, tools []Tool, promptTemplate string) (*ToolAugmentedAgent, error) {
+	tmpl, err := ParsePromptTemplate(promptTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := NewToolAugmentedAgent(llmInterface, tools)
+	agent.promptTemplate = tmpl
+	return agent, nil
+}
+
+// describeTools lists tools for a system prompt, with instructions for using them.
+func describeTools(tools []Tool) string {
+	var b strings.Builder
+	for _, tool := range tools {
+		b.WriteString("- " + tool.Name() + ": " + tool.Description() + "\n")
+	}
+	b.WriteString("\nWhen you need to use a tool, specify the tool name and parameters in your response.")
+	return b.String()
+}
+
+// taskSystemPrompt returns the system prompt for a task.
+func (a *ToolAugmentedAgent) taskSystemPrompt(taskCtx task.Context) (string, error) {
+	if a.promptTemplate == nil {
+		return a.systemPrompt, nil
+	}
+	prompt, err := a.promptTemplate.Render(taskCtx)
+	if err != nil {
+		return "", err
+	}
+	return prompt + "\n\nYou have access to the following tools:\n\n" + describeTools(a.tools), nil
+}
+
 // ProcessTask implements AgentEngine.ProcessTask.
 func (a *ToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
 	updateChan := make(chan task.YieldUpdate)
@@ -181,23 +233,20 @@ func (a *ToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Conte
 	go func() {
 		defer close(updateChan)
 
-		// Extract the user's message
-		userMessage := taskCtx.UserMessage
-		var userText string
-		for _, part := range userMessage.Parts {
-			if textPart, ok := part.(a2a.TextPart); ok {
-				userText = textPart.Text
-				break
-			}
-		}
+		// Build the prompt from all parts of the user's message
+		userText := taskPrompt(taskCtx)
 
 		// Send a working status update
 		updateChan <- task.StatusUpdate{
 			State: a2a.TaskStateWorking,
 		}
 
-		// Process the message with the LLM
-		response, err := a.llm.Generate(ctx, userText, llm.WithSystemPrompt(a.systemPrompt))
+		// Process the message with the LLM, using the system prompt for this task
+		systemPrompt, err := a.taskSystemPrompt(taskCtx)
+		var response string
+		if err == nil {
+			response, err = a.llm.Generate(ctx, userText, llm.WithSystemPrompt(systemPrompt))
+		}
 		if err != nil {
 			// Send a failed status update
 			updateChan <- task.StatusUpdate{
@@ -229,16 +278,11 @@ func (a *ToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Conte
 			},
 		}
 
-		// Send a working status update with the response
+		// Complete the task with the response as its final message
 		updateChan <- task.StatusUpdate{
-			State:   a2a.TaskStateWorking,
+			State:   a2a.TaskStateCompleted,
 			Message: &responseMessage,
 		}
-
-		// Send a completed status update
-		updateChan <- task.StatusUpdate{
-			State: a2a.TaskStateCompleted,
-		}
 	}()
 
 	return updateChan, nil
@@ -248,3 +292,87 @@ func (a *ToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Conte
 func (a *ToolAugmentedAgent) GetCapabilities() AgentCapabilities {
 	return a.capabilities
 }
+
+// taskPrompt builds the LLM prompt for a task from every part of the user message:
+// the text parts in order, followed by a description of each attached file and the
+// JSON of each data part. The content of inline text files is included in full.
+func taskPrompt(taskCtx task.Context) string {
+	var sections []string
+	if text := taskCtx.AllText(); text != "" {
+		sections = append(sections, text)
+	}
+
+	for _, part := range taskCtx.UserMessage.Parts {
+		switch p := part.(type) {
+		case a2a.FilePart:
+			section := "Attached " + p.Summary()
+			if p.Description != nil && *p.Description != "" {
+				section += ": " + *p.Description
+			}
+			if p.URI != nil {
+				section += "\nURI: " + *p.URI
+			}
+			if content, ok := inlineTextContent(p); ok {
+				section += "\nContent:\n" + content
+			}
+			sections = append(sections, section)
+		case a2a.DataPart:
+			data, err := json.MarshalIndent(p.Data, "", "  ")
+			if err != nil {
+				continue
+			}
+			sections = append(sections, fmt.Sprintf("Attached data (%s):\n%s", p.MimeType, data))
+		}
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// inlineTextContent returns the decoded content of a file part if it is inline text.
+func inlineTextContent(p a2a.FilePart) (string, bool) {
+	if p.Content == nil {
+		return "", false
+	}
+	if !strings.HasPrefix(p.MimeType, "text/") && p.MimeType != "application/json" {
+		return "", false
+	}
+	if p.Content.Encoding != "base64" {
+		return p.Content.Data, true
+	}
+	data, err := base64.StdEncoding.DecodeString(p.Content.Data)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// skillEngineHandler returns a task handler that processes tasks with the agent engine of
+// their skill, and other tasks with fallback.
+func skillEngineHandler(engines map[string]AgentEngine, fallback task.Handler) task.Handler {
+	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		if engine, ok := engines[taskCtx.SkillID]; ok && taskCtx.SkillID != "" {
+			return engine.ProcessTask(ctx, taskCtx)
+		}
+		return fallback(ctx, taskCtx)
+	}
+}
+
+// checkSkillAgentEngines checks that every skill with an agent engine is in the agent card.
+func checkSkillAgentEngines(engines map[string]AgentEngine, card *a2a.AgentCard) error {
+	for skillID, engine := range engines {
+		if engine == nil {
+			return fmt.Errorf("agent engine for skill %q is nil", skillID)
+		}
+		found := false
+		for _, skill := range card.Skills {
+			if skill.ID == skillID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("agent engine for unknown skill %q", skillID)
+		}
+	}
+	return nil
+}
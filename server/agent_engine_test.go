@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/llm"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+func TestBasicLLMAgent_PromptIncludesAllParts(t *testing.T) {
+	fake := &fakeLLM{reply: "done"}
+	agent := NewBasicLLMAgent(fake, "You are a helpful assistant.")
+
+	description := "Quarterly figures"
+	updates, err := agent.ProcessTask(context.Background(), task.Context{
+		TaskID: "task-1",
+		UserMessage: a2a.Message{
+			Role: a2a.RoleUser,
+			Parts: []a2a.Part{
+				a2a.TextPart{Type: "text", Text: "Summarise the attached report."},
+				a2a.FilePart{
+					Type:        "file",
+					Filename:    "report.txt",
+					MimeType:    "text/plain",
+					Description: &description,
+					Content: &a2a.FileContent{
+						Encoding: "base64",
+						Data:     base64.StdEncoding.EncodeToString([]byte("Revenue grew 12%.")),
+					},
+				},
+				a2a.TextPart{Type: "text", Text: "Keep it to one sentence."},
+				a2a.DataPart{Type: "data", MimeType: "application/json", Data: map[string]interface{}{"region": "APAC"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask failed: %v", err)
+	}
+	for range updates {
+	}
+
+	prompts := fake.Prompts()
+	if len(prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(prompts))
+	}
+	prompt := prompts[0]
+	for _, want := range []string{
+		"Summarise the attached report.\nKeep it to one sentence.",
+		"Attached File: report.txt (text/plain, 17 bytes): Quarterly figures",
+		"Revenue grew 12%.",
+		`"region": "APAC"`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestLLMAgents_PromptTemplate(t *testing.T) {
+	const promptTemplate = `You help {{.Metadata.user}} with {{.SkillID}} in session {{.SessionID}}.`
+	taskCtx := task.Context{
+		TaskID:      "task-1",
+		SessionID:   "session-1",
+		SkillID:     "billing",
+		UserMessage: newTextMessage(a2a.RoleUser, "Why was I charged twice?"),
+		Metadata:    map[string]interface{}{"user": "alice"},
+	}
+	const want = "You help alice with billing in session session-1."
+
+	fake := &fakeLLM{reply: "done"}
+	basic, err := NewBasicLLMAgentWithPromptTemplate(fake, promptTemplate)
+	if err != nil {
+		t.Fatalf("Failed to create basic agent: %v", err)
+	}
+	tools, err := NewToolAugmentedAgentWithPromptTemplate(fake, nil, promptTemplate)
+	if err != nil {
+		t.Fatalf("Failed to create tool augmented agent: %v", err)
+	}
+
+	for _, agent := range []AgentEngine{basic, tools} {
+		updates, err := agent.ProcessTask(context.Background(), taskCtx)
+		if err != nil {
+			t.Fatalf("ProcessTask failed: %v", err)
+		}
+		for range updates {
+		}
+		if got := fake.Options().SystemPrompt; !strings.HasPrefix(got, want) {
+			t.Errorf("%T: expected the system prompt to start with %q, got %q", agent, want, got)
+		}
+	}
+
+	if _, err := NewBasicLLMAgentWithPromptTemplate(fake, "You help with {{.SkillID"); err == nil {
+		t.Error("Expected an invalid template to be rejected")
+	}
+}
+
+// blockingLLM is an LLM whose Generate calls block until their context is done, like a
+// model request still in flight. It reports each call's context error on cancelled.
+type blockingLLM struct {
+	fakeLLM
+	started   chan struct{}
+	cancelled chan error
+}
+
+func (b *blockingLLM) Generate(ctx context.Context, prompt string, options ...llm.LLMOption) (string, error) {
+	close(b.started)
+	<-ctx.Done()
+	b.cancelled <- ctx.Err()
+	return "", ctx.Err()
+}
+
+func TestBasicLLMAgent_CancelledMidGeneration(t *testing.T) {
+	model := &blockingLLM{started: make(chan struct{}), cancelled: make(chan error, 1)}
+	agent := NewBasicLLMAgent(model, "You are a helpful assistant.")
+
+	// Note when the agent has sent its last update
+	finished := make(chan struct{})
+	tm := NewInMemoryTaskManager(func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates, err := agent.ProcessTask(ctx, taskCtx)
+		if err != nil {
+			return nil, err
+		}
+		forwarded := make(chan task.YieldUpdate)
+		go func() {
+			defer close(finished)
+			defer close(forwarded)
+			for update := range updates {
+				forwarded <- update
+			}
+		}()
+		return forwarded, nil
+	})
+
+	taskObj, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "Write a long story.")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	select {
+	case <-model.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the model call")
+	}
+
+	if _, err := tm.OnCancelTask(context.Background(), &a2a.TaskIdParams{TaskID: taskObj.ID}); err != nil {
+		t.Fatalf("OnCancelTask failed: %v", err)
+	}
+	select {
+	case err := <-model.cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the model call's context to be cancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("The model call's context was not cancelled")
+	}
+
+	// The failure the agent reports for the aborted call must not replace the cancellation
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the agent to finish")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := waitForState(t, tm, taskObj.ID, a2a.TaskStateCancelled); got.Status.Reason != a2a.TaskStatusReasonUser {
+		t.Errorf("Expected the task to stay cancelled by the user, got %+v", got.Status)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// artifactDownloadPath is the path of the artifact download endpoint, relative to the A2A
+// path prefix.
+const artifactDownloadPath = "tasks/{taskId}/artifacts/{artifactId}"
+
+// artifactDownloadURI returns the path of an artifact's download endpoint, relative to the
+// A2A endpoint. Clients resolve such relative URIs against the agent's endpoint.
+func artifactDownloadURI(taskID, artifactID string) string {
+	return "tasks/" + url.PathEscape(taskID) + "/artifacts/" + url.PathEscape(artifactID)
+}
+
+// parseArtifactDownloadURI returns the task and artifact IDs of a URI returned by
+// artifactDownloadURI.
+func parseArtifactDownloadURI(uri string) (taskID, artifactID string, ok bool) {
+	parts := strings.Split(uri, "/")
+	if len(parts) != 4 || parts[0] != "tasks" || parts[2] != "artifacts" {
+		return "", "", false
+	}
+	taskID, err1 := url.PathUnescape(parts[1])
+	artifactID, err2 := url.PathUnescape(parts[3])
+	return taskID, artifactID, err1 == nil && err2 == nil
+}
+
+// handleArtifactDownload serves the raw content of a task's artifact, with its MIME type
+// as the Content-Type, instead of base64 inside a JSON-RPC response. Content offloaded to
+// the artifact store is read back from it.
+func (s *Server) handleArtifactDownload(w http.ResponseWriter, r *http.Request) {
+	taskID, artifactID := r.PathValue("taskId"), r.PathValue("artifactId")
+
+	taskObj, err := s.taskManager.OnGetTask(r.Context(), &a2a.TaskQueryParams{TaskID: taskID})
+	if err != nil {
+		var a2aErr *a2a.Error
+		if errors.As(err, &a2aErr) && a2aErr.Code == a2a.CodeTaskNotFound {
+			http.Error(w, "Task not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get task", http.StatusInternalServerError)
+		return
+	}
+
+	// The most recent artifact with the ID, as artifacts may be replaced
+	var artifact *a2a.Artifact
+	for i := len(taskObj.Artifacts) - 1; i >= 0; i-- {
+		if taskObj.Artifacts[i].ID == artifactID {
+			artifact = &taskObj.Artifacts[i]
+			break
+		}
+	}
+	if artifact == nil {
+		http.Error(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+
+	file, err := s.artifactFile(r, *artifact)
+	if err != nil {
+		http.Error(w, "Failed to read artifact", http.StatusInternalServerError)
+		return
+	}
+	if file == nil {
+		http.Error(w, "Artifact has no content to download", http.StatusNotFound)
+		return
+	}
+
+	contentType := file.mimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filename := file.filename
+	if filename == "" {
+		filename = artifact.ID
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(file.data)))
+	w.Write(file.data)
+}
+
+// artifactFile returns the content of an artifact as a file, reading file parts that only
+// reference a URI from the artifact store. It returns nil if the content is not available.
+func (s *Server) artifactFile(r *http.Request, artifact a2a.Artifact) (*artifactFile, error) {
+	file, err := inlineArtifactFile(artifact)
+	if err != nil || file != nil {
+		return file, err
+	}
+
+	p, ok := artifact.Part.(a2a.FilePart)
+	if !ok || p.URI == nil || s.config.ArtifactStore == nil {
+		return nil, nil
+	}
+	data, err := s.config.ArtifactStore.Get(r.Context(), *p.URI)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactFile{data: data, filename: p.Filename, mimeType: p.MimeType}, nil
+}
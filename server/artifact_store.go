@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// ArtifactStore stores artifact content outside of the task, so large files and data
+// do not have to be kept in memory or sent inline in responses and push notifications.
+type ArtifactStore interface {
+	// Put stores the content of an artifact and returns a URI from which it can be retrieved.
+	Put(ctx context.Context, taskID, artifactID string, data []byte) (string, error)
+
+	// Get returns the content stored at a URI returned by Put.
+	Get(ctx context.Context, uri string) ([]byte, error)
+}
+
+// FileArtifactStore is an ArtifactStore that keeps artifact content in files under a
+// directory, one subdirectory per task. Its URIs are the path of the artifact download
+// endpoint relative to the A2A endpoint ("tasks/{taskId}/artifacts/{artifactId}"), so
+// clients fetch the content from the server rather than being handed paths on the
+// server's filesystem.
+type FileArtifactStore struct {
+	dir string
+}
+
+// NewFileArtifactStore creates a FileArtifactStore rooted at dir, creating it if needed.
+func NewFileArtifactStore(dir string) (*FileArtifactStore, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	return &FileArtifactStore{dir: abs}, nil
+}
+
+// Put implements ArtifactStore.Put.
+func (s *FileArtifactStore) Put(ctx context.Context, taskID, artifactID string, data []byte) (string, error) {
+	if !isSafePathElement(taskID) || !isSafePathElement(artifactID) {
+		return "", fmt.Errorf("invalid artifact location %q/%q", taskID, artifactID)
+	}
+
+	taskDir := filepath.Join(s.dir, taskID)
+	if err := os.MkdirAll(taskDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create task artifact directory: %w", err)
+	}
+	path := filepath.Join(taskDir, artifactID)
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return artifactDownloadURI(taskID, artifactID), nil
+}
+
+// PutStream stores content read from r, like Put, without holding it all in memory. The
+// content is written to a temporary file that replaces any earlier content once complete.
+func (s *FileArtifactStore) PutStream(ctx context.Context, taskID, artifactID string, r io.Reader) (string, error) {
+	if !isSafePathElement(taskID) || !isSafePathElement(artifactID) {
+		return "", fmt.Errorf("invalid artifact location %q/%q", taskID, artifactID)
+	}
+
+	taskDir := filepath.Join(s.dir, taskID)
+	if err := os.MkdirAll(taskDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create task artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(taskDir, "."+artifactID+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifact: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o640); err != nil {
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(taskDir, artifactID)); err != nil {
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	return artifactDownloadURI(taskID, artifactID), nil
+}
+
+// Get implements ArtifactStore.Get. Only URIs within the store's directory are read.
+func (s *FileArtifactStore) Get(ctx context.Context, uri string) ([]byte, error) {
+	path, err := s.path(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes the content stored at a URI returned by Put. Deleting content that does
+// not exist is not an error.
+func (s *FileArtifactStore) Delete(ctx context.Context, uri string) error {
+	path, err := s.path(uri)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete artifact: %w", err)
+	}
+	return nil
+}
+
+// path returns the file a URI returned by Put refers to. file:// URIs within the store's
+// directory, returned by earlier versions, are accepted too.
+func (s *FileArtifactStore) path(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid artifact URI %q: %w", uri, err)
+	}
+
+	if u.Scheme == "" {
+		taskID, artifactID, ok := parseArtifactDownloadURI(u.EscapedPath())
+		if !ok || !isSafePathElement(taskID) || !isSafePathElement(artifactID) {
+			return "", fmt.Errorf("not an artifact URI: %q", uri)
+		}
+		return filepath.Join(s.dir, taskID, artifactID), nil
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("not an artifact URI: %q", uri)
+	}
+	path := filepath.Clean(filepath.FromSlash(u.Path))
+	if rel, err := filepath.Rel(s.dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("artifact URI %q is outside the artifact store", uri)
+	}
+	return path, nil
+}
+
+// artifactDeleter is implemented by artifact stores that can delete content, such as
+// FileArtifactStore. Content that is no longer needed is deleted if the store supports it.
+type artifactDeleter interface {
+	Delete(ctx context.Context, uri string) error
+}
+
+// artifactStreamer is implemented by artifact stores that can store content read from a
+// reader, such as FileArtifactStore. Large content is streamed into the store if it
+// supports it.
+type artifactStreamer interface {
+	PutStream(ctx context.Context, taskID, artifactID string, r io.Reader) (string, error)
+}
+
+// isSafePathElement reports whether name can be used as a single path element.
+func isSafePathElement(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// offloadArtifact stores the content of an artifact's part in the store and replaces the
+// part with a FilePart referencing it. Text and data parts become files of their MIME type.
+// File parts that are already only a URI reference are left as they are.
+func offloadArtifact(ctx context.Context, store ArtifactStore, artifact *a2a.Artifact) error {
+	file, err := inlineArtifactFile(*artifact)
+	if err != nil || file == nil {
+		return err
+	}
+
+	uri, err := store.Put(ctx, artifact.TaskID, artifact.ID, file.data)
+	if err != nil {
+		return err
+	}
+
+	part := a2a.FilePart{Type: "file", Filename: file.filename, MimeType: file.mimeType, URI: &uri}
+	if p, ok := artifact.Part.(a2a.FilePart); ok {
+		part.Description = p.Description
+	}
+	artifact.Part = part
+	return nil
+}
+
+// artifactFile is the content of an artifact as a file.
+type artifactFile struct {
+	data     []byte
+	filename string
+	mimeType string
+}
+
+// inlineArtifactFile returns the inline content of an artifact's part as a file. Text and
+// data parts become files of their MIME type. It returns nil for parts without inline
+// content, such as file parts that only reference a URI.
+func inlineArtifactFile(artifact a2a.Artifact) (*artifactFile, error) {
+	switch p := artifact.Part.(type) {
+	case a2a.TextPart:
+		return &artifactFile{data: []byte(p.Text), filename: artifact.ID + ".txt", mimeType: "text/plain"}, nil
+	case a2a.DataPart:
+		encoded, err := json.Marshal(p.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data part: %w", err)
+		}
+		mimeType := p.MimeType
+		if mimeType == "" {
+			mimeType = "application/json"
+		}
+		return &artifactFile{data: encoded, filename: artifact.ID + ".json", mimeType: mimeType}, nil
+	case a2a.FilePart:
+		if p.Content == nil {
+			return nil, nil
+		}
+		data := []byte(p.Content.Data)
+		if p.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(p.Content.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode file part: %w", err)
+			}
+			data = decoded
+		}
+		return &artifactFile{data: data, filename: p.Filename, mimeType: p.MimeType}, nil
+	default:
+		return nil, nil
+	}
+}
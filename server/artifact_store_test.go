@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+func TestFileArtifactStore(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	uri, err := store.Put(ctx, "task-1", "artifact-1", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if uri != "tasks/task-1/artifacts/artifact-1" {
+		t.Errorf("Expected the artifact's download path, got %q", uri)
+	}
+
+	data, err := store.Get(ctx, uri)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("Expected %q, got %q", "hello", data)
+	}
+
+	if _, err := store.Put(ctx, "../escape", "artifact-1", []byte("x")); err == nil {
+		t.Error("Expected an error for a task ID outside the store")
+	}
+	if _, err := store.Get(ctx, "file:///etc/passwd"); err == nil {
+		t.Error("Expected an error for a URI outside the store")
+	}
+}
+
+func TestArtifactStoreOffloading(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	image := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a}
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 3)
+		updates <- task.ArtifactUpdate{Part: a2a.FilePart{
+			Type:     "file",
+			Filename: "cat.png",
+			MimeType: "image/png",
+			Content:  &a2a.FileContent{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(image)},
+		}}
+		updates <- task.ArtifactUpdate{Part: a2a.DataPart{Type: "data", MimeType: "application/json", Data: map[string]interface{}{"count": 1}}}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler, WithArtifactStore(store))
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	sent, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "draw a cat")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	var taskObj *a2a.Task
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if taskObj, err = c.GetTask(ctx, sent.ID); err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+		if taskObj.Status.State == a2a.TaskStateCompleted {
+			break
+		}
+	}
+	if len(taskObj.Artifacts) != 2 {
+		t.Fatalf("Expected 2 artifacts, got %d", len(taskObj.Artifacts))
+	}
+
+	want := []string{string(image), `{"count":1}`}
+	for i, artifact := range taskObj.Artifacts {
+		part, ok := artifact.Part.(a2a.FilePart)
+		if !ok {
+			t.Fatalf("Expected artifact %d to be a file part, got %T", i, artifact.Part)
+		}
+		if part.Content != nil || part.URI == nil {
+			t.Fatalf("Expected artifact %d to reference its content by URI, got %+v", i, part)
+		}
+
+		stored, err := store.Get(ctx, *part.URI)
+		if err != nil {
+			t.Fatalf("Failed to get artifact %d from the store: %v", i, err)
+		}
+		if string(stored) != want[i] {
+			t.Errorf("Expected stored artifact %d to be %q, got %q", i, want[i], stored)
+		}
+
+		resolved, err := c.ResolveFile(ctx, part)
+		if err != nil {
+			t.Fatalf("Failed to resolve artifact %d: %v", i, err)
+		}
+		data, err := base64.StdEncoding.DecodeString(resolved.Content.Data)
+		if err != nil {
+			t.Fatalf("Failed to decode resolved artifact %d: %v", i, err)
+		}
+		if string(data) != want[i] {
+			t.Errorf("Expected resolved artifact %d to be %q, got %q", i, want[i], data)
+		}
+	}
+	if part := taskObj.Artifacts[0].Part.(a2a.FilePart); part.Filename != "cat.png" || part.MimeType != "image/png" {
+		t.Errorf("Expected the file name and MIME type to be kept, got %+v", part)
+	}
+}
+
+func TestArtifactDownload(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x00, 0xff}
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 3)
+		updates <- task.ArtifactUpdate{Part: a2a.FilePart{
+			Type:     "file",
+			Filename: "cat.png",
+			MimeType: "image/png",
+			Content:  &a2a.FileContent{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(image)},
+		}}
+		updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: "a cat"}}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"inline", nil},
+		{"offloaded", []Option{WithArtifactStore(store)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, baseURL := newTestServer(t, handler, tt.opts...)
+			c, err := client.NewClient(client.WithBaseURL(baseURL))
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			ctx := context.Background()
+			sent, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "draw a cat")})
+			if err != nil {
+				t.Fatalf("SendTask failed: %v", err)
+			}
+
+			var taskObj *a2a.Task
+			for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				if taskObj, err = c.GetTask(ctx, sent.ID); err != nil {
+					t.Fatalf("GetTask failed: %v", err)
+				}
+				if taskObj.Status.State == a2a.TaskStateCompleted {
+					break
+				}
+			}
+			if len(taskObj.Artifacts) != 2 {
+				t.Fatalf("Expected 2 artifacts, got %d", len(taskObj.Artifacts))
+			}
+
+			want := []struct {
+				data        string
+				contentType string
+				filename    string
+			}{
+				{string(image), "image/png", "cat.png"},
+				{"a cat", "text/plain", taskObj.Artifacts[1].ID + ".txt"},
+			}
+			for i, artifact := range taskObj.Artifacts {
+				download, err := c.DownloadArtifact(ctx, sent.ID, artifact.ID)
+				if err != nil {
+					t.Fatalf("DownloadArtifact %d failed: %v", i, err)
+				}
+				data, err := io.ReadAll(download.Body)
+				download.Body.Close()
+				if err != nil {
+					t.Fatalf("Failed to read artifact %d: %v", i, err)
+				}
+
+				if string(data) != want[i].data {
+					t.Errorf("Expected artifact %d to be %q, got %q", i, want[i].data, data)
+				}
+				if download.ContentType != want[i].contentType {
+					t.Errorf("Expected artifact %d to have Content-Type %s, got %s", i, want[i].contentType, download.ContentType)
+				}
+				if download.Filename != want[i].filename {
+					t.Errorf("Expected artifact %d to have filename %s, got %s", i, want[i].filename, download.Filename)
+				}
+				if download.Size != int64(len(want[i].data)) {
+					t.Errorf("Expected artifact %d to have size %d, got %d", i, len(want[i].data), download.Size)
+				}
+			}
+
+			if _, err := c.DownloadArtifact(ctx, sent.ID, "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+				t.Errorf("Expected a 404 error for a missing artifact, got %v", err)
+			}
+			if _, err := c.DownloadArtifact(ctx, "missing", taskObj.Artifacts[0].ID); err == nil || !strings.Contains(err.Error(), "404") {
+				t.Errorf("Expected a 404 error for a missing task, got %v", err)
+			}
+		})
+	}
+}
+
+func TestArtifactStoreOffloading_Streamed(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 2)
+		updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: "a cat"}}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler, WithArtifactStore(store))
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "draw a cat")})
+	var taskID string
+	var streamed []a2a.Artifact
+	for update := range updates {
+		taskID = update.TaskID
+		if update.Artifact != nil {
+			streamed = append(streamed, *update.Artifact)
+		}
+		if update.Status != nil && update.Status.State == a2a.TaskStateCompleted {
+			break
+		}
+	}
+	if len(streamed) != 1 {
+		t.Fatalf("Expected 1 streamed artifact, got %d (%v)", len(streamed), <-errs)
+	}
+
+	// Subscribers receive the artifact as stored: with its ID, and offloaded to the store
+	taskObj, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if len(taskObj.Artifacts) != 1 || taskObj.Artifacts[0].ID != streamed[0].ID {
+		t.Fatalf("Expected the streamed artifact %q to be stored, got %+v", streamed[0].ID, taskObj.Artifacts)
+	}
+	part, ok := streamed[0].Part.(a2a.FilePart)
+	if !ok || part.URI == nil {
+		t.Fatalf("Expected the streamed artifact to reference its content by URI, got %+v", streamed[0].Part)
+	}
+	resolved, err := c.ResolveFile(ctx, part)
+	if err != nil {
+		t.Fatalf("Failed to resolve the streamed artifact: %v", err)
+	}
+	if data, _ := base64.StdEncoding.DecodeString(resolved.Content.Data); string(data) != "a cat" {
+		t.Errorf("Expected %q, got %q", "a cat", data)
+	}
+}
@@ -2,9 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
 	"github.com/sammcj/go-a2a/server/middleware"
 )
 
@@ -24,30 +30,168 @@ func CreateAuthValidator(validator middleware.AuthValidator) AuthValidator {
 }
 
 // SimpleTokenValidator creates an AuthValidator that validates requests using a simple token comparison.
-// This is useful for basic authentication scenarios where a single token is used.
+// This is useful for basic authentication scenarios where a single token is used. As the token
+// is shared, the caller's identity only names the authentication type it was sent with.
 func SimpleTokenValidator(expectedToken string) AuthValidator {
 	validator := func(ctx context.Context, info middleware.AuthInfo) (bool, error) {
-		// For bearer token authentication
-		if info.Type == "bearer" {
-			return info.Value == expectedToken, nil
+		// Bearer token and header-based authentication are supported
+		if info.Type != "bearer" && info.Type != "header" {
+			return false, nil
 		}
-
-		// For header-based authentication
-		if info.Type == "header" {
-			return info.Value == expectedToken, nil
+		if info.Value != expectedToken {
+			return false, nil
 		}
 
-		// Unsupported authentication type
-		return false, nil
+		middleware.SetIdentity(ctx, task.Identity{Scheme: info.Type})
+		return true, nil
 	}
 
 	return CreateAuthValidator(validator)
 }
 
+// AuthProvider verifies the credentials of one type of authentication scheme. It is given a
+// scheme declared in an agent card and returns the validator for its credentials, configured
+// from the scheme's configuration, or an error if the configuration is invalid.
+type AuthProvider func(scheme a2a.AgentAuthentication) (middleware.AuthValidator, error)
+
+// NewAuthValidatorFromCard creates an AuthValidator that enforces the authentication schemes
+// declared in the agent card, so deployments need not write their own validator. providers
+// maps scheme types (e.g., "bearer", "oauth2", "header") to the providers that verify them,
+// such as JWKSBearerProvider and APIKeyProvider. Every declared scheme must have a provider.
+// Requests are accepted if their credentials are valid for any declared scheme.
+func NewAuthValidatorFromCard(card *a2a.AgentCard, providers map[string]AuthProvider) (AuthValidator, error) {
+	validators := make(map[string]middleware.AuthValidator, len(card.Authentication))
+	for _, scheme := range card.Authentication {
+		key := authSchemeKey(scheme.Type, configString(scheme.Configuration, "headerName"))
+		if _, ok := validators[key]; ok {
+			continue // Credentials are checked against the first scheme they match, as they are extracted
+		}
+
+		provider, ok := providers[scheme.Type]
+		if !ok {
+			return nil, fmt.Errorf("no auth provider for %q authentication", scheme.Type)
+		}
+		validator, err := provider(scheme)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q authentication configuration: %w", scheme.Type, err)
+		}
+		validators[key] = validator
+	}
+
+	return CreateAuthValidator(func(ctx context.Context, info middleware.AuthInfo) (bool, error) {
+		validator, ok := validators[authSchemeKey(info.Type, info.Scheme)]
+		if !ok {
+			return false, nil
+		}
+		return validator(ctx, info)
+	}), nil
+}
+
+// authSchemeKey identifies a scheme by its type, and for header schemes, the header name,
+// which is the scheme of the credentials extracted for it.
+func authSchemeKey(schemeType, headerName string) string {
+	if schemeType == "header" {
+		return schemeType + ":" + http.CanonicalHeaderKey(headerName)
+	}
+	return schemeType
+}
+
+// configString returns a string value from an authentication scheme's configuration.
+func configString(config interface{}, key string) string {
+	m, _ := config.(map[string]interface{})
+	s, _ := m[key].(string)
+	return s
+}
+
+// JWKSBearerProvider returns an AuthProvider for "bearer" and "oauth2" schemes whose tokens
+// are JWTs signed with a key from a JSON Web Key Set. The scheme's configuration must set
+// "jwksUrl", and may set "issuer" and "audience", which the token's claims must then match.
+// Expired tokens, and tokens without an expiry, are rejected. The key set is fetched with
+// client (http.DefaultClient if nil) when a token is first verified and cached; after a
+// failed fetch, tokens needing the key set are refused for a few seconds before it is tried
+// again. The caller's identity is taken from the token: its "sub" claim is the subject, and
+// the claim named by the scheme's "tenantClaim" configuration ("tenant" by default) the tenant.
+func JWKSBearerProvider(client *http.Client) AuthProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(scheme a2a.AgentAuthentication) (middleware.AuthValidator, error) {
+		jwksURL := configString(scheme.Configuration, "jwksUrl")
+		if jwksURL == "" {
+			return nil, fmt.Errorf("jwksUrl is required")
+		}
+		issuer := configString(scheme.Configuration, "issuer")
+		audience := configString(scheme.Configuration, "audience")
+		tenantClaim := configString(scheme.Configuration, "tenantClaim")
+		if tenantClaim == "" {
+			tenantClaim = "tenant"
+		}
+		keys := newJWKSCache(jwksURL, client)
+
+		return func(ctx context.Context, info middleware.AuthInfo) (bool, error) {
+			claims, err := verifyJWT(ctx, info.Value, keys)
+			if errors.Is(err, errJWKSUnavailable) {
+				return false, err
+			}
+			if err != nil {
+				return false, nil
+			}
+
+			now := time.Now()
+			if !now.Before(claims.ExpiresAt) {
+				return false, nil
+			}
+			if claims.NotBefore != nil && now.Before(*claims.NotBefore) {
+				return false, nil
+			}
+			if issuer != "" && claims.Issuer != issuer {
+				return false, nil
+			}
+			if audience != "" && !slices.Contains(claims.Audience, audience) {
+				return false, nil
+			}
+
+			identity := task.Identity{Scheme: info.Type, Claims: claims.All}
+			identity.Subject, _ = claims.All["sub"].(string)
+			identity.Tenant, _ = claims.All[tenantClaim].(string)
+			middleware.SetIdentity(ctx, identity)
+			return true, nil
+		}, nil
+	}
+}
+
+// APIKeyProvider returns an AuthProvider for "header" schemes, which send an API key in the
+// header named by the scheme's "headerName" configuration. Keys are accepted if lookup
+// returns true for them. The caller's identity names the "header" scheme; lookup may also name
+// the key's owner by calling middleware.SetIdentity with the context it is given.
+func APIKeyProvider(lookup func(ctx context.Context, key string) (bool, error)) AuthProvider {
+	return func(scheme a2a.AgentAuthentication) (middleware.AuthValidator, error) {
+		if configString(scheme.Configuration, "headerName") == "" {
+			return nil, fmt.Errorf("headerName is required")
+		}
+		return func(ctx context.Context, info middleware.AuthInfo) (bool, error) {
+			// Set before the lookup, so an identity it sets replaces this one
+			middleware.SetIdentity(ctx, task.Identity{Scheme: info.Type})
+			return lookup(ctx, info.Value)
+		}, nil
+	}
+}
+
 // NoAuthValidator is an AuthValidator that allows all requests.
-// This is useful for development or when authentication is not required.
+// This is useful for development or when authentication is not required. Requests are not
+// authenticated, so task handlers see no identity.
 func NoAuthValidator() AuthValidator {
-	return func(w http.ResponseWriter, r *http.Request, next http.Handler, card *a2a.AgentCard) {
-		next.ServeHTTP(w, r)
-	}
+	return allowAllRequests
+}
+
+// allowAllRequests is the AuthValidator returned by NoAuthValidator.
+func allowAllRequests(w http.ResponseWriter, r *http.Request, next http.Handler, card *a2a.AgentCard) {
+	next.ServeHTTP(w, r)
+}
+
+// isNoAuthValidator reports whether validator is the one returned by NoAuthValidator, which
+// is how a deployment says on purpose that it serves endpoints without authentication.
+func isNoAuthValidator(validator AuthValidator) bool {
+	return validator != nil && reflect.ValueOf(validator).Pointer() == reflect.ValueOf(allowAllRequests).Pointer()
 }
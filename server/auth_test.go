@@ -0,0 +1,322 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/server/middleware"
+)
+
+// signJWT signs claims as an RS256 JWT with the given key ID.
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to marshal JWT part: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encode(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign JWT: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newJWKSServer serves a key set holding the public key of key under the given key ID.
+func newJWKSServer(t *testing.T, key *rsa.PrivateKey, kid string) *httptest.Server {
+	t.Helper()
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewAuthValidatorFromCard(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwksServer := newJWKSServer(t, key, "key-1")
+
+	card := &a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "test-agent",
+		Name:       "Test Agent",
+		Authentication: []a2a.AgentAuthentication{
+			{Type: "bearer", Configuration: map[string]interface{}{
+				"jwksUrl":  jwksServer.URL,
+				"issuer":   "https://issuer.example.com",
+				"audience": "test-agent",
+			}},
+			{Type: "header", Configuration: map[string]interface{}{"headerName": "X-API-Key"}},
+		},
+	}
+	validator, err := NewAuthValidatorFromCard(card, map[string]AuthProvider{
+		"bearer": JWKSBearerProvider(nil),
+		"header": APIKeyProvider(func(ctx context.Context, key string) (bool, error) {
+			return key == "valid-key", nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewAuthValidatorFromCard failed: %v", err)
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(card), WithAuthValidator(validator))
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": "https://issuer.example.com",
+			"aud": "test-agent",
+			"sub": "alice",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		authorized bool
+	}{
+		{"valid token", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(nil)), true},
+		{"expired token", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})), false},
+		{"no expiry", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(map[string]interface{}{"exp": nil})), false},
+		{"non-numeric expiry", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(map[string]interface{}{"exp": "tomorrow"})), false},
+		{"wrong audience", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(map[string]interface{}{"aud": "other-agent"})), false},
+		{"wrong issuer", "Authorization", "Bearer " + signJWT(t, key, "key-1", claims(map[string]interface{}{"iss": "https://evil.example.com"})), false},
+		{"signed by another key", "Authorization", "Bearer " + signJWT(t, otherKey, "key-1", claims(nil)), false},
+		{"not a JWT", "Authorization", "Bearer opaque-token", false},
+		{"valid API key", "X-API-Key", "valid-key", true},
+		{"invalid API key", "X-API-Key", "wrong-key", false},
+		{"no credentials", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(`{"jsonrpc":"2.0","method":"tasks/get","params":{"taskId":"missing"},"id":1}`))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if authorized := resp.StatusCode != http.StatusUnauthorized; authorized != tt.authorized {
+				t.Errorf("Expected authorized = %v, got status %d", tt.authorized, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAuthIdentity(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwksServer := newJWKSServer(t, key, "key-1")
+
+	card := &a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "test-agent",
+		Name:       "Test Agent",
+		Authentication: []a2a.AgentAuthentication{
+			{Type: "bearer", Configuration: map[string]interface{}{"jwksUrl": jwksServer.URL, "tenantClaim": "org"}},
+			{Type: "header", Configuration: map[string]interface{}{"headerName": "X-API-Key"}},
+		},
+	}
+	validator, err := NewAuthValidatorFromCard(card, map[string]AuthProvider{
+		"bearer": JWKSBearerProvider(nil),
+		"header": APIKeyProvider(func(ctx context.Context, key string) (bool, error) {
+			if key == "service-key" {
+				return true, nil
+			}
+			if key != "bob-key" {
+				return false, nil
+			}
+			middleware.SetIdentity(ctx, task.Identity{Scheme: "header", Subject: "bob"})
+			return true, nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewAuthValidatorFromCard failed: %v", err)
+	}
+
+	identities := make(chan *task.Identity, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		identities <- taskCtx.Identity
+		return task.EchoHandler(ctx, taskCtx)
+	}
+	_, baseURL := newTestServer(t, handler, WithAgentCard(card), WithAuthValidator(validator))
+
+	token := signJWT(t, key, "key-1", map[string]interface{}{
+		"sub": "alice",
+		"org": "acme",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		scheme  string
+		subject string
+		tenant  string
+	}{
+		{"bearer token", "Authorization", "Bearer " + token, "bearer", "alice", "acme"},
+		{"API key", "X-API-Key", "bob-key", "header", "bob", ""},
+		{"API key without owner", "X-API-Key", "service-key", "header", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`
+			req, err := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(tt.header, tt.value)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+			}
+
+			select {
+			case identity := <-identities:
+				if identity == nil {
+					t.Fatal("Expected the handler to see an identity")
+				}
+				if identity.Scheme != tt.scheme || identity.Subject != tt.subject || identity.Tenant != tt.tenant {
+					t.Errorf("Expected scheme %q, subject %q and tenant %q, got %+v", tt.scheme, tt.subject, tt.tenant, identity)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the handler")
+			}
+		})
+	}
+}
+
+func TestSimpleTokenValidator_Identity(t *testing.T) {
+	card := &a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "test-agent",
+		Name:       "Test Agent",
+		Authentication: []a2a.AgentAuthentication{
+			{Type: "bearer"},
+		},
+	}
+
+	identities := make(chan *task.Identity, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		identities <- taskCtx.Identity
+		return task.EchoHandler(ctx, taskCtx)
+	}
+	_, baseURL := newTestServer(t, handler, WithAgentCard(card), WithAuthValidator(SimpleTokenValidator("secret")))
+
+	body := `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`
+	req, err := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	select {
+	case identity := <-identities:
+		if identity == nil || identity.Scheme != "bearer" {
+			t.Errorf("Expected a bearer identity, got %+v", identity)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the handler")
+	}
+}
+
+func TestNewAuthValidatorFromCard_InvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    a2a.AgentAuthentication
+		providers map[string]AuthProvider
+		wantErr   string
+	}{
+		{
+			name:      "no provider for scheme",
+			scheme:    a2a.AgentAuthentication{Type: "oauth2"},
+			providers: map[string]AuthProvider{"bearer": JWKSBearerProvider(nil)},
+			wantErr:   `no auth provider for "oauth2" authentication`,
+		},
+		{
+			name:      "missing JWKS URL",
+			scheme:    a2a.AgentAuthentication{Type: "bearer"},
+			providers: map[string]AuthProvider{"bearer": JWKSBearerProvider(nil)},
+			wantErr:   "jwksUrl is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &a2a.AgentCard{Authentication: []a2a.AgentAuthentication{tt.scheme}}
+			_, err := NewAuthValidatorFromCard(card, tt.providers)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -2,91 +2,288 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/schema"
+	"github.com/sammcj/go-a2a/pkg/trace"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // handleA2ARequest is the main entry point for incoming A2A JSON-RPC requests.
 // This is a more complete implementation that replaces the placeholder in server.go.
 func (s *Server) handleA2ARequest(w http.ResponseWriter, r *http.Request) {
+	request, ok := readJSONRPCRequest(w, r, s.maxInputBytes())
+	if !ok {
+		return
+	}
+
+	// Create context with timeout
+	// TODO: Make timeout configurable
+	ctx := s.traceContext(w, r)
+	ctx, span := startRequestSpan(ctx, request)
+	defer span.End()
+	ctx = withJSONRPCID(ctx, request.ID)
+
+	// Notifications are processed without writing a response body
+	if request.IsNotification() {
+		if !notificationMethods[request.Method] {
+			writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Method cannot be sent as a notification: "+request.Method), nil)
+			return
+		}
+		s.routeA2ARequest(ctx, &discardResponseWriter{header: make(http.Header)}, r, request)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.routeA2ARequest(ctx, w, r, request)
+}
+
+// traceContext returns the request context carrying the caller's span context, the propagated
+// trace headers and the tracer.
+// The request ID is echoed back on the response.
+func (s *Server) traceContext(w http.ResponseWriter, r *http.Request) context.Context {
+	headers := trace.FromRequest(r, s.config.PropagatedHeaders)
+	w.Header().Set(trace.RequestIDHeader, headers[http.CanonicalHeaderKey(trace.RequestIDHeader)])
+
+	ctx := trace.WithHeaders(trace.Extract(r.Context(), r), headers)
+	return trace.WithTracerProvider(ctx, s.config.TracerProvider)
+}
+
+// startRequestSpan starts a span for a JSON-RPC request.
+func startRequestSpan(ctx context.Context, request *a2a.JSONRPCRequest) (context.Context, trace.Span) {
+	return trace.StartSpan(ctx, "a2a.request",
+		attribute.String("a2a.method", request.Method),
+		attribute.String("a2a.request_id", trace.RequestIDFromContext(ctx)),
+	)
+}
+
+// jsonrpcIDKey is the context key for the ID of the JSON-RPC request being handled.
+type jsonrpcIDKey struct{}
+
+// withJSONRPCID returns a copy of ctx carrying the ID of the JSON-RPC request being handled.
+func withJSONRPCID(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, jsonrpcIDKey{}, id)
+}
+
+// jsonrpcIDFromContext returns the ID of the JSON-RPC request carried by ctx, or nil if
+// there is none (e.g. for notifications).
+func jsonrpcIDFromContext(ctx context.Context) interface{} {
+	return ctx.Value(jsonrpcIDKey{})
+}
+
+// notificationMethods are the methods that may be sent as JSON-RPC notifications.
+// Methods whose only purpose is to return data (e.g. tasks/get) are excluded.
+var notificationMethods = map[string]bool{
+	"tasks/send":                    true,
+	"tasks/cancel":                  true,
+	"tasks/pushNotification/set":    true,
+	"tasks/pushNotification/delete": true,
+}
+
+// discardResponseWriter is an http.ResponseWriter that discards everything written to it.
+// It is used when processing notifications, which must not produce a response.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponseWriter) WriteHeader(statusCode int)  {}
+
+// routeA2ARequest routes a parsed JSON-RPC request to the handler for its method.
+func (s *Server) routeA2ARequest(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Disabled methods are treated as unknown
+	if s.disabled[request.Method] {
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+		return
+	}
+
+	// Route request to appropriate handler based on method
+	switch request.Method {
+	case "tasks/send":
+		s.handleTaskSend(ctx, w, r, request)
+	case "tasks/get":
+		s.handleTaskGet(ctx, w, r, request)
+	case "tasks/cancel":
+		s.handleTaskCancel(ctx, w, r, request)
+	case "sessions/cancel":
+		s.handleSessionCancel(ctx, w, r, request)
+	case "tasks/list":
+		s.handleTaskList(ctx, w, r, request)
+	case "tasks/pushNotification/set":
+		s.handleTaskPushNotificationSet(ctx, w, r, request)
+	case "tasks/pushNotification/get":
+		s.handleTaskPushNotificationGet(ctx, w, r, request)
+	case "tasks/pushNotification/delete":
+		s.handleTaskPushNotificationDelete(ctx, w, r, request)
+	case "agent/getCapabilities":
+		s.handleGetCapabilities(ctx, w, r, request)
+	case "skills/list":
+		s.handleSkillsList(ctx, w, r, request)
+	case "tasks/sendSubscribe":
+		// Streaming methods are also served here, as on the SSE endpoint
+		s.handleTaskSendSubscribe(ctx, w, r, request)
+	case "tasks/resubscribe":
+		s.handleTaskResubscribe(ctx, w, r, request)
+	case "tasks/listSubscribe":
+		s.handleTaskListSubscribe(ctx, w, r, request)
+	case "files/initUpload":
+		s.handleInitUpload(ctx, w, r, request)
+	case "files/uploadChunk":
+		s.handleUploadChunk(ctx, w, r, request)
+	case "files/completeUpload":
+		s.handleCompleteUpload(ctx, w, r, request)
+	default:
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+	}
+}
+
+// readJSONRPCRequest reads and validates a JSON-RPC request from the HTTP request.
+// Requests whose params exceed maxInputBytes are rejected (0 = unlimited).
+// If the request is invalid, an error response is written and false is returned.
+func readJSONRPCRequest(w http.ResponseWriter, r *http.Request, maxInputBytes int64) (*a2a.JSONRPCRequest, bool) {
 	// Only accept POST requests
 	if r.Method != http.MethodPost {
 		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Method not allowed"), nil)
-		return
+		return nil, false
+	}
+
+	// Stop reading bodies that cannot fit within the limit, leaving room for the envelope
+	if maxInputBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, maxInputBytes+maxEnvelopeBytes)
 	}
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSONRPCError(w, r, errInputTooLarge(maxInputBytes), nil)
+			return nil, false
+		}
 		writeJSONRPCError(w, r, a2a.ErrParseError(err), nil)
-		return
+		return nil, false
 	}
 
 	// Parse JSON-RPC request
 	var request a2a.JSONRPCRequest
 	if err := json.Unmarshal(body, &request); err != nil {
 		writeJSONRPCError(w, r, a2a.ErrParseError(err), nil)
-		return
+		return nil, false
+	}
+
+	// Validate request ID type (string, number, or null)
+	if !a2a.IsValidID(request.ID) {
+		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Invalid JSON-RPC id: must be a string, number, or null"), nil)
+		return nil, false
 	}
 
 	// Validate JSON-RPC version
 	if request.JSONRPC != "2.0" {
 		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Invalid JSON-RPC version"), request.ID)
-		return
+		return nil, false
 	}
 
-	// Create context with timeout
-	// TODO: Make timeout configurable
-	ctx := r.Context()
+	if maxInputBytes > 0 && int64(len(request.Params)) > maxInputBytes {
+		writeJSONRPCError(w, r, errInputTooLarge(maxInputBytes), request.ID)
+		return nil, false
+	}
 
-	// Route request to appropriate handler based on method
-	switch request.Method {
-	case "tasks/send":
-		s.handleTaskSend(ctx, w, r, &request)
-	case "tasks/get":
-		s.handleTaskGet(ctx, w, r, &request)
-	case "tasks/cancel":
-		s.handleTaskCancel(ctx, w, r, &request)
-	case "tasks/pushNotification/set":
-		s.handleTaskPushNotificationSet(ctx, w, r, &request)
-	case "tasks/pushNotification/get":
-		s.handleTaskPushNotificationGet(ctx, w, r, &request)
-	case "tasks/sendSubscribe":
-		// Redirect to SSE endpoint
-		http.Redirect(w, r, r.URL.Path+"/sse", http.StatusTemporaryRedirect)
-		return
-	case "tasks/resubscribe":
-		// Redirect to SSE endpoint
-		http.Redirect(w, r, r.URL.Path+"/sse", http.StatusTemporaryRedirect)
-		return
-	default:
-		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+	return &request, true
+}
+
+// maxEnvelopeBytes is how much larger than the params limit a request body may be, to
+// allow for the JSON-RPC envelope around the params.
+const maxEnvelopeBytes = 4096
+
+// errInputTooLarge returns the error for a request whose params exceed the input limit.
+func errInputTooLarge(maxInputBytes int64) *a2a.Error {
+	return a2a.ErrInvalidParams(fmt.Sprintf("Request exceeds the agent's maximum input size of %d bytes", maxInputBytes))
+}
+
+// maxInputBytes returns the input size limit advertised in the agent card (0 = unlimited).
+func (s *Server) maxInputBytes() int64 {
+	if card := s.agentCard(); card.Capabilities != nil {
+		return card.Capabilities.MaxInputBytes
 	}
+	return 0
 }
 
+// toA2AError returns err as an A2A error, or as an internal error if it does not wrap one.
+func toA2AError(err error) *a2a.Error {
+	var a2aErr *a2a.Error
+	if errors.As(err, &a2aErr) {
+		return a2aErr
+	}
+	return a2a.ErrInternalError(err)
+}
+
+// unmarshalParams decodes the params of a JSON-RPC request into v. Fields v has no place for
+// are rejected (see a2a.UnmarshalParams) unless the server accepts lenient params.
+func (s *Server) unmarshalParams(data json.RawMessage, v interface{}) error {
+	if s.config.LenientParams {
+		return json.Unmarshal(data, v)
+	}
+	return a2a.UnmarshalParams(data, v)
+}
+
+// idempotencyKeyHeader is the HTTP header carrying a tasks/send idempotency key.
+const idempotencyKeyHeader = "Idempotency-Key"
+
 // handleTaskSend handles the tasks/send method.
 func (s *Server) handleTaskSend(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
 	// Parse params
 	var params a2a.TaskSendParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	// The idempotency key may be sent as a header instead of a param
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" && params.IdempotencyKey == nil {
+		params.IdempotencyKey = &key
+	}
+
+	// Narrow the accepted output modes to those the agent supports
+	if err := s.negotiateOutputModes(&params); err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Give the task the content of the files it references by upload
+	if err := s.resolveUploads(ctx, &params); err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Validate only, without running the task
+	if params.DryRun != nil && *params.DryRun {
+		if err := s.validateTaskSend(ctx, &params); err != nil {
+			writeJSONRPCError(w, r, err, request.ID)
+			return
+		}
+		writeJSONRPCResponse(w, r, dryRunTask(&params), request.ID)
+		return
+	}
+
+	if !s.allowSkillTask(ctx, w, r, &params, request.ID) {
 		return
 	}
 
 	// Call TaskManager
 	task, err := s.taskManager.OnSendTask(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
@@ -94,26 +291,182 @@ func (s *Server) handleTaskSend(ctx context.Context, w http.ResponseWriter, r *h
 	writeJSONRPCResponse(w, r, task, request.ID)
 }
 
+// allowSkillTask applies the rate limit of the skill a task is sent to, if it has one. The
+// skill is resolved as for routing, from the skill ID or the "skillId" message metadata.
+// If the limit is exceeded, a rate-limit error is written and false is returned.
+func (s *Server) allowSkillTask(ctx context.Context, w http.ResponseWriter, r *http.Request, params *a2a.TaskSendParams, id interface{}) bool {
+	skillID := ""
+	if params.SkillID != nil {
+		skillID = *params.SkillID
+	}
+	skillID = a2a.RequestedSkillID(skillID, params.Message)
+	if skillID == "" {
+		return true
+	}
+	if ok, retryAfter := s.skillLimits.allow(ctx, skillID); !ok {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+		writeJSONRPCError(w, r, a2a.ErrRateLimitExceeded(), id)
+		return false
+	}
+	return true
+}
+
+// negotiateOutputModes narrows the output modes accepted by the client to those the agent
+// engine supports, in the client's order of preference, so the task handler sees only modes
+// it can produce. It returns an error if the agent supports none of them. Requests that
+// accept any mode, and agents that do not declare their output modalities, are not checked.
+// Tasks for a skill with its own agent engine are checked against that engine.
+func (s *Server) negotiateOutputModes(params *a2a.TaskSendParams) *a2a.Error {
+	engine := s.agentEngine(params.SkillID)
+	if len(params.AcceptedOutputModes) == 0 || engine == nil {
+		return nil
+	}
+	supported := engine.GetCapabilities().SupportedOutputModalities
+	if len(supported) == 0 {
+		return nil
+	}
+
+	var negotiated []string
+	seen := make(map[string]bool)
+	for _, accepted := range params.AcceptedOutputModes {
+		for _, mode := range supported {
+			if !seen[mode] && outputModeMatches(accepted, mode) {
+				seen[mode] = true
+				negotiated = append(negotiated, mode)
+			}
+		}
+	}
+	if len(negotiated) == 0 {
+		return a2a.ErrContentTypeNotSupported(params.AcceptedOutputModes, supported)
+	}
+
+	params.AcceptedOutputModes = negotiated
+	return nil
+}
+
+// agentEngine returns the agent engine that processes tasks for a skill (nil = no skill).
+func (s *Server) agentEngine(skillID *string) AgentEngine {
+	if skillID != nil {
+		if engine, ok := s.config.SkillAgentEngines[*skillID]; ok {
+			return engine
+		}
+	}
+	return s.config.AgentEngine
+}
+
+// outputModeMatches reports whether an accepted MIME type, which may be a wildcard such as
+// "image/*" or "*/*", matches a supported one. Parameters such as ";q=0.5" are ignored.
+func outputModeMatches(accepted, supported string) bool {
+	accepted = strings.ToLower(strings.TrimSpace(strings.Split(accepted, ";")[0]))
+	supported = strings.ToLower(strings.TrimSpace(strings.Split(supported, ";")[0]))
+
+	if accepted == "*/*" || accepted == supported {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(accepted, "/*"); ok {
+		return strings.HasPrefix(supported, prefix+"/")
+	}
+	return false
+}
+
+// validateTaskSend checks that a tasks/send request could be run: the message has content,
+// the mode suits the params, the requested skill exists and the message's input matches its
+// input schema, and the task being resumed exists and belongs to the request's session. The
+// skill is resolved as for routing, from the skill ID or the "skillId" message metadata.
+// Authentication is not checked here, as the auth validator has already accepted the request.
+func (s *Server) validateTaskSend(ctx context.Context, params *a2a.TaskSendParams) *a2a.Error {
+	if len(params.Message.Parts) == 0 {
+		return a2a.ErrValidation(a2a.FieldError{Field: "message.parts", Reason: "must contain at least one part"})
+	}
+
+	if _, err := taskSendMode(params); err != nil {
+		return toA2AError(err)
+	}
+
+	skillID := ""
+	if params.SkillID != nil {
+		skillID = *params.SkillID
+	}
+	if skillID = a2a.RequestedSkillID(skillID, params.Message); skillID != "" {
+		if err := schema.ValidateSkillInput(s.agentCard(), skillID, skillInput(params.Message)); err != nil {
+			return toA2AError(err)
+		}
+	}
+
+	if params.TaskID != nil {
+		existing, err := s.taskManager.OnGetTask(ctx, &a2a.TaskQueryParams{TaskID: *params.TaskID})
+		if err != nil {
+			return toA2AError(err)
+		}
+		if params.SessionID != nil && (existing.SessionID == nil || *existing.SessionID != *params.SessionID) {
+			return a2a.ErrValidation(a2a.FieldError{Field: "sessionId", Reason: fmt.Sprintf("does not match the session of task %s", *params.TaskID)})
+		}
+	}
+
+	return nil
+}
+
+// skillInput returns the input a message gives a skill, as SkillClient.Invoke sends it: the
+// data of its first data part, or otherwise its text.
+func skillInput(message a2a.Message) interface{} {
+	var texts []string
+	for _, part := range message.Parts {
+		switch p := part.(type) {
+		case a2a.DataPart:
+			return p.Data
+		case a2a.TextPart:
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// dryRunTask creates the synthetic task returned for a validated dry-run request.
+// It is not stored and the task handler is not invoked.
+func dryRunTask(params *a2a.TaskSendParams) *a2a.Task {
+	taskID := "dry-run"
+	if params.TaskID != nil {
+		taskID = *params.TaskID
+	}
+
+	now := time.Now()
+	return &a2a.Task{
+		ID:        taskID,
+		SessionID: params.SessionID,
+		Status: a2a.TaskStatus{
+			State:     a2a.TaskStateCompleted,
+			Timestamp: now,
+			Message: &a2a.Message{
+				Role:      a2a.RoleAgent,
+				Timestamp: now,
+				Parts: []a2a.Part{
+					a2a.TextPart{
+						Type: "text",
+						Text: "Dry run: request is valid and was not executed",
+					},
+				},
+			},
+		},
+		History:   []a2a.Message{params.Message},
+		Artifacts: []a2a.Artifact{},
+		Metadata:  map[string]interface{}{"dryRun": true},
+	}
+}
+
 // handleTaskGet handles the tasks/get method.
 func (s *Server) handleTaskGet(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
 	// Parse params
 	var params a2a.TaskQueryParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
 		return
 	}
 
 	// Call TaskManager
 	task, err := s.taskManager.OnGetTask(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
@@ -125,22 +478,15 @@ func (s *Server) handleTaskGet(ctx context.Context, w http.ResponseWriter, r *ht
 func (s *Server) handleTaskCancel(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
 	// Parse params
 	var params a2a.TaskIdParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
 		return
 	}
 
 	// Call TaskManager
 	task, err := s.taskManager.OnCancelTask(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
@@ -148,26 +494,83 @@ func (s *Server) handleTaskCancel(ctx context.Context, w http.ResponseWriter, r
 	writeJSONRPCResponse(w, r, task, request.ID)
 }
 
+// handleSessionCancel handles the sessions/cancel method.
+func (s *Server) handleSessionCancel(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	canceller, ok := s.taskManager.(SessionCanceller)
+	if !ok {
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+		return
+	}
+
+	// Parse params
+	var params a2a.SessionIdParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	// Call TaskManager
+	tasks, err := canceller.OnCancelSession(ctx, &params)
+	if err != nil {
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
+		return
+	}
+
+	// Write successful response
+	result := a2a.CancelSessionResult{Tasks: make([]a2a.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		result.Tasks = append(result.Tasks, *task)
+	}
+	writeJSONRPCResponse(w, r, result, request.ID)
+}
+
+// handleTaskList handles the tasks/list method.
+func (s *Server) handleTaskList(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	lister, ok := s.taskManager.(TaskLister)
+	if !ok {
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+		return
+	}
+
+	// Parse params, which are optional
+	var params a2a.ListTasksParams
+	if len(request.Params) > 0 {
+		if err := s.unmarshalParams(request.Params, &params); err != nil {
+			writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+			return
+		}
+	}
+
+	// Call TaskManager
+	result, err := lister.OnListTasks(ctx, &params)
+	if err != nil {
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
+		return
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, result, request.ID)
+}
+
 // handleTaskPushNotificationSet handles the tasks/pushNotification/set method.
 func (s *Server) handleTaskPushNotificationSet(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Reject push notifications if the agent does not support them
+	if card := s.agentCard(); card.Capabilities == nil || !card.Capabilities.SupportsPushNotification {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("push notifications"), request.ID)
+		return
+	}
+
 	// Parse params
 	var params a2a.TaskPushNotificationConfigParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
 		return
 	}
 
 	// Call TaskManager
 	config, err := s.taskManager.OnSetTaskPushNotification(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
@@ -179,22 +582,15 @@ func (s *Server) handleTaskPushNotificationSet(ctx context.Context, w http.Respo
 func (s *Server) handleTaskPushNotificationGet(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
 	// Parse params
 	var params a2a.TaskIdParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
 		return
 	}
 
 	// Call TaskManager
 	config, err := s.taskManager.OnGetTaskPushNotification(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
@@ -202,6 +598,174 @@ func (s *Server) handleTaskPushNotificationGet(ctx context.Context, w http.Respo
 	writeJSONRPCResponse(w, r, config, request.ID)
 }
 
+// handleTaskPushNotificationDelete handles the tasks/pushNotification/delete method.
+// The result echoes the task ID whose configuration was removed.
+func (s *Server) handleTaskPushNotificationDelete(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Parse params
+	var params a2a.TaskIdParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	// Call TaskManager
+	if err := s.taskManager.OnDeleteTaskPushNotification(ctx, &params); err != nil {
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
+		return
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, params, request.ID)
+}
+
+// handleGetCapabilities handles the agent/getCapabilities method.
+// It lets clients probe liveness and negotiate capabilities over the JSON-RPC channel.
+func (s *Server) handleGetCapabilities(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	card := s.agentCard()
+	result := a2a.AgentCapabilitiesResult{
+		A2AVersion: card.A2AVersion,
+		AgentID:    card.ID,
+	}
+	if card.Capabilities != nil {
+		result.Capabilities = *card.Capabilities
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, result, request.ID)
+}
+
+// handleSkillsList handles the skills/list method.
+func (s *Server) handleSkillsList(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Parse params (optional; no params lists all skills)
+	var filter a2a.SkillFilter
+	if len(request.Params) > 0 {
+		if err := s.unmarshalParams(request.Params, &filter); err != nil {
+			writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+			return
+		}
+	}
+
+	// Write successful response
+	result := a2a.SkillsListResult{
+		Skills: a2a.FilterSkills(s.agentCard().Skills, filter),
+	}
+	writeJSONRPCResponse(w, r, result, request.ID)
+}
+
+// handleInitUpload handles the files/initUpload method.
+func (s *Server) handleInitUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Uploads are kept in the artifact store, so they need one
+	if s.uploads == nil {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("file uploads"), request.ID)
+		return
+	}
+
+	// Parse params
+	var params a2a.InitUploadParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	status, err := s.uploads.init(&params, s.maxInputBytes())
+	if err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, status, request.ID)
+}
+
+// handleUploadChunk handles the files/uploadChunk method.
+func (s *Server) handleUploadChunk(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	if s.uploads == nil {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("file uploads"), request.ID)
+		return
+	}
+
+	// Parse params
+	var params a2a.UploadChunkParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	status, err := s.uploads.putChunk(ctx, &params)
+	if err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, status, request.ID)
+}
+
+// handleCompleteUpload handles the files/completeUpload method. The result is a FilePart
+// referencing the uploaded file, for use in a later tasks/send.
+func (s *Server) handleCompleteUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	if s.uploads == nil {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("file uploads"), request.ID)
+		return
+	}
+
+	// Parse params
+	var params a2a.CompleteUploadParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	part, err := s.uploads.complete(ctx, &params)
+	if err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Write successful response
+	writeJSONRPCResponse(w, r, part, request.ID)
+}
+
+// resolveUploads replaces the file parts of a task's message that reference completed
+// uploads with the uploaded content, so the task handler receives it inline. The resolved
+// message may not exceed the agent's maximum input size.
+func (s *Server) resolveUploads(ctx context.Context, params *a2a.TaskSendParams) *a2a.Error {
+	if s.uploads == nil {
+		return nil
+	}
+
+	var total int64
+	for i, part := range params.Message.Parts {
+		file, ok := part.(a2a.FilePart)
+		if !ok || file.URI == nil {
+			continue
+		}
+		data, isUpload, err := s.uploads.resolve(ctx, *file.URI)
+		if !isUpload {
+			continue
+		}
+		if err != nil {
+			if err.Code == a2a.CodeUploadNotFound {
+				return a2a.ErrValidation(a2a.FieldError{Field: fmt.Sprintf("message.parts[%d].uri", i), Reason: "refers to an unknown or expired upload"})
+			}
+			return err
+		}
+
+		total += int64(len(data))
+		if maxInputBytes := s.maxInputBytes(); maxInputBytes > 0 && total > maxInputBytes {
+			return errInputTooLarge(maxInputBytes)
+		}
+		file.URI = nil
+		file.Content = &a2a.FileContent{
+			Encoding: "base64",
+			Data:     base64.StdEncoding.EncodeToString(data),
+			Size:     int64(len(data)),
+		}
+		params.Message.Parts[i] = file
+	}
+	return nil
+}
+
 // writeJSONRPCResponse writes a successful JSON-RPC response.
 func writeJSONRPCResponse(w http.ResponseWriter, r *http.Request, result interface{}, id interface{}) {
 	response := a2a.JSONRPCResponse{
@@ -240,20 +804,8 @@ func writeJSONRPCError(w http.ResponseWriter, r *http.Request, err *a2a.Error, i
 		return
 	}
 
-	// Determine HTTP status code based on error code
-	httpStatus := http.StatusOK // Default for valid JSON-RPC errors
-	if err.Code == a2a.CodeAuthenticationRequired || err.Code == a2a.CodeAuthenticationFailed {
-		httpStatus = http.StatusUnauthorized
-	} else if err.Code == a2a.CodeMethodNotFound {
-		httpStatus = http.StatusNotFound
-	} else if err.Code == a2a.CodeInvalidRequest || err.Code == a2a.CodeInvalidParams {
-		httpStatus = http.StatusBadRequest
-	} else if err.Code == a2a.CodeRateLimitExceeded {
-		httpStatus = http.StatusTooManyRequests
-	}
-
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(httpStatus)
+	w.WriteHeader(err.HTTPStatus())
 	w.Write(jsonResp)
 }
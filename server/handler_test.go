@@ -0,0 +1,1203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/pkg/trace"
+)
+
+// postJSONRPC posts a raw JSON-RPC body to the given URL and returns the response and its body.
+func postJSONRPC(t *testing.T, url, body string) (*http.Response, []byte) {
+	t.Helper()
+
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	return resp, respBody
+}
+
+func TestHandleGetCapabilities(t *testing.T) {
+	capabilities := &a2a.AgentCapabilities{
+		SupportsStreaming:        true,
+		SupportsPushNotification: true,
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(&a2a.AgentCard{
+		A2AVersion:   "1.0",
+		ID:           "capable-agent",
+		Name:         "Capable Agent",
+		Capabilities: capabilities,
+	}))
+
+	resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"agent/getCapabilities","id":1}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	var response struct {
+		Result a2a.AgentCapabilitiesResult `json:"result"`
+		Error  *a2a.JSONRPCError           `json:"error"`
+		ID     interface{}                 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %+v", response.Error)
+	}
+	if response.ID != float64(1) {
+		t.Errorf("Expected id 1, got %v", response.ID)
+	}
+	if response.Result.A2AVersion != "1.0" || response.Result.AgentID != "capable-agent" {
+		t.Errorf("Unexpected result: %+v", response.Result)
+	}
+	// The server advertises where its streaming methods are served
+	want := *capabilities
+	want.StreamingEndpoint = DefaultSSEPath
+	want.StreamingProtocol = a2a.StreamingProtocolSSE
+	if response.Result.Capabilities != want {
+		t.Errorf("Expected capabilities %+v, got %+v", want, response.Result.Capabilities)
+	}
+}
+
+func TestStreamingRejectedWhenUnsupported(t *testing.T) {
+	noStreaming := &a2a.AgentCard{
+		A2AVersion:   "1.0",
+		ID:           "no-streaming",
+		Name:         "No Streaming",
+		Capabilities: &a2a.AgentCapabilities{SupportsStreaming: false},
+	}
+	s, _ := newTestServer(t, newMockHandler(), WithAgentCard(noStreaming))
+
+	for _, method := range []string{"tasks/sendSubscribe", "tasks/resubscribe"} {
+		t.Run(method, func(t *testing.T) {
+			body := `{"jsonrpc":"2.0","method":"` + method + `","id":"1","params":{"taskId":"task-1","message":{"role":"user","parts":[]}}}`
+			req := httptest.NewRequest(http.MethodPost, "/a2a/sse", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			s.handleSSERequest(rec, req)
+
+			var response a2a.JSONRPCResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+			}
+			if response.Error == nil || response.Error.Code != a2a.CodeOperationNotSupported {
+				t.Fatalf("Expected operation not supported error, got %+v", response.Error)
+			}
+			if rec.Code != http.StatusNotImplemented {
+				t.Errorf("Expected status 501, got %d", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct == "text/event-stream" {
+				t.Error("Expected no SSE stream to be started")
+			}
+		})
+	}
+}
+
+func TestPushNotificationSetRejectedWhenUnsupported(t *testing.T) {
+	noPush := &a2a.AgentCard{
+		A2AVersion:   "1.0",
+		ID:           "no-push",
+		Name:         "No Push",
+		Capabilities: &a2a.AgentCapabilities{SupportsPushNotification: false},
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(noPush))
+
+	resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/pushNotification/set","id":"1","params":{"taskId":"task-1","url":"http://example.com/hook"}}`)
+
+	var response a2a.JSONRPCResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.Error == nil || response.Error.Code != a2a.CodeOperationNotSupported {
+		t.Fatalf("Expected operation not supported error, got %+v", response.Error)
+	}
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleA2ARequest_IDValidation(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	tests := []struct {
+		name      string
+		id        string
+		wantError bool
+		wantID    interface{}
+	}{
+		{name: "string", id: `"abc"`, wantID: "abc"},
+		{name: "number", id: `42`, wantID: float64(42)},
+		{name: "null", id: `null`, wantID: nil},
+		{name: "object", id: `{"key":"value"}`, wantError: true, wantID: nil},
+		{name: "array", id: `[1,2]`, wantError: true, wantID: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"agent/getCapabilities","id":`+tt.id+`}`)
+
+			var response a2a.JSONRPCResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+
+			if tt.wantError {
+				if response.Error == nil || response.Error.Code != a2a.CodeInvalidRequest {
+					t.Fatalf("Expected invalid request error, got %+v", response.Error)
+				}
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Errorf("Expected status 400, got %d", resp.StatusCode)
+				}
+			} else if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+
+			if response.ID != tt.wantID {
+				t.Errorf("Expected id %v, got %v", tt.wantID, response.ID)
+			}
+		})
+	}
+}
+
+func TestHandleA2ARequest_Notification(t *testing.T) {
+	s, baseURL := newTestServer(t, newMockHandler())
+
+	// A notification is processed but produces no response body
+	resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", resp.StatusCode)
+	}
+	if len(body) != 0 {
+		t.Errorf("Expected empty body for notification, got %q", body)
+	}
+
+	tm := s.taskManager.(*InMemoryTaskManager)
+	tm.mu.RLock()
+	numTasks := len(tm.tasks)
+	tm.mu.RUnlock()
+	if numTasks != 1 {
+		t.Errorf("Expected notification to create 1 task, got %d", numTasks)
+	}
+
+	// The same request with an id gets a normal response
+	resp, body = postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	var response struct {
+		Result a2a.Task    `json:"result"`
+		ID     interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.ID != "1" || response.Result.ID == "" {
+		t.Errorf("Expected a task response with id 1, got %+v", response)
+	}
+}
+
+func TestHandleA2ARequest_NotificationNotAllowed(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/get","params":{"taskId":"task-1"}}`)
+
+	var response a2a.JSONRPCResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.Error == nil || response.Error.Code != a2a.CodeInvalidRequest {
+		t.Fatalf("Expected invalid request error, got %+v", response.Error)
+	}
+}
+
+func TestTraceHeaderPropagation(t *testing.T) {
+	// Downstream agent records the headers of the delegated request
+	downstreamHeaders := make(chan http.Header, 1)
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamHeaders <- r.Header.Clone()
+		writeJSONRPCResponse(w, r, a2a.Task{ID: "downstream-task"}, "1")
+	}))
+	defer downstream.Close()
+
+	downstreamClient, err := client.NewClient(client.WithBaseURL(downstream.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// Upstream handler delegates to the downstream agent using its context
+	handlerTraceHeaders := make(chan map[string]string, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		handlerTraceHeaders <- taskCtx.TraceHeaders
+		if _, err := downstreamClient.SendTask(ctx, &a2a.TaskSendParams{Message: taskCtx.UserMessage}); err != nil {
+			t.Errorf("Delegated SendTask failed: %v", err)
+		}
+		updates := make(chan task.YieldUpdate)
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler)
+
+	tests := []struct {
+		name      string
+		requestID string
+	}{
+		{name: "incoming request ID", requestID: "req-123"},
+		{name: "generated request ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`
+			req, _ := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			if tt.requestID != "" {
+				req.Header.Set(trace.RequestIDHeader, tt.requestID)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+			resp.Body.Close()
+
+			requestID := resp.Header.Get(trace.RequestIDHeader)
+			if requestID == "" {
+				t.Fatal("Expected response to carry a request ID")
+			}
+			if tt.requestID != "" && requestID != tt.requestID {
+				t.Errorf("Expected request ID %q, got %q", tt.requestID, requestID)
+			}
+
+			select {
+			case headers := <-handlerTraceHeaders:
+				if got := headers[http.CanonicalHeaderKey(trace.RequestIDHeader)]; got != requestID {
+					t.Errorf("Expected task context request ID %q, got %q", requestID, got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Handler was not called")
+			}
+
+			select {
+			case headers := <-downstreamHeaders:
+				if got := headers.Get(trace.RequestIDHeader); got != requestID {
+					t.Errorf("Expected downstream request ID %q, got %q", requestID, got)
+				}
+				if got := headers.Get("traceparent"); got != req.Header.Get("traceparent") {
+					t.Errorf("Expected downstream traceparent %q, got %q", req.Header.Get("traceparent"), got)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Downstream agent was not called")
+			}
+		})
+	}
+}
+
+func TestHandleSkillsList(t *testing.T) {
+	skills := []a2a.AgentSkill{
+		{ID: "search", Name: "Web Search", Tags: []string{"search", "web"}},
+		{ID: "summarise", Name: "Summarise Text", Tags: []string{"text"}},
+		{ID: "translate", Name: "Translate Text", Tags: []string{"text", "language"}},
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(&a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "skilled-agent",
+		Name:       "Skilled Agent",
+		Skills:     skills,
+	}))
+
+	tests := []struct {
+		name    string
+		params  string
+		wantIDs []string
+	}{
+		{name: "no params", params: ``, wantIDs: []string{"search", "summarise", "translate"}},
+		{name: "empty filter", params: `,"params":{}`, wantIDs: []string{"search", "summarise", "translate"}},
+		{name: "by tag", params: `,"params":{"tag":"TEXT"}`, wantIDs: []string{"summarise", "translate"}},
+		{name: "by name", params: `,"params":{"name":"search"}`, wantIDs: []string{"search"}},
+		{name: "by tag and name", params: `,"params":{"tag":"text","name":"translate"}`, wantIDs: []string{"translate"}},
+		{name: "no match", params: `,"params":{"tag":"missing"}`, wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"skills/list","id":1`+tt.params+`}`)
+
+			var response struct {
+				Result a2a.SkillsListResult `json:"result"`
+				Error  *a2a.JSONRPCError    `json:"error"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+
+			ids := make([]string, 0, len(response.Result.Skills))
+			for _, skill := range response.Result.Skills {
+				ids = append(ids, skill.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("Expected skills %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestHandleTaskSend_DryRun(t *testing.T) {
+	var invocations int32
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		atomic.AddInt32(&invocations, 1)
+		updates := make(chan task.YieldUpdate)
+		close(updates)
+		return updates, nil
+	}
+
+	s, baseURL := newTestServer(t, handler, WithAgentCard(&a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "dry-run-agent",
+		Name:       "Dry Run Agent",
+		Skills: []a2a.AgentSkill{
+			{ID: "echo", Name: "Echo"},
+			{
+				ID:   "weather",
+				Name: "Weather",
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"city"},
+				},
+			},
+		},
+	}))
+
+	// A task to resume, in its own session
+	sessionID := "session-1"
+	tm := s.taskManager.(*InMemoryTaskManager)
+	tm.mu.Lock()
+	tm.storeTask(&a2a.Task{
+		ID:        "task-1",
+		SessionID: &sessionID,
+		Status:    a2a.TaskStatus{State: a2a.TaskStateInputRequired, Timestamp: time.Now()},
+	})
+	tm.mu.Unlock()
+
+	tests := []struct {
+		name     string
+		params   string
+		wantCode int // 0 for success
+	}{
+		{
+			name:   "valid",
+			params: `{"dryRun":true,"skillId":"echo","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`,
+		},
+		{
+			name:     "unknown skill",
+			params:   `{"dryRun":true,"skillId":"missing","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`,
+			wantCode: a2a.CodeSkillNotFound,
+		},
+		{
+			name:   "valid input",
+			params: `{"dryRun":true,"skillId":"weather","message":{"role":"user","parts":[{"type":"data","data":{"city":"Sydney"}}]}}`,
+		},
+		{
+			name:     "input not matching the skill schema",
+			params:   `{"dryRun":true,"skillId":"weather","message":{"role":"user","parts":[{"type":"data","data":{"town":"Sydney"}}]}}`,
+			wantCode: a2a.CodeInvalidParams,
+		},
+		{
+			name:     "text input for a data skill",
+			params:   `{"dryRun":true,"skillId":"weather","message":{"role":"user","parts":[{"type":"text","text":"Sydney"}]}}`,
+			wantCode: a2a.CodeInvalidParams,
+		},
+		{
+			name:     "skill in metadata with invalid input",
+			params:   `{"dryRun":true,"message":{"role":"user","metadata":{"skillId":"weather"},"parts":[{"type":"data","data":{}}]}}`,
+			wantCode: a2a.CodeInvalidParams,
+		},
+		{
+			name:     "unknown skill in metadata",
+			params:   `{"dryRun":true,"message":{"role":"user","metadata":{"skillId":"missing"},"parts":[{"type":"text","text":"hello"}]}}`,
+			wantCode: a2a.CodeSkillNotFound,
+		},
+		{
+			name:     "unknown task",
+			params:   `{"dryRun":true,"taskId":"missing","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`,
+			wantCode: a2a.CodeTaskNotFound,
+		},
+		{
+			name:   "resume in the task's session",
+			params: `{"dryRun":true,"taskId":"task-1","sessionId":"session-1","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`,
+		},
+		{
+			name:     "resume in another session",
+			params:   `{"dryRun":true,"taskId":"task-1","sessionId":"session-2","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`,
+			wantCode: a2a.CodeInvalidParams,
+		},
+		{
+			name:     "empty message",
+			params:   `{"dryRun":true,"message":{"role":"user","parts":[]}}`,
+			wantCode: a2a.CodeInvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":`+tt.params+`}`)
+
+			var response struct {
+				Result *a2a.Task         `json:"result"`
+				Error  *a2a.JSONRPCError `json:"error"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+
+			if tt.wantCode != 0 {
+				if response.Error == nil || response.Error.Code != tt.wantCode {
+					t.Fatalf("Expected error code %d, got %+v", tt.wantCode, response.Error)
+				}
+				return
+			}
+
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+			if response.Result.Status.State != a2a.TaskStateCompleted {
+				t.Errorf("Expected completed state, got %s", response.Result.Status.State)
+			}
+			if dryRun, _ := response.Result.Metadata["dryRun"].(bool); !dryRun {
+				t.Error("Expected dry run task to be marked in metadata")
+			}
+		})
+	}
+
+	// Give any (incorrectly) started handler a chance to run
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&invocations); n != 0 {
+		t.Errorf("Expected handler not to be invoked, got %d invocations", n)
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if len(tm.tasks) != 1 {
+		t.Errorf("Expected only the task to resume to be stored, got %d tasks", len(tm.tasks))
+	}
+}
+
+func TestHandleTaskSend_DryRunRequiresAuthentication(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(&a2a.AgentCard{
+		A2AVersion:     "1.0",
+		ID:             "test-agent",
+		Name:           "Test Agent",
+		Authentication: []a2a.AgentAuthentication{{Type: "bearer"}},
+	}), WithAuthValidator(SimpleTokenValidator("secret")))
+
+	resp, _ := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"dryRun":true,"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an unauthenticated dry run to be rejected with status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestHandleTaskPushNotificationDelete(t *testing.T) {
+	s, baseURL := newTestServer(t, newMockHandler())
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	// Deleting when no config is set is a no-op
+	if err := c.DeleteTaskPushNotification(ctx, created.ID); err != nil {
+		t.Fatalf("Expected deleting a missing config to succeed, got %v", err)
+	}
+
+	if _, err := c.SetTaskPushNotification(ctx, &a2a.TaskPushNotificationConfigParams{
+		TaskID: created.ID,
+		URL:    "http://example.com/push",
+	}); err != nil {
+		t.Fatalf("SetTaskPushNotification failed: %v", err)
+	}
+
+	if err := c.DeleteTaskPushNotification(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteTaskPushNotification failed: %v", err)
+	}
+	if _, err := s.taskManager.OnGetTaskPushNotification(ctx, &a2a.TaskIdParams{TaskID: created.ID}); err == nil {
+		t.Error("Expected push notification config to be removed")
+	}
+
+	// Unknown tasks are still reported
+	err = c.DeleteTaskPushNotification(ctx, "missing")
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("code=%d", a2a.CodeTaskNotFound)) {
+		t.Errorf("Expected task not found error, got %v", err)
+	}
+}
+
+func TestHandleTaskSend_IdempotencyKey(t *testing.T) {
+	var calls int32
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		atomic.AddInt32(&calls, 1)
+		updates := make(chan task.YieldUpdate)
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler)
+
+	send := func(key string) string {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`
+		req, err := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response struct {
+			Result a2a.Task          `json:"result"`
+			Error  *a2a.JSONRPCError `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %+v", response.Error)
+		}
+		return response.Result.ID
+	}
+
+	first := send("retry-key")
+	if second := send("retry-key"); second != first {
+		t.Errorf("Expected repeated key to return task %s, got %s", first, second)
+	}
+	if other := send("other-key"); other == first {
+		t.Error("Expected a different key to create a new task")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected the handler to run twice, ran %d times", n)
+	}
+}
+
+func TestDisabledMethods(t *testing.T) {
+	s, baseURL := newTestServer(t, newMockHandler(), WithDisabledMethods("tasks/cancel", "tasks/sendSubscribe"))
+
+	c, err := client.NewClient(client.WithBaseURL(strings.TrimSuffix(baseURL, "/")))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	created, err := c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	// Enabled methods still work
+	if _, err := c.GetTask(context.Background(), created.ID); err != nil {
+		t.Errorf("Expected tasks/get to work, got: %v", err)
+	}
+
+	// Disabled methods are not found, even though the server implements them
+	_, err = c.CancelTask(context.Background(), created.ID)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("code=%d", a2a.CodeMethodNotFound)) {
+		t.Errorf("Expected method not found for tasks/cancel, got: %v", err)
+	}
+
+	body := `{"jsonrpc":"2.0","method":"tasks/sendSubscribe","id":"1","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`
+	rec := httptest.NewRecorder()
+	s.handleSSERequest(rec, httptest.NewRequest(http.MethodPost, "/a2a/sse", strings.NewReader(body)))
+
+	var response a2a.JSONRPCResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	if response.Error == nil || response.Error.Code != a2a.CodeMethodNotFound {
+		t.Errorf("Expected method not found for tasks/sendSubscribe, got %+v", response.Error)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+// multimodalAgentEngine is a stub engine that declares the output modalities it supports.
+type multimodalAgentEngine struct {
+	stubAgentEngine
+}
+
+func (multimodalAgentEngine) GetCapabilities() AgentCapabilities {
+	return AgentCapabilities{
+		SupportsStreaming:         true,
+		SupportedOutputModalities: []string{"text/plain", "image/png"},
+	}
+}
+
+func TestHandleTaskSend_AcceptedOutputModes(t *testing.T) {
+	outputModes := make(chan []string, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		outputModes <- taskCtx.OutputModes
+		updates := make(chan task.YieldUpdate)
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler, WithAgentEngine(multimodalAgentEngine{}))
+
+	tests := []struct {
+		name     string
+		accepted string
+		want     []string
+		wantCode int // 0 for success
+	}{
+		{
+			name:     "supported",
+			accepted: `["application/json","image/*","text/plain"]`,
+			want:     []string{"image/png", "text/plain"},
+		},
+		{
+			name:     "unsupported",
+			accepted: `["audio/mpeg"]`,
+			wantCode: a2a.CodeContentTypeNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"acceptedOutputModes":`+
+				tt.accepted+`,"message":{"role":"user","parts":[{"type":"text","text":"draw a cat"}]}}}`)
+
+			var response struct {
+				Result *a2a.Task         `json:"result"`
+				Error  *a2a.JSONRPCError `json:"error"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+
+			if tt.wantCode != 0 {
+				if response.Error == nil || response.Error.Code != tt.wantCode {
+					t.Fatalf("Expected error code %d, got %+v", tt.wantCode, response.Error)
+				}
+				data, _ := response.Error.Data.(map[string]interface{})
+				if supported, _ := data["supportedOutputModes"].([]interface{}); len(supported) != 2 {
+					t.Errorf("Expected supported output modes in error data, got %+v", response.Error.Data)
+				}
+				return
+			}
+
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+			select {
+			case got := <-outputModes:
+				if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+					t.Errorf("Expected output modes %v, got %v", tt.want, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Timed out waiting for the handler to be invoked")
+			}
+		})
+	}
+}
+
+func TestHandleA2ARequest_MaxInputBytes(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler(), WithAgentCard(&a2a.AgentCard{
+		A2AVersion:   "1.0",
+		ID:           "limited-agent",
+		Name:         "Limited Agent",
+		Capabilities: &a2a.AgentCapabilities{MaxInputBytes: 200},
+	}))
+
+	tests := []struct {
+		name     string
+		text     string
+		wantCode int // 0 for success
+	}{
+		{name: "within limit", text: "hello"},
+		{name: "params over limit", text: strings.Repeat("x", 300), wantCode: a2a.CodeInvalidParams},
+		{name: "body over limit", text: strings.Repeat("x", 10000), wantCode: a2a.CodeInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":`+
+				`{"message":{"role":"user","parts":[{"type":"text","text":"`+tt.text+`"}]}}}`)
+
+			var response struct {
+				Result *a2a.Task         `json:"result"`
+				Error  *a2a.JSONRPCError `json:"error"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+
+			if tt.wantCode == 0 {
+				if response.Error != nil {
+					t.Fatalf("Unexpected error: %+v", response.Error)
+				}
+				return
+			}
+			if response.Error == nil || response.Error.Code != tt.wantCode {
+				t.Fatalf("Expected error code %d, got %+v", tt.wantCode, response.Error)
+			}
+			if !strings.Contains(response.Error.Message, "200 bytes") {
+				t.Errorf("Expected the error to mention the limit, got %q", response.Error.Message)
+			}
+		})
+	}
+}
+
+func TestHandleTaskSend_SkillRateLimits(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler(),
+		WithAgentCard(&a2a.AgentCard{
+			A2AVersion: "1.0",
+			ID:         "limited-agent",
+			Name:       "Limited Agent",
+			Skills:     []a2a.AgentSkill{{ID: "cheap", Name: "Cheap"}, {ID: "expensive", Name: "Expensive"}},
+		}),
+		WithSkillRateLimits(map[string]Limit{
+			"cheap":     {Requests: 3, Window: time.Hour},
+			"expensive": {Requests: 1, Window: time.Hour},
+		}),
+	)
+
+	send := func(skillID string) (*http.Response, *a2a.JSONRPCError) {
+		resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":`+
+			`{"skillId":"`+skillID+`","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`)
+		var response struct {
+			Error *a2a.JSONRPCError `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", body, err)
+		}
+		return resp, response.Error
+	}
+
+	for skillID, allowed := range map[string]int{"cheap": 3, "expensive": 1} {
+		for i := 0; i < allowed; i++ {
+			if _, rpcErr := send(skillID); rpcErr != nil {
+				t.Fatalf("Expected task %d for skill %s to be allowed, got %+v", i+1, skillID, rpcErr)
+			}
+		}
+
+		resp, rpcErr := send(skillID)
+		if rpcErr == nil || rpcErr.Code != a2a.CodeRateLimitExceeded {
+			t.Fatalf("Expected task %d for skill %s to be rate limited, got %+v", allowed+1, skillID, rpcErr)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header")
+		}
+	}
+
+	// A skill requested through the message metadata is limited as it is routed
+	resp, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":`+
+		`{"message":{"role":"user","parts":[{"type":"text","text":"hello"}],"metadata":{"skillId":"expensive"}}}}`)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected a task for a skill given in the metadata to be rate limited, got status %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestHandleTaskSend_ValidationErrorData(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	tests := []struct {
+		name      string
+		params    string
+		wantField string
+	}{
+		{name: "malformed part", params: `{"message":{"role":"user","parts":[{"type":"text","text":5}]}}`, wantField: "message.parts[0].text"},
+		{name: "empty message", params: `{"dryRun":true,"message":{"role":"user","parts":[]}}`, wantField: "message.parts"},
+		{name: "unknown field", params: `{"dryRun":true,"sessionID":"s1","mesage":{"role":"user","parts":[]}}`, wantField: "mesage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":`+tt.params+`}`)
+
+			var response struct {
+				Error *struct {
+					Code int `json:"code"`
+					Data struct {
+						Fields []a2a.FieldError `json:"fields"`
+					} `json:"data"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", body, err)
+			}
+			if response.Error == nil || response.Error.Code != a2a.CodeInvalidParams {
+				t.Fatalf("Expected an invalid params error, got %s", body)
+			}
+			if fields := response.Error.Data.Fields; len(fields) != 1 || fields[0].Field != tt.wantField {
+				t.Errorf("Expected the error data to name field %q, got %s", tt.wantField, body)
+			}
+		})
+	}
+
+	t.Run("client", func(t *testing.T) {
+		c, err := client.NewClient(client.WithBaseURL(baseURL))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		dryRun := true
+		_, err = c.SendTask(context.Background(), &a2a.TaskSendParams{
+			DryRun:  &dryRun,
+			Message: a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{}},
+		})
+
+		var a2aErr *a2a.Error
+		if !errors.As(err, &a2aErr) {
+			t.Fatalf("Expected an *a2a.Error, got %v", err)
+		}
+		if fields := a2aErr.ValidationErrors(); len(fields) != 1 || fields[0].Field != "message.parts" {
+			t.Errorf("Expected a validation error for message.parts, got %+v", fields)
+		}
+	})
+}
+
+func TestHandleTaskSend_LenientParams(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler(), WithLenientParams(true))
+
+	// A field from a newer version of the protocol is ignored
+	_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/send","id":"1","params":{"futureField":true,"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`)
+
+	var response struct {
+		Result *a2a.Task  `json:"result"`
+		Error  *a2a.Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.Error != nil || response.Result == nil {
+		t.Errorf("Expected the task to be created, got %s", body)
+	}
+}
+
+func TestHandleTaskPushNotificationSet_FieldNames(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	created, err := c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	// The callback URL is sent as "url"; other spellings are rejected rather than ignored
+	_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/pushNotification/set","id":"1","params":{"taskId":"`+created.ID+`","url":"http://example.com/push"}}`)
+	if strings.Contains(string(body), `"error"`) {
+		t.Fatalf("Expected the push notification config to be set, got %s", body)
+	}
+
+	_, body = postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/pushNotification/set","id":"2","params":{"taskId":"`+created.ID+`","callbackUrl":"http://example.com/push"}}`)
+	var response struct {
+		Error *a2a.JSONRPCError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.Error == nil || response.Error.Code != a2a.CodeInvalidParams || !strings.Contains(response.Error.Message, "callbackUrl") {
+		t.Errorf("Expected an invalid params error naming callbackUrl, got %s", body)
+	}
+}
+
+func TestHandleTaskPushNotification_RoundTrip(t *testing.T) {
+	s, baseURL := newTestServer(t, newMockHandler())
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	includeTask, includeArtifacts, replay := false, true, false
+	params := &a2a.TaskPushNotificationConfigParams{
+		TaskID: created.ID,
+		URL:    "http://example.com/push",
+		Authentication: &a2a.AuthenticationInfo{
+			Type:          "header",
+			Configuration: map[string]interface{}{"headerName": "X-API-Key", "value": "secret"},
+		},
+		IncludeTaskData:   &includeTask,
+		IncludeArtifacts:  &includeArtifacts,
+		Headers:           map[string]string{"X-Tenant": "acme"},
+		ReplayOnSubscribe: &replay,
+	}
+	want := &a2a.PushNotificationConfig{
+		TaskID:            params.TaskID,
+		URL:               params.URL,
+		Authentication:    params.Authentication,
+		IncludeTaskData:   params.IncludeTaskData,
+		IncludeArtifacts:  params.IncludeArtifacts,
+		Headers:           params.Headers,
+		ReplayOnSubscribe: params.ReplayOnSubscribe,
+	}
+
+	set, err := c.SetTaskPushNotification(ctx, params)
+	if err != nil {
+		t.Fatalf("SetTaskPushNotification failed: %v", err)
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("Set returned %+v, want %+v", set, want)
+	}
+
+	stored, err := s.taskManager.OnGetTaskPushNotification(ctx, &a2a.TaskIdParams{TaskID: created.ID})
+	if err != nil {
+		t.Fatalf("Failed to get the stored config: %v", err)
+	}
+	if !reflect.DeepEqual(stored, want) {
+		t.Errorf("Server stored %+v, want %+v", stored, want)
+	}
+
+	got, err := c.GetTaskPushNotification(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetTaskPushNotification failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get returned %+v, want %+v", got, want)
+	}
+}
+
+func TestHandleTaskList_Pagination(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	var sent []string
+	for i := 0; i < 5; i++ {
+		created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+			Message: newTextMessage(a2a.RoleUser, fmt.Sprintf("task %d", i)),
+		})
+		if err != nil {
+			t.Fatalf("SendTask failed: %v", err)
+		}
+		sent = append(sent, created.ID)
+	}
+
+	// More tasks than one page holds take several requests
+	var listed []string
+	var pages int
+	params := &a2a.ListTasksParams{PageSize: 2}
+	for {
+		result, err := c.ListTasks(ctx, params)
+		if err != nil {
+			t.Fatalf("ListTasks failed: %v", err)
+		}
+		pages++
+		for _, taskObj := range result.Tasks {
+			listed = append(listed, taskObj.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		params.Cursor = result.NextCursor
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if !reflect.DeepEqual(listed, sent) {
+		t.Errorf("Expected tasks %v, got %v", sent, listed)
+	}
+
+	// The stream sends the same pages, then new tasks as they are created
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pageChan, errChan := c.ListTasksSubscribe(streamCtx, &a2a.ListTasksParams{PageSize: 2})
+
+	var streamed []string
+	next := func() {
+		t.Helper()
+		select {
+		case page, ok := <-pageChan:
+			if !ok {
+				t.Fatalf("Stream closed early: %v", <-errChan)
+			}
+			for _, taskObj := range page.Tasks {
+				streamed = append(streamed, taskObj.ID)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a page")
+		}
+	}
+	for len(streamed) < len(sent) {
+		next()
+	}
+
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "late task"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	next()
+	if want := append(sent, created.ID); !reflect.DeepEqual(streamed, want) {
+		t.Errorf("Expected streamed tasks %v, got %v", want, streamed)
+	}
+}
+
+func TestHandleTaskSend_RequestIDMetadata(t *testing.T) {
+	sendTask := func(t *testing.T, baseURL string) *a2a.Task {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodPost, baseURL, strings.NewReader(
+			`{"jsonrpc":"2.0","method":"tasks/send","id":"req-42","params":{"message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}}`))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "client-request-7")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var sent struct {
+			Result *a2a.Task `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil || sent.Result == nil {
+			t.Fatalf("Failed to decode tasks/send response: %v", err)
+		}
+
+		// The metadata is also returned by tasks/get
+		_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/get","id":"2","params":{"taskId":"`+sent.Result.ID+`"}}`)
+		var got struct {
+			Result *a2a.Task `json:"result"`
+		}
+		if err := json.Unmarshal(body, &got); err != nil || got.Result == nil {
+			t.Fatalf("Failed to decode tasks/get response %q: %v", body, err)
+		}
+		return got.Result
+	}
+
+	t.Run("recorded", func(t *testing.T) {
+		_, baseURL := newTestServer(t, newMockHandler(), WithRequestIDMetadata(true))
+
+		taskObj := sendTask(t, baseURL)
+		if id := taskObj.Metadata[a2a.TaskMetadataRequestID]; id != "req-42" {
+			t.Errorf("Expected request ID req-42 in metadata, got %v", id)
+		}
+		if id := taskObj.Metadata[a2a.TaskMetadataHTTPRequestID]; id != "client-request-7" {
+			t.Errorf("Expected X-Request-ID client-request-7 in metadata, got %v", id)
+		}
+	})
+
+	t.Run("not recorded by default", func(t *testing.T) {
+		_, baseURL := newTestServer(t, newMockHandler())
+
+		taskObj := sendTask(t, baseURL)
+		if _, ok := taskObj.Metadata[a2a.TaskMetadataRequestID]; ok {
+			t.Errorf("Expected no request ID in metadata, got %v", taskObj.Metadata)
+		}
+	})
+}
+
+func TestHandler_OptionalTaskManagerMethods(t *testing.T) {
+	// Embedding the interface hides the in-memory task manager's optional methods
+	tm := struct{ TaskManager }{NewInMemoryTaskManager(newMockHandler())}
+	_, baseURL := newTestServer(t, nil, WithTaskManager(tm))
+
+	for _, request := range []string{
+		`{"jsonrpc":"2.0","method":"tasks/list","id":"1"}`,
+		`{"jsonrpc":"2.0","method":"sessions/cancel","id":"1","params":{"sessionId":"s1"}}`,
+	} {
+		_, body := postJSONRPC(t, baseURL, request)
+
+		var response struct {
+			Error *a2a.Error `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", body, err)
+		}
+		if response.Error == nil || response.Error.Code != a2a.CodeMethodNotFound {
+			t.Errorf("Expected a method not found error for %s, got %s", request, body)
+		}
+	}
+}
+
+// wrappingTaskManager wraps the errors of OnGetTask, as a task manager adding context would.
+type wrappingTaskManager struct {
+	TaskManager
+}
+
+func (tm wrappingTaskManager) OnGetTask(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error) {
+	taskObj, err := tm.TaskManager.OnGetTask(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("loading task: %w", err)
+	}
+	return taskObj, nil
+}
+
+func TestHandleTaskGet_WrappedError(t *testing.T) {
+	tm := wrappingTaskManager{NewInMemoryTaskManager(newMockHandler())}
+	_, baseURL := newTestServer(t, nil, WithTaskManager(tm))
+
+	_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"tasks/get","id":"1","params":{"taskId":"missing"}}`)
+
+	var response struct {
+		Error *a2a.Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", body, err)
+	}
+	if response.Error == nil || response.Error.Code != a2a.CodeTaskNotFound {
+		t.Errorf("Expected a task not found error, got %s", body)
+	}
+}
@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register the hashes used by JWT signatures
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is the minimum time between fetches of a JWKS, so tokens with unknown
+// key IDs cannot make the validator fetch the key set on every request.
+const jwksRefreshInterval = time.Minute
+
+// jwksRetryInterval is how long after a failed JWKS fetch requests needing a fetch fail at
+// once, so an unreachable key set does not hold up every request for the fetch timeout.
+const jwksRetryInterval = 10 * time.Second
+
+// jwksFetchTimeout bounds how long a JWKS fetch may take.
+const jwksFetchTimeout = 10 * time.Second
+
+// errJWKSUnavailable is returned when a key set cannot be fetched, as opposed to when a token
+// is invalid.
+var errJWKSUnavailable = errors.New("JWKS unavailable")
+
+// jwksCache fetches the keys of a JSON Web Key Set and caches them by key ID.
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time     // When the last fetch ended
+	fetchErr  error         // Why the last fetch failed (nil if it succeeded)
+	fetching  chan struct{} // Closed when the fetch in progress ends (nil if none is)
+}
+
+// newJWKSCache creates a cache for the key set at url.
+func newJWKSCache(url string, client *http.Client) *jwksCache {
+	return &jwksCache{url: url, client: client}
+}
+
+// key returns the key with the given ID, fetching the key set if the key is not cached
+// and the set has not been fetched recently. The lock is not held while fetching; requests
+// arriving meanwhile wait for the fetch in progress rather than starting their own.
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	fetched := false
+	for {
+		c.mu.Lock()
+		if key, ok := c.keys[kid]; ok {
+			c.mu.Unlock()
+			return key, nil
+		}
+		if c.fetchErr != nil && time.Since(c.fetchedAt) < jwksRetryInterval {
+			err := c.fetchErr
+			c.mu.Unlock()
+			return nil, fmt.Errorf("%w: %w", errJWKSUnavailable, err)
+		}
+		if fetched || (c.keys != nil && c.fetchErr == nil && time.Since(c.fetchedAt) < jwksRefreshInterval) {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if wait := c.fetching; wait != nil {
+			c.mu.Unlock()
+			select {
+			case <-wait:
+				fetched = true
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		c.fetching = done
+		c.mu.Unlock()
+
+		// The fetch is shared, so it is not cut short if this request goes away
+		keys, err := c.fetch(context.WithoutCancel(ctx))
+
+		c.mu.Lock()
+		c.fetching = nil
+		c.fetchedAt = time.Now()
+		c.fetchErr = err
+		if err == nil {
+			c.keys = keys
+		}
+		c.mu.Unlock()
+		close(done)
+		fetched = true
+	}
+}
+
+// jsonWebKey is a key in a JSON Web Key Set. Only the fields of RSA and EC keys are decoded.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch fetches the key set. Keys of unsupported types, or not meant for signatures, are skipped.
+func (c *jwksCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status code %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key a JSON Web Key describes.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// jwtClaims are the registered JWT claims checked by verifyJWT, along with all claims.
+type jwtClaims struct {
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore *time.Time
+	All       map[string]interface{}
+}
+
+// jwtHashes are the hashes of the supported JWT signature algorithms.
+var jwtHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// jwtCurves are the curves of the keys each ECDSA signature algorithm must be used with.
+var jwtCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// verifyJWT verifies the signature of a compact JWT with a key from keys and returns its
+// claims. Only RSA (RS*) and ECDSA (ES*) signatures are supported. Tokens without a numeric
+// expiry ("exp") are rejected, as are tokens with a "nbf" claim that is not numeric.
+func verifyJWT(ctx context.Context, token string, keys *jwksCache) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	hash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	key, err := keys.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "RS") {
+			return nil, fmt.Errorf("key %q cannot verify %s signatures", header.Kid, header.Alg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+			return nil, errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if jwtCurves[header.Alg] != key.Curve.Params().Name {
+			return nil, fmt.Errorf("key %q cannot verify %s signatures", header.Kid, header.Alg)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return nil, errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return nil, errors.New("invalid signature")
+		}
+	}
+
+	var all map[string]interface{}
+	if err := decodeJWTPart(parts[1], &all); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	claims := &jwtClaims{All: all}
+	claims.Issuer, _ = all["iss"].(string)
+	switch aud := all["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+	exp, ok := all["exp"].(float64)
+	if !ok {
+		return nil, errors.New("token has no numeric expiry")
+	}
+	claims.ExpiresAt = time.Unix(int64(exp), 0)
+	if nbf, present := all["nbf"]; present {
+		nbf, ok := nbf.(float64)
+		if !ok {
+			return nil, errors.New("token has a non-numeric not-before time")
+		}
+		t := time.Unix(int64(nbf), 0)
+		claims.NotBefore = &t
+	}
+	return claims, nil
+}
+
+// decodeJWTPart decodes a base64url-encoded JSON part of a JWT into v.
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
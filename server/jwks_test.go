@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// signES256 signs claims as an ES256 JWT with the given key ID, whatever the key's curve.
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to marshal JWT part: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(map[string]string{"alg": "ES256", "typ": "JWT", "kid": kid}) + "." + encode(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign JWT: %v", err)
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newCachedJWKS returns a key cache holding keys, as if it had just fetched them.
+func newCachedJWKS(keys map[string]crypto.PublicKey) *jwksCache {
+	return &jwksCache{keys: keys, fetchedAt: time.Now()}
+}
+
+func TestVerifyJWT_AlgorithmMustMatchCurve(t *testing.T) {
+	claims := map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()}
+	for _, tt := range []struct {
+		curve elliptic.Curve
+		valid bool
+	}{
+		{elliptic.P256(), true},
+		{elliptic.P384(), false},
+	} {
+		key, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		keys := newCachedJWKS(map[string]crypto.PublicKey{"key-1": &key.PublicKey})
+
+		_, err = verifyJWT(context.Background(), signES256(t, key, "key-1", claims), keys)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("ES256 with a %s key: expected valid = %v, got error %v", tt.curve.Params().Name, tt.valid, err)
+		}
+	}
+}
+
+func TestJWKSCache_BacksOffAfterFailedFetch(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	keys := newJWKSCache(server.URL, server.Client())
+
+	for i := 0; i < 3; i++ {
+		if _, err := keys.key(context.Background(), "key-1"); !errors.Is(err, errJWKSUnavailable) {
+			t.Fatalf("Expected the key set to be unavailable, got %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("Expected one fetch while backing off, got %d", n)
+	}
+
+	// Once the retry interval has passed, the key set is fetched again
+	keys.mu.Lock()
+	keys.fetchedAt = time.Now().Add(-jwksRetryInterval)
+	keys.mu.Unlock()
+	keys.key(context.Background(), "key-1")
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected a second fetch after the retry interval, got %d fetches", n)
+	}
+}
+
+func TestJWKSCache_LookupsDoNotWaitForFetch(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keys := newJWKSCache(server.URL, server.Client())
+	keys.keys = map[string]crypto.PublicKey{"known": &key.PublicKey}
+
+	// Two lookups of an unknown key share one fetch
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := keys.key(context.Background(), "unknown")
+			errs <- err
+		}()
+	}
+
+	// A cached key is returned while the fetch is in progress
+	deadline := time.Now().Add(2 * time.Second)
+	for fetches.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := keys.key(context.Background(), "known")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the cached key, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a cached key lookup not to wait for the fetch")
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil || errors.Is(err, errJWKSUnavailable) {
+			t.Errorf("Expected an unknown key error, got %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("Expected one fetch, got %d", n)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultMCPDiscoveryTTL is how long a CachingMCPClient caches tool and resource lists when
+// no TTL is given.
+const DefaultMCPDiscoveryTTL = 5 * time.Minute
+
+// CachingMCPClient is an MCPClient that caches the tool and resource lists of the client it
+// wraps, so building many adapters and agents with it lists them from the MCP server once.
+// Tool calls and resource reads are not cached. It is safe for concurrent use.
+type CachingMCPClient struct {
+	MCPClient
+	ttl   time.Duration
+	clock Clock
+
+	toolsMu     sync.Mutex // Held while listing tools, so concurrent misses list them once
+	tools       []MCPToolInfo
+	toolsAt     time.Time  // When tools were listed (zero = not cached)
+	resourcesMu sync.Mutex // Held while listing resources, so concurrent misses list them once
+	resources   []MCPResourceInfo
+	resourcesAt time.Time // When resources were listed (zero = not cached)
+}
+
+// NewCachingMCPClient wraps client to cache its tool and resource lists for ttl
+// (DefaultMCPDiscoveryTTL if 0). Errors are not cached.
+func NewCachingMCPClient(client MCPClient, ttl time.Duration) *CachingMCPClient {
+	if ttl <= 0 {
+		ttl = DefaultMCPDiscoveryTTL
+	}
+	return &CachingMCPClient{MCPClient: client, ttl: ttl, clock: realClock{}}
+}
+
+// SetClock sets the clock the cache's expiry is checked against. It must be called before
+// the client is used.
+func (c *CachingMCPClient) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// GetAvailableTools returns the cached tool list, listing the tools from the wrapped client
+// if they are not cached or the cache has expired.
+func (c *CachingMCPClient) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+
+	if c.toolsAt.IsZero() || c.clock.Now().Sub(c.toolsAt) >= c.ttl {
+		tools, err := c.MCPClient.GetAvailableTools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.tools, c.toolsAt = tools, c.clock.Now()
+	}
+	return slices.Clone(c.tools), nil
+}
+
+// GetAvailableResources returns the cached resource list, listing the resources from the
+// wrapped client if they are not cached or the cache has expired.
+func (c *CachingMCPClient) GetAvailableResources(ctx context.Context) ([]MCPResourceInfo, error) {
+	c.resourcesMu.Lock()
+	defer c.resourcesMu.Unlock()
+
+	if c.resourcesAt.IsZero() || c.clock.Now().Sub(c.resourcesAt) >= c.ttl {
+		resources, err := c.MCPClient.GetAvailableResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.resources, c.resourcesAt = resources, c.clock.Now()
+	}
+	return slices.Clone(c.resources), nil
+}
+
+// Invalidate clears the cached tool and resource lists, e.g. when the MCP server reports
+// that they changed, so the next calls list them again.
+func (c *CachingMCPClient) Invalidate() {
+	c.toolsMu.Lock()
+	c.tools, c.toolsAt = nil, time.Time{}
+	c.toolsMu.Unlock()
+
+	c.resourcesMu.Lock()
+	c.resources, c.resourcesAt = nil, time.Time{}
+	c.resourcesMu.Unlock()
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discoveryCountingMCPClient is a fakeMCPClient with a "docs" resource that counts the
+// times its tools and resources are listed, failing while fail is set.
+type discoveryCountingMCPClient struct {
+	fakeMCPClient
+	toolLists     atomic.Int32
+	resourceLists atomic.Int32
+	fail          atomic.Bool
+}
+
+func (c *discoveryCountingMCPClient) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	c.toolLists.Add(1)
+	if c.fail.Load() {
+		return nil, errors.New("MCP server unavailable")
+	}
+	return c.fakeMCPClient.GetAvailableTools(ctx)
+}
+
+func (c *discoveryCountingMCPClient) GetAvailableResources(ctx context.Context) ([]MCPResourceInfo, error) {
+	c.resourceLists.Add(1)
+	if c.fail.Load() {
+		return nil, errors.New("MCP server unavailable")
+	}
+	return []MCPResourceInfo{{URI: "docs://readme", Name: "docs"}}, nil
+}
+
+func TestCachingMCPClient(t *testing.T) {
+	inner := &discoveryCountingMCPClient{fakeMCPClient: fakeMCPClient{results: map[string]interface{}{"weather": "sunny"}}}
+	clock := NewFakeClock(time.Now())
+	client := NewCachingMCPClient(inner, time.Minute)
+	client.SetClock(clock)
+
+	// Adapters and agents built within the TTL share one listing of each
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewMCPToolAdapter(client, "weather", nil); err != nil {
+				t.Errorf("NewMCPToolAdapter failed: %v", err)
+			}
+			if _, err := NewMCPResourceAdapter(client, "docs://readme"); err != nil {
+				t.Errorf("NewMCPResourceAdapter failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := NewMCPToolAugmentedAgent(&fakeLLM{}, client); err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	if n := inner.toolLists.Load(); n != 1 {
+		t.Errorf("Expected the tools to be listed once, got %d", n)
+	}
+	if n := inner.resourceLists.Load(); n != 1 {
+		t.Errorf("Expected the resources to be listed once, got %d", n)
+	}
+
+	// Tool calls are not cached
+	client.CallTool(context.Background(), "weather", nil)
+
+	// The lists are listed again once the TTL has passed
+	clock.Advance(time.Minute)
+	if _, err := NewMCPToolAdapter(client, "weather", nil); err != nil {
+		t.Fatalf("NewMCPToolAdapter failed: %v", err)
+	}
+	if n := inner.toolLists.Load(); n != 2 {
+		t.Errorf("Expected the tools to be listed again after the TTL, got %d listings", n)
+	}
+
+	// Or when the cache is invalidated
+	client.Invalidate()
+	if _, err := client.GetAvailableTools(context.Background()); err != nil {
+		t.Fatalf("GetAvailableTools failed: %v", err)
+	}
+	if _, err := client.GetAvailableResources(context.Background()); err != nil {
+		t.Fatalf("GetAvailableResources failed: %v", err)
+	}
+	if tools, resources := inner.toolLists.Load(), inner.resourceLists.Load(); tools != 3 || resources != 2 {
+		t.Errorf("Expected the lists to be listed again after Invalidate, got %d tool and %d resource listings", tools, resources)
+	}
+}
+
+func TestCachingMCPClient_ErrorsNotCached(t *testing.T) {
+	inner := &discoveryCountingMCPClient{fakeMCPClient: fakeMCPClient{results: map[string]interface{}{"weather": "sunny"}}}
+	client := NewCachingMCPClient(inner, time.Minute)
+
+	inner.fail.Store(true)
+	if _, err := client.GetAvailableTools(context.Background()); err == nil {
+		t.Fatal("Expected the listing error")
+	}
+	inner.fail.Store(false)
+	tools, err := client.GetAvailableTools(context.Background())
+	if err != nil || len(tools) != 1 {
+		t.Errorf("Expected the tools once the MCP server recovers, got %v, %v", tools, err)
+	}
+	if n := inner.toolLists.Load(); n != 2 {
+		t.Errorf("Expected the failed listing not to be cached, got %d listings", n)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/llm"
+	"github.com/sammcj/go-a2a/pkg/schema"
 	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/pkg/trace"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // MCPClient defines the interface for interacting with MCP servers.
@@ -43,6 +48,43 @@ type MCPResourceInfo struct {
 	MIMEType    string `json:"mimeType"`
 }
 
+// ToolParamsError is returned when the params of a tool call do not match the tool's input
+// schema. Its message lists the invalid fields, so it can be relayed to a model to correct
+// the call.
+type ToolParamsError struct {
+	Tool   string           // Name of the tool
+	Fields []a2a.FieldError // The invalid fields
+}
+
+// Error implements the standard Go error interface.
+func (e *ToolParamsError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i := range e.Fields {
+		reasons[i] = e.Fields[i].Error()
+	}
+	return fmt.Sprintf("invalid params for tool %q: %s", e.Tool, strings.Join(reasons, "; "))
+}
+
+// validateToolParams checks params against a tool's input schema, returning a
+// *ToolParamsError if they do not match. A tool without a schema accepts any params.
+func validateToolParams(tool MCPToolInfo, params map[string]interface{}) error {
+	if len(tool.InputSchema) == 0 {
+		return nil
+	}
+	if params == nil {
+		// The tool is called with an empty object
+		params = map[string]interface{}{}
+	}
+	fields, err := schema.Validate(tool.InputSchema, params)
+	if err != nil {
+		return fmt.Errorf("failed to validate params for tool %q: %w", tool.Name, err)
+	}
+	if len(fields) > 0 {
+		return &ToolParamsError{Tool: tool.Name, Fields: fields}
+	}
+	return nil
+}
+
 // MCPToolAdapter adapts the Tool interface to work with MCP tools.
 // It implements the Tool interface and delegates calls to an MCPClient.
 type MCPToolAdapter struct {
@@ -101,17 +143,24 @@ func (a *MCPToolAdapter) Description() string {
 	return a.toolInfo.Description
 }
 
-// Execute executes the tool with the given parameters.
+// Execute executes the tool with the given parameters. It returns a *ToolParamsError without
+// calling the tool if the converted parameters do not match the tool's input schema.
 func (a *MCPToolAdapter) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
 	// Convert parameters if needed
 	mcpParams, err := a.converter(params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert parameters: %w", err)
 	}
+	if err := validateToolParams(a.toolInfo, mcpParams); err != nil {
+		return nil, err
+	}
 
 	// Call the MCP tool
+	ctx, span := trace.StartSpan(ctx, "a2a.tool", attribute.String("a2a.tool", a.toolName))
+	defer span.End()
 	result, err := a.client.CallTool(ctx, a.toolName, mcpParams)
 	if err != nil {
+		trace.RecordError(span, err)
 		return nil, fmt.Errorf("failed to call MCP tool: %w", err)
 	}
 
@@ -229,29 +278,32 @@ type MCPClientConfig struct {
 	// AuthToken is the authentication token to use when connecting to the MCP server.
 	AuthToken string
 
-	// Timeout is the timeout for MCP requests.
+	// Timeout is the timeout for MCP requests, in seconds (0 = DefaultMCPTimeout).
 	Timeout int
 }
 
-// NewMCPClient creates a new MCP client based on the provided configuration.
-// This function should be implemented by the application developer to create
-// an MCP client that implements the MCPClient interface.
+// NewMCPClient creates an HTTPMCPClient for the MCP server at config.ServerURL. Other
+// transports can be used by implementing MCPClient.
 func NewMCPClient(config MCPClientConfig) (MCPClient, error) {
-	// This is a placeholder function that should be implemented by the application developer.
-	// It should create an MCP client that implements the MCPClient interface.
-	// The implementation will depend on the specific MCP client library being used.
-	return nil, fmt.Errorf("NewMCPClient is not implemented")
+	client, err := NewHTTPMCPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
 }
 
 // MCPToolAugmentedAgent implements AgentEngine using an LLM with MCP tools.
 type MCPToolAugmentedAgent struct {
 	llm          llm.LLMInterface
 	mcpClient    MCPClient
+	tools        map[string]MCPToolInfo // Tools the MCP server advertised, keyed by name
 	systemPrompt string
 	capabilities AgentCapabilities
+	auditTools   bool // Whether each tool call is recorded in a ToolAuditRecord artifact
 }
 
-// NewMCPToolAugmentedAgent creates a new MCPToolAugmentedAgent.
+// NewMCPToolAugmentedAgent creates a new MCPToolAugmentedAgent. The agent consumes streamed
+// responses, so wrap an LLM that cannot stream with llm.WithStreamingFallback.
 func NewMCPToolAugmentedAgent(llmInterface llm.LLMInterface, mcpClient MCPClient) (*MCPToolAugmentedAgent, error) {
 	// Get model info to determine capabilities
 	modelInfo := llmInterface.GetModelInfo()
@@ -271,9 +323,15 @@ func NewMCPToolAugmentedAgent(llmInterface llm.LLMInterface, mcpClient MCPClient
 	systemPrompt += "```json\n{\"tool\": \"tool_name\", \"params\": {\"param1\": \"value1\", \"param2\": \"value2\"}}\n```\n"
 	systemPrompt += "I will execute the tool and return the result to you."
 
+	toolsByName := make(map[string]MCPToolInfo, len(tools))
+	for _, tool := range tools {
+		toolsByName[tool.Name] = tool
+	}
+
 	return &MCPToolAugmentedAgent{
 		llm:          llmInterface,
 		mcpClient:    mcpClient,
+		tools:        toolsByName,
 		systemPrompt: systemPrompt,
 		capabilities: AgentCapabilities{
 			SupportsStreaming:         true,
@@ -283,6 +341,13 @@ func NewMCPToolAugmentedAgent(llmInterface llm.LLMInterface, mcpClient MCPClient
 	}, nil
 }
 
+// SetToolAudit sets whether the agent records each tool call it makes, successful or not, in
+// a ToolAuditRecord artifact sent before the call's result. It is off by default, and must be
+// set before any tasks are processed.
+func (a *MCPToolAugmentedAgent) SetToolAudit(enabled bool) {
+	a.auditTools = enabled
+}
+
 // ProcessTask implements AgentEngine.ProcessTask.
 func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
 	updateChan := make(chan task.YieldUpdate)
@@ -290,15 +355,8 @@ func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Co
 	go func() {
 		defer close(updateChan)
 
-		// Extract the user's message
-		userMessage := taskCtx.UserMessage
-		var userText string
-		for _, part := range userMessage.Parts {
-			if textPart, ok := part.(a2a.TextPart); ok {
-				userText = textPart.Text
-				break
-			}
-		}
+		// Build the prompt from all parts of the user's message
+		userText := taskPrompt(taskCtx)
 
 		// Send a working status update
 		updateChan <- task.StatusUpdate{
@@ -312,13 +370,15 @@ func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Co
 		var responseBuffer string
 		var toolCall *ToolCall
 
-		// Process the streaming response
+		// Process the streaming response until the stream ends, which is when the chunk
+		// channel closes or a chunk is marked Completed, as not every provider does both
+	stream:
 		for {
 			select {
 			case chunk, ok := <-chunkChan:
 				if !ok {
 					// Channel closed, all chunks received
-					break
+					break stream
 				}
 
 				// Accumulate the response
@@ -344,97 +404,17 @@ func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Co
 					Message: &responseMessage,
 				}
 
-				// If this is the final chunk, process any tool calls
-				if chunk.Completed && toolCall != nil {
-					// Execute the tool
-					result, err := a.mcpClient.CallTool(ctx, toolCall.Tool, toolCall.Params)
-					if err != nil {
-						// Send a failed status update
-						errorMessage := a2a.Message{
-							Role: a2a.RoleSystem,
-							Parts: []a2a.Part{
-								a2a.TextPart{
-									Type: "text",
-									Text: fmt.Sprintf("Failed to execute tool %q: %v", toolCall.Tool, err),
-								},
-							},
-						}
-						updateChan <- task.StatusUpdate{
-							State:   a2a.TaskStateFailed,
-							Message: &errorMessage,
-						}
-						return
-					}
-
-					// Convert the result to a string
-					resultStr, err := formatToolResult(result)
-					if err != nil {
-						// Send a failed status update
-						errorMessage := a2a.Message{
-							Role: a2a.RoleSystem,
-							Parts: []a2a.Part{
-								a2a.TextPart{
-									Type: "text",
-									Text: fmt.Sprintf("Failed to format tool result: %v", err),
-								},
-							},
-						}
-						updateChan <- task.StatusUpdate{
-							State:   a2a.TaskStateFailed,
-							Message: &errorMessage,
-						}
-						return
-					}
-
-					// Send the tool result as an artifact update
-					updateChan <- task.ArtifactUpdate{
-						Part: a2a.TextPart{
-							Type: "text",
-							Text: resultStr,
-						},
-						Metadata: map[string]interface{}{
-							"tool": toolCall.Tool,
-						},
-					}
-
-					// Process the tool result with the LLM
-					prompt := fmt.Sprintf("I executed the tool %q with the parameters %v and got the following result:\n\n%s\n\nPlease continue helping the user based on this result.", toolCall.Tool, toolCall.Params, resultStr)
-					response, err := a.llm.Generate(ctx, prompt, llm.WithSystemPrompt(a.systemPrompt))
-					if err != nil {
-						// Send a failed status update
-						errorMessage := a2a.Message{
-							Role: a2a.RoleSystem,
-							Parts: []a2a.Part{
-								a2a.TextPart{
-									Type: "text",
-									Text: fmt.Sprintf("Failed to process tool result: %v", err),
-								},
-							},
-						}
-						updateChan <- task.StatusUpdate{
-							State:   a2a.TaskStateFailed,
-							Message: &errorMessage,
-						}
-						return
-					}
-
-					// Send the response
-					responseMessage := a2a.Message{
-						Role: a2a.RoleAgent,
-						Parts: []a2a.Part{
-							a2a.TextPart{
-								Type: "text",
-								Text: response,
-							},
-						},
-					}
-					updateChan <- task.StatusUpdate{
-						State:   a2a.TaskStateWorking,
-						Message: &responseMessage,
-					}
+				if chunk.Completed {
+					break stream
+				}
+
+			case err, ok := <-errChan:
+				if !ok || err == nil {
+					// No error was reported; keep reading chunks
+					errChan = nil
+					continue
 				}
 
-			case err := <-errChan:
 				// Error occurred during generation
 				errorMessage := a2a.Message{
 					Role: a2a.RoleSystem,
@@ -470,6 +450,11 @@ func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Co
 			}
 		}
 
+		// Now the response is complete, execute any tool call it contains
+		if toolCall != nil && !a.executeToolCall(ctx, userText, responseBuffer, toolCall, updateChan) {
+			return
+		}
+
 		// Send a completed status update
 		updateChan <- task.StatusUpdate{
 			State: a2a.TaskStateCompleted,
@@ -479,6 +464,177 @@ func (a *MCPToolAugmentedAgent) ProcessTask(ctx context.Context, taskCtx task.Co
 	return updateChan, nil
 }
 
+// executeToolCall calls the tool that the LLM's reply to the user's prompt asked for, sends
+// its result as an artifact, and then the LLM's response to the result
+// as a working status update. If tool auditing is on, the call's audit record is sent first.
+// If any step fails it sends a failed status update and returns false.
+func (a *MCPToolAugmentedAgent) executeToolCall(ctx context.Context, userText, reply string, toolCall *ToolCall, updateChan chan<- task.YieldUpdate) bool {
+	// Give the model a chance to correct params that do not match the tool's input schema
+	toolCall, reply, err := a.checkToolParams(ctx, userText, reply, toolCall)
+	if err != nil {
+		// Send a failed status update
+		errorMessage := a2a.Message{
+			Role: a2a.RoleSystem,
+			Parts: []a2a.Part{
+				a2a.TextPart{
+					Type: "text",
+					Text: fmt.Sprintf("Failed to execute tool %q: %v", toolCall.Tool, err),
+				},
+			},
+		}
+		updateChan <- task.StatusUpdate{
+			State:   a2a.TaskStateFailed,
+			Message: &errorMessage,
+		}
+		return false
+	}
+
+	// Execute the tool
+	toolCtx, span := trace.StartSpan(ctx, "a2a.tool", attribute.String("a2a.tool", toolCall.Tool))
+	startedAt := time.Now()
+	result, err := a.mcpClient.CallTool(toolCtx, toolCall.Tool, toolCall.Params)
+	if err != nil {
+		trace.RecordError(span, err)
+	}
+	span.End()
+	if a.auditTools {
+		updateChan <- newToolAuditRecord(toolCall.Tool, toolCall.Params, startedAt, result, err).artifact()
+	}
+	if err != nil {
+		// Send a failed status update
+		errorMessage := a2a.Message{
+			Role: a2a.RoleSystem,
+			Parts: []a2a.Part{
+				a2a.TextPart{
+					Type: "text",
+					Text: fmt.Sprintf("Failed to execute tool %q: %v", toolCall.Tool, err),
+				},
+			},
+		}
+		updateChan <- task.StatusUpdate{
+			State:   a2a.TaskStateFailed,
+			Message: &errorMessage,
+		}
+		return false
+	}
+
+	// Render the result as text for the LLM
+	resultStr, err := formatToolResult(result)
+	if err != nil {
+		// Send a failed status update
+		errorMessage := a2a.Message{
+			Role: a2a.RoleSystem,
+			Parts: []a2a.Part{
+				a2a.TextPart{
+					Type: "text",
+					Text: fmt.Sprintf("Failed to format tool result: %v", err),
+				},
+			},
+		}
+		updateChan <- task.StatusUpdate{
+			State:   a2a.TaskStateFailed,
+			Message: &errorMessage,
+		}
+		return false
+	}
+
+	// Send the structured tool result as an artifact update
+	updateChan <- task.ArtifactUpdate{
+		Part: a2a.DataPart{
+			Type:     "data",
+			MimeType: "application/json",
+			Data:     result,
+		},
+		Metadata: map[string]interface{}{
+			"tool": toolCall.Tool,
+		},
+	}
+
+	// Process the tool result with the LLM, sending the result with the tool role so the
+	// model can tell it apart from user input
+	prompt := fmt.Sprintf("Please continue helping the user based on the result of the tool %q.", toolCall.Tool)
+	response, err := a.llm.Generate(ctx, prompt,
+		llm.WithSystemPrompt(a.systemPrompt),
+		llm.WithHistory(
+			llm.Message{Role: llm.RoleUser, Content: userText},
+			llm.Message{Role: llm.RoleAssistant, Content: reply},
+			llm.Message{Role: llm.RoleTool, Content: resultStr, Name: toolCall.Tool},
+		),
+	)
+	if err != nil {
+		// Send a failed status update
+		errorMessage := a2a.Message{
+			Role: a2a.RoleSystem,
+			Parts: []a2a.Part{
+				a2a.TextPart{
+					Type: "text",
+					Text: fmt.Sprintf("Failed to process tool result: %v", err),
+				},
+			},
+		}
+		updateChan <- task.StatusUpdate{
+			State:   a2a.TaskStateFailed,
+			Message: &errorMessage,
+		}
+		return false
+	}
+
+	// Send the response
+	responseMessage := a2a.Message{
+		Role: a2a.RoleAgent,
+		Parts: []a2a.Part{
+			a2a.TextPart{
+				Type: "text",
+				Text: response,
+			},
+		},
+	}
+	updateChan <- task.StatusUpdate{
+		State:   a2a.TaskStateWorking,
+		Message: &responseMessage,
+	}
+	return true
+}
+
+// checkToolParams validates a tool call's params against the tool's input schema. If they
+// are invalid, the validation error is relayed to the LLM, which is asked once to make the
+// call again. It returns the valid tool call with the reply making it, or the validation
+// error if the LLM does not correct the call.
+func (a *MCPToolAugmentedAgent) checkToolParams(ctx context.Context, userText, reply string, toolCall *ToolCall) (*ToolCall, string, error) {
+	tool, ok := a.tools[toolCall.Tool]
+	if !ok {
+		// Leave unknown tools to the MCP server
+		return toolCall, reply, nil
+	}
+	err := validateToolParams(tool, toolCall.Params)
+	if err == nil {
+		return toolCall, reply, nil
+	}
+
+	prompt := fmt.Sprintf("Please call the tool %q again, with parameters that fix these errors.", toolCall.Tool)
+	correction, genErr := a.llm.Generate(ctx, prompt,
+		llm.WithSystemPrompt(a.systemPrompt),
+		llm.WithHistory(
+			llm.Message{Role: llm.RoleUser, Content: userText},
+			llm.Message{Role: llm.RoleAssistant, Content: reply},
+			llm.Message{Role: llm.RoleTool, Content: err.Error(), Name: toolCall.Tool},
+		),
+	)
+	if genErr != nil {
+		return toolCall, reply, err
+	}
+	corrected := extractToolCall(correction)
+	if corrected == nil {
+		return toolCall, reply, err
+	}
+	if tool, ok := a.tools[corrected.Tool]; ok {
+		if err := validateToolParams(tool, corrected.Params); err != nil {
+			return corrected, correction, err
+		}
+	}
+	return corrected, correction, nil
+}
+
 // GetCapabilities implements AgentEngine.GetCapabilities.
 func (a *MCPToolAugmentedAgent) GetCapabilities() AgentCapabilities {
 	return a.capabilities
@@ -566,7 +722,7 @@ func extractToolCall(response string) *ToolCall {
 	}
 }
 
-// formatToolResult formats a tool result as a string.
+// formatToolResult renders a tool result as indented JSON text for the LLM prompt.
 func formatToolResult(result interface{}) (string, error) {
 	// Convert the result to JSON
 	resultJSON, err := json.MarshalIndent(result, "", "  ")
@@ -0,0 +1,429 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/llm"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// fakeLLM is an LLM that streams a fixed response and answers every Generate call with reply.
+// It records the prompts it receives, and the options of the last Generate call.
+type fakeLLM struct {
+	stream string
+	reply  string
+
+	mu      sync.Mutex
+	prompts []string
+	options *llm.LLMOptions
+}
+
+func (f *fakeLLM) record(prompt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, prompt)
+}
+
+// Prompts returns the prompts received so far.
+func (f *fakeLLM) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
+
+// Options returns the options of the last Generate call (nil if there was none).
+func (f *fakeLLM) Options() *llm.LLMOptions {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.options
+}
+
+func (f *fakeLLM) Generate(ctx context.Context, prompt string, options ...llm.LLMOption) (string, error) {
+	f.record(prompt)
+	opts := llm.DefaultLLMOptions()
+	for _, opt := range options {
+		opt(opts)
+	}
+	f.mu.Lock()
+	f.options = opts
+	f.mu.Unlock()
+	return f.reply, nil
+}
+
+func (f *fakeLLM) GenerateStream(ctx context.Context, prompt string, options ...llm.LLMOption) (<-chan llm.LLMChunk, <-chan error) {
+	f.record(prompt)
+	chunks := make(chan llm.LLMChunk, 1)
+	chunks <- llm.LLMChunk{Text: f.stream, Completed: true}
+	close(chunks)
+	return chunks, make(chan error)
+}
+
+func (f *fakeLLM) GetModelInfo() llm.LLMModelInfo {
+	return llm.LLMModelInfo{Name: "fake", SupportsStreaming: true}
+}
+
+// blockingFakeLLM is a fakeLLM for a provider that cannot stream: its first Generate call
+// returns the stream response, and its GenerateStream never sends anything.
+type blockingFakeLLM struct {
+	fakeLLM
+}
+
+func (f *blockingFakeLLM) Generate(ctx context.Context, prompt string, options ...llm.LLMOption) (string, error) {
+	f.record(prompt)
+	if len(f.Prompts()) == 1 {
+		return f.stream, nil
+	}
+	return f.reply, nil
+}
+
+func (f *blockingFakeLLM) GenerateStream(ctx context.Context, prompt string, options ...llm.LLMOption) (<-chan llm.LLMChunk, <-chan error) {
+	return make(chan llm.LLMChunk), make(chan error)
+}
+
+func (f *blockingFakeLLM) GetModelInfo() llm.LLMModelInfo {
+	return llm.LLMModelInfo{Name: "blocking"}
+}
+
+// uncompletedFakeLLM is a fakeLLM for a provider that streams its response in two chunks
+// and closes the stream without marking any chunk Completed.
+type uncompletedFakeLLM struct {
+	fakeLLM
+}
+
+func (f *uncompletedFakeLLM) GenerateStream(ctx context.Context, prompt string, options ...llm.LLMOption) (<-chan llm.LLMChunk, <-chan error) {
+	f.record(prompt)
+	chunks := make(chan llm.LLMChunk, 2)
+	half := len(f.stream) / 2
+	chunks <- llm.LLMChunk{Text: f.stream[:half]}
+	chunks <- llm.LLMChunk{Text: f.stream[half:]}
+	close(chunks)
+	errs := make(chan error)
+	close(errs)
+	return chunks, errs
+}
+
+// fakeMCPClient is an MCP client whose tools return fixed results, or fixed errors, and may
+// have input schemas.
+type fakeMCPClient struct {
+	results map[string]interface{}
+	errs    map[string]error
+	schemas map[string]map[string]interface{}
+}
+
+func (f *fakeMCPClient) CallTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
+	if err := f.errs[toolName]; err != nil {
+		return nil, err
+	}
+	return f.results[toolName], nil
+}
+
+func (f *fakeMCPClient) ReadResource(ctx context.Context, uri string) (string, string, error) {
+	return "", "", nil
+}
+
+func (f *fakeMCPClient) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	tools := make([]MCPToolInfo, 0, len(f.results))
+	for name := range f.results {
+		tools = append(tools, MCPToolInfo{Name: name, InputSchema: f.schemas[name]})
+	}
+	return tools, nil
+}
+
+func (f *fakeMCPClient) GetAvailableResources(ctx context.Context) ([]MCPResourceInfo, error) {
+	return nil, nil
+}
+
+func TestMCPToolAugmentedAgent_ToolResultDataPart(t *testing.T) {
+	const toolCall = `{"tool": "weather", "params": {"city": "Melbourne"}}`
+	tests := []struct {
+		name string
+		llm  llm.LLMInterface
+	}{
+		{"streaming", &fakeLLM{stream: toolCall, reply: "It is sunny."}},
+		{"blocking only", llm.WithStreamingFallback(&blockingFakeLLM{fakeLLM{stream: toolCall, reply: "It is sunny."}})},
+		{"stream never completed", &uncompletedFakeLLM{fakeLLM{stream: toolCall, reply: "It is sunny."}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testToolResultDataPart(t, tt.llm)
+		})
+	}
+}
+
+// testToolResultDataPart checks that an MCPToolAugmentedAgent using l, which asks for the
+// weather tool, emits the tool's result as a DataPart artifact.
+func testToolResultDataPart(t *testing.T, l llm.LLMInterface) {
+	result := map[string]interface{}{
+		"temperature": 21.5,
+		"conditions":  []interface{}{"sunny", "windy"},
+	}
+	agent, err := NewMCPToolAugmentedAgent(l, &fakeMCPClient{results: map[string]interface{}{"weather": result}})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := agent.ProcessTask(ctx, task.Context{
+		TaskID:      "task-1",
+		UserMessage: newTextMessage(a2a.RoleUser, "What's the weather in Melbourne?"),
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask failed: %v", err)
+	}
+
+	var artifact *task.ArtifactUpdate
+	timeout := time.After(2 * time.Second)
+	for artifact == nil {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				t.Fatal("Updates closed without a tool result artifact")
+			}
+			if u, ok := update.(task.ArtifactUpdate); ok {
+				artifact = &u
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the tool result artifact")
+		}
+	}
+	cancel()
+	for range updates {
+	}
+
+	part, ok := artifact.Part.(a2a.DataPart)
+	if !ok {
+		t.Fatalf("Expected a DataPart artifact, got %T", artifact.Part)
+	}
+	if part.MimeType != "application/json" {
+		t.Errorf("Expected MIME type application/json, got %q", part.MimeType)
+	}
+	if !reflect.DeepEqual(part.Data, result) {
+		t.Errorf("Expected data %v, got %v", result, part.Data)
+	}
+	if metadata, _ := artifact.Metadata.(map[string]interface{}); metadata["tool"] != "weather" {
+		t.Errorf("Expected artifact metadata to name the tool, got %v", artifact.Metadata)
+	}
+}
+
+func TestMCPToolAugmentedAgent_CompletesOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		llm  llm.LLMInterface
+	}{
+		{"streaming", &fakeLLM{stream: "Hello there."}},
+		{"blocking only", llm.WithStreamingFallback(&blockingFakeLLM{fakeLLM{stream: "Hello there."}})},
+		{"stream never completed", &uncompletedFakeLLM{fakeLLM{stream: "Hello there."}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent, err := NewMCPToolAugmentedAgent(tt.llm, &fakeMCPClient{})
+			if err != nil {
+				t.Fatalf("Failed to create agent: %v", err)
+			}
+			updates, err := agent.ProcessTask(context.Background(), task.Context{
+				TaskID:      "task-1",
+				UserMessage: newTextMessage(a2a.RoleUser, "Hi"),
+			})
+			if err != nil {
+				t.Fatalf("ProcessTask failed: %v", err)
+			}
+
+			// The updates close by themselves once the stream ends, without cancelling the task
+			var states []a2a.TaskState
+			timeout := time.After(2 * time.Second)
+			for done := false; !done; {
+				select {
+				case update, ok := <-updates:
+					if !ok {
+						done = true
+					} else if u, ok := update.(task.StatusUpdate); ok {
+						states = append(states, u.State)
+					}
+				case <-timeout:
+					t.Fatalf("Timed out waiting for the updates to close, got states %v", states)
+				}
+			}
+
+			completed := 0
+			for _, state := range states {
+				if state == a2a.TaskStateCompleted {
+					completed++
+				}
+			}
+			if completed != 1 || states[len(states)-1] != a2a.TaskStateCompleted {
+				t.Errorf("Expected a single completed update at the end, got states %v", states)
+			}
+		})
+	}
+}
+
+func TestMCPToolAugmentedAgent_ToolResultRole(t *testing.T) {
+	fake := &fakeLLM{stream: `{"tool": "weather", "params": {"city": "Melbourne"}}`, reply: "It is sunny."}
+	agent, err := NewMCPToolAugmentedAgent(fake, &fakeMCPClient{results: map[string]interface{}{
+		"weather": map[string]interface{}{"temperature": 21.5},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	updates, err := agent.ProcessTask(context.Background(), task.Context{
+		TaskID:      "task-1",
+		UserMessage: newTextMessage(a2a.RoleUser, "What's the weather in Melbourne?"),
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask failed: %v", err)
+	}
+
+	// The result is sent as an artifact; the task's messages keep A2A roles
+	var artifacts []task.ArtifactUpdate
+	for update := range updates {
+		switch u := update.(type) {
+		case task.ArtifactUpdate:
+			artifacts = append(artifacts, u)
+		case task.StatusUpdate:
+			if u.Message != nil && u.Message.Role != a2a.RoleAgent && u.Message.Role != a2a.RoleSystem {
+				t.Errorf("Expected messages with A2A roles, got %q", u.Message.Role)
+			}
+		}
+	}
+	if len(artifacts) != 1 {
+		t.Fatalf("Expected 1 artifact, got %d", len(artifacts))
+	}
+	if data, _ := artifacts[0].Part.(a2a.DataPart).Data.(map[string]interface{}); data["temperature"] != 21.5 {
+		t.Errorf("Expected the artifact to hold the result, got %+v", artifacts[0].Part)
+	}
+
+	// The LLM gets the result with the tool role rather than in the prompt
+	history := fake.Options().History
+	if len(history) != 3 {
+		t.Fatalf("Expected the user prompt, tool request and tool result in the history, got %+v", history)
+	}
+	if last := history[2]; last.Role != llm.RoleTool || last.Name != "weather" || !strings.Contains(last.Content, "21.5") {
+		t.Errorf("Expected the tool result with the tool role, got %+v", last)
+	}
+	if prompts := fake.Prompts(); strings.Contains(prompts[len(prompts)-1], "21.5") {
+		t.Errorf("Expected the tool result to be left out of the prompt, got %q", prompts[len(prompts)-1])
+	}
+}
+
+// weatherSchema is the input schema of a weather tool that requires a city.
+var weatherSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []interface{}{"city"},
+	"properties": map[string]interface{}{
+		"city": map[string]interface{}{"type": "string"},
+		"days": map[string]interface{}{"type": "integer", "minimum": 1},
+	},
+}
+
+func TestMCPToolAdapter_ValidatesParams(t *testing.T) {
+	client := &callRecordingMCPClient{fakeMCPClient: fakeMCPClient{
+		results: map[string]interface{}{"weather": "sunny"},
+		schemas: map[string]map[string]interface{}{"weather": weatherSchema},
+	}}
+	adapter, err := NewMCPToolAdapter(client, "weather", nil)
+	if err != nil {
+		t.Fatalf("NewMCPToolAdapter failed: %v", err)
+	}
+
+	_, err = adapter.Execute(context.Background(), map[string]interface{}{"days": 0})
+	var paramsErr *ToolParamsError
+	if !errors.As(err, &paramsErr) {
+		t.Fatalf("Expected a ToolParamsError, got %v", err)
+	}
+	if paramsErr.Tool != "weather" || len(paramsErr.Fields) != 2 {
+		t.Errorf("Expected the missing city and invalid days, got %+v", paramsErr)
+	}
+	if !strings.Contains(err.Error(), "city") || !strings.Contains(err.Error(), "days") {
+		t.Errorf("Expected the error to name the invalid fields, got %q", err)
+	}
+	if len(client.called) != 0 {
+		t.Errorf("Expected the tool not to be called with invalid params, got calls %v", client.called)
+	}
+
+	result, err := adapter.Execute(context.Background(), map[string]interface{}{"city": "Melbourne", "days": 3})
+	if err != nil || result != "sunny" {
+		t.Errorf("Expected the tool to be called with valid params, got %v, %v", result, err)
+	}
+}
+
+func TestMCPToolAugmentedAgent_CorrectsInvalidParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		correction string // The model's reply once told the params are invalid
+		wantState  a2a.TaskState
+		wantCalled bool
+	}{
+		{"corrected", `{"tool": "weather", "params": {"city": "Melbourne"}}`, a2a.TaskStateCompleted, true},
+		{"still invalid", `{"tool": "weather", "params": {"town": "Melbourne"}}`, a2a.TaskStateFailed, false},
+		{"no tool call", "Sorry, I can't.", a2a.TaskStateFailed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &callRecordingMCPClient{fakeMCPClient: fakeMCPClient{
+				results: map[string]interface{}{"weather": "sunny"},
+				schemas: map[string]map[string]interface{}{"weather": weatherSchema},
+			}}
+			fake := &fakeLLM{stream: `{"tool": "weather", "params": {"town": "Melbourne"}}`, reply: tt.correction}
+			agent, err := NewMCPToolAugmentedAgent(fake, client)
+			if err != nil {
+				t.Fatalf("Failed to create agent: %v", err)
+			}
+			updates, err := agent.ProcessTask(context.Background(), task.Context{
+				TaskID:      "task-1",
+				UserMessage: newTextMessage(a2a.RoleUser, "What's the weather in Melbourne?"),
+			})
+			if err != nil {
+				t.Fatalf("ProcessTask failed: %v", err)
+			}
+			var last task.StatusUpdate
+			for update := range updates {
+				if u, ok := update.(task.StatusUpdate); ok {
+					last = u
+				}
+			}
+
+			if last.State != tt.wantState {
+				t.Fatalf("Expected the task to end %s, got %s", tt.wantState, last.State)
+			}
+			if tt.wantCalled {
+				if len(client.params) != 1 || client.params[0]["city"] != "Melbourne" {
+					t.Errorf("Expected the tool to be called with the corrected params, got %v", client.params)
+				}
+			} else {
+				if len(client.called) != 0 {
+					t.Errorf("Expected the tool not to be called, got calls %v", client.called)
+				}
+				if text := last.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, "city") {
+					t.Errorf("Expected the failure to name the missing field, got %q", text)
+				}
+
+				// The last request to the model relayed what was wrong with its params
+				if history := fake.Options().History; len(history) != 3 || history[2].Role != llm.RoleTool || !strings.Contains(history[2].Content, "city") {
+					t.Errorf("Expected the validation error to be relayed to the model, got %+v", history)
+				}
+			}
+
+			// The model was asked once to correct its params
+			corrections := 0
+			for _, prompt := range fake.Prompts() {
+				if strings.Contains(prompt, "fix these errors") {
+					corrections++
+				}
+			}
+			if corrections != 1 {
+				t.Errorf("Expected the model to be asked once to correct the call, got %d requests", corrections)
+			}
+		})
+	}
+}
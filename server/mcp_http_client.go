@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMCPTimeout is the timeout for MCP requests when MCPClientConfig.Timeout is 0.
+const DefaultMCPTimeout = 30 * time.Second
+
+// HTTPMCPClient is an MCPClient that talks to an MCP server over HTTP, using the MCP
+// Streamable HTTP transport: each JSON-RPC request is POSTed to the server's URL, which
+// responds with JSON or with an event stream carrying the response. The session is
+// initialized on the first call, and again if the server ends it. It is safe for
+// concurrent use.
+type HTTPMCPClient struct {
+	mcpSession
+	serverURL  string
+	authToken  string
+	httpClient *http.Client
+	nextID     atomic.Int64
+
+	mu        sync.Mutex
+	sessionID string // Session ID assigned by the server (empty = none)
+}
+
+// NewHTTPMCPClient creates an HTTPMCPClient for the MCP server at config.ServerURL. It
+// returns an error if ServerURL is missing.
+func NewHTTPMCPClient(config MCPClientConfig) (*HTTPMCPClient, error) {
+	if config.ServerURL == "" {
+		return nil, fmt.Errorf("MCP server URL is required")
+	}
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultMCPTimeout
+	}
+
+	c := &HTTPMCPClient{
+		serverURL:  config.ServerURL,
+		authToken:  config.AuthToken,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	c.mcpSession.transport = c
+	return c, nil
+}
+
+// call sends a request and decodes the result of its response into result.
+func (c *HTTPMCPClient) call(ctx context.Context, method string, params, result interface{}) error {
+	id := c.nextID.Add(1)
+	resp, err := c.post(ctx, mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if method == "initialize" {
+		if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+			c.mu.Lock()
+			c.sessionID = sessionID
+			c.mu.Unlock()
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var response *mcpResponse
+	if mediaType == "text/event-stream" {
+		response, err = readMCPEventStream(resp.Body, id)
+	} else {
+		response = &mcpResponse{}
+		if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+			err = fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return response.decode(result)
+}
+
+// notify sends a notification, which has no response.
+func (c *HTTPMCPClient) notify(ctx context.Context, method string, params interface{}) error {
+	resp, err := c.post(ctx, mcpRequest{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a JSON-RPC message to the server, returning the response if its status is
+// successful. The caller must close the response body.
+func (c *HTTPMCPClient) post(ctx context.Context, message mcpRequest) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serverURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		// The server no longer knows the session, so a new one must be started
+		c.mu.Lock()
+		if c.sessionID == sessionID {
+			c.sessionID = ""
+		}
+		c.mu.Unlock()
+		return nil, errMCPSessionExpired
+	}
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+}
+
+// readMCPEventStream reads the events of an MCP server's event stream until one carries the
+// response to the request with the given ID, skipping the server's requests and
+// notifications.
+func readMCPEventStream(body io.Reader, id int64) (*mcpResponse, error) {
+	reader := bufio.NewReader(body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(value, " "))
+		}
+
+		// A blank line or the end of the stream ends an event
+		if (line == "" || err != nil) && data.Len() > 0 {
+			var response mcpResponse
+			if json.Unmarshal([]byte(data.String()), &response) == nil && response.isResponseTo(id) {
+				return &response, nil
+			}
+			data.Reset()
+		}
+
+		if err == io.EOF {
+			return nil, fmt.Errorf("event stream ended without a response")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event stream: %w", err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMCPServer answers MCP JSON-RPC requests with a fixed set of tools and resources.
+type fakeMCPServer struct {
+	mu      sync.Mutex
+	methods []string // Methods of the requests and notifications received, in order
+}
+
+// handle answers a request or notification, returning nil for notifications.
+func (s *fakeMCPServer) handle(message mcpRequest, params json.RawMessage) *mcpResponse {
+	s.mu.Lock()
+	s.methods = append(s.methods, message.Method)
+	s.mu.Unlock()
+	if message.ID == nil {
+		return nil
+	}
+
+	result, mcpErr := s.result(message.Method, params)
+	response := &mcpResponse{ID: json.RawMessage(fmt.Sprint(*message.ID)), Error: mcpErr}
+	if mcpErr == nil {
+		response.Result, _ = json.Marshal(result)
+	}
+	return response
+}
+
+func (s *fakeMCPServer) result(method string, raw json.RawMessage) (interface{}, *MCPError) {
+	var params struct {
+		Cursor    string                 `json:"cursor"`
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+		URI       string                 `json:"uri"`
+	}
+	json.Unmarshal(raw, &params)
+
+	text := func(text string) map[string]interface{} {
+		return map[string]interface{}{"type": "text", "text": text}
+	}
+	switch method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0.0"},
+		}, nil
+	case "tools/list":
+		// Tools are listed one page at a time
+		if params.Cursor == "" {
+			return map[string]interface{}{
+				"tools":      []MCPToolInfo{{Name: "get_weather", Description: "Gets the weather", InputSchema: weatherSchema}},
+				"nextCursor": "page-2",
+			}, nil
+		}
+		return map[string]interface{}{"tools": []MCPToolInfo{{Name: "get_time", Description: "Gets the time"}}}, nil
+	case "tools/call":
+		switch params.Name {
+		case "get_weather":
+			return map[string]interface{}{"content": []interface{}{text(fmt.Sprintf("Sunny in %v", params.Arguments["location"]))}}, nil
+		case "get_forecast":
+			return map[string]interface{}{
+				"content":           []interface{}{text(`{"high":25}`)},
+				"structuredContent": map[string]interface{}{"high": 25.0},
+			}, nil
+		case "get_map":
+			return map[string]interface{}{"content": []interface{}{
+				text("A map"),
+				map[string]interface{}{"type": "image", "data": "aW1hZ2U=", "mimeType": "image/png"},
+			}}, nil
+		case "broken":
+			return map[string]interface{}{"content": []interface{}{text("weather service unavailable")}, "isError": true}, nil
+		}
+		return nil, &MCPError{Code: -32602, Message: "Unknown tool: " + params.Name}
+	case "resources/list":
+		return map[string]interface{}{"resources": []MCPResourceInfo{{URI: "file:///readme.md", Name: "readme", MIMEType: "text/markdown"}}}, nil
+	case "resources/read":
+		if params.URI != "file:///readme.md" {
+			return nil, &MCPError{Code: -32002, Message: "Resource not found"}
+		}
+		return map[string]interface{}{"contents": []interface{}{
+			map[string]interface{}{"uri": params.URI, "mimeType": "text/markdown", "text": "# Readme"},
+		}}, nil
+	}
+	return nil, &MCPError{Code: -32601, Message: "Method not found"}
+}
+
+// Methods returns the methods of the requests and notifications received, in order.
+func (s *fakeMCPServer) Methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.methods...)
+}
+
+// fakeMCPHTTPServer serves a fakeMCPServer over the MCP Streamable HTTP transport.
+type fakeMCPHTTPServer struct {
+	fakeMCPServer
+	*httptest.Server
+	eventStream bool // Respond with event streams rather than JSON
+
+	sessionMu sync.Mutex
+	sessions  int    // Number of sessions started
+	sessionID string // ID of the current session
+}
+
+func newFakeMCPHTTPServer(t *testing.T, token string) *fakeMCPHTTPServer {
+	s := &fakeMCPHTTPServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var params json.RawMessage
+		message := mcpRequest{Params: &params}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.sessionMu.Lock()
+		if message.Method == "initialize" {
+			s.sessions++
+			s.sessionID = fmt.Sprintf("session-%d", s.sessions)
+			w.Header().Set("Mcp-Session-Id", s.sessionID)
+		} else if r.Header.Get("Mcp-Session-Id") != s.sessionID {
+			s.sessionMu.Unlock()
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		s.sessionMu.Unlock()
+
+		response := s.handle(message, params)
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if !s.eventStream {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		// A notification precedes the response, which is split over several data lines
+		data, _ := json.MarshalIndent(response, "", "  ")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", strings.ReplaceAll(string(data), "\n", "\ndata: "))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// endSession makes the server forget the current session, as when it restarts.
+func (s *fakeMCPHTTPServer) endSession() {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	s.sessionID = ""
+}
+
+// Sessions returns the number of sessions started.
+func (s *fakeMCPHTTPServer) Sessions() int {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	return s.sessions
+}
+
+// testMCPClient exercises every MCPClient method against a fakeMCPServer.
+func testMCPClient(t *testing.T, client MCPClient) {
+	ctx := context.Background()
+
+	tools, err := client.GetAvailableTools(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableTools failed: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "get_weather" || tools[1].Name != "get_time" {
+		t.Fatalf("Expected the tools on both pages, got %+v", tools)
+	}
+	want, _ := json.Marshal(weatherSchema)
+	if got, _ := json.Marshal(tools[0].InputSchema); string(got) != string(want) {
+		t.Errorf("Expected the tool's input schema, got %v", tools[0].InputSchema)
+	}
+
+	tests := []struct {
+		tool string
+		want interface{}
+	}{
+		{"get_weather", "Sunny in Sydney"},
+		{"get_forecast", map[string]interface{}{"high": 25.0}},
+		{"get_map", []map[string]interface{}{
+			{"type": "text", "text": "A map"},
+			{"type": "image", "data": "aW1hZ2U=", "mimeType": "image/png"},
+		}},
+	}
+	for _, tt := range tests {
+		result, err := client.CallTool(ctx, tt.tool, map[string]interface{}{"location": "Sydney"})
+		if err != nil {
+			t.Fatalf("CallTool(%s) failed: %v", tt.tool, err)
+		}
+		if !reflect.DeepEqual(result, tt.want) {
+			t.Errorf("CallTool(%s): expected %#v, got %#v", tt.tool, tt.want, result)
+		}
+	}
+
+	_, err = client.CallTool(ctx, "broken", nil)
+	if err == nil || !strings.Contains(err.Error(), "weather service unavailable") {
+		t.Errorf("Expected a tool error to be returned with its text, got %v", err)
+	}
+	_, err = client.CallTool(ctx, "unknown", nil)
+	var mcpErr *MCPError
+	if !errors.As(err, &mcpErr) || mcpErr.Code != -32602 {
+		t.Errorf("Expected the server's JSON-RPC error, got %v", err)
+	}
+
+	resources, err := client.GetAvailableResources(ctx)
+	if err != nil {
+		t.Fatalf("GetAvailableResources failed: %v", err)
+	}
+	if len(resources) != 1 || resources[0].URI != "file:///readme.md" || resources[0].MIMEType != "text/markdown" {
+		t.Errorf("Expected the readme resource, got %+v", resources)
+	}
+
+	content, mimeType, err := client.ReadResource(ctx, "file:///readme.md")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if content != "# Readme" || mimeType != "text/markdown" {
+		t.Errorf("Expected the readme's content, got %q (%s)", content, mimeType)
+	}
+	if _, _, err := client.ReadResource(ctx, "file:///missing.md"); !errors.As(err, &mcpErr) {
+		t.Errorf("Expected the server's JSON-RPC error for a missing resource, got %v", err)
+	}
+}
+
+func TestHTTPMCPClient(t *testing.T) {
+	for _, eventStream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("eventStream=%v", eventStream), func(t *testing.T) {
+			server := newFakeMCPHTTPServer(t, "secret")
+			server.eventStream = eventStream
+
+			client, err := NewMCPClient(MCPClientConfig{ServerURL: server.URL, AuthToken: "secret"})
+			if err != nil {
+				t.Fatalf("NewMCPClient failed: %v", err)
+			}
+			testMCPClient(t, client)
+
+			// The session is initialized once, before the first request
+			methods := server.Methods()
+			if len(methods) < 2 || methods[0] != "initialize" || methods[1] != "notifications/initialized" {
+				t.Errorf("Expected the initialization handshake first, got %v", methods)
+			}
+			if n := server.Sessions(); n != 1 {
+				t.Errorf("Expected 1 session, got %d", n)
+			}
+		})
+	}
+}
+
+func TestHTTPMCPClient_SessionExpired(t *testing.T) {
+	server := newFakeMCPHTTPServer(t, "")
+	client, err := NewHTTPMCPClient(MCPClientConfig{ServerURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPMCPClient failed: %v", err)
+	}
+	if _, err := client.GetAvailableResources(context.Background()); err != nil {
+		t.Fatalf("GetAvailableResources failed: %v", err)
+	}
+
+	// A new session is started when the server forgets the old one
+	server.endSession()
+	result, err := client.CallTool(context.Background(), "get_weather", map[string]interface{}{"location": "Perth"})
+	if err != nil {
+		t.Fatalf("Expected the call to be retried in a new session, got %v", err)
+	}
+	if result != "Sunny in Perth" {
+		t.Errorf("Expected the tool's result, got %v", result)
+	}
+	if n := server.Sessions(); n != 2 {
+		t.Errorf("Expected 2 sessions, got %d", n)
+	}
+}
+
+func TestHTTPMCPClient_Errors(t *testing.T) {
+	if _, err := NewMCPClient(MCPClientConfig{}); err == nil {
+		t.Error("Expected an error without a server URL")
+	}
+
+	client, err := NewHTTPMCPClient(MCPClientConfig{ServerURL: "http://localhost", Timeout: 5})
+	if err != nil {
+		t.Fatalf("NewHTTPMCPClient failed: %v", err)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected a 5s timeout, got %v", client.httpClient.Timeout)
+	}
+
+	// Failed initialization is retried by the next call
+	server := newFakeMCPHTTPServer(t, "secret")
+	client, err = NewHTTPMCPClient(MCPClientConfig{ServerURL: server.URL, AuthToken: "wrong"})
+	if err != nil {
+		t.Fatalf("NewHTTPMCPClient failed: %v", err)
+	}
+	_, err = client.GetAvailableTools(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+	client.authToken = "secret"
+	if _, err := client.GetAvailableTools(context.Background()); err != nil {
+		t.Errorf("Expected initialization to be retried, got %v", err)
+	}
+}
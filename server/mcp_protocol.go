@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// mcpProtocolVersion is the MCP protocol version the MCP clients request when initializing.
+const mcpProtocolVersion = "2025-03-26"
+
+// mcpClientName is the name the MCP clients give the server when initializing.
+const mcpClientName = "go-a2a"
+
+// MCPError is a JSON-RPC error returned by an MCP server.
+type MCPError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the standard Go error interface.
+func (e *MCPError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+// mcpRequest is a JSON-RPC request or, without an ID, notification sent to an MCP server.
+type mcpRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// mcpResponse is a JSON-RPC message received from an MCP server. Messages with a method are
+// requests or notifications from the server rather than responses.
+type mcpResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *MCPError       `json:"error,omitempty"`
+}
+
+// isResponseTo reports whether the message is the response to the request with the given ID.
+func (r *mcpResponse) isResponseTo(id int64) bool {
+	if r.Method != "" {
+		return false
+	}
+	var got int64
+	return json.Unmarshal(r.ID, &got) == nil && got == id
+}
+
+// decode returns the response's error, or decodes its result into result.
+func (r *mcpResponse) decode(result interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if result == nil || len(r.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+
+// errMCPSessionExpired is returned by an mcpTransport when the server no longer knows its
+// session, so the session must be initialized again.
+var errMCPSessionExpired = errors.New("MCP session expired")
+
+// mcpTransport sends JSON-RPC messages to an MCP server.
+type mcpTransport interface {
+	// call sends a request and decodes the result of its response into result.
+	call(ctx context.Context, method string, params, result interface{}) error
+
+	// notify sends a notification, which has no response.
+	notify(ctx context.Context, method string, params interface{}) error
+}
+
+// mcpSession implements the MCP methods of MCPClient over a transport, initializing the
+// session before the first request.
+type mcpSession struct {
+	transport mcpTransport
+
+	mu          sync.Mutex // Held while initializing
+	initialized bool
+}
+
+// request initializes the session if needed, then sends a request. If the session has
+// expired, it is initialized again and the request retried once.
+func (s *mcpSession) request(ctx context.Context, method string, params, result interface{}) error {
+	if err := s.initialize(ctx); err != nil {
+		return err
+	}
+	err := s.transport.call(ctx, method, params, result)
+	if !errors.Is(err, errMCPSessionExpired) {
+		return err
+	}
+
+	s.mu.Lock()
+	s.initialized = false
+	s.mu.Unlock()
+	if err := s.initialize(ctx); err != nil {
+		return err
+	}
+	return s.transport.call(ctx, method, params, result)
+}
+
+// initialize performs the MCP initialization handshake, unless it has already succeeded.
+func (s *mcpSession) initialize(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initialized {
+		return nil
+	}
+
+	params := map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": mcpClientName},
+	}
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := s.transport.call(ctx, "initialize", params, &result); err != nil {
+		return fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+	if err := s.transport.notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+	s.initialized = true
+	return nil
+}
+
+// CallTool calls an MCP tool with the given name and parameters. The result is the tool's
+// structured content if it returned any, its text if it returned only text, and otherwise
+// its content items. A result the tool flags as an error is returned as an error.
+func (s *mcpSession) CallTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	var result struct {
+		Content           []map[string]interface{} `json:"content"`
+		StructuredContent interface{}              `json:"structuredContent"`
+		IsError           bool                     `json:"isError"`
+	}
+	err := s.request(ctx, "tools/call", map[string]interface{}{"name": toolName, "arguments": params}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call tool %q: %w", toolName, err)
+	}
+
+	texts := make([]string, 0, len(result.Content))
+	for _, item := range result.Content {
+		if text, ok := item["text"].(string); ok && item["type"] == "text" {
+			texts = append(texts, text)
+		}
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("tool %q failed: %s", toolName, strings.Join(texts, "\n"))
+	}
+	switch {
+	case result.StructuredContent != nil:
+		return result.StructuredContent, nil
+	case len(texts) == len(result.Content):
+		return strings.Join(texts, "\n"), nil
+	default:
+		return result.Content, nil
+	}
+}
+
+// ReadResource reads an MCP resource with the given URI, returning its content and MIME
+// type. Binary content is returned base64-encoded.
+func (s *mcpSession) ReadResource(ctx context.Context, uri string) (string, string, error) {
+	var result struct {
+		Contents []struct {
+			URI      string  `json:"uri"`
+			MIMEType string  `json:"mimeType"`
+			Text     *string `json:"text"`
+			Blob     string  `json:"blob"`
+		} `json:"contents"`
+	}
+	if err := s.request(ctx, "resources/read", map[string]interface{}{"uri": uri}, &result); err != nil {
+		return "", "", fmt.Errorf("failed to read resource %q: %w", uri, err)
+	}
+	if len(result.Contents) == 0 {
+		return "", "", fmt.Errorf("resource %q has no contents", uri)
+	}
+
+	contents := result.Contents[0]
+	if contents.Text != nil {
+		return *contents.Text, contents.MIMEType, nil
+	}
+	return contents.Blob, contents.MIMEType, nil
+}
+
+// GetAvailableTools returns the tools the MCP server lists, following its pagination.
+func (s *mcpSession) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	var tools []MCPToolInfo
+	err := s.list(ctx, "tools/list", func(page json.RawMessage) (string, error) {
+		var result struct {
+			Tools      []MCPToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		err := json.Unmarshal(page, &result)
+		tools = append(tools, result.Tools...)
+		return result.NextCursor, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	return tools, nil
+}
+
+// GetAvailableResources returns the resources the MCP server lists, following its pagination.
+func (s *mcpSession) GetAvailableResources(ctx context.Context) ([]MCPResourceInfo, error) {
+	var resources []MCPResourceInfo
+	err := s.list(ctx, "resources/list", func(page json.RawMessage) (string, error) {
+		var result struct {
+			Resources  []MCPResourceInfo `json:"resources"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		err := json.Unmarshal(page, &result)
+		resources = append(resources, result.Resources...)
+		return result.NextCursor, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	return resources, nil
+}
+
+// list calls a paginated list method, passing each page of results to add, which returns
+// the cursor of the next page (empty on the last page).
+func (s *mcpSession) list(ctx context.Context, method string, add func(page json.RawMessage) (string, error)) error {
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page json.RawMessage
+		if err := s.request(ctx, method, params, &page); err != nil {
+			return err
+		}
+		next, err := add(page)
+		if err != nil {
+			return fmt.Errorf("failed to decode result: %w", err)
+		}
+		if next == "" || next == cursor {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,381 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stdioCloseTimeout is how long Close waits for an MCP server subprocess to exit after its
+// stdin is closed before killing it.
+const stdioCloseTimeout = 5 * time.Second
+
+// maxStderrLine is the maximum length in bytes of a line of an MCP server's stderr kept for
+// error messages.
+const maxStderrLine = 1024
+
+// StdioMCPConfig contains configuration options for a StdioMCPClient.
+type StdioMCPConfig struct {
+	// Command is the MCP server executable to launch.
+	Command string
+
+	// Args are the arguments to pass to the command.
+	Args []string
+
+	// Env are extra environment variables for the command, as "KEY=value", added to the
+	// environment of this process.
+	Env []string
+
+	// Dir is the working directory of the command (empty = this process's working directory).
+	Dir string
+
+	// Stderr receives the command's stderr. If nil, each line is logged.
+	Stderr io.Writer
+
+	// Timeout is the timeout for MCP requests, in seconds (0 = DefaultMCPTimeout).
+	Timeout int
+}
+
+// StdioMCPClient is an MCPClient that launches an MCP server as a subprocess and talks to it
+// over the MCP stdio transport: newline-delimited JSON-RPC messages on the subprocess's stdin
+// and stdout. The subprocess is launched once and serves every call, and its stderr is kept
+// apart from the messages. The session is initialized on the first call. It is safe for
+// concurrent use; Close stops the subprocess.
+type StdioMCPClient struct {
+	mcpSession
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *mcpStderr
+	timeout time.Duration
+	nextID  atomic.Int64
+
+	writes    chan stdioWrite // Messages for the writer goroutine, which alone writes to stdin
+	replies   chan []byte     // Answers to the subprocess's requests, written by the writer goroutine
+	closing   chan struct{}   // Closed by Close, making the writer goroutine close stdin
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	pending map[int64]chan *mcpResponse // Channels awaiting the responses to requests, keyed by ID
+	exitErr error                       // Why the subprocess exited (nil while it runs)
+	done    chan struct{}               // Closed once the subprocess has exited
+}
+
+// stdioWrite is a message waiting to be written to the subprocess's stdin.
+type stdioWrite struct {
+	data   []byte
+	result chan error // Receives the result of the write
+}
+
+// maxPendingReplies is the number of answers to the subprocess's requests that may wait to
+// be written before further requests are left unanswered.
+const maxPendingReplies = 16
+
+// NewStdioMCPClient launches the MCP server command in config and returns a client
+// connected to it. It returns an error if the command cannot be started.
+func NewStdioMCPClient(config StdioMCPConfig) (*StdioMCPClient, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("MCP server command is required")
+	}
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultMCPTimeout
+	}
+
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Dir = config.Dir
+	if len(config.Env) > 0 {
+		cmd.Env = append(os.Environ(), config.Env...)
+	}
+	stderr := &mcpStderr{command: config.Command, out: config.Stderr}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server %q: %w", config.Command, err)
+	}
+
+	c := &StdioMCPClient{
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		timeout: timeout,
+		writes:  make(chan stdioWrite),
+		replies: make(chan []byte, maxPendingReplies),
+		closing: make(chan struct{}),
+		pending: make(map[int64]chan *mcpResponse),
+		done:    make(chan struct{}),
+	}
+	c.mcpSession.transport = c
+	go c.write()
+	go c.read(stdout)
+	return c, nil
+}
+
+// Close stops the MCP server subprocess by closing its stdin, killing it if it has not
+// exited within a few seconds. Calls made after Close fail.
+func (c *StdioMCPClient) Close() error {
+	c.closeOnce.Do(func() { close(c.closing) })
+
+	select {
+	case <-c.done:
+	case <-time.After(stdioCloseTimeout):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+	return nil
+}
+
+// call sends a request and decodes the result of its response into result.
+func (c *StdioMCPClient) call(ctx context.Context, method string, params, result interface{}) error {
+	id := c.nextID.Add(1)
+	responses := make(chan *mcpResponse, 1)
+	c.mu.Lock()
+	if c.exitErr != nil {
+		c.mu.Unlock()
+		return c.exitErr
+	}
+	c.pending[id] = responses
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if err := c.send(ctx, mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responses:
+		return response.decode(result)
+	case <-c.done:
+		select {
+		case response := <-responses:
+			// The response arrived before the subprocess exited
+			return response.decode(result)
+		default:
+		}
+		return c.exitError()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notify sends a notification, which has no response.
+func (c *StdioMCPClient) notify(ctx context.Context, method string, params interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.send(ctx, mcpRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// send passes a message to the writer goroutine, which writes it to the subprocess's stdin
+// on a line of its own, and waits for the write. It gives up when ctx is done, as a
+// subprocess that stops reading its stdin would otherwise block the caller forever.
+func (c *StdioMCPClient) send(ctx context.Context, message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	result := make(chan error, 1)
+	select {
+	case c.writes <- stdioWrite{data: append(data, '\n'), result: result}:
+	case <-c.closing:
+		return fmt.Errorf("MCP server %q is closed", c.cmd.Path)
+	case <-c.done:
+		return c.exitError()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.exitErr != nil {
+				return c.exitErr
+			}
+			return fmt.Errorf("failed to write to MCP server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exitError returns why the subprocess exited.
+func (c *StdioMCPClient) exitError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exitErr
+}
+
+// write writes messages and replies to the subprocess's stdin, one at a time, until the
+// client is closed or the subprocess exits. Being the only writer, it keeps messages whole
+// without a lock that a blocked write would leave held.
+func (c *StdioMCPClient) write() {
+	defer c.stdin.Close()
+	for {
+		select {
+		case w := <-c.writes:
+			_, err := c.stdin.Write(w.data)
+			w.result <- err
+		case reply := <-c.replies:
+			if _, err := c.stdin.Write(reply); err != nil {
+				fmt.Printf("Failed to answer a request from MCP server %q: %v\n", c.cmd.Path, err)
+			}
+		case <-c.closing:
+			return
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// read passes each message the subprocess writes to stdout to dispatch until stdout is
+// closed, then waits for the subprocess to exit and fails the requests awaiting responses.
+func (c *StdioMCPClient) read(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			c.dispatch(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	exitErr := fmt.Errorf("MCP server %q exited", c.cmd.Path)
+	if err := c.cmd.Wait(); err != nil {
+		exitErr = fmt.Errorf("MCP server %q exited: %w", c.cmd.Path, err)
+	}
+	if last := c.stderr.lastLine(); last != "" {
+		exitErr = fmt.Errorf("%w (stderr: %s)", exitErr, last)
+	}
+	c.mu.Lock()
+	c.exitErr = exitErr
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// dispatch passes a response to the request awaiting it, and answers the subprocess's
+// requests. Notifications and lines that are not JSON-RPC messages are ignored.
+func (c *StdioMCPClient) dispatch(line []byte) {
+	var message mcpResponse
+	if err := json.Unmarshal(line, &message); err != nil {
+		fmt.Printf("Ignoring invalid message from MCP server %q: %v\n", c.cmd.Path, err)
+		return
+	}
+
+	if message.Method != "" {
+		if len(message.ID) == 0 {
+			return
+		}
+		// Only pings are supported, as the client declares no capabilities
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": message.ID}
+		if message.Method == "ping" {
+			reply["result"] = map[string]interface{}{}
+		} else {
+			reply["error"] = MCPError{Code: -32601, Message: "Method not found"}
+		}
+		// The reply is queued for the writer goroutine, as writing here could block reading
+		// the responses the subprocess is waiting to send
+		data, _ := json.Marshal(reply)
+		select {
+		case c.replies <- append(data, '\n'):
+		default:
+			fmt.Printf("Not answering %s request from MCP server %q: too many replies pending\n", message.Method, c.cmd.Path)
+		}
+		return
+	}
+
+	var id int64
+	if err := json.Unmarshal(message.ID, &id); err != nil {
+		return
+	}
+	c.mu.Lock()
+	responses, ok := c.pending[id]
+	c.mu.Unlock()
+	if ok {
+		responses <- &message
+	}
+}
+
+// mcpStderr receives an MCP server subprocess's stderr, passing it on to a writer or logging
+// it line by line, and keeps the last line for error messages.
+type mcpStderr struct {
+	command string
+	out     io.Writer // Receives the stderr (nil = log each line)
+
+	mu      sync.Mutex
+	partial []byte // Start of a line not yet ended
+	last    string // Last non-empty line
+}
+
+// Write implements io.Writer.
+func (s *mcpStderr) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out != nil {
+		s.out.Write(p)
+	}
+
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(s.partial[:i]))
+		s.partial = s.partial[i+1:]
+		if line == "" {
+			continue
+		}
+		s.last = truncateLine(line)
+		if s.out == nil {
+			fmt.Printf("MCP server %q: %s\n", s.command, line)
+		}
+	}
+	if len(s.partial) > maxStderrLine {
+		s.partial = s.partial[len(s.partial)-maxStderrLine:]
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last line written, including one not yet ended.
+func (s *mcpStderr) lastLine() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if line := strings.TrimSpace(string(s.partial)); line != "" {
+		return truncateLine(line)
+	}
+	return s.last
+}
+
+// truncateLine shortens line to maxStderrLine bytes.
+func truncateLine(line string) string {
+	if len(line) > maxStderrLine {
+		return line[:maxStderrLine] + "..."
+	}
+	return line
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMCPSubprocessEnv is set in the environment of the test binary when it is launched as a
+// fake MCP server subprocess.
+const fakeMCPSubprocessEnv = "GO_A2A_FAKE_MCP_SUBPROCESS"
+
+// TestFakeMCPSubprocess is not a test: launched by newFakeMCPSubprocess, it serves a
+// fakeMCPServer over stdio. It also writes a line that is not a JSON-RPC message to stdout,
+// pings the client while initializing, and exits with an error when the tool "crash" is called.
+func TestFakeMCPSubprocess(t *testing.T) {
+	if os.Getenv(fakeMCPSubprocessEnv) != "1" {
+		t.Skip("Only run as a fake MCP server subprocess")
+	}
+
+	fmt.Fprintln(os.Stderr, "fake MCP server starting")
+	fmt.Println("not a JSON-RPC message")
+
+	var server fakeMCPServer
+	pinged := false
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var params json.RawMessage
+		message := struct {
+			mcpRequest
+			Result json.RawMessage `json:"result"`
+		}{mcpRequest: mcpRequest{Params: &params}}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid message: %v\n", err)
+			os.Exit(2)
+		}
+
+		var name struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(params, &name)
+		switch {
+		case message.Method == "":
+			// The client's answer to the ping
+			pinged = message.Result != nil
+			continue
+		case message.Method == "initialize":
+			fmt.Println(`{"jsonrpc":"2.0","id":1000,"method":"ping"}`)
+		case message.Method == "tools/list" && !pinged:
+			fmt.Fprintln(os.Stderr, "the ping was not answered")
+			os.Exit(2)
+		case message.Method == "tools/call" && name.Name == "crash":
+			fmt.Fprintln(os.Stderr, "fatal: the weather service crashed")
+			os.Exit(3)
+		}
+
+		if response := server.handle(message.mcpRequest, params); response != nil {
+			data, _ := json.Marshal(response)
+			fmt.Println(string(data))
+		}
+	}
+	os.Exit(0)
+}
+
+// newFakeMCPSubprocess launches a fake MCP server subprocess, writing its stderr to stderr,
+// and returns a client connected to it.
+func newFakeMCPSubprocess(t *testing.T, stderr *syncBuffer) *StdioMCPClient {
+	client, err := NewStdioMCPClient(StdioMCPConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeMCPSubprocess$"},
+		Env:     []string{fakeMCPSubprocessEnv + "=1"},
+		Stderr:  stderr,
+	})
+	if err != nil {
+		t.Fatalf("NewStdioMCPClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStdioMCPClient(t *testing.T) {
+	var stderr syncBuffer
+	client := newFakeMCPSubprocess(t, &stderr)
+	testMCPClient(t, client)
+
+	// Concurrent calls share the subprocess, each receiving its own response
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			location := fmt.Sprintf("city-%d", i)
+			result, err := client.CallTool(context.Background(), "get_weather", map[string]interface{}{"location": location})
+			if err == nil && result != "Sunny in "+location {
+				err = fmt.Errorf("expected the weather in %s, got %v", location, result)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "fake MCP server starting") {
+		t.Errorf("Expected the subprocess's stderr to be passed on, got %q", stderr.String())
+	}
+	if _, err := client.GetAvailableResources(context.Background()); err == nil {
+		t.Error("Expected calls after Close to fail")
+	}
+}
+
+func TestStdioMCPClient_SubprocessExits(t *testing.T) {
+	client := newFakeMCPSubprocess(t, &syncBuffer{})
+
+	_, err := client.CallTool(context.Background(), "crash", nil)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "the weather service crashed") {
+		t.Errorf("Expected an error with the exit status and the last line of stderr, got %v", err)
+	}
+	if _, err := client.CallTool(context.Background(), "get_weather", nil); err == nil {
+		t.Error("Expected calls after the subprocess exited to fail")
+	}
+}
+
+func TestNewStdioMCPClient_Errors(t *testing.T) {
+	if _, err := NewStdioMCPClient(StdioMCPConfig{}); err == nil {
+		t.Error("Expected an error without a command")
+	}
+	if _, err := NewStdioMCPClient(StdioMCPConfig{Command: "go-a2a-no-such-mcp-server"}); err == nil {
+		t.Error("Expected an error for a command that does not exist")
+	}
+}
+
+func TestStdioMCPClient_SendRespectsContext(t *testing.T) {
+	// A subprocess that does not read its stdin blocks writes to it
+	stdout, stdin := io.Pipe()
+	defer stdout.Close()
+	c := &StdioMCPClient{
+		cmd:     &exec.Cmd{Path: "stuck-mcp-server"},
+		stdin:   stdin,
+		timeout: time.Minute,
+		writes:  make(chan stdioWrite),
+		replies: make(chan []byte, maxPendingReplies),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go c.write()
+	defer close(c.closing)
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		err := c.send(ctx, mcpRequest{JSONRPC: "2.0", Method: "notifications/initialized"})
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Send %d: expected the context deadline to end the send, got %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrToolNotAllowed is returned when calling an MCP tool that a ToolFilter does not allow.
+var ErrToolNotAllowed = errors.New("tool not allowed")
+
+// ToolFilter restricts the MCP tools an agent may call. Tools are matched by name, or by
+// glob patterns as in path.Match (e.g., "github_*").
+type ToolFilter struct {
+	Allow []string // Tools that may be called (empty = every tool not denied)
+	Deny  []string // Tools that may not be called, even if allowed
+}
+
+// Allows reports whether the filter allows the named tool.
+func (f ToolFilter) Allows(name string) bool {
+	if matchesAny(f.Deny, name) {
+		return false
+	}
+	return len(f.Allow) == 0 || matchesAny(f.Allow, name)
+}
+
+// validate checks that the filter's patterns are valid.
+func (f ToolFilter) validate() error {
+	for _, pattern := range append(append([]string(nil), f.Allow...), f.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesAny reports whether name matches any of patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredMCPClient is an MCPClient that only lists and calls the tools its filter allows.
+type filteredMCPClient struct {
+	MCPClient
+	filter ToolFilter
+}
+
+// NewFilteredMCPClient wraps client so that only the tools filter allows are listed by
+// GetAvailableTools, keeping the others out of agents' system prompts, and can be called
+// with CallTool, which returns ErrToolNotAllowed for the others even if a model asks for
+// them. It returns an error if a pattern is invalid.
+func NewFilteredMCPClient(client MCPClient, filter ToolFilter) (MCPClient, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	return &filteredMCPClient{MCPClient: client, filter: filter}, nil
+}
+
+// CallTool calls the named tool if the filter allows it.
+func (c *filteredMCPClient) CallTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
+	if !c.filter.Allows(toolName) {
+		return nil, fmt.Errorf("%w: %q", ErrToolNotAllowed, toolName)
+	}
+	return c.MCPClient.CallTool(ctx, toolName, params)
+}
+
+// GetAvailableTools returns the available tools the filter allows.
+func (c *filteredMCPClient) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	tools, err := c.MCPClient.GetAvailableTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make([]MCPToolInfo, 0, len(tools))
+	for _, tool := range tools {
+		if c.filter.Allows(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed, nil
+}
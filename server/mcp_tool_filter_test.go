@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// callRecordingMCPClient is a fakeMCPClient that records the tools called and their params.
+type callRecordingMCPClient struct {
+	fakeMCPClient
+	called []string
+	params []map[string]interface{}
+}
+
+func (c *callRecordingMCPClient) CallTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
+	c.called = append(c.called, toolName)
+	c.params = append(c.params, params)
+	return c.fakeMCPClient.CallTool(ctx, toolName, params)
+}
+
+func TestToolFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ToolFilter
+		tool   string
+		want   bool
+	}{
+		{"no lists", ToolFilter{}, "weather", true},
+		{"allowed by name", ToolFilter{Allow: []string{"weather"}}, "weather", true},
+		{"not allowed", ToolFilter{Allow: []string{"weather"}}, "shell", false},
+		{"allowed by glob", ToolFilter{Allow: []string{"github_*"}}, "github_search", true},
+		{"denied by name", ToolFilter{Deny: []string{"shell"}}, "shell", false},
+		{"denied by glob", ToolFilter{Deny: []string{"*_delete"}}, "file_delete", false},
+		{"deny wins", ToolFilter{Allow: []string{"file_*"}, Deny: []string{"file_delete"}}, "file_delete", false},
+		{"allowed and not denied", ToolFilter{Allow: []string{"file_*"}, Deny: []string{"file_delete"}}, "file_read", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.tool); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFilteredMCPClient(&fakeMCPClient{}, ToolFilter{Deny: []string{"[invalid"}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+// runFilteredToolCall processes a task with an MCPToolAugmentedAgent whose LLM asks for
+// tool, using client filtered by filter, and returns the final status update and the agent.
+func runFilteredToolCall(t *testing.T, client MCPClient, filter ToolFilter, tool string) (task.StatusUpdate, *MCPToolAugmentedAgent) {
+	t.Helper()
+
+	filtered, err := NewFilteredMCPClient(client, filter)
+	if err != nil {
+		t.Fatalf("NewFilteredMCPClient failed: %v", err)
+	}
+	fake := &fakeLLM{stream: `{"tool": "` + tool + `", "params": {}}`, reply: "Done."}
+	agent, err := NewMCPToolAugmentedAgent(fake, filtered)
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	updates, err := agent.ProcessTask(context.Background(), task.Context{
+		TaskID:      "task-1",
+		UserMessage: newTextMessage(a2a.RoleUser, "Go ahead."),
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask failed: %v", err)
+	}
+	var last task.StatusUpdate
+	for update := range updates {
+		if u, ok := update.(task.StatusUpdate); ok {
+			last = u
+		}
+	}
+	return last, agent
+}
+
+func TestMCPToolAugmentedAgent_DeniedTool(t *testing.T) {
+	client := &callRecordingMCPClient{fakeMCPClient: fakeMCPClient{results: map[string]interface{}{
+		"weather": "sunny",
+		"shell":   "rm -rf /",
+	}}}
+	filter := ToolFilter{Deny: []string{"shell"}}
+
+	// The model asks for the denied tool anyway
+	last, agent := runFilteredToolCall(t, client, filter, "shell")
+	if last.State != a2a.TaskStateFailed {
+		t.Fatalf("Expected the task to fail, got %s", last.State)
+	}
+	if text := last.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, "not allowed") {
+		t.Errorf("Expected the failure to say the tool is not allowed, got %q", text)
+	}
+	if len(client.called) != 0 {
+		t.Errorf("Expected the denied tool not to be called, got calls %v", client.called)
+	}
+
+	// The denied tool is left out of the system prompt
+	if systemPrompt := agent.systemPrompt; strings.Contains(systemPrompt, "shell") || !strings.Contains(systemPrompt, "weather") {
+		t.Errorf("Expected the system prompt to list only the allowed tools, got %q", systemPrompt)
+	}
+
+	filtered, _ := NewFilteredMCPClient(client, filter)
+	if _, err := filtered.CallTool(context.Background(), "shell", nil); !errors.Is(err, ErrToolNotAllowed) {
+		t.Errorf("Expected ErrToolNotAllowed, got %v", err)
+	}
+}
+
+func TestMCPToolAugmentedAgent_AllowedTool(t *testing.T) {
+	client := &callRecordingMCPClient{fakeMCPClient: fakeMCPClient{results: map[string]interface{}{
+		"weather": "sunny",
+		"shell":   "rm -rf /",
+	}}}
+
+	last, _ := runFilteredToolCall(t, client, ToolFilter{Allow: []string{"weath*"}}, "weather")
+	if last.State != a2a.TaskStateCompleted {
+		t.Fatalf("Expected the task to complete, got %s", last.State)
+	}
+	if len(client.called) != 1 || client.called[0] != "weather" {
+		t.Errorf("Expected the allowed tool to be called, got calls %v", client.called)
+	}
+}
@@ -3,10 +3,12 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
 )
 
 // AuthKey is the context key for storing authentication information.
@@ -23,12 +25,41 @@ type AuthInfo struct {
 // AuthValidator is a function that validates authentication information.
 type AuthValidator func(ctx context.Context, info AuthInfo) (bool, error)
 
+// protectedAgentCardKey is the context key marking agent card requests that must be authenticated.
+type protectedAgentCardKey struct{}
+
+// WithProtectedAgentCard returns a context that makes AuthMiddleware authenticate agent card
+// requests, which are otherwise served without authentication.
+func WithProtectedAgentCard(ctx context.Context) context.Context {
+	return context.WithValue(ctx, protectedAgentCardKey{}, true)
+}
+
+// isAgentCardProtected reports whether agent card requests must be authenticated.
+func isAgentCardProtected(ctx context.Context) bool {
+	protected, _ := ctx.Value(protectedAgentCardKey{}).(bool)
+	return protected
+}
+
+// identityHolderKey is the context key for the identity a validator sets with SetIdentity.
+type identityHolderKey struct{}
+
+// SetIdentity records the identity of the caller whose credentials are being validated. It
+// is called by an AuthValidator with the context it was given. Once the credentials are
+// accepted, the identity is available from the request context with task.IdentityFromContext,
+// and to task handlers as task.Context.Identity. Identities from validators that do not set
+// one only carry the authentication type.
+func SetIdentity(ctx context.Context, identity task.Identity) {
+	if holder, ok := ctx.Value(identityHolderKey{}).(*task.Identity); ok {
+		*holder = identity
+	}
+}
+
 // AuthMiddleware creates middleware that authenticates requests based on the agent card's authentication schemes.
 func AuthMiddleware(card *a2a.AgentCard, validator AuthValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip authentication for agent card requests
-			if strings.HasSuffix(r.URL.Path, "/.well-known/agent.json") {
+			// Skip authentication for agent card requests unless the card is protected
+			if strings.HasSuffix(r.URL.Path, "/.well-known/agent.json") && !isAgentCardProtected(r.Context()) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -44,9 +75,7 @@ func AuthMiddleware(card *a2a.AgentCard, validator AuthValidator) func(http.Hand
 			if err != nil {
 				// Convert error to A2A error if needed
 				var a2aErr *a2a.Error
-				if e, ok := err.(*a2a.Error); ok {
-					a2aErr = e
-				} else {
+				if !errors.As(err, &a2aErr) {
 					a2aErr = a2a.WrapError(err, a2a.CodeAuthenticationFailed, "Authentication extraction failed")
 				}
 				writeAuthError(w, r, a2aErr)
@@ -60,8 +89,9 @@ func AuthMiddleware(card *a2a.AgentCard, validator AuthValidator) func(http.Hand
 			}
 
 			// Validate the authentication information
+			identity := &task.Identity{}
 			if validator != nil {
-				valid, err := validator(r.Context(), *authInfo)
+				valid, err := validator(context.WithValue(r.Context(), identityHolderKey{}, identity), *authInfo)
 				if err != nil {
 					writeAuthError(w, r, a2a.WrapError(err, a2a.CodeAuthenticationFailed, "Authentication validation failed"))
 					return
@@ -72,8 +102,13 @@ func AuthMiddleware(card *a2a.AgentCard, validator AuthValidator) func(http.Hand
 				}
 			}
 
-			// Add authentication information to the request context
+			if identity.Scheme == "" {
+				identity.Scheme = authInfo.Type
+			}
+
+			// Add authentication information and the caller's identity to the request context
 			ctx := context.WithValue(r.Context(), AuthKey{}, authInfo)
+			ctx = task.WithIdentity(ctx, identity)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultCompressionThreshold is the minimum response size in bytes that is compressed by default.
+const DefaultCompressionThreshold = 1024
+
+// CompressionMiddleware creates middleware that decompresses gzip request bodies and
+// compresses responses with gzip when the client accepts it and the body is at least
+// threshold bytes. Streaming (SSE) responses are never compressed.
+func CompressionMiddleware(threshold int) func(http.Handler) http.Handler {
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Decompress gzip request bodies
+			if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+				gz, err := gzip.NewReader(r.Body)
+				if err != nil {
+					http.Error(w, "Invalid gzip request body", http.StatusBadRequest)
+					return
+				}
+				defer gz.Close()
+				r.Body = gz
+				r.Header.Del("Content-Encoding")
+				r.Header.Del("Content-Length")
+				r.ContentLength = -1
+			}
+
+			if !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, threshold: threshold}
+			next.ServeHTTP(gw, r)
+			gw.finish()
+		})
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding header includes gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if strings.EqualFold(strings.TrimSpace(strings.Split(encoding, ";")[0]), "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the response so it can decide whether to compress it once the
+// size is known. It switches to pass-through for streaming responses.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	threshold   int
+	buf         bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+// WriteHeader records the status code, or writes it immediately for streaming responses.
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.passthrough || g.status != 0 {
+		return
+	}
+	g.status = status
+
+	if strings.HasPrefix(g.Header().Get("Content-Type"), "text/event-stream") {
+		g.passthrough = true
+		g.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write buffers the body, or writes it immediately for streaming responses.
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if g.status == 0 {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.passthrough {
+		return g.ResponseWriter.Write(b)
+	}
+	return g.buf.Write(b)
+}
+
+// Flush switches to pass-through, since a flushing handler is streaming its response.
+func (g *gzipResponseWriter) Flush() {
+	if !g.passthrough {
+		if g.status == 0 {
+			g.status = http.StatusOK
+		}
+		g.passthrough = true
+		g.ResponseWriter.WriteHeader(g.status)
+		g.ResponseWriter.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+	if flusher, ok := g.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish writes the buffered response, compressing it if it is large enough.
+func (g *gzipResponseWriter) finish() {
+	if g.passthrough {
+		return
+	}
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+
+	g.Header().Add("Vary", "Accept-Encoding")
+
+	if g.buf.Len() < g.threshold || g.Header().Get("Content-Encoding") != "" {
+		if g.buf.Len() > 0 {
+			g.Header().Set("Content-Length", strconv.Itoa(g.buf.Len()))
+		}
+		g.ResponseWriter.WriteHeader(g.status)
+		g.ResponseWriter.Write(g.buf.Bytes())
+		return
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(g.buf.Bytes())
+	gz.Close()
+
+	g.Header().Set("Content-Encoding", "gzip")
+	g.Header().Set("Content-Length", strconv.Itoa(compressed.Len()))
+	g.ResponseWriter.WriteHeader(g.status)
+	g.ResponseWriter.Write(compressed.Bytes())
+}
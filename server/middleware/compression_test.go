@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat("a", DefaultCompressionThreshold*2)
+
+	tests := []struct {
+		name           string
+		body           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{name: "large body accepted", body: large, acceptEncoding: "gzip, deflate", wantGzip: true},
+		{name: "small body", body: "small", acceptEncoding: "gzip", wantGzip: false},
+		{name: "gzip not accepted", body: large, acceptEncoding: "", wantGzip: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CompressionMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/a2a", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			body := rec.Body.Bytes()
+			if tt.wantGzip {
+				if rec.Header().Get("Content-Encoding") != "gzip" {
+					t.Fatal("Expected gzip Content-Encoding")
+				}
+				gz, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("Failed to create gzip reader: %v", err)
+				}
+				if body, err = io.ReadAll(gz); err != nil {
+					t.Fatalf("Failed to decompress body: %v", err)
+				}
+			} else if rec.Header().Get("Content-Encoding") != "" {
+				t.Errorf("Expected no Content-Encoding, got %q", rec.Header().Get("Content-Encoding"))
+			}
+
+			if string(body) != tt.body {
+				t.Errorf("Body mismatch: got %d bytes, want %d bytes", len(body), len(tt.body))
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_DecompressesRequest(t *testing.T) {
+	var received string
+	handler := CompressionMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"jsonrpc":"2.0"}`))
+	gz.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/a2a", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received != `{"jsonrpc":"2.0"}` {
+		t.Errorf("Expected decompressed body, got %q", received)
+	}
+}
+
+func TestCompressionMiddleware_StreamingPassthrough(t *testing.T) {
+	handler := CompressionMiddleware(1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: hello\n\n"))
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/a2a/sse", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected streaming response not to be compressed")
+	}
+	if rec.Body.String() != "data: hello\n\n" {
+		t.Errorf("Unexpected body %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/redact"
+)
+
+// maxLoggedResponseSize is the largest response body inspected for a JSON-RPC error.
+const maxLoggedResponseSize = 64 * 1024
+
+// maxLoggedRequestSize is the largest request body read for logging. The rest of a larger
+// body is left for the handler, which enforces its own size limit.
+const maxLoggedRequestSize = 64 * 1024
+
+// maxLoggedParamsLength is the length in bytes beyond which logged params are truncated.
+const maxLoggedParamsLength = 4096
+
+// RequestLoggingMiddleware creates middleware that logs each JSON-RPC call's method, ID,
+// duration, and outcome, along with its params with the given JSON paths redacted (see
+// redact.JSON). Requests that are not JSON-RPC calls, such as agent card fetches, are not logged.
+// Only the first 64 KiB of a request are read for logging; the params of a larger request are
+// not logged, and long params are truncated.
+func RequestLoggingMiddleware(logger *slog.Logger, redactFields []string) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Read the start of the body so it can be logged, then restore it for the handler
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedRequestSize+1))
+			r.Body = restoredBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var request a2a.JSONRPCRequest
+			truncated := len(body) > maxLoggedRequestSize
+			if truncated {
+				request = scanJSONRPCRequest(body)
+			} else if err := json.Unmarshal(body, &request); err != nil {
+				request = a2a.JSONRPCRequest{}
+			}
+			if request.Method == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			lw := &loggingResponseWriter{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(lw, r)
+
+			attrs := []any{
+				"method", request.Method,
+				"id", request.ID,
+				"duration", time.Since(start),
+				"status", lw.statusCode(),
+			}
+			switch {
+			case truncated:
+				attrs = append(attrs, "params", "(omitted: request body too large)")
+			case len(request.Params) > 0:
+				attrs = append(attrs, "params", truncateParams(string(redact.JSON(request.Params, redactFields))))
+			}
+			if rpcErr := lw.jsonRPCError(); rpcErr != nil {
+				attrs = append(attrs, "outcome", "error", "errorCode", rpcErr.Code, "error", rpcErr.Message)
+				logger.Warn("a2a request", attrs...)
+				return
+			}
+			if lw.statusCode() >= http.StatusBadRequest {
+				attrs = append(attrs, "outcome", "error")
+				logger.Warn("a2a request", attrs...)
+				return
+			}
+			logger.Info("a2a request", append(attrs, "outcome", "ok")...)
+		})
+	}
+}
+
+// restoredBody is a request body whose start has been read for logging, reading that start
+// again before the rest of the original body.
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+// scanJSONRPCRequest returns the method and ID of a JSON-RPC request whose body is cut off,
+// reading the members that precede the cut.
+func scanJSONRPCRequest(body []byte) a2a.JSONRPCRequest {
+	var request a2a.JSONRPCRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return request
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			break
+		}
+		switch key {
+		case "method":
+			json.Unmarshal(value, &request.Method)
+		case "id":
+			json.Unmarshal(value, &request.ID)
+		}
+	}
+	return request
+}
+
+// truncateParams shortens logged params to maxLoggedParamsLength bytes, cutting on a rune
+// boundary.
+func truncateParams(params string) string {
+	if len(params) <= maxLoggedParamsLength {
+		return params
+	}
+	end := maxLoggedParamsLength
+	for end > 0 && !utf8.RuneStart(params[end]) {
+		end--
+	}
+	return params[:end] + "...(truncated)"
+}
+
+// loggingResponseWriter records the status code of a response and keeps a copy of its body,
+// unless it is a stream, so the outcome of the call can be logged.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	buf       bytes.Buffer
+	streaming bool
+}
+
+// WriteHeader records the status code before writing it.
+func (l *loggingResponseWriter) WriteHeader(status int) {
+	if l.status == 0 {
+		l.status = status
+		l.streaming = strings.HasPrefix(l.Header().Get("Content-Type"), "text/event-stream")
+	}
+	l.ResponseWriter.WriteHeader(status)
+}
+
+// Write copies the body before writing it.
+func (l *loggingResponseWriter) Write(b []byte) (int, error) {
+	if l.status == 0 {
+		l.WriteHeader(http.StatusOK)
+	}
+	if !l.streaming && l.buf.Len()+len(b) <= maxLoggedResponseSize {
+		l.buf.Write(b)
+	}
+	return l.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer, if it supports flushing.
+func (l *loggingResponseWriter) Flush() {
+	if flusher, ok := l.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// statusCode returns the response status code.
+func (l *loggingResponseWriter) statusCode() int {
+	if l.status == 0 {
+		return http.StatusOK
+	}
+	return l.status
+}
+
+// jsonRPCError returns the error in the response body, if there is one.
+func (l *loggingResponseWriter) jsonRPCError() *a2a.JSONRPCError {
+	if l.streaming || l.buf.Len() == 0 {
+		return nil
+	}
+	var response struct {
+		Error *a2a.JSONRPCError `json:"error"`
+	}
+	if err := json.Unmarshal(l.buf.Bytes(), &response); err != nil {
+		return nil
+	}
+	return response.Error
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	requestBody := `{"jsonrpc":"2.0","id":"req-1","method":"tasks/pushNotification/set","params":{"id":"task-1","pushNotificationConfig":{"url":"https://example.com/push","authentication":{"schemes":["bearer"],"credentials":"super-secret"}}}}`
+	handler := RequestLoggingMiddleware(logger, []string{"pushNotificationConfig.authentication.credentials"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The handler still sees the original body
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != requestBody {
+				t.Errorf("Expected handler to receive the original body, got %s", body)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":"req-1","error":{"code":-32001,"message":"Task not found"}}`))
+		}))
+
+	req := httptest.NewRequest(http.MethodPost, "/a2a", strings.NewReader(requestBody))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	logged := logs.String()
+	if strings.Contains(logged, "super-secret") {
+		t.Errorf("Expected credentials to be redacted, got %s", logged)
+	}
+	for _, want := range []string{`"method":"tasks/pushNotification/set"`, `"id":"req-1"`, `"duration":`, `"outcome":"error"`, `"errorCode":-32001`, "[REDACTED]", "https://example.com/push"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("Expected log to contain %s, got %s", want, logged)
+		}
+	}
+}
+
+func TestRequestLoggingMiddleware_SkipsNonJSONRPC(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	handler := RequestLoggingMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/.well-known/agent.json", nil))
+	if logs.Len() != 0 {
+		t.Errorf("Expected agent card request not to be logged, got %s", logs.String())
+	}
+}
+
+func TestRequestLoggingMiddleware_LargeRequests(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var received []byte
+	handler := RequestLoggingMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+
+	// The body of a request over the read limit is passed on whole, but its params are not logged
+	large := `{"jsonrpc":"2.0","id":"req-1","method":"tasks/send","params":{"text":"` + strings.Repeat("a", maxLoggedRequestSize) + `"}}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/a2a", strings.NewReader(large)))
+	if string(received) != large {
+		t.Errorf("Expected handler to receive the whole body, got %d of %d bytes", len(received), len(large))
+	}
+	logged := logs.String()
+	if !strings.Contains(logged, `"method":"tasks/send"`) || !strings.Contains(logged, "request body too large") {
+		t.Errorf("Expected the method to be logged without the params, got %s", logged)
+	}
+	if strings.Contains(logged, "aaaa") {
+		t.Errorf("Expected the params not to be logged, got %d bytes of logs", len(logged))
+	}
+
+	// Long params are truncated
+	logs.Reset()
+	long := `{"jsonrpc":"2.0","id":"req-2","method":"tasks/send","params":{"text":"` + strings.Repeat("b", 2*maxLoggedParamsLength) + `"}}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/a2a", strings.NewReader(long)))
+	logged = logs.String()
+	if !strings.Contains(logged, "...(truncated)") || strings.Contains(logged, strings.Repeat("b", maxLoggedParamsLength+1)) {
+		t.Errorf("Expected the params to be truncated, got %d bytes of logs", len(logged))
+	}
+}
@@ -1,30 +1,99 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/llm"
 	"github.com/sammcj/go-a2a/llm/gollm"
 	"github.com/sammcj/go-a2a/pkg/config"
 	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/pkg/trace"
 )
 
 // AuthValidator is a function that validates authentication for requests.
 type AuthValidator func(w http.ResponseWriter, r *http.Request, next http.Handler, card *a2a.AgentCard)
 
+// AgentCardLoader loads the agent card when the server is created, e.g., from a URL or a
+// central registry (see AgentCardFromURL).
+type AgentCardLoader func(ctx context.Context) (*a2a.AgentCard, error)
+
 // Config holds the configuration for the A2A server.
 type Config struct {
 	ListenAddress string         // Address to listen on (e.g., ":8080")
 	A2APathPrefix string         // Path prefix for A2A endpoints (e.g., "/a2a")
+	SSEPath       string         // Path of the SSE endpoint, relative to A2APathPrefix (e.g., "sse")
 	AgentCard     *a2a.AgentCard // The agent card describing this agent
 	AgentCardPath string         // Path to serve the agent card (e.g., "/.well-known/agent.json")
 	TaskManager   TaskManager    // The task manager implementation
 	TaskHandler   task.Handler   // The application-specific task handler logic
 	AgentEngine   AgentEngine    // The agent engine implementation
 	AuthValidator AuthValidator  // Optional authentication validator function
+	// ProtectedAgentCard runs the AuthValidator on agent card requests instead of serving the card publicly
+	ProtectedAgentCard bool
+	MaxHistory         int // Maximum number of messages kept in a task's history (0 = unbounded)
+	// PropagatedHeaders are the trace/correlation headers copied from incoming requests into the task context
+	PropagatedHeaders []string
+	TracerProvider    trace.TracerProvider // Optional OpenTelemetry tracer provider for request, task, and tool spans
+	// Compression enables gzip compression of responses and decompression of gzip request bodies
+	Compression bool
+	// CompressionThreshold is the minimum response size in bytes that is compressed (0 = default)
+	CompressionThreshold int
+	// MaxConcurrentTasks is the number of workers running task handlers, and so the maximum
+	// number running at once (0 = unlimited)
+	MaxConcurrentTasks int
+	// TaskOverflowPolicy controls what happens to tasks sent while MaxConcurrentTasks are running
+	TaskOverflowPolicy TaskOverflowPolicy
+	// TaskQueue holds tasks waiting for a worker (nil = a MemoryTaskQueue)
+	TaskQueue TaskQueue
+	// DisabledMethods are the JSON-RPC methods rejected with a method-not-found error
+	DisabledMethods []string
+	// IdempotencyTTL is how long tasks/send idempotency keys are remembered (0 = DefaultIdempotencyTTL)
+	IdempotencyTTL time.Duration
+	// SSEBufferSize is the number of events buffered per SSE connection before a slow client is disconnected (0 = default)
+	SSEBufferSize int
+	// RequestLogger logs each JSON-RPC call when set
+	RequestLogger *slog.Logger
+	// RedactFields are the JSON paths in params replaced before requests are logged
+	RedactFields []string
+	// ArtifactStore offloads artifact content from tasks, leaving FilePart URI references (nil = inline)
+	ArtifactStore ArtifactStore
+	// MaxUploadSize is the largest file that can be uploaded in chunks (0 = DefaultMaxUploadSize)
+	MaxUploadSize int64
+	// MaxUploads is the number of chunked uploads that may be in progress at once (0 = DefaultMaxUploads)
+	MaxUploads int
+	// SkillRateLimits are the rate limits on tasks sent to each skill, keyed by skill ID
+	SkillRateLimits map[string]Limit
+	// TaskTimeout is the maximum time a task's handler may run before the task is failed (0 = unlimited)
+	TaskTimeout time.Duration
+	// AdminAPI serves runtime stats at {prefix}/admin/stats
+	AdminAPI bool
+	// ArtifactValidation controls what happens to data artifacts that do not match their skill's artifact schema
+	ArtifactValidation ArtifactValidationPolicy
+
+	// RecordRequestIDs records the IDs of the request creating a task in the task's metadata
+	RecordRequestIDs bool
+
+	// PushBatchWindow is how long push notifications for a task are coalesced (0 = no batching)
+	PushBatchWindow time.Duration
+	// AgentCardLoader loads the agent card in NewServer, replacing AgentCard
+	AgentCardLoader AgentCardLoader
+	// SkillAgentEngines are the agent engines that process tasks sent to each skill, keyed by skill ID
+	SkillAgentEngines map[string]AgentEngine
+	// VirtualAgents are additional agents served below their own path prefixes
+	VirtualAgents []VirtualAgent
+	// LenientParams ignores unknown fields in request params instead of rejecting them, e.g.
+	// for clients on a newer version of the protocol
+	LenientParams bool
+	// ToolAudit records each tool call made by the agent engines in a ToolAuditRecord artifact
+	ToolAudit bool
 	// TODO: Add fields for optional TLS config, middleware, SSE config, etc.
 	gollmOptions []gollm.Option
+	optionErrs   []error // Errors from options that could not be applied, returned by NewServer
 }
 
 // Option is a function that modifies the server configuration.
@@ -33,9 +102,11 @@ type Option func(*Config)
 // DefaultConfig returns a Config with default values.
 func DefaultConfig() Config {
 	return Config{
-		ListenAddress: ":8080",              // Default listen address
-		A2APathPrefix: "/a2a",               // Default A2A path prefix
-		AgentCardPath: DefaultAgentCardPath, // Default agent card path
+		ListenAddress:     ":8080",              // Default listen address
+		A2APathPrefix:     "/a2a",               // Default A2A path prefix
+		SSEPath:           DefaultSSEPath,       // Default SSE path
+		AgentCardPath:     DefaultAgentCardPath, // Default agent card path
+		PropagatedHeaders: trace.DefaultHeaders,
 		// AgentCard is required, must be provided via WithAgentCard
 		// TaskManager defaults to InMemoryTaskManager if TaskHandler is provided
 		// TaskHandler is required, must be provided via WithTaskHandler
@@ -57,6 +128,16 @@ func WithA2APathPrefix(prefix string) Option {
 	}
 }
 
+// WithSSEPath sets the path of the endpoint serving the streaming methods
+// (tasks/sendSubscribe and tasks/resubscribe), relative to the A2A path prefix.
+// The path is advertised as the streaming endpoint in the agent card's capabilities so
+// clients can find it.
+func WithSSEPath(path string) Option {
+	return func(c *Config) {
+		c.SSEPath = path
+	}
+}
+
 // WithAgentCard sets the Agent Card for the server.
 func WithAgentCard(card *a2a.AgentCard) Option {
 	return func(c *Config) {
@@ -64,6 +145,15 @@ func WithAgentCard(card *a2a.AgentCard) Option {
 	}
 }
 
+// WithAgentCardLoader sets a function that loads the Agent Card when the server is created,
+// instead of providing it with WithAgentCard. The loaded card is validated like any other;
+// if loading fails, NewServer returns the error.
+func WithAgentCardLoader(loader AgentCardLoader) Option {
+	return func(c *Config) {
+		c.AgentCardLoader = loader
+	}
+}
+
 // WithTaskManager sets a custom TaskManager implementation.
 func WithTaskManager(tm TaskManager) Option {
 	return func(c *Config) {
@@ -86,6 +176,196 @@ func WithAuthValidator(validator AuthValidator) Option {
 	}
 }
 
+// WithProtectedAgentCard requires agent card requests to pass the AuthValidator.
+// By default the card is served without authentication so that clients can discover
+// the agent's authentication requirements.
+func WithProtectedAgentCard(protected bool) Option {
+	return func(c *Config) {
+		c.ProtectedAgentCard = protected
+	}
+}
+
+// WithMaxHistory sets the maximum number of messages kept in a task's history.
+// When exceeded, the oldest non-system messages are dropped (the initial user message is kept).
+func WithMaxHistory(n int) Option {
+	return func(c *Config) {
+		c.MaxHistory = n
+	}
+}
+
+// WithMaxConcurrentTasks sets the number of workers running task handlers, limiting the
+// number running at once. Tasks sent while every worker is busy are queued or rejected
+// according to the TaskOverflowPolicy (queued by default). A value of 0 removes the limit.
+func WithMaxConcurrentTasks(n int) Option {
+	return func(c *Config) {
+		c.MaxConcurrentTasks = n
+	}
+}
+
+// WithTaskOverflowPolicy sets what happens to tasks sent while the MaxConcurrentTasks limit is reached.
+func WithTaskOverflowPolicy(policy TaskOverflowPolicy) Option {
+	return func(c *Config) {
+		c.TaskOverflowPolicy = policy
+	}
+}
+
+// WithTaskQueue sets the queue holding tasks waiting for a worker to run their handlers,
+// e.g. one kept in an external system. By default tasks are queued in memory.
+func WithTaskQueue(queue TaskQueue) Option {
+	return func(c *Config) {
+		c.TaskQueue = queue
+	}
+}
+
+// WithDisabledMethods disables the given JSON-RPC methods (e.g., "tasks/cancel" or
+// "tasks/sendSubscribe"). Requests for them are rejected with a method-not-found error,
+// as if the server did not implement them. This can be used to run a read-only agent.
+func WithDisabledMethods(methods ...string) Option {
+	return func(c *Config) {
+		c.DisabledMethods = append(c.DisabledMethods, methods...)
+	}
+}
+
+// WithArtifactStore stores the content of task artifacts in the given store instead of in
+// the task, which keeps only a FilePart with the content's URI. Offloading artifacts only
+// applies to the default in-memory task manager. The store also enables the files/initUpload,
+// files/uploadChunk and files/completeUpload methods, which upload large files in chunks
+// for tasks to reference by URI.
+func WithArtifactStore(store ArtifactStore) Option {
+	return func(c *Config) {
+		c.ArtifactStore = store
+	}
+}
+
+// WithUploadLimits sets the largest file that can be uploaded in chunks, and the number of
+// uploads that may be in progress at once. A limit of 0 keeps its default
+// (DefaultMaxUploadSize and DefaultMaxUploads). Uploads are also limited to the agent
+// card's MaxInputBytes, if it is set.
+func WithUploadLimits(maxSize int64, maxUploads int) Option {
+	return func(c *Config) {
+		c.MaxUploadSize = maxSize
+		c.MaxUploads = maxUploads
+	}
+}
+
+// WithArtifactValidation validates the DataPart artifacts of tasks sent to a skill against
+// the skill's ArtifactSchema in the agent card, and logs or fails the task on violations
+// according to policy. Artifacts are not validated by default. Validation only applies to
+// the default in-memory task manager.
+func WithArtifactValidation(policy ArtifactValidationPolicy) Option {
+	return func(c *Config) {
+		c.ArtifactValidation = policy
+	}
+}
+
+// WithSkillRateLimits limits the rate at which tasks may be sent to each skill, keyed by
+// skill ID, so expensive skills can be limited more tightly than cheap ones. Tasks sent
+// beyond a limit are rejected with a rate-limit error and a Retry-After header. Tasks that
+// do not name a skill, and skills without a limit, are not limited.
+func WithSkillRateLimits(limits map[string]Limit) Option {
+	return func(c *Config) {
+		c.SkillRateLimits = limits
+	}
+}
+
+// WithTaskTimeout sets the maximum time a task's handler may run. A task still running when
+// it passes is failed with a timeout message, firing the usual SSE and push notification
+// updates, and its handler's context is cancelled. Clients may ask for a shorter timeout
+// with TaskSendParams.Timeout. Only applies to the default in-memory task manager.
+func WithTaskTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.TaskTimeout = timeout
+	}
+}
+
+// WithRequestIDMetadata records the ID of the JSON-RPC request that created each task, and
+// its X-Request-ID header, in the task's metadata (see a2a.TaskMetadataRequestID
+// and a2a.TaskMetadataHTTPRequestID), so stored tasks can be correlated with request logs.
+func WithRequestIDMetadata(enabled bool) Option {
+	return func(c *Config) {
+		c.RecordRequestIDs = enabled
+	}
+}
+
+// WithPushBatchWindow batches push notifications: events for a task within the window are
+// sent as one request carrying an events array, in order. Final status updates are sent
+// immediately, along with any events still waiting. Typical windows are a few hundred
+// milliseconds.
+func WithPushBatchWindow(window time.Duration) Option {
+	return func(c *Config) {
+		c.PushBatchWindow = window
+	}
+}
+
+// WithIdempotencyTTL sets how long idempotency keys are remembered. A tasks/send request
+// carrying an Idempotency-Key header (or idempotencyKey param) already seen within the TTL
+// returns the original task instead of creating a new one.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.IdempotencyTTL = ttl
+	}
+}
+
+// WithSSEBufferSize sets the number of events buffered per SSE connection.
+// A client that falls further behind is disconnected instead of stalling the task.
+func WithSSEBufferSize(n int) Option {
+	return func(c *Config) {
+		c.SSEBufferSize = n
+	}
+}
+
+// WithPropagatedHeaders sets the trace/correlation headers copied from incoming requests
+// into the task context, replacing the defaults (X-Request-ID and traceparent).
+// A request ID is always generated if the incoming request does not carry one.
+func WithPropagatedHeaders(headers ...string) Option {
+	return func(c *Config) {
+		c.PropagatedHeaders = headers
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used to create spans for JSON-RPC
+// requests, task handling, and tool execution. Without one, the global tracer provider is used.
+// Request spans continue the trace of the calling agent, as read by the global propagator.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = provider
+	}
+}
+
+// WithLenientParams makes the server ignore fields in request params that it does not know,
+// as sent by clients on a newer version of the protocol. By default such fields are rejected
+// with an invalid params error naming the field, so misspelt fields are not silently dropped.
+func WithLenientParams(enabled bool) Option {
+	return func(c *Config) {
+		c.LenientParams = enabled
+	}
+}
+
+// WithCompression enables or disables gzip compression. When enabled, gzip request bodies
+// are decompressed and responses are compressed if the client accepts gzip.
+func WithCompression(enabled bool) Option {
+	return func(c *Config) {
+		c.Compression = enabled
+	}
+}
+
+// WithCompressionThreshold sets the minimum response size in bytes that is compressed.
+func WithCompressionThreshold(n int) Option {
+	return func(c *Config) {
+		c.CompressionThreshold = n
+	}
+}
+
+// WithRequestLogging logs each JSON-RPC call's method, ID, duration, and outcome to logger,
+// along with its params. The value at each of the redactFields JSON paths in params (e.g.
+// "pushNotification.authentication.credentials") is replaced before logging; see redact.JSON.
+func WithRequestLogging(logger *slog.Logger, redactFields []string) Option {
+	return func(c *Config) {
+		c.RequestLogger = logger
+		c.RedactFields = redactFields
+	}
+}
+
 // WithAgentEngine sets a custom AgentEngine implementation.
 func WithAgentEngine(engine AgentEngine) Option {
 	return func(c *Config) {
@@ -93,6 +373,16 @@ func WithAgentEngine(engine AgentEngine) Option {
 	}
 }
 
+// WithSkillAgentEngines processes tasks sent to each skill with its own agent engine, keyed
+// by skill ID, so e.g. simple skills can use a cheap model and reasoning skills a strong one.
+// Tasks for other skills, or for no skill, go to the task handler, or to the default agent
+// engine if no task handler is set. Every skill ID must be in the agent card.
+func WithSkillAgentEngines(engines map[string]AgentEngine) Option {
+	return func(c *Config) {
+		c.SkillAgentEngines = engines
+	}
+}
+
 // WithGollmOptions sets gollm options to be used when creating LLM interfaces
 func WithGollmOptions(options []gollm.Option) Option {
 	return func(c *Config) {
@@ -146,8 +436,7 @@ func WithToolAugmentedGollmAgent(provider, model, apiKey string, tools []Tool) O
 			gollm.WithAPIKey(apiKey),
 		)
 		if err != nil {
-			// Log error and return without setting the agent engine
-			// TODO: Consider a better way to handle errors in options
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("failed to create gollm adapter: %w", err))
 			return
 		}
 
@@ -162,8 +451,7 @@ func WithMCPToolAugmentedAgent(llmInterface llm.LLMInterface, mcpClient MCPClien
 		// Create agent
 		agent, err := NewMCPToolAugmentedAgent(llmInterface, mcpClient)
 		if err != nil {
-			// Log error and return without setting the agent engine
-			// TODO: Consider a better way to handle errors in options
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("failed to create MCP tool-augmented agent: %w", err))
 			return
 		}
 
@@ -171,6 +459,15 @@ func WithMCPToolAugmentedAgent(llmInterface llm.LLMInterface, mcpClient MCPClien
 	}
 }
 
+// WithToolAudit records every tool call made by the agent engine, and by the skill agent
+// engines, in a ToolAuditRecord artifact (see MCPToolAugmentedAgent.SetToolAudit). NewServer
+// returns an error if none of the engines makes tool calls that can be audited.
+func WithToolAudit(enabled bool) Option {
+	return func(c *Config) {
+		c.ToolAudit = enabled
+	}
+}
+
 // WithMCPToolAugmentedGollmAgent creates a MCPToolAugmentedAgent with a gollm adapter and MCP client.
 func WithMCPToolAugmentedGollmAgent(provider, model, apiKey string, mcpClient MCPClient) Option {
 	return func(c *Config) {
@@ -181,16 +478,14 @@ func WithMCPToolAugmentedGollmAgent(provider, model, apiKey string, mcpClient MC
 			gollm.WithAPIKey(apiKey),
 		)
 		if err != nil {
-			// Log error and return without setting the agent engine
-			// TODO: Consider a better way to handle errors in options
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("failed to create gollm adapter: %w", err))
 			return
 		}
 
 		// Create agent
 		agent, err := NewMCPToolAugmentedAgent(adapter, mcpClient)
 		if err != nil {
-			// Log error and return without setting the agent engine
-			// TODO: Consider a better way to handle errors in options
+			c.optionErrs = append(c.optionErrs, fmt.Errorf("failed to create MCP tool-augmented agent: %w", err))
 			return
 		}
 
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// PromptVars are the variables available to a system prompt template.
+type PromptVars struct {
+	SkillID   string      // Skill requested by the client (empty if none)
+	SessionID string      // Session the task belongs to (empty if none)
+	Now       time.Time   // Time the prompt is rendered
+	Metadata  interface{} // Metadata sent with the task request, e.g., {{.Metadata.user}}
+}
+
+// PromptTemplate is a system prompt written as a Go text/template, rendered for each task
+// with PromptVars.
+type PromptTemplate struct {
+	tmpl *template.Template
+}
+
+// ParsePromptTemplate parses a system prompt template.
+func ParsePromptTemplate(text string) (*PromptTemplate, error) {
+	tmpl, err := template.New("system prompt").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system prompt template: %w", err)
+	}
+	return &PromptTemplate{tmpl: tmpl}, nil
+}
+
+// Render renders the template for a task.
+func (p *PromptTemplate) Render(taskCtx task.Context) (string, error) {
+	vars := PromptVars{
+		SkillID:   taskCtx.SkillID,
+		SessionID: taskCtx.SessionID,
+		Now:       time.Now(),
+		Metadata:  taskCtx.Metadata,
+	}
+
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render system prompt: %w", err)
+	}
+	return b.String(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
@@ -13,7 +14,27 @@ import (
 
 // PushNotifier handles sending push notifications for task updates.
 type PushNotifier struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	sequences   map[string]*taskSequence // Per-task sequence state, keyed by task ID
+	batchWindow time.Duration            // How long events are coalesced before sending (0 = no batching)
+	mu          sync.Mutex               // Protects sequences, batchWindow and the batching state of each sequence
+}
+
+// taskSequence tracks the push notification sequence for a single task.
+// Holding mu while sending serializes deliveries for the task, so the
+// receiver gets notifications in sequence order.
+type taskSequence struct {
+	mu   sync.Mutex
+	last uint64
+
+	// Closed once a replay started with Replay has been sent (nil = none), protected
+	// by PushNotifier.mu. Sends wait for it before taking mu.
+	replay chan struct{}
+
+	// Events waiting to be sent in the next batch, protected by PushNotifier.mu
+	pending []*PushNotificationPayload
+	config  *a2a.PushNotificationConfig // Config of the most recent pending event
+	timer   *time.Timer                 // Flushes the pending events when the batch window ends
 }
 
 // NewPushNotifier creates a new PushNotifier.
@@ -26,38 +47,65 @@ func NewPushNotifier(timeout time.Duration) *PushNotifier {
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		sequences: make(map[string]*taskSequence),
 	}
 }
 
 // PushNotificationPayload represents the payload sent in a push notification.
+//
+// Sequence starts at 1 for each task and increases by one with every
+// notification sent for it. Notifications for the same task are delivered one
+// at a time, in order, but a receiver should still use Sequence to reassemble
+// chunked artifacts and to detect gaps (e.g. a delivery that failed).
 type PushNotificationPayload struct {
 	TaskID    string          `json:"taskId"`
+	Sequence  uint64          `json:"sequence"`
 	EventType string          `json:"eventType"` // "status" or "artifact"
 	Status    *a2a.TaskStatus `json:"status,omitempty"`
 	Artifact  *a2a.Artifact   `json:"artifact,omitempty"`
 	Task      *a2a.Task       `json:"task,omitempty"` // Full task data, included if IncludeTaskData is true
 }
 
+// PushNotificationBatch is the payload sent in place of single notifications when batching
+// is enabled (see SetBatchWindow). Events are in sequence order and have the same form as
+// unbatched notifications.
+type PushNotificationBatch struct {
+	TaskID string                    `json:"taskId"`
+	Events []PushNotificationPayload `json:"events"`
+}
+
+// SetBatchWindow enables coalescing of push notifications. Events for a task are collected
+// for up to window after the first one and sent together as a PushNotificationBatch, so
+// a burst of updates costs the receiver one request. An event moving the task to a final
+// state is sent at once, along with any events still waiting. A window of 0 (the default)
+// sends every event on its own.
+func (p *PushNotifier) SetBatchWindow(window time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batchWindow = window
+}
+
 // SendStatusUpdate sends a push notification for a task status update.
 func (p *PushNotifier) SendStatusUpdate(ctx context.Context, task *a2a.Task, config *a2a.PushNotificationConfig) error {
 	if config == nil || config.URL == "" {
 		return nil // No push notification configured
 	}
 
-	// Create payload
-	payload := PushNotificationPayload{
-		TaskID:    task.ID,
-		EventType: "status",
-		Status:    &task.Status,
-	}
+	final := task.Status.State == a2a.TaskStateCompleted ||
+		task.Status.State == a2a.TaskStateFailed ||
+		task.Status.State == a2a.TaskStateCancelled
 
-	// Include full task data if requested
-	if config.IncludeTaskData != nil && *config.IncludeTaskData {
-		payload.Task = task
+	// Send notification
+	err := p.send(ctx, config, statusPayload(task, config), final)
+
+	// No further notifications are expected once the task reaches a final state
+	if final {
+		p.mu.Lock()
+		delete(p.sequences, task.ID)
+		p.mu.Unlock()
 	}
 
-	// Send notification
-	return p.sendNotification(ctx, config, payload)
+	return err
 }
 
 // SendArtifactUpdate sends a push notification for a task artifact update.
@@ -66,13 +114,105 @@ func (p *PushNotifier) SendArtifactUpdate(ctx context.Context, task *a2a.Task, a
 		return nil // No push notification configured
 	}
 
+	payload := artifactPayload(task, artifact, config)
+	if payload == nil {
+		return nil
+	}
+
+	// Send notification
+	return p.send(ctx, config, payload, false)
+}
+
+// Replay sends a task's status, followed by its artifacts, to a push notification config
+// that was set after the task started. The notifications are sent in the background but
+// take their place in the task's sequence when Replay is called, so they are delivered
+// before any notification for the task sent after Replay returns.
+func (p *PushNotifier) Replay(task *a2a.Task, config *a2a.PushNotificationConfig) {
+	if config == nil || config.URL == "" {
+		return // No push notification configured
+	}
+
+	payloads := []*PushNotificationPayload{statusPayload(task, config)}
+	for _, artifact := range task.Artifacts {
+		if payload := artifactPayload(task, artifact, config); payload != nil {
+			payloads = append(payloads, payload)
+		}
+	}
+
+	// Hold back later sends for the task until the replay has been sent
+	p.mu.Lock()
+	seq := p.sequence(task.ID)
+	previous, done := seq.replay, make(chan struct{})
+	seq.replay = done
+	batched := p.batchWindow > 0
+	p.mu.Unlock()
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			if seq.replay == done {
+				seq.replay = nil
+			}
+			p.mu.Unlock()
+			close(done)
+		}()
+		if previous != nil {
+			<-previous
+		}
+
+		seq.mu.Lock()
+		defer seq.mu.Unlock()
+		var err error
+		if batched {
+			batch := PushNotificationBatch{TaskID: task.ID, Events: make([]PushNotificationPayload, len(payloads))}
+			for i, payload := range payloads {
+				seq.last++
+				payload.Sequence = seq.last
+				batch.Events[i] = *payload
+			}
+			err = p.sendNotification(context.Background(), config, batch)
+		} else {
+			for _, payload := range payloads {
+				seq.last++
+				payload.Sequence = seq.last
+				if err = p.sendNotification(context.Background(), config, payload); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			// Just log the error, as the replay runs in the background
+			fmt.Printf("Failed to replay push notifications for task %s: %v\n", task.ID, err)
+		}
+	}()
+}
+
+// statusPayload returns the payload of a status notification for the task.
+func statusPayload(task *a2a.Task, config *a2a.PushNotificationConfig) *PushNotificationPayload {
+	// Copy the status, as the payload may wait in a batch
+	status := task.Status
+	payload := &PushNotificationPayload{
+		TaskID:    task.ID,
+		EventType: "status",
+		Status:    &status,
+	}
+
+	// Include full task data if requested
+	if config.IncludeTaskData != nil && *config.IncludeTaskData {
+		payload.Task = task
+	}
+	return payload
+}
+
+// artifactPayload returns the payload of an artifact notification for the task, or nil if
+// the config excludes artifacts.
+func artifactPayload(task *a2a.Task, artifact a2a.Artifact, config *a2a.PushNotificationConfig) *PushNotificationPayload {
 	// Skip if artifacts are not included in push notifications
 	if config.IncludeArtifacts != nil && !*config.IncludeArtifacts {
 		return nil
 	}
 
-	// Create payload
-	payload := PushNotificationPayload{
+	payload := &PushNotificationPayload{
 		TaskID:    task.ID,
 		EventType: "artifact",
 		Artifact:  &artifact,
@@ -82,11 +222,111 @@ func (p *PushNotifier) SendArtifactUpdate(ctx context.Context, task *a2a.Task, a
 	if config.IncludeTaskData != nil && *config.IncludeTaskData {
 		payload.Task = task
 	}
+	return payload
+}
+
+// send sends the payload, or adds it to its task's pending batch if batching is enabled.
+// A final payload flushes the batch immediately.
+func (p *PushNotifier) send(ctx context.Context, config *a2a.PushNotificationConfig, payload *PushNotificationPayload, final bool) error {
+	p.mu.Lock()
+	seq := p.sequence(payload.TaskID)
+	if p.batchWindow <= 0 {
+		p.mu.Unlock()
+		return p.sendSequenced(ctx, seq, config, payload)
+	}
+
+	seq.pending = append(seq.pending, payload)
+	seq.config = config
+	if !final {
+		if seq.timer == nil {
+			seq.timer = time.AfterFunc(p.batchWindow, func() {
+				if err := p.flushBatch(context.Background(), payload.TaskID, seq); err != nil {
+					// Just log the error, as the update that started the batch has already returned
+					fmt.Printf("Failed to send push notification batch for task %s: %v\n", payload.TaskID, err)
+				}
+			})
+		}
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	return p.flushBatch(ctx, payload.TaskID, seq)
+}
+
+// sequence returns the sequence state of a task, creating it if needed.
+// The caller must hold p.mu.
+func (p *PushNotifier) sequence(taskID string) *taskSequence {
+	seq, ok := p.sequences[taskID]
+	if !ok {
+		seq = &taskSequence{}
+		p.sequences[taskID] = seq
+	}
+	return seq
+}
+
+// sendSequenced assigns the next sequence number for the payload's task and
+// sends it. Sends for the same task are serialized to preserve ordering.
+func (p *PushNotifier) sendSequenced(ctx context.Context, seq *taskSequence, config *a2a.PushNotificationConfig, payload *PushNotificationPayload) error {
+	if err := p.waitForReplay(ctx, seq); err != nil {
+		return err
+	}
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	seq.last++
+	payload.Sequence = seq.last
 
-	// Send notification
 	return p.sendNotification(ctx, config, payload)
 }
 
+// waitForReplay waits until a replay pending for the task has been sent.
+func (p *PushNotifier) waitForReplay(ctx context.Context, seq *taskSequence) error {
+	p.mu.Lock()
+	replay := seq.replay
+	p.mu.Unlock()
+	if replay == nil {
+		return nil
+	}
+	select {
+	case <-replay:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushBatch sends the pending events of a task as one batch. The pending events are taken
+// only once the task's send lock is held, so batches are delivered in order even when a
+// final event and the batch timer flush at the same time.
+func (p *PushNotifier) flushBatch(ctx context.Context, taskID string, seq *taskSequence) error {
+	if err := p.waitForReplay(ctx, seq); err != nil {
+		return err
+	}
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	p.mu.Lock()
+	events, config := seq.pending, seq.config
+	seq.pending, seq.config = nil, nil
+	if seq.timer != nil {
+		seq.timer.Stop()
+		seq.timer = nil
+	}
+	p.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil // Already sent with a later batch
+	}
+
+	batch := PushNotificationBatch{TaskID: taskID, Events: make([]PushNotificationPayload, len(events))}
+	for i, event := range events {
+		seq.last++
+		event.Sequence = seq.last
+		batch.Events[i] = *event
+	}
+	return p.sendNotification(ctx, config, batch)
+}
+
 // sendNotification sends a push notification to the configured URL.
 func (p *PushNotifier) sendNotification(ctx context.Context, config *a2a.PushNotificationConfig, payload interface{}) error {
 	// Marshal payload to JSON
@@ -105,6 +345,11 @@ func (p *PushNotifier) sendNotification(ctx context.Context, config *a2a.PushNot
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "go-a2a-push-notifier")
 
+	// Add custom headers (authentication below takes precedence)
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+
 	// Add authentication if configured
 	if config.Authentication != nil {
 		if err := addAuthenticationToRequest(req, config.Authentication); err != nil {
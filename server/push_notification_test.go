@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -226,3 +228,256 @@ func TestPushNotifier_SkipArtifactNotification(t *testing.T) {
 
 	// No assertions needed - the test server will fail if it receives a request
 }
+
+func TestPushNotifier_SequenceNumbers(t *testing.T) {
+	const numArtifacts = 5
+
+	var mu sync.Mutex
+	var received []PushNotificationPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PushNotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewPushNotifier(5 * time.Second)
+	task := &a2a.Task{ID: "test-task-seq"}
+	config := &a2a.PushNotificationConfig{TaskID: task.ID, URL: server.URL}
+
+	for i := 0; i < numArtifacts; i++ {
+		artifact := a2a.Artifact{
+			ID:     fmt.Sprintf("chunk-%d", i),
+			TaskID: task.ID,
+			Part:   a2a.TextPart{Type: "text", Text: fmt.Sprintf("chunk %d", i)},
+		}
+		if err := notifier.SendArtifactUpdate(context.Background(), task, artifact, config); err != nil {
+			t.Fatalf("Failed to send push notification: %v", err)
+		}
+	}
+
+	task.Status = a2a.TaskStatus{State: a2a.TaskStateCompleted}
+	if err := notifier.SendStatusUpdate(context.Background(), task, config); err != nil {
+		t.Fatalf("Failed to send push notification: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != numArtifacts+1 {
+		t.Fatalf("Expected %d notifications, got %d", numArtifacts+1, len(received))
+	}
+	for i, payload := range received {
+		if payload.Sequence != uint64(i+1) {
+			t.Errorf("Expected notification %d to have sequence %d, got %d", i, i+1, payload.Sequence)
+		}
+		if i < numArtifacts && payload.Artifact.ID != fmt.Sprintf("chunk-%d", i) {
+			t.Errorf("Expected notification %d to carry chunk-%d, got %s", i, i, payload.Artifact.ID)
+		}
+	}
+
+	// Sequence state is released once the task reaches a final state
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	if _, ok := notifier.sequences[task.ID]; ok {
+		t.Error("Expected sequence state to be removed after the final status update")
+	}
+}
+
+func TestPushNotifier_CustomHeaders(t *testing.T) {
+	var receivedHeaders http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewPushNotifier(5 * time.Second)
+	task := &a2a.Task{
+		ID:     "test-task-headers",
+		Status: a2a.TaskStatus{State: a2a.TaskStateWorking},
+	}
+	config := &a2a.PushNotificationConfig{
+		TaskID: task.ID,
+		URL:    server.URL,
+		Headers: map[string]string{
+			"X-Tenant-ID":   "tenant-42",
+			"Authorization": "Bearer overridden",
+		},
+		Authentication: &a2a.AuthenticationInfo{
+			Type: "bearer",
+			Configuration: map[string]interface{}{
+				"token": "test-token",
+			},
+		},
+	}
+
+	if err := notifier.SendStatusUpdate(context.Background(), task, config); err != nil {
+		t.Fatalf("Failed to send push notification: %v", err)
+	}
+
+	if got := receivedHeaders.Get("X-Tenant-ID"); got != "tenant-42" {
+		t.Errorf("Expected X-Tenant-ID header tenant-42, got %q", got)
+	}
+	if got := receivedHeaders.Get("Authorization"); got != "Bearer test-token" {
+		t.Errorf("Expected authentication to take precedence over custom headers, got %q", got)
+	}
+}
+
+// newBatchReceiver starts a server that records the push notification batches it receives.
+func newBatchReceiver(t *testing.T) (*httptest.Server, <-chan PushNotificationBatch) {
+	t.Helper()
+
+	batches := make(chan PushNotificationBatch, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch PushNotificationBatch
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		batches <- batch
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, batches
+}
+
+func TestPushNotifier_BatchesRapidUpdates(t *testing.T) {
+	server, batches := newBatchReceiver(t)
+
+	notifier := NewPushNotifier(5 * time.Second)
+	notifier.SetBatchWindow(100 * time.Millisecond)
+	task := &a2a.Task{ID: "test-task-batch", Status: a2a.TaskStatus{State: a2a.TaskStateWorking}}
+	config := &a2a.PushNotificationConfig{TaskID: task.ID, URL: server.URL}
+
+	if err := notifier.SendStatusUpdate(context.Background(), task, config); err != nil {
+		t.Fatalf("Failed to send push notification: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		artifact := a2a.Artifact{ID: fmt.Sprintf("chunk-%d", i), TaskID: task.ID, Part: a2a.TextPart{Type: "text", Text: "chunk"}}
+		if err := notifier.SendArtifactUpdate(context.Background(), task, artifact, config); err != nil {
+			t.Fatalf("Failed to send push notification: %v", err)
+		}
+	}
+
+	var batch PushNotificationBatch
+	select {
+	case batch = <-batches:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the batch")
+	}
+	if batch.TaskID != task.ID || len(batch.Events) != 4 {
+		t.Fatalf("Expected one batch of 4 events for %s, got %+v", task.ID, batch)
+	}
+	if batch.Events[0].EventType != "status" {
+		t.Errorf("Expected the status update first, got %s", batch.Events[0].EventType)
+	}
+	for i, event := range batch.Events {
+		if event.Sequence != uint64(i+1) {
+			t.Errorf("Expected event %d to have sequence %d, got %d", i, i+1, event.Sequence)
+		}
+		if i > 0 && event.Artifact.ID != fmt.Sprintf("chunk-%d", i-1) {
+			t.Errorf("Expected event %d to carry chunk-%d, got %s", i, i-1, event.Artifact.ID)
+		}
+	}
+
+	select {
+	case extra := <-batches:
+		t.Errorf("Expected a single batch, also got %+v", extra)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestPushNotifier_BatchFlushesOnFinalState(t *testing.T) {
+	server, batches := newBatchReceiver(t)
+
+	notifier := NewPushNotifier(5 * time.Second)
+	notifier.SetBatchWindow(time.Hour)
+	task := &a2a.Task{ID: "test-task-final"}
+	config := &a2a.PushNotificationConfig{TaskID: task.ID, URL: server.URL}
+
+	artifact := a2a.Artifact{ID: "result", TaskID: task.ID, Part: a2a.TextPart{Type: "text", Text: "result"}}
+	if err := notifier.SendArtifactUpdate(context.Background(), task, artifact, config); err != nil {
+		t.Fatalf("Failed to send push notification: %v", err)
+	}
+	task.Status = a2a.TaskStatus{State: a2a.TaskStateCompleted}
+	if err := notifier.SendStatusUpdate(context.Background(), task, config); err != nil {
+		t.Fatalf("Failed to send push notification: %v", err)
+	}
+
+	// The final update is sent before SendStatusUpdate returns, without waiting for the window
+	select {
+	case batch := <-batches:
+		if len(batch.Events) != 2 || batch.Events[0].EventType != "artifact" ||
+			batch.Events[1].Status == nil || batch.Events[1].Status.State != a2a.TaskStateCompleted {
+			t.Errorf("Expected the artifact followed by the completion, got %+v", batch.Events)
+		}
+	default:
+		t.Fatal("Expected the final update to flush the batch immediately")
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	if _, ok := notifier.sequences[task.ID]; ok {
+		t.Error("Expected sequence state to be removed after the final status update")
+	}
+}
+
+func TestPushNotifier_ReplayBeforeLaterUpdates(t *testing.T) {
+	// Delay the first request, so a send that does not wait for the replay would overtake it
+	var mu sync.Mutex
+	var received []PushNotificationPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PushNotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if payload.Sequence == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	notifier := NewPushNotifier(5 * time.Second)
+	config := &a2a.PushNotificationConfig{URL: server.URL}
+	task := &a2a.Task{
+		ID:        "replayed-task",
+		Status:    a2a.TaskStatus{State: a2a.TaskStateWorking, Timestamp: time.Now()},
+		Artifacts: []a2a.Artifact{{ID: "artifact-1", Part: a2a.TextPart{Type: "text", Text: "partial"}}},
+	}
+
+	notifier.Replay(task, config)
+	task.Status.State = a2a.TaskStateCompleted
+	if err := notifier.SendStatusUpdate(context.Background(), task, config); err != nil {
+		t.Fatalf("SendStatusUpdate failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 notifications, got %d", len(received))
+	}
+	expected := []string{"status", "artifact", "status"}
+	for i, payload := range received {
+		if payload.EventType != expected[i] || payload.Sequence != uint64(i+1) {
+			t.Errorf("Expected notification %d to be a %s event with sequence %d, got %s with sequence %d",
+				i, expected[i], i+1, payload.EventType, payload.Sequence)
+		}
+	}
+	if received[2].Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected the live update last, got %s", received[2].Status.State)
+	}
+}
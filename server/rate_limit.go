@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sammcj/go-a2a/server/middleware"
+)
+
+// Limit is a rate limit of Requests tasks per Window. Up to Requests tasks may be sent at
+// once, after which capacity is regained at a steady rate over the window.
+type Limit struct {
+	Requests int           // Number of tasks allowed per window
+	Window   time.Duration // Length of the window
+	// PerIdentity applies the limit to each authenticated client separately instead of to all
+	// clients together. Unauthenticated clients share a single limit.
+	PerIdentity bool
+}
+
+// maxRateLimitBuckets is the number of buckets after which idle ones are discarded.
+const maxRateLimitBuckets = 10000
+
+// skillRateLimiter enforces per-skill limits on task sends using a token bucket for each
+// skill, or for each skill and client identity.
+type skillRateLimiter struct {
+	limits map[string]Limit
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket holds the remaining capacity of a limit.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newSkillRateLimiter creates a limiter for the given limits, keyed by skill ID.
+// Limits with no requests or window are ignored.
+func newSkillRateLimiter(limits map[string]Limit) *skillRateLimiter {
+	valid := make(map[string]Limit, len(limits))
+	for skillID, limit := range limits {
+		if limit.Requests > 0 && limit.Window > 0 {
+			valid[skillID] = limit
+		}
+	}
+	return &skillRateLimiter{
+		limits:  valid,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes one task from the limit for a skill, if it has one. If the limit is
+// exhausted, it returns false and how long until the next task would be allowed.
+func (l *skillRateLimiter) allow(ctx context.Context, skillID string) (bool, time.Duration) {
+	limit, ok := l.limits[skillID]
+	if !ok {
+		return true, 0
+	}
+
+	key := skillID
+	if limit.PerIdentity {
+		key += "\x00" + identityKey(ctx)
+	}
+	rate := float64(limit.Requests) / limit.Window.Seconds() // Tokens regained per second
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitBuckets {
+			l.prune(now)
+		}
+		bucket = &tokenBucket{tokens: float64(limit.Requests), updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(limit.Requests), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// prune discards the buckets that have regained their full capacity, as they are
+// equivalent to new ones.
+func (l *skillRateLimiter) prune(now time.Time) {
+	for key, bucket := range l.buckets {
+		skillID, _, _ := strings.Cut(key, "\x00")
+		limit := l.limits[skillID]
+		if now.Sub(bucket.updated) >= limit.Window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// identityKey returns a key identifying the authenticated client of a request, or "" if
+// it is not authenticated. Credentials are hashed so they are not kept in memory.
+func identityKey(ctx context.Context) string {
+	info, ok := ctx.Value(middleware.AuthKey{}).(*middleware.AuthInfo)
+	if !ok || info == nil || info.Value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(info.Type + ":" + info.Value))
+	return hex.EncodeToString(sum[:])
+}
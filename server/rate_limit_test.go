@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/server/middleware"
+)
+
+func TestSkillRateLimiter_PerIdentity(t *testing.T) {
+	limiter := newSkillRateLimiter(map[string]Limit{
+		"search": {Requests: 1, Window: time.Hour, PerIdentity: true},
+	})
+
+	alice := context.WithValue(context.Background(), middleware.AuthKey{}, &middleware.AuthInfo{Type: "bearer", Value: "alice-token"})
+	bob := context.WithValue(context.Background(), middleware.AuthKey{}, &middleware.AuthInfo{Type: "bearer", Value: "bob-token"})
+
+	if ok, _ := limiter.allow(alice, "search"); !ok {
+		t.Fatal("Expected the first task from alice to be allowed")
+	}
+	if ok, retryAfter := limiter.allow(alice, "search"); ok || retryAfter <= 0 {
+		t.Fatalf("Expected the second task from alice to be limited with a retry delay, got %v, %v", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow(bob, "search"); !ok {
+		t.Error("Expected bob to have a separate limit")
+	}
+	if ok, _ := limiter.allow(alice, "unlimited"); !ok {
+		t.Error("Expected skills without a limit to be allowed")
+	}
+}
+
+func TestSkillRateLimiter_Refill(t *testing.T) {
+	limiter := newSkillRateLimiter(map[string]Limit{
+		"search": {Requests: 2, Window: 100 * time.Millisecond},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow(ctx, "search"); !ok {
+			t.Fatalf("Expected task %d to be allowed", i+1)
+		}
+	}
+	ok, retryAfter := limiter.allow(ctx, "search")
+	if ok {
+		t.Fatal("Expected the third task to be limited")
+	}
+
+	time.Sleep(retryAfter + 10*time.Millisecond)
+	if ok, _ := limiter.allow(ctx, "search"); !ok {
+		t.Error("Expected a task to be allowed once capacity was regained")
+	}
+}
@@ -5,16 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/llm/gollm"
+	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/server/middleware"
 )
 
+// ErrNoAgentEngine is returned by NewServer when nothing is configured to process tasks:
+// no task handler, task manager or agent engine, and no gollm options to create one.
+var ErrNoAgentEngine = errors.New("gollm options must be set when agent engine not set")
+
 // Server implements the A2A server functionality.
 type Server struct {
 	config      Config
 	httpServer  *http.Server
-	taskManager TaskManager // Interface for task management logic
-	sseManager  *SSEManager // Manager for SSE connections
+	taskManager TaskManager                   // Interface for task management logic
+	sseManager  *SSEManager                   // Manager for SSE connections
+	card        atomic.Pointer[a2a.AgentCard] // Current agent card; replaced by Reload
+	disabled    map[string]bool               // Methods rejected as not found
+	skillLimits *skillRateLimiter             // Per-skill task rate limits
+	uploads     *uploadManager                // Chunked file uploads (nil = no artifact store)
+	virtual     map[string]*Server            // Virtual agents, keyed by path prefix
+	started     time.Time                     // When the server was created
 }
 
 // NewServer creates a new A2A Server instance.
@@ -23,20 +39,36 @@ func NewServer(opts ...Option) (*Server, error) {
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if err := errors.Join(cfg.optionErrs...); err != nil {
+		return nil, err
+	}
+	if cfg.TaskHandler == nil && cfg.TaskManager == nil && cfg.AgentEngine == nil && cfg.gollmOptions == nil {
+		return nil, ErrNoAgentEngine
+	}
 
+	if cfg.AgentCardLoader != nil {
+		card, err := cfg.AgentCardLoader(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agent card: %w", err)
+		}
+		cfg.AgentCard = card
+	}
 	if cfg.AgentCard == nil {
 		return nil, fmt.Errorf("agent card configuration is required")
 	}
-	if cfg.TaskManager == nil {
-		// Use default in-memory task manager if none provided
-		cfg.TaskManager = NewInMemoryTaskManager(cfg.TaskHandler) // Assuming TaskHandler is configured
-		// TODO: Check if TaskHandler is nil and handle appropriately
+	if cfg.SSEPath == "" {
+		cfg.SSEPath = DefaultSSEPath
 	}
-
-	if cfg.AgentEngine == nil {
-		if cfg.gollmOptions == nil {
-			return nil, errors.New("gollm options must be set when agent engine not set")
-		}
+	card, err := normalizeAgentCard(cfg.AgentCard, cfg.SSEPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AgentCard = card
+	if cfg.AdminAPI && !isNoAuthValidator(cfg.AuthValidator) && (cfg.AuthValidator == nil || len(card.Authentication) == 0) {
+		// Validators check the schemes the card declares, so without one the endpoint is open
+		return nil, errAdminAPIUnauthenticated
+	}
+	if cfg.AgentEngine == nil && cfg.gollmOptions != nil {
 		// Create a gollm adapter with the provided options
 		adapter, err := gollm.NewAdapter(cfg.gollmOptions...)
 		if err != nil {
@@ -46,25 +78,75 @@ func NewServer(opts ...Option) (*Server, error) {
 		// Create a basic LLM agent
 		cfg.AgentEngine = NewBasicLLMAgent(adapter, "You are a helpful assistant.")
 	}
+
+	if cfg.ToolAudit {
+		if err := enableToolAudit(cfg.AgentEngine, cfg.SkillAgentEngines); err != nil {
+			return nil, err
+		}
+	}
+
+	// Without a task handler, tasks are processed by the agent engine
+	if cfg.TaskHandler == nil && cfg.AgentEngine != nil {
+		cfg.TaskHandler = cfg.AgentEngine.ProcessTask
+	}
+
+	// Tasks for skills with their own agent engine bypass the task handler
+	if len(cfg.SkillAgentEngines) > 0 {
+		if err := checkSkillAgentEngines(cfg.SkillAgentEngines, cfg.AgentCard); err != nil {
+			return nil, err
+		}
+		cfg.TaskHandler = skillEngineHandler(cfg.SkillAgentEngines, cfg.TaskHandler)
+	}
+
+	if cfg.TaskManager == nil {
+		// Use default in-memory task manager if none provided
+		tm := NewInMemoryTaskManager(cfg.TaskHandler)
+		tm.SetMaxHistory(cfg.MaxHistory)
+		tm.SetMaxConcurrentTasks(cfg.MaxConcurrentTasks, cfg.TaskOverflowPolicy)
+		if cfg.TaskQueue != nil {
+			tm.SetTaskQueue(cfg.TaskQueue)
+		}
+		tm.SetIdempotencyTTL(cfg.IdempotencyTTL)
+		tm.SetArtifactStore(cfg.ArtifactStore)
+		tm.SetMaxOutputBytes(maxOutputBytes(cfg.AgentCard))
+		tm.SetArtifactValidation(artifactSchemas(cfg.AgentCard), cfg.ArtifactValidation)
+		tm.SetTaskTimeout(cfg.TaskTimeout)
+		tm.SetPushBatchWindow(cfg.PushBatchWindow)
+		tm.SetRecordRequestIDs(cfg.RecordRequestIDs)
+		cfg.TaskManager = tm
+	}
+
 	// TODO: Validate other config options (e.g., address)
 
 	s := &Server{
 		config:      cfg,
 		taskManager: cfg.TaskManager,
 		sseManager:  NewSSEManager(),
+		disabled:    make(map[string]bool, len(cfg.DisabledMethods)),
+		skillLimits: newSkillRateLimiter(cfg.SkillRateLimits),
+		started:     time.Now(),
+	}
+	for _, method := range cfg.DisabledMethods {
+		s.disabled[method] = true
+	}
+	if cfg.ArtifactStore != nil {
+		s.uploads = newUploadManager(cfg.ArtifactStore, cfg.MaxUploadSize, cfg.MaxUploads)
 	}
+	s.card.Store(cfg.AgentCard)
+	s.sseManager.SetBufferSize(cfg.SSEBufferSize)
 
 	// Setup HTTP routing
 	mux := http.NewServeMux()
 
-	// Register Agent Card handler
-	RegisterAgentCardHandler(mux, cfg.AgentCard, cfg.AgentCardPath)
-
-	// Register main A2A endpoint
-	mux.HandleFunc(cfg.A2APathPrefix, s.handleA2ARequest)
+	s.registerEndpoints(mux)
 
-	// Register SSE endpoint
-	mux.HandleFunc(cfg.A2APathPrefix+"/sse", s.handleSSERequest)
+	// Register the endpoints of each virtual agent below its prefix
+	if s.virtual, err = newVirtualAgents(cfg); err != nil {
+		return nil, err
+	}
+	for _, agent := range s.virtual {
+		agent.registerEndpoints(mux)
+	}
 
 	// Create the final handler with middleware
 	var handler http.Handler = mux
@@ -74,19 +156,33 @@ func NewServer(opts ...Option) (*Server, error) {
 		// Import the middleware package locally to avoid import issues
 		authMiddleware := func(next http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Skip authentication for agent card requests
-				if r.URL.Path == cfg.AgentCardPath {
-					next.ServeHTTP(w, r)
-					return
+				// Skip authentication for agent card requests unless the card is protected
+				agent := s.agentFor(r.URL.Path)
+				if r.URL.Path == agent.config.AgentCardPath {
+					if !cfg.ProtectedAgentCard {
+						next.ServeHTTP(w, r)
+						return
+					}
+					r = r.WithContext(middleware.WithProtectedAgentCard(r.Context()))
 				}
 
 				// Apply authentication logic
-				cfg.AuthValidator(w, r, next, cfg.AgentCard)
+				cfg.AuthValidator(w, r, next, agent.agentCard())
 			})
 		}
 		handler = authMiddleware(handler)
 	}
 
+	// Apply request logging middleware if configured
+	if cfg.RequestLogger != nil {
+		handler = middleware.RequestLoggingMiddleware(cfg.RequestLogger, cfg.RedactFields)(handler)
+	}
+
+	// Apply compression middleware if enabled
+	if cfg.Compression {
+		handler = middleware.CompressionMiddleware(cfg.CompressionThreshold)(handler)
+	}
+
 	s.httpServer = &http.Server{
 		Addr:    cfg.ListenAddress,
 		Handler: handler,
@@ -96,9 +192,39 @@ func NewServer(opts ...Option) (*Server, error) {
 	return s, nil
 }
 
+// registerEndpoints registers the server's endpoints on mux.
+func (s *Server) registerEndpoints(mux *http.ServeMux) {
+	// Register Agent Card handler
+	registerAgentCardHandlerFunc(mux, s.agentCard, s.config.AgentCardPath)
+
+	// Register main A2A endpoint
+	mux.HandleFunc(s.config.A2APathPrefix, s.handleA2ARequest)
+
+	// Register SSE endpoint
+	mux.HandleFunc(joinPath(s.config.A2APathPrefix, s.config.SSEPath), s.handleSSERequest)
+
+	// Register artifact download endpoint
+	mux.HandleFunc("GET "+joinPath(s.config.A2APathPrefix, artifactDownloadPath), s.handleArtifactDownload)
+
+	// Register admin endpoint if enabled
+	if s.config.AdminAPI {
+		mux.HandleFunc("GET "+joinPath(s.config.A2APathPrefix, adminStatsPath), s.handleAdminStats)
+	}
+}
+
+// Handler returns the server's HTTP handler, serving the agent card, A2A and SSE endpoints
+// with the configured middleware, so they can be mounted on an existing HTTP server instead
+// of calling Start. The handler expects the agent card at the configured AgentCardPath and
+// A2A requests below the prefix set by WithA2APathPrefix, and those of virtual agents below
+// their prefixes; use http.StripPrefix to mount it below another path.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
 // Start runs the A2A server. It blocks until the server is stopped.
+// It is a convenience for serving Handler on the configured listen address.
 func (s *Server) Start() error {
-	fmt.Printf("Starting A2A server for agent '%s' at %s%s\n", s.config.AgentCard.ID, s.config.ListenAddress, s.config.A2APathPrefix)
+	fmt.Printf("Starting A2A server for agent '%s' at %s%s\n", s.agentCard().ID, s.config.ListenAddress, s.config.A2APathPrefix)
 	err := s.httpServer.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -106,6 +232,108 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// normalizeAgentCard validates the agent card and returns a copy with defaults filled
+// in (e.g., empty capabilities rather than nil) and the server's SSE endpoint advertised
+// if it supports streaming.
+func normalizeAgentCard(card *a2a.AgentCard, ssePath string) (*a2a.AgentCard, error) {
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+
+	normalized := *card
+	capabilities := a2a.AgentCapabilities{}
+	if normalized.Capabilities != nil {
+		capabilities = *normalized.Capabilities
+	}
+	if capabilities.SupportsStreaming {
+		capabilities.StreamingEndpoint = strings.TrimPrefix(ssePath, "/")
+		capabilities.StreamingProtocol = a2a.StreamingProtocolSSE
+	}
+	normalized.Capabilities = &capabilities
+	if normalized.Skills == nil {
+		normalized.Skills = []a2a.AgentSkill{}
+	}
+	return &normalized, nil
+}
+
+// joinPath joins the A2A path prefix and a path relative to it with a single slash.
+func joinPath(prefix, path string) string {
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// agentCard returns the agent card currently served by the server.
+func (s *Server) agentCard() *a2a.AgentCard {
+	return s.card.Load()
+}
+
+// maxOutputBytes returns the output size limit advertised in an agent card (0 = unlimited).
+func maxOutputBytes(card *a2a.AgentCard) int64 {
+	if card.Capabilities == nil {
+		return 0
+	}
+	return card.Capabilities.MaxOutputBytes
+}
+
+// artifactSchemas returns the artifact schemas of the skills in an agent card, keyed by
+// skill ID. Skills without a schema are left out.
+func artifactSchemas(card *a2a.AgentCard) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	for _, skill := range card.Skills {
+		if skill.ArtifactSchema != nil {
+			schemas[skill.ID] = skill.ArtifactSchema
+		}
+	}
+	return schemas
+}
+
+// ReloadConfig holds the parts of the server configuration that can be replaced while
+// the server is running. Nil fields are left unchanged.
+type ReloadConfig struct {
+	AgentCard   *a2a.AgentCard // New agent card to serve
+	TaskHandler task.Handler   // New handler for tasks started after the reload
+}
+
+// Reload replaces the agent card and task handler of a running server.
+// Tasks that are already running keep using the handler they were started with.
+// If the reload cannot be applied, an error is returned and nothing is changed.
+func (s *Server) Reload(cfg ReloadConfig) error {
+	var setter interface{ SetTaskHandler(task.Handler) }
+	if cfg.TaskHandler != nil {
+		var ok bool
+		if setter, ok = s.taskManager.(interface{ SetTaskHandler(task.Handler) }); !ok {
+			return errors.New("task manager does not support replacing the task handler")
+		}
+	}
+
+	var card *a2a.AgentCard
+	if cfg.AgentCard != nil {
+		var err error
+		if card, err = normalizeAgentCard(cfg.AgentCard, s.config.SSEPath); err != nil {
+			return err
+		}
+	}
+
+	if card != nil {
+		s.card.Store(card)
+		if limiter, ok := s.taskManager.(interface{ SetMaxOutputBytes(int64) }); ok {
+			limiter.SetMaxOutputBytes(maxOutputBytes(card))
+		}
+		if validator, ok := s.taskManager.(interface {
+			SetArtifactValidation(map[string]interface{}, ArtifactValidationPolicy)
+		}); ok {
+			validator.SetArtifactValidation(artifactSchemas(card), s.config.ArtifactValidation)
+		}
+	}
+	if setter != nil {
+		handler := cfg.TaskHandler
+		if len(s.config.SkillAgentEngines) > 0 {
+			handler = skillEngineHandler(s.config.SkillAgentEngines, handler)
+		}
+		setter.SetTaskHandler(handler)
+	}
+	return nil
+}
+
 func (s *Server) handleAgentEngineRequest(w http.ResponseWriter, r *http.Request) {
 	if handler, ok := s.config.AgentEngine.(interface {
 		HandleRequest(http.ResponseWriter, *http.Request)
@@ -121,12 +349,26 @@ func (s *Server) Stop(ctx context.Context) error {
 	// TODO: Log server shutdown
 	fmt.Println("Stopping A2A server...")
 	err := s.httpServer.Shutdown(ctx)
+
+	// Cancel the tasks still running, so clients know to send them again
+	s.shutdownTasks()
+	for _, agent := range s.virtual {
+		agent.shutdownTasks()
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to gracefully shutdown HTTP server: %w", err)
 	}
-	// TODO: Add cleanup for TaskManager, SSE connections etc.
+	// TODO: Add cleanup for SSE connections etc.
 	fmt.Println("A2A server stopped.")
 	return nil
 }
 
+// shutdownTasks cancels the tasks still running, if the task manager supports it.
+func (s *Server) shutdownTasks() {
+	if tm, ok := s.taskManager.(interface{ Shutdown() []*a2a.Task }); ok {
+		tm.Shutdown()
+	}
+}
+
 // Note: The handleA2ARequest method is now implemented in handler.go
@@ -0,0 +1,1146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/pkg/task"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubAgentEngine is a no-op agent engine used to construct test servers.
+type stubAgentEngine struct{}
+
+func (stubAgentEngine) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+	updates := make(chan task.YieldUpdate)
+	close(updates)
+	return updates, nil
+}
+
+func (stubAgentEngine) GetCapabilities() AgentCapabilities {
+	return AgentCapabilities{SupportsStreaming: true}
+}
+
+// newTestServer creates a Server for the given handler and serves it over HTTP.
+// The returned URL is the base URL of the A2A endpoint.
+func newTestServer(t *testing.T, handler task.Handler, opts ...Option) (*Server, string) {
+	t.Helper()
+
+	defaults := []Option{
+		WithAgentCard(&a2a.AgentCard{
+			A2AVersion: "1.0",
+			ID:         "test-agent",
+			Name:       "Test Agent",
+			Capabilities: &a2a.AgentCapabilities{
+				SupportsStreaming:        true,
+				SupportsPushNotification: true,
+			},
+		}),
+		WithA2APathPrefix("/a2a/"),
+		WithAgentEngine(stubAgentEngine{}),
+		WithTaskHandler(handler),
+	}
+
+	s, err := NewServer(append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	httpServer := httptest.NewServer(s.Handler())
+	t.Cleanup(httpServer.Close)
+
+	return s, httpServer.URL + s.config.A2APathPrefix
+}
+
+func TestServer_MessageMetadataRoundTrip(t *testing.T) {
+	metadata := map[string]interface{}{
+		"source":     "test",
+		"confidence": 0.9,
+		"tags":       []interface{}{"a", "b"},
+		"nested":     map[string]interface{}{"key": "value"},
+	}
+
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		msg := newTextMessage(a2a.RoleAgent, "done")
+		update := task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &msg}
+		for k, v := range metadata {
+			update = update.WithMetadata(k, v)
+		}
+		updates <- update
+		close(updates)
+		return updates, nil
+	}
+
+	_, baseURL := newTestServer(t, handler)
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	var taskObj *a2a.Task
+	for taskObj == nil || taskObj.Status.State != a2a.TaskStateCompleted {
+		if ctx.Err() != nil {
+			t.Fatalf("Task did not complete: %v", ctx.Err())
+		}
+		taskObj, err = c.GetTask(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if taskObj.Status.Message == nil {
+		t.Fatal("Expected final status to carry a message")
+	}
+	if !reflect.DeepEqual(taskObj.Status.Message.Metadata, metadata) {
+		t.Errorf("Status message metadata mismatch: got %v, want %v", taskObj.Status.Message.Metadata, metadata)
+	}
+
+	last := taskObj.History[len(taskObj.History)-1]
+	if !reflect.DeepEqual(last.Metadata, metadata) {
+		t.Errorf("History message metadata mismatch: got %v, want %v", last.Metadata, metadata)
+	}
+}
+
+func TestStatusUpdate_WithMetadataDoesNotModifyOriginal(t *testing.T) {
+	msg := newTextMessage(a2a.RoleAgent, "hello")
+	msg.Metadata = map[string]interface{}{"existing": true}
+	original := task.StatusUpdate{State: a2a.TaskStateWorking, Message: &msg}
+
+	updated := original.WithMetadata("added", "value")
+
+	if _, ok := msg.Metadata.(map[string]interface{})["added"]; ok {
+		t.Error("Expected original message metadata to be unchanged")
+	}
+	metadata := updated.Message.Metadata.(map[string]interface{})
+	if metadata["existing"] != true || metadata["added"] != "value" {
+		t.Errorf("Unexpected metadata: %v", metadata)
+	}
+}
+
+func TestServer_TracingSpans(t *testing.T) {
+	// Span contexts are sent between agents by the global propagator
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, baseURL := newTestServer(t, newMockHandler(), WithTracerProvider(provider))
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL), client.WithTracerProvider(provider))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	created, err := c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	// Wait for the task span to end
+	var spans []sdktrace.ReadOnlySpan
+	deadline := time.Now().Add(2 * time.Second)
+	for len(spans) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		spans = recorder.Ended()
+	}
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+	attr := func(span sdktrace.ReadOnlySpan, key string) string {
+		for _, kv := range span.Attributes() {
+			if string(kv.Key) == key {
+				return kv.Value.AsString()
+			}
+		}
+		return ""
+	}
+
+	clientSpan, ok := byName["a2a.client"]
+	if !ok {
+		t.Fatalf("Expected a client span, got %d spans", len(spans))
+	}
+	if got := attr(clientSpan, "a2a.method"); got != "tasks/send" {
+		t.Errorf("Expected client span method tasks/send, got %v", got)
+	}
+
+	requestSpan, ok := byName["a2a.request"]
+	if !ok {
+		t.Fatalf("Expected a request span, got %d spans", len(spans))
+	}
+	if got := attr(requestSpan, "a2a.method"); got != "tasks/send" {
+		t.Errorf("Expected request span method tasks/send, got %v", got)
+	}
+	if attr(requestSpan, "a2a.request_id") == "" {
+		t.Error("Expected request span to carry a request ID")
+	}
+	if requestSpan.Parent().SpanID() != clientSpan.SpanContext().SpanID() || requestSpan.SpanContext().TraceID() != clientSpan.SpanContext().TraceID() {
+		t.Errorf("Expected request span to be a child of the client span")
+	}
+	if !requestSpan.Parent().IsRemote() {
+		t.Error("Expected request span's parent to be the remote client span")
+	}
+
+	taskSpan, ok := byName["a2a.task"]
+	if !ok {
+		t.Fatalf("Expected a task span, got %d spans", len(spans))
+	}
+	if taskSpan.Parent().SpanID() != requestSpan.SpanContext().SpanID() {
+		t.Errorf("Expected task span to be a child of the request span")
+	}
+	if got := attr(taskSpan, "a2a.task_id"); got != created.ID {
+		t.Errorf("Expected task span task ID %s, got %v", created.ID, got)
+	}
+	if got := attr(taskSpan, "a2a.task_state"); got != string(a2a.TaskStateCompleted) {
+		t.Errorf("Expected task span state completed, got %v", got)
+	}
+}
+
+// recordingTransport records the headers of requests and responses passing through it.
+type recordingTransport struct {
+	requestHeaders  []http.Header
+	responseHeaders []http.Header
+	mu              sync.Mutex
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.requestHeaders = append(rt.requestHeaders, req.Header.Clone())
+	if resp != nil {
+		rt.responseHeaders = append(rt.responseHeaders, resp.Header.Clone())
+	}
+	return resp, err
+}
+
+func TestServer_CompressionRoundTrip(t *testing.T) {
+	largeText := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1000)
+
+	// Echo the user's message back as the task result
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		msg := taskCtx.UserMessage
+		msg.Role = a2a.RoleAgent
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &msg}
+		close(updates)
+		return updates, nil
+	}
+	s, baseURL := newTestServer(t, handler, WithCompression(true))
+
+	transport := &recordingTransport{}
+	c, err := client.NewClient(
+		client.WithBaseURL(baseURL),
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithCompression(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	created, err := c.SendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, largeText),
+	})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	waitForState(t, s.taskManager, created.ID, a2a.TaskStateCompleted)
+
+	taskObj, err := c.GetTask(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != largeText {
+		t.Errorf("Expected echoed text of length %d, got length %d", len(largeText), len(text))
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if got := transport.requestHeaders[0].Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Expected compressed request, got Content-Encoding %q", got)
+	}
+	for i, headers := range transport.responseHeaders {
+		if got := headers.Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("Expected compressed response %d, got Content-Encoding %q", i, got)
+		}
+	}
+}
+
+func TestServer_Reload(t *testing.T) {
+	cardFile := filepath.Join(t.TempDir(), "agent.json")
+	writeCard := func(name string) {
+		data, err := json.Marshal(a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: name})
+		if err != nil {
+			t.Fatalf("Failed to marshal card: %v", err)
+		}
+		if err := os.WriteFile(cardFile, data, 0644); err != nil {
+			t.Fatalf("Failed to write card file: %v", err)
+		}
+	}
+	loadCard := func() *a2a.AgentCard {
+		data, err := os.ReadFile(cardFile)
+		if err != nil {
+			t.Fatalf("Failed to read card file: %v", err)
+		}
+		var card a2a.AgentCard
+		if err := json.Unmarshal(data, &card); err != nil {
+			t.Fatalf("Failed to parse card file: %v", err)
+		}
+		return &card
+	}
+
+	// The first handler blocks until released, so its task is in flight during the reload
+	release := make(chan struct{})
+	firstHandler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-release
+			msg := newTextMessage(a2a.RoleAgent, "first")
+			updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &msg}
+		}()
+		return updates, nil
+	}
+	secondHandler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		msg := newTextMessage(a2a.RoleAgent, "second")
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &msg}
+		close(updates)
+		return updates, nil
+	}
+
+	writeCard("Before")
+	s, baseURL := newTestServer(t, firstHandler, WithAgentCard(loadCard()))
+	cardURL := strings.TrimSuffix(baseURL, s.config.A2APathPrefix) + s.config.AgentCardPath
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	inFlight, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+
+	writeCard("After")
+	if err := s.Reload(ReloadConfig{AgentCard: loadCard(), TaskHandler: secondHandler}); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	resp, err := http.Get(cardURL)
+	if err != nil {
+		t.Fatalf("Failed to fetch agent card: %v", err)
+	}
+	defer resp.Body.Close()
+	var card a2a.AgentCard
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		t.Fatalf("Failed to decode agent card: %v", err)
+	}
+	if card.Name != "After" {
+		t.Errorf("Expected reloaded card name After, got %s", card.Name)
+	}
+
+	// New tasks use the new handler; the in-flight task finishes with the old one
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	taskObj := waitForState(t, s.taskManager, created.ID, a2a.TaskStateCompleted)
+	if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != "second" {
+		t.Errorf("Expected new task to use the reloaded handler, got %q", text)
+	}
+
+	close(release)
+	taskObj = waitForState(t, s.taskManager, inFlight.ID, a2a.TaskStateCompleted)
+	if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != "first" {
+		t.Errorf("Expected in-flight task to finish with the original handler, got %q", text)
+	}
+}
+
+func TestNewServer_InvalidAgentCard(t *testing.T) {
+	_, err := NewServer(
+		WithAgentCard(&a2a.AgentCard{
+			ID:   "test-agent",
+			Name: "Test Agent",
+			Skills: []a2a.AgentSkill{
+				{ID: "echo", Name: "Echo"},
+				{ID: "echo", Name: "Echo"},
+			},
+		}),
+		WithAgentEngine(stubAgentEngine{}),
+	)
+	if err == nil {
+		t.Fatal("Expected NewServer to reject an invalid agent card")
+	}
+	for _, want := range []string{"a2aVersion is required", `duplicate skill id "echo"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestNewServer_AgentCardLoader(t *testing.T) {
+	card := &a2a.AgentCard{A2AVersion: "1.0", ID: "loaded-agent", Name: "Loaded Agent"}
+
+	t.Run("loaded card is served", func(t *testing.T) {
+		cardServer := httptest.NewServer(AgentCardHandler(card))
+		defer cardServer.Close()
+
+		s, err := NewServer(
+			WithAgentCardLoader(AgentCardFromURL(cardServer.URL)),
+			WithAgentEngine(stubAgentEngine{}),
+			WithTaskHandler(newMockHandler()),
+		)
+		if err != nil {
+			t.Fatalf("NewServer failed: %v", err)
+		}
+		if got := s.agentCard(); got.ID != "loaded-agent" || got.Skills == nil {
+			t.Errorf("Expected the loaded card to be normalized and served, got %+v", got)
+		}
+	})
+
+	t.Run("loaded card replaces the configured card", func(t *testing.T) {
+		s, err := NewServer(
+			WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "static-agent", Name: "Static Agent"}),
+			WithAgentCardLoader(func(ctx context.Context) (*a2a.AgentCard, error) { return card, nil }),
+			WithAgentEngine(stubAgentEngine{}),
+			WithTaskHandler(newMockHandler()),
+		)
+		if err != nil {
+			t.Fatalf("NewServer failed: %v", err)
+		}
+		if got := s.agentCard().ID; got != "loaded-agent" {
+			t.Errorf("Expected the loaded card, got %q", got)
+		}
+	})
+
+	t.Run("loader error aborts startup", func(t *testing.T) {
+		_, err := NewServer(
+			WithAgentCardLoader(func(ctx context.Context) (*a2a.AgentCard, error) {
+				return nil, errors.New("registry unavailable")
+			}),
+			WithAgentEngine(stubAgentEngine{}),
+		)
+		if err == nil || !strings.Contains(err.Error(), "registry unavailable") {
+			t.Errorf("Expected the loader error, got %v", err)
+		}
+	})
+
+	t.Run("invalid loaded card is rejected", func(t *testing.T) {
+		_, err := NewServer(
+			WithAgentCardLoader(func(ctx context.Context) (*a2a.AgentCard, error) {
+				return &a2a.AgentCard{ID: "loaded-agent", Name: "Loaded Agent"}, nil
+			}),
+			WithAgentEngine(stubAgentEngine{}),
+		)
+		if err == nil || !strings.Contains(err.Error(), "a2aVersion is required") {
+			t.Errorf("Expected the loaded card to be validated, got %v", err)
+		}
+	})
+}
+
+func TestServer_ProtectedAgentCard(t *testing.T) {
+	card := &a2a.AgentCard{
+		A2AVersion:     "1.0",
+		ID:             "test-agent",
+		Name:           "Test Agent",
+		Authentication: []a2a.AgentAuthentication{{Type: "bearer"}},
+	}
+
+	tests := []struct {
+		name       string
+		protected  bool
+		token      string
+		wantStatus int
+	}{
+		{name: "public card without credentials", protected: false, wantStatus: http.StatusOK},
+		{name: "protected card without credentials", protected: true, wantStatus: http.StatusUnauthorized},
+		{name: "protected card with invalid credentials", protected: true, token: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "protected card with credentials", protected: true, token: "secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, baseURL := newTestServer(t, newMockHandler(),
+				WithAgentCard(card),
+				WithAuthValidator(SimpleTokenValidator("secret")),
+				WithProtectedAgentCard(tt.protected),
+			)
+			serverURL := strings.TrimSuffix(baseURL, "/a2a/")
+
+			req, err := http.NewRequest(http.MethodGet, serverURL+DefaultAgentCardPath, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Failed to fetch agent card: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	// The client sends its auth headers when fetching the card
+	_, baseURL := newTestServer(t, newMockHandler(),
+		WithAgentCard(card),
+		WithAuthValidator(SimpleTokenValidator("secret")),
+		WithProtectedAgentCard(true),
+	)
+	c, err := client.NewClient(
+		client.WithBaseURL(strings.TrimSuffix(baseURL, "/a2a/")),
+		client.WithBearerToken("secret"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	fetched, err := c.FetchAgentCard(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to fetch protected agent card: %v", err)
+	}
+	if fetched.ID != card.ID {
+		t.Errorf("Expected card ID %q, got %q", card.ID, fetched.ID)
+	}
+}
+
+func TestServer_CancelSession(t *testing.T) {
+	// Create a handler that keeps tasks working until released
+	release := make(chan struct{})
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			updates <- task.StatusUpdate{State: a2a.TaskStateWorking}
+			<-release
+		}()
+		return updates, nil
+	}
+	defer close(release)
+
+	_, baseURL := newTestServer(t, handler)
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	// Start two tasks in one session and one in another
+	send := func(sessionID string) string {
+		created, err := c.SendTask(ctx, &a2a.TaskSendParams{
+			SessionID: &sessionID,
+			Message:   newTextMessage(a2a.RoleUser, "hello"),
+		})
+		if err != nil {
+			t.Fatalf("SendTask failed: %v", err)
+		}
+		return created.ID
+	}
+	first, second, other := send("session-1"), send("session-1"), send("session-2")
+
+	cancelled, err := c.CancelSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("CancelSession failed: %v", err)
+	}
+	if len(cancelled) != 2 {
+		t.Fatalf("Expected 2 cancelled tasks, got %d", len(cancelled))
+	}
+	if cancelled[0].ID != first || cancelled[1].ID != second {
+		t.Errorf("Expected the cancelled tasks in creation order, got %s and %s", cancelled[0].ID, cancelled[1].ID)
+	}
+
+	for _, id := range []string{first, second} {
+		taskObj, err := c.GetTask(ctx, id)
+		if err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+		if taskObj.Status.State != a2a.TaskStateCancelled {
+			t.Errorf("Expected task %s to be cancelled, got %s", id, taskObj.Status.State)
+		}
+	}
+	taskObj, err := c.GetTask(ctx, other)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if taskObj.Status.State == a2a.TaskStateCancelled {
+		t.Error("Expected the task in another session not to be cancelled")
+	}
+
+	// Cancelling again finds nothing left to cancel
+	cancelled, err = c.CancelSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("CancelSession failed: %v", err)
+	}
+	if len(cancelled) != 0 {
+		t.Errorf("Expected no tasks to be cancelled again, got %d", len(cancelled))
+	}
+}
+
+func TestServer_HandlerMountedOnExistingMux(t *testing.T) {
+	s, err := NewServer(
+		WithAgentCard(&a2a.AgentCard{
+			A2AVersion:   "1.0",
+			ID:           "mounted-agent",
+			Name:         "Mounted Agent",
+			Capabilities: &a2a.AgentCapabilities{SupportsStreaming: true},
+		}),
+		WithA2APathPrefix("/a2a/"),
+		WithAgentEngine(stubAgentEngine{}),
+		WithTaskHandler(newMockHandler()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// The application's own server has other routes alongside the A2A ones
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.Handle("/", s.Handler())
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("Failed to get health check: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the application's route to be served, got status %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(httpServer.URL + DefaultAgentCardPath)
+	if err != nil {
+		t.Fatalf("Failed to get agent card: %v", err)
+	}
+	var card a2a.AgentCard
+	err = json.NewDecoder(resp.Body).Decode(&card)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode agent card: %v", err)
+	}
+	if card.ID != "mounted-agent" {
+		t.Errorf("Expected the mounted agent's card, got %q", card.ID)
+	}
+
+	c, err := client.NewClient(client.WithBaseURL(httpServer.URL + "/a2a/"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	created, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if _, err := c.GetTask(ctx, created.ID); err != nil {
+		t.Errorf("GetTask failed: %v", err)
+	}
+}
+
+func TestServer_SSEPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantPath string
+	}{
+		{name: "default", wantPath: DefaultSSEPath},
+		{name: "configured", opts: []Option{WithSSEPath("/events")}, wantPath: "events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without a trailing slash the prefix only matches the JSON-RPC endpoint itself, so
+			// streams only work if the client posts to the SSE path the server registered
+			opts := append([]Option{WithA2APathPrefix("/a2a")}, tt.opts...)
+			s, baseURL := newTestServer(t, newMockHandler(), opts...)
+
+			resp, err := http.Get(strings.TrimSuffix(baseURL, "/a2a") + DefaultAgentCardPath)
+			if err != nil {
+				t.Fatalf("Failed to get agent card: %v", err)
+			}
+			var card a2a.AgentCard
+			err = json.NewDecoder(resp.Body).Decode(&card)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("Failed to decode agent card: %v", err)
+			}
+			if card.Capabilities == nil || card.Capabilities.StreamingEndpoint != tt.wantPath ||
+				card.Capabilities.StreamingProtocol != a2a.StreamingProtocolSSE {
+				t.Fatalf("Expected the card to advertise SSE endpoint %q, got %+v", tt.wantPath, card.Capabilities)
+			}
+
+			c, err := client.NewClient(client.WithBaseURL(baseURL), client.WithAgentCard(&card))
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			created, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+			if err != nil {
+				t.Fatalf("Failed to send task: %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{
+				TaskID:  &created.ID,
+				Message: newTextMessage(a2a.RoleUser, "again"),
+			})
+
+			// The stream is only opened if the client posted to the server's SSE path
+			if _, ok := <-updates; !ok {
+				t.Fatalf("Expected a stream of updates, got error: %v", <-errs)
+			}
+		})
+	}
+}
+
+func TestServer_StreamingOnJSONRPCEndpoint(t *testing.T) {
+	s, baseURL := newTestServer(t, newMockHandler())
+	created, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("Failed to send task: %v", err)
+	}
+
+	body := `{"jsonrpc":"2.0","method":"tasks/resubscribe","id":"1","params":{"taskId":"` + created.ID + `"}}`
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Post(baseURL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the stream to be served without a redirect, got status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Expected an SSE stream, got content type %q", ct)
+	}
+}
+
+func TestServer_StreamsStatusReason(t *testing.T) {
+	// Every run of the task hangs until it times out
+	hang := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-ctx.Done()
+		}()
+		return updates, nil
+	}
+	s, baseURL := newTestServer(t, hang, WithTaskTimeout(50*time.Millisecond))
+	created, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("Failed to send task: %v", err)
+	}
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{
+		TaskID:  &created.ID,
+		Message: newTextMessage(a2a.RoleUser, "again"),
+	})
+
+	for update := range updates {
+		if update.Status == nil || update.Status.State != a2a.TaskStateFailed {
+			continue
+		}
+		if update.Status.Reason != a2a.TaskStatusReasonTimeout || !update.Status.Retryable {
+			t.Errorf("Expected a retryable timeout, got reason %q (retryable %v)", update.Status.Reason, update.Status.Retryable)
+		}
+		return
+	}
+	t.Fatalf("Stream ended without a failed update: %v", <-errs)
+}
+
+func TestServer_SendSubscribeStreamsSubmitted(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+
+	var states []a2a.TaskState
+	for update := range updates {
+		if update.Status == nil {
+			continue
+		}
+		states = append(states, update.Status.State)
+		if update.Status.State == a2a.TaskStateCompleted {
+			if states[0] != a2a.TaskStateSubmitted {
+				t.Errorf("Expected the submitted status first, got %v", states)
+			}
+			return
+		}
+	}
+	t.Fatalf("Stream ended before the task completed, got %v: %v", states, <-errs)
+}
+
+// namedAgentEngine is an agent engine that completes tasks with its name.
+type namedAgentEngine struct {
+	stubAgentEngine
+	name string
+}
+
+func (e namedAgentEngine) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+	updates := make(chan task.YieldUpdate, 1)
+	msg := newTextMessage(a2a.RoleAgent, e.name)
+	updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &msg}
+	close(updates)
+	return updates, nil
+}
+
+func TestServer_SkillAgentEngines(t *testing.T) {
+	card := &a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "test-agent",
+		Name:       "Test Agent",
+		Skills: []a2a.AgentSkill{
+			{ID: "simple", Name: "Simple"},
+			{ID: "reasoning", Name: "Reasoning"},
+			{ID: "chat", Name: "Chat"},
+		},
+	}
+	engines := map[string]AgentEngine{
+		"simple":    namedAgentEngine{name: "small model"},
+		"reasoning": namedAgentEngine{name: "large model"},
+	}
+	skill := func(id string) *string { return &id }
+
+	tests := []struct {
+		name    string
+		handler task.Handler
+		skillID *string
+		want    string
+	}{
+		{"simple skill", nil, skill("simple"), "small model"},
+		{"reasoning skill", nil, skill("reasoning"), "large model"},
+		{"skill without an engine", nil, skill("chat"), "default model"},
+		{"no skill", nil, nil, "default model"},
+		{"no skill with a task handler", namedAgentEngine{name: "handler"}.ProcessTask, nil, "handler"},
+		{"skill with a task handler", namedAgentEngine{name: "handler"}.ProcessTask, skill("reasoning"), "large model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewServer(
+				WithAgentCard(card),
+				WithAgentEngine(namedAgentEngine{name: "default model"}),
+				WithSkillAgentEngines(engines),
+				WithTaskHandler(tt.handler),
+			)
+			if err != nil {
+				t.Fatalf("NewServer failed: %v", err)
+			}
+
+			created, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{
+				SkillID: tt.skillID,
+				Message: newTextMessage(a2a.RoleUser, "hello"),
+			})
+			if err != nil {
+				t.Fatalf("OnSendTask failed: %v", err)
+			}
+			taskObj := waitForState(t, s.taskManager, created.ID, a2a.TaskStateCompleted)
+			if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != tt.want {
+				t.Errorf("Expected the task to be handled by %q, got %q", tt.want, text)
+			}
+		})
+	}
+}
+
+func TestNewServer_InvalidSkillAgentEngines(t *testing.T) {
+	card := &a2a.AgentCard{
+		A2AVersion: "1.0",
+		ID:         "test-agent",
+		Name:       "Test Agent",
+		Skills:     []a2a.AgentSkill{{ID: "simple", Name: "Simple"}},
+	}
+
+	tests := []struct {
+		name    string
+		engines map[string]AgentEngine
+		wantErr string
+	}{
+		{"unknown skill", map[string]AgentEngine{"missing": stubAgentEngine{}}, `agent engine for unknown skill "missing"`},
+		{"nil engine", map[string]AgentEngine{"simple": nil}, `agent engine for skill "simple" is nil`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServer(
+				WithAgentCard(card),
+				WithAgentEngine(stubAgentEngine{}),
+				WithSkillAgentEngines(tt.engines),
+			)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// unreachableMCPClient is an MCP client whose server cannot be reached.
+type unreachableMCPClient struct {
+	fakeMCPClient
+}
+
+func (*unreachableMCPClient) GetAvailableTools(ctx context.Context) ([]MCPToolInfo, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestNewServer_AgentEngineRequired(t *testing.T) {
+	handler := newMockHandler()
+
+	t.Run("nothing to process tasks", func(t *testing.T) {
+		// The agent card is invalid too, but the missing agent is reported first
+		_, err := NewServer(WithAgentCard(&a2a.AgentCard{ID: "test-agent"}))
+		if !errors.Is(err, ErrNoAgentEngine) || err.Error() != "gollm options must be set when agent engine not set" {
+			t.Errorf("Expected ErrNoAgentEngine, got %v", err)
+		}
+	})
+
+	t.Run("task handler without an agent engine", func(t *testing.T) {
+		s, err := NewServer(
+			WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}),
+			WithTaskHandler(handler),
+		)
+		if err != nil {
+			t.Fatalf("NewServer failed: %v", err)
+		}
+		if s.config.AgentEngine != nil {
+			t.Errorf("Expected no agent engine to be created, got %T", s.config.AgentEngine)
+		}
+	})
+
+	t.Run("task manager without an agent engine", func(t *testing.T) {
+		tm := NewInMemoryTaskManager(handler)
+		s, err := NewServer(
+			WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}),
+			WithTaskManager(tm),
+		)
+		if err != nil {
+			t.Fatalf("NewServer failed: %v", err)
+		}
+		if s.taskManager != tm {
+			t.Error("Expected the task manager to be used")
+		}
+	})
+
+	t.Run("agent option failed", func(t *testing.T) {
+		_, err := NewServer(
+			WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}),
+			WithTaskHandler(handler),
+			WithMCPToolAugmentedAgent(&fakeLLM{}, &unreachableMCPClient{}),
+		)
+		if err == nil || !strings.Contains(err.Error(), "failed to create MCP tool-augmented agent") ||
+			!strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Expected the option's error, got %v", err)
+		}
+	})
+
+	t.Run("no task handler", func(t *testing.T) {
+		s, err := NewServer(
+			WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}),
+			WithAgentEngine(namedAgentEngine{name: "engine"}),
+		)
+		if err != nil {
+			t.Fatalf("NewServer failed: %v", err)
+		}
+
+		created, err := s.taskManager.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		taskObj := waitForState(t, s.taskManager, created.ID, a2a.TaskStateCompleted)
+		if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != "engine" {
+			t.Errorf("Expected the agent engine to process the task, got %q", text)
+		}
+	})
+}
+
+func TestServer_VirtualAgents(t *testing.T) {
+	agentCard := func(id string) *a2a.AgentCard {
+		return &a2a.AgentCard{A2AVersion: "1.0", ID: id, Name: id}
+	}
+	s, baseURL := newTestServer(t, newMockHandler(),
+		WithVirtualAgent("/agent-a", agentCard("agent-a"), namedAgentEngine{name: "agent-a"}.ProcessTask),
+		WithVirtualAgent("/agent-b/", agentCard("agent-b"), namedAgentEngine{name: "agent-b"}.ProcessTask),
+	)
+	rootURL := strings.TrimSuffix(baseURL, s.config.A2APathPrefix)
+	ctx := context.Background()
+
+	sent := make(map[string]string) // Map of agent to the ID of the task sent to it
+	for _, agent := range []string{"agent-a", "agent-b"} {
+		resp, err := http.Get(rootURL + "/" + agent + DefaultAgentCardPath)
+		if err != nil {
+			t.Fatalf("Failed to fetch agent card: %v", err)
+		}
+		var card a2a.AgentCard
+		err = json.NewDecoder(resp.Body).Decode(&card)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode agent card: %v", err)
+		}
+		if card.ID != agent {
+			t.Errorf("Expected the card of %s, got %s", agent, card.ID)
+		}
+
+		c, err := client.NewClient(client.WithBaseURL(rootURL + "/" + agent + s.config.A2APathPrefix))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		created, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("SendTask to %s failed: %v", agent, err)
+		}
+		sent[agent] = created.ID
+
+		var taskObj *a2a.Task
+		for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if taskObj, err = c.GetTask(ctx, created.ID); err != nil {
+				t.Fatalf("GetTask from %s failed: %v", agent, err)
+			}
+			if taskObj.Status.State == a2a.TaskStateCompleted || time.Now().After(deadline) {
+				break
+			}
+		}
+		if taskObj.Status.State != a2a.TaskStateCompleted {
+			t.Fatalf("Expected the task sent to %s to complete, got %s", agent, taskObj.Status.State)
+		}
+		if text := taskObj.Status.Message.Parts[0].(a2a.TextPart).Text; text != agent {
+			t.Errorf("Expected the task sent to %s to be handled by it, got %q", agent, text)
+		}
+	}
+
+	// Each agent keeps its own tasks
+	c, err := client.NewClient(client.WithBaseURL(rootURL + "/agent-b" + s.config.A2APathPrefix))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := c.GetTask(ctx, sent["agent-a"]); err == nil {
+		t.Error("Expected agent-b not to know the task sent to agent-a")
+	}
+	if _, err := s.taskManager.OnGetTask(ctx, &a2a.TaskQueryParams{TaskID: sent["agent-a"]}); err == nil {
+		t.Error("Expected the main agent not to know the task sent to agent-a")
+	}
+}
+
+func TestNewServer_InvalidVirtualAgents(t *testing.T) {
+	card := &a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{"relative prefix", []Option{WithVirtualAgent("agent-a", card, nil)}, `virtual agent prefix "agent-a" must start with /`},
+		{"root prefix", []Option{WithVirtualAgent("/", card, nil)}, `virtual agent prefix "/" must start with /`},
+		{"duplicate prefix", []Option{WithVirtualAgent("/agent-a", card, nil), WithVirtualAgent("/agent-a/", card, nil)}, `duplicate virtual agent prefix "/agent-a/"`},
+		{"invalid card", []Option{WithVirtualAgent("/agent-a", &a2a.AgentCard{ID: "agent-a"}, nil)}, `virtual agent "/agent-a": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithAgentCard(card), WithAgentEngine(stubAgentEngine{})}, tt.opts...)
+			_, err := NewServer(opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestServer_PathPrefixWithoutSlash(t *testing.T) {
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 2)
+		updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: "a cat"}}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler, WithA2APathPrefix("/a2a"))
+	if !strings.HasSuffix(baseURL, "/a2a") {
+		t.Fatalf("Expected a base URL without a trailing slash, got %s", baseURL)
+	}
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The stream, the JSON-RPC endpoint and the artifact download are all found below the prefix
+	updates, errs := c.SendSubscribe(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "draw a cat")})
+	var taskID string
+	for update := range updates {
+		taskID = update.TaskID
+		if update.Status != nil && update.Status.State == a2a.TaskStateCompleted {
+			break
+		}
+	}
+	if taskID == "" {
+		t.Fatalf("SendSubscribe failed: %v", <-errs)
+	}
+
+	taskObj, err := c.GetTask(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if taskObj.Status.State != a2a.TaskStateCompleted || len(taskObj.Artifacts) != 1 {
+		t.Fatalf("Expected a completed task with 1 artifact, got %s with %d", taskObj.Status.State, len(taskObj.Artifacts))
+	}
+	download, err := c.DownloadArtifact(ctx, taskID, taskObj.Artifacts[0].ID)
+	if err != nil {
+		t.Fatalf("DownloadArtifact failed: %v", err)
+	}
+	download.Body.Close()
+}
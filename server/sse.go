@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -13,10 +12,22 @@ import (
 	"github.com/sammcj/go-a2a/pkg/task"
 )
 
+// DefaultSSEPath is the default path of the SSE endpoint, relative to the A2A path prefix.
+const DefaultSSEPath = "sse"
+
+// DefaultSSEBufferSize is the default number of events buffered per SSE connection.
+const DefaultSSEBufferSize = 64
+
 // SSEManager manages Server-Sent Events (SSE) connections for A2A tasks.
+//
+// Events are queued on a buffered channel per connection and written by the
+// connection's own goroutine, so a slow client never blocks the task sending
+// updates. A client whose buffer fills up is disconnected; it can resubscribe
+// with the Last-Event-ID header to pick up where it left off.
 type SSEManager struct {
 	// Map of task ID to a map of connection IDs to SSE connections
 	connections map[string]map[string]*sseConnection
+	bufferSize  int // Events buffered per connection before it is disconnected
 	mu          sync.RWMutex
 }
 
@@ -26,31 +37,75 @@ type sseConnection struct {
 	connectionID string
 	w            http.ResponseWriter
 	flusher      http.Flusher
+	events       chan []byte // Outbound events, written by the connection's goroutine
 	done         chan struct{}
-	lastEventID  string
+	closeOnce    sync.Once
+	lastEventID  string     // ID of the last event queued
+	mu           sync.Mutex // Guards lastEventID, and orders the events queued
+}
+
+// close signals the connection's goroutine to stop. It is safe to call more than once.
+func (c *sseConnection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 }
 
 // NewSSEManager creates a new SSE manager.
 func NewSSEManager() *SSEManager {
 	return &SSEManager{
 		connections: make(map[string]map[string]*sseConnection),
+		bufferSize:  DefaultSSEBufferSize,
+	}
+}
+
+// SetBufferSize sets the number of events buffered per connection. A client that falls
+// further behind than this is disconnected. Values below 1 use DefaultSSEBufferSize.
+// It only affects connections opened afterwards.
+func (sm *SSEManager) SetBufferSize(n int) {
+	if n < 1 {
+		n = DefaultSSEBufferSize
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.bufferSize = n
+}
+
+// ConnectionCount returns the number of open SSE connections.
+func (sm *SSEManager) ConnectionCount() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	n := 0
+	for _, conns := range sm.connections {
+		n += len(conns)
 	}
+	return n
 }
 
 // HandleSSE handles an SSE connection for a task.
 func (sm *SSEManager) HandleSSE(w http.ResponseWriter, r *http.Request, taskID string, lastEventID string) {
+	conn, ok := sm.openConnection(w, taskID, lastEventID)
+	if !ok {
+		return
+	}
+	defer sm.removeConnection(taskID, conn.connectionID)
+	sm.serveConnection(r, conn)
+}
+
+// openConnection starts the SSE response for a task and registers the connection, so events
+// sent from now on are queued on it. It reports false, having written an error response, if
+// the response writer cannot stream. The caller must remove the connection when done.
+func (sm *SSEManager) openConnection(w http.ResponseWriter, taskID string, lastEventID string) (*sseConnection, bool) {
 	// Check if the client supports SSE
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	setSSEHeaders(w)
 
 	// Create a unique connection ID
 	connectionID := fmt.Sprintf("conn_%d", time.Now().UnixNano())
@@ -59,11 +114,15 @@ func (sm *SSEManager) HandleSSE(w http.ResponseWriter, r *http.Request, taskID s
 	done := make(chan struct{})
 
 	// Create the SSE connection
+	sm.mu.RLock()
+	bufferSize := sm.bufferSize
+	sm.mu.RUnlock()
 	conn := &sseConnection{
 		taskID:       taskID,
 		connectionID: connectionID,
 		w:            w,
 		flusher:      flusher,
+		events:       make(chan []byte, bufferSize),
 		done:         done,
 		lastEventID:  lastEventID,
 	}
@@ -71,24 +130,56 @@ func (sm *SSEManager) HandleSSE(w http.ResponseWriter, r *http.Request, taskID s
 	// Register the connection
 	sm.registerConnection(taskID, connectionID, conn)
 
-	// Remove the connection when the handler returns
-	defer sm.removeConnection(taskID, connectionID)
-
 	// Send a comment to establish the connection
 	fmt.Fprint(w, ": connected\n\n")
 	flusher.Flush()
+	return conn, true
+}
 
-	// Wait for the connection to be closed
-	select {
-	case <-r.Context().Done():
-		// Request context was cancelled (client disconnected)
-		return
-	case <-done:
-		// Connection was closed by the server
-		return
+// serveConnection writes the events queued on a connection until the client disconnects or
+// the connection is closed. Events queued before the connection was closed are still written.
+func (sm *SSEManager) serveConnection(r *http.Request, conn *sseConnection) {
+	for {
+		select {
+		case <-r.Context().Done():
+			// Request context was cancelled (client disconnected)
+			return
+		case <-conn.done:
+			// Connection was closed by the server
+			conn.drain()
+			return
+		case event := <-conn.events:
+			conn.write(event)
+		}
 	}
 }
 
+// write writes an event to the client.
+func (c *sseConnection) write(event []byte) {
+	c.w.Write(event)
+	c.flusher.Flush()
+}
+
+// drain writes the events still queued on the connection.
+func (c *sseConnection) drain() {
+	for {
+		select {
+		case event := <-c.events:
+			c.write(event)
+		default:
+			return
+		}
+	}
+}
+
+// setSSEHeaders sets the headers of an SSE response.
+func setSSEHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+}
+
 // registerConnection registers a new SSE connection.
 func (sm *SSEManager) registerConnection(taskID, connectionID string, conn *sseConnection) {
 	sm.mu.Lock()
@@ -128,7 +219,7 @@ func (sm *SSEManager) closeConnection(taskID, connectionID string) {
 	if conns, exists := sm.connections[taskID]; exists {
 		if conn, exists := conns[connectionID]; exists {
 			// Signal that the connection is closed
-			close(conn.done)
+			conn.close()
 		}
 	}
 }
@@ -173,30 +264,63 @@ func (sm *SSEManager) sendEvent(taskID, eventType string, data interface{}, even
 	}
 	sm.mu.RUnlock()
 
-	// Send the event to all connections
+	// Format the event once for all connections
+	event := []byte(fmt.Sprintf("event: %s\nid: %s\ndata: %s\n\n", eventType, eventID, jsonData))
+
+	// Queue the event on all connections
 	for _, conn := range conns {
-		// Skip if the connection already received this event
-		if conn.lastEventID >= eventID {
-			continue
-		}
+		conn.queue(event, eventID)
+	}
+}
 
-		// Send the event
-		fmt.Fprintf(conn.w, "event: %s\n", eventType)
-		fmt.Fprintf(conn.w, "id: %s\n", eventID)
-		fmt.Fprintf(conn.w, "data: %s\n\n", jsonData)
-		conn.flusher.Flush()
+// queue queues an event on the connection, unless it was the last event queued. Event IDs
+// are not ordered (e.g. ":status:completed" follows ":status:working"), so only repeats are
+// skipped. A client that cannot keep up is disconnected rather than blocking the task.
+func (c *sseConnection) queue(event []byte, eventID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		// Update the last event ID
-		conn.lastEventID = eventID
+	if c.lastEventID == eventID {
+		return
+	}
+
+	select {
+	case c.events <- event:
+		c.lastEventID = eventID
+	default:
+		fmt.Printf("Disconnecting slow SSE client %s for task %s\n", c.connectionID, c.taskID)
+		c.close()
 	}
 }
 
 // HandleTaskSendSubscribe handles the tasks/sendSubscribe method.
 func (s *Server) handleTaskSendSubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Reject streaming if the agent does not support it
+	if !s.supportsStreaming() {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("streaming"), request.ID)
+		return
+	}
+
 	// Parse params
 	var params a2a.TaskSendParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+		return
+	}
+
+	// Narrow the accepted output modes to those the agent supports
+	if err := s.negotiateOutputModes(&params); err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	// Give the task the content of the files it references by upload
+	if err := s.resolveUploads(ctx, &params); err != nil {
+		writeJSONRPCError(w, r, err, request.ID)
+		return
+	}
+
+	if !s.allowSkillTask(ctx, w, r, &params, request.ID) {
 		return
 	}
 
@@ -204,110 +328,98 @@ func (s *Server) handleTaskSendSubscribe(ctx context.Context, w http.ResponseWri
 	lastEventID := r.Header.Get("Last-Event-ID")
 
 	// Call TaskManager to start the task
-	updateChan, err := s.taskManager.OnSendTaskSubscribe(ctx, &params)
+	taskID, updateChan, err := s.taskManager.OnSendTaskSubscribe(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
-	// Get the task ID from the task manager
-	var taskID string
-	if params.TaskID != nil {
-		taskID = *params.TaskID
-	} else {
-		// For new tasks, we need to get the task ID from the first update
-		select {
-		case update := <-updateChan:
-			if statusUpdate, ok := update.(task.StatusUpdate); ok && statusUpdate.State == a2a.TaskStateSubmitted {
-				// Get the task from the task manager
-				task, err := s.taskManager.OnGetTask(ctx, &a2a.TaskQueryParams{TaskID: taskID})
-				if err != nil {
-					writeJSONRPCError(w, r, a2a.ErrInternalError(err), request.ID)
-					return
-				}
-				taskID = task.ID
-			} else {
-				writeJSONRPCError(w, r, a2a.ErrInternalError(fmt.Errorf("unexpected first update")), request.ID)
-				return
-			}
-		case <-ctx.Done():
-			writeJSONRPCError(w, r, a2a.ErrInternalError(ctx.Err()), request.ID)
-			return
-		}
-	}
+	s.streamUpdates(w, r, taskID, lastEventID, updateChan)
+}
 
-	// Start a goroutine to process updates from the task manager
+// streamUpdates streams a task's updates from the task manager to the client over SSE. The
+// connection is registered before the first update is forwarded, so the client receives
+// every update, including the task's first status.
+func (s *Server) streamUpdates(w http.ResponseWriter, r *http.Request, taskID, lastEventID string, updates <-chan task.YieldUpdate) {
+	conn, ok := s.sseManager.openConnection(w, taskID, lastEventID)
+
+	// Forward the updates even if the response cannot stream, so the task is not blocked
 	go func() {
-		for update := range updateChan {
+		for update := range updates {
 			switch u := update.(type) {
 			case task.StatusUpdate:
-				s.sseManager.SendTaskStatusUpdate(taskID, a2a.TaskStatus{
-					State:     u.State,
-					Timestamp: time.Now(),
-					Message:   u.Message,
-				})
+				s.sseManager.SendTaskStatusUpdate(taskID, statusFromUpdate(u, time.Now()))
 			case task.ArtifactUpdate:
+				// The task manager sets the ID of the artifact it stored for the update
 				artifact := a2a.Artifact{
-					ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
+					ID:        u.ID,
 					TaskID:    taskID,
-					Timestamp: time.Now(),
+					Timestamp: u.Timestamp,
 					Part:      u.Part,
 					Metadata:  u.Metadata,
 				}
+				if artifact.ID == "" {
+					artifact.ID = fmt.Sprintf("artifact_%d", time.Now().UnixNano())
+					artifact.Timestamp = time.Now()
+				}
 				s.sseManager.SendTaskArtifactUpdate(taskID, artifact)
 			}
 		}
 	}()
 
-	// Handle the SSE connection
-	s.sseManager.HandleSSE(w, r, taskID, lastEventID)
-}
-
-// handleSSERequest handles SSE requests.
-func (s *Server) handleSSERequest(w http.ResponseWriter, r *http.Request) {
-	// Only accept POST requests
-	if r.Method != http.MethodPost {
-		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Method not allowed"), nil)
+	if !ok {
 		return
 	}
+	defer s.sseManager.removeConnection(taskID, conn.connectionID)
+	s.sseManager.serveConnection(r, conn)
+}
 
-	// Read request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		writeJSONRPCError(w, r, a2a.ErrParseError(err), nil)
-		return
+// supportsStreaming reports whether both the agent card and the agent engine support streaming.
+func (s *Server) supportsStreaming() bool {
+	card := s.agentCard()
+	if card.Capabilities == nil || !card.Capabilities.SupportsStreaming {
+		return false
+	}
+	if s.config.AgentEngine != nil && !s.config.AgentEngine.GetCapabilities().SupportsStreaming {
+		return false
 	}
+	return true
+}
 
-	// Parse JSON-RPC request
-	var request a2a.JSONRPCRequest
-	if err := json.Unmarshal(body, &request); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrParseError(err), nil)
+// handleSSERequest handles SSE requests.
+func (s *Server) handleSSERequest(w http.ResponseWriter, r *http.Request) {
+	request, ok := readJSONRPCRequest(w, r, s.maxInputBytes())
+	if !ok {
 		return
 	}
 
-	// Validate JSON-RPC version
-	if request.JSONRPC != "2.0" {
-		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Invalid JSON-RPC version"), request.ID)
+	// Streaming methods always produce a response, so notifications are not allowed
+	if request.IsNotification() {
+		writeJSONRPCError(w, r, a2a.ErrInvalidRequest("Method cannot be sent as a notification: "+request.Method), nil)
 		return
 	}
 
 	// Create context with timeout
 	// TODO: Make timeout configurable
-	ctx := r.Context()
+	ctx := s.traceContext(w, r)
+	ctx, span := startRequestSpan(ctx, request)
+	defer span.End()
+	ctx = withJSONRPCID(ctx, request.ID)
+
+	// Disabled methods are treated as unknown
+	if s.disabled[request.Method] {
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+		return
+	}
 
 	// Route request to appropriate handler based on method
 	switch request.Method {
 	case "tasks/sendSubscribe":
-		s.handleTaskSendSubscribe(ctx, w, r, &request)
+		s.handleTaskSendSubscribe(ctx, w, r, request)
 	case "tasks/resubscribe":
-		s.handleTaskResubscribe(ctx, w, r, &request)
+		s.handleTaskResubscribe(ctx, w, r, request)
+	case "tasks/listSubscribe":
+		s.handleTaskListSubscribe(ctx, w, r, request)
 	default:
 		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
 	}
@@ -315,10 +427,16 @@ func (s *Server) handleSSERequest(w http.ResponseWriter, r *http.Request) {
 
 // HandleTaskResubscribe handles the tasks/resubscribe method.
 func (s *Server) handleTaskResubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Reject streaming if the agent does not support it
+	if !s.supportsStreaming() {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("streaming"), request.ID)
+		return
+	}
+
 	// Parse params
 	var params a2a.TaskIdParams
-	if err := json.Unmarshal(request.Params, &params); err != nil {
-		writeJSONRPCError(w, r, a2a.ErrInvalidParams(err.Error()), request.ID)
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
 		return
 	}
 
@@ -328,40 +446,70 @@ func (s *Server) handleTaskResubscribe(ctx context.Context, w http.ResponseWrite
 	// Call TaskManager to resubscribe to the task
 	updateChan, err := s.taskManager.OnResubscribeToTask(ctx, &params)
 	if err != nil {
-		// Convert error to JSON-RPC error
-		var a2aErr *a2a.Error
-		if e, ok := err.(*a2a.Error); ok {
-			a2aErr = e
-		} else {
-			a2aErr = a2a.ErrInternalError(err)
-		}
-		writeJSONRPCError(w, r, a2aErr, request.ID)
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
 		return
 	}
 
-	// Start a goroutine to process updates from the task manager
-	go func() {
-		for update := range updateChan {
-			switch u := update.(type) {
-			case task.StatusUpdate:
-				s.sseManager.SendTaskStatusUpdate(params.TaskID, a2a.TaskStatus{
-					State:     u.State,
-					Timestamp: time.Now(),
-					Message:   u.Message,
-				})
-			case task.ArtifactUpdate:
-				artifact := a2a.Artifact{
-					ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
-					TaskID:    params.TaskID,
-					Timestamp: time.Now(),
-					Part:      u.Part,
-					Metadata:  u.Metadata,
-				}
-				s.sseManager.SendTaskArtifactUpdate(params.TaskID, artifact)
-			}
+	s.streamUpdates(w, r, params.TaskID, lastEventID, updateChan)
+}
+
+// handleTaskListSubscribe handles the tasks/listSubscribe method. Each page of tasks is sent
+// as a taskList event whose ID is the page's cursor, so a client that reconnects with the
+// Last-Event-ID header continues after the last page it received.
+//
+// Pages are written by the request's own goroutine rather than queued through the
+// SSEManager, so a long list is sent at the pace the client reads it.
+func (s *Server) handleTaskListSubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, request *a2a.JSONRPCRequest) {
+	// Reject streaming if the agent does not support it
+	if !s.supportsStreaming() {
+		writeJSONRPCError(w, r, a2a.ErrOperationNotSupported("streaming"), request.ID)
+		return
+	}
+	lister, ok := s.taskManager.(TaskLister)
+	if !ok {
+		writeJSONRPCError(w, r, a2a.ErrMethodNotFound(request.Method), request.ID)
+		return
+	}
+
+	// Parse params, which are optional
+	var params a2a.ListTasksParams
+	if len(request.Params) > 0 {
+		if err := s.unmarshalParams(request.Params, &params); err != nil {
+			writeJSONRPCError(w, r, a2a.ErrInvalidParamsJSON(err), request.ID)
+			return
 		}
-	}()
+	}
 
-	// Handle the SSE connection
-	s.sseManager.HandleSSE(w, r, params.TaskID, lastEventID)
+	// Resume after the last page received, if reconnecting
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		params.Cursor = lastEventID
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Call TaskManager to start listing tasks
+	pages, err := lister.OnListTasksSubscribe(ctx, &params)
+	if err != nil {
+		writeJSONRPCError(w, r, toA2AError(err), request.ID)
+		return
+	}
+
+	setSSEHeaders(w)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	// The task manager closes pages once the client disconnects
+	for page := range pages {
+		data, err := json.Marshal(page)
+		if err != nil {
+			fmt.Printf("Error marshalling SSE event data: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(w, "event: taskList\nid: %s\ndata: %s\n\n", page.NextCursor, data)
+		flusher.Flush()
+	}
 }
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// eventRecorder is an http.ResponseWriter that records SSE output.
+// If block is set, every write after the first blocks until it is closed.
+type eventRecorder struct {
+	header http.Header
+	block  chan struct{}
+	writes int
+	buf    strings.Builder
+	mu     sync.Mutex
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{header: make(http.Header)}
+}
+
+func (r *eventRecorder) Header() http.Header { return r.header }
+
+func (r *eventRecorder) WriteHeader(int) {}
+
+func (r *eventRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	r.writes++
+	blocking := r.block != nil && r.writes > 1
+	r.mu.Unlock()
+	if blocking {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *eventRecorder) Flush() {}
+
+// eventCount returns the number of events written so far.
+func (r *eventRecorder) eventCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Count(r.buf.String(), "event: ")
+}
+
+// waitForConnections waits until the task has the given number of SSE connections.
+func waitForConnections(t *testing.T, sm *SSEManager, taskID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		sm.mu.RLock()
+		count := len(sm.connections[taskID])
+		sm.mu.RUnlock()
+		if count == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d SSE connections for task %s", n, taskID)
+}
+
+func TestSSEManager_SlowClientDoesNotStallOthers(t *testing.T) {
+	const bufferSize = 4
+	const numEvents = 20
+
+	sm := NewSSEManager()
+	sm.SetBufferSize(bufferSize)
+
+	slow := newEventRecorder()
+	slow.block = make(chan struct{})
+	fast := newEventRecorder()
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		sm.HandleSSE(slow, httptest.NewRequest(http.MethodPost, "/sse", nil), "task-1", "")
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sm.HandleSSE(fast, httptest.NewRequest(http.MethodPost, "/sse", nil).WithContext(ctx), "task-1", "")
+	waitForConnections(t, sm, "task-1", 2)
+
+	// Sending must not block on the slow client
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < numEvents; i++ {
+			sm.SendTaskArtifactUpdate("task-1", a2a.Artifact{
+				ID:   fmt.Sprintf("artifact_%03d", i),
+				Part: a2a.TextPart{Type: "text", Text: "chunk"},
+			})
+			// Pace the events so the fast client keeps up
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	select {
+	case <-sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sending events blocked on a slow client")
+	}
+
+	// The fast client receives every event
+	deadline := time.Now().Add(2 * time.Second)
+	for fast.eventCount() < numEvents && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := fast.eventCount(); n != numEvents {
+		t.Errorf("Expected fast client to receive %d events, got %d", numEvents, n)
+	}
+
+	// The slow client is disconnected once its buffer overflows
+	close(slow.block)
+	select {
+	case <-slowDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the slow client to be disconnected")
+	}
+}
+
+func TestSSEManager_ClosedConnectionWritesQueuedEvents(t *testing.T) {
+	sm := NewSSEManager()
+	recorder := newEventRecorder()
+	conn, ok := sm.openConnection(recorder, "task-1", "")
+	if !ok {
+		t.Fatal("Failed to open the connection")
+	}
+	defer sm.removeConnection("task-1", conn.connectionID)
+
+	// Queue events, then close the connection before they are written
+	for i := 0; i < 3; i++ {
+		sm.SendTaskArtifactUpdate("task-1", a2a.Artifact{
+			ID:   fmt.Sprintf("artifact_%d", i),
+			Part: a2a.TextPart{Type: "text", Text: "chunk"},
+		})
+	}
+	sm.closeConnection("task-1", conn.connectionID)
+
+	sm.serveConnection(httptest.NewRequest(http.MethodPost, "/sse", nil), conn)
+	if n := recorder.eventCount(); n != 3 {
+		t.Errorf("Expected the 3 queued events to be written, got %d", n)
+	}
+}
@@ -1,13 +1,24 @@
 package server
 
 import (
+	"cmp"
+	"container/heap"
 	"context"
+	"encoding/base64"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/schema"
 	"github.com/sammcj/go-a2a/pkg/task"
+	"github.com/sammcj/go-a2a/pkg/trace"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // TaskManager defines the interface for task management operations.
@@ -15,8 +26,9 @@ type TaskManager interface {
 	// Handles non-streaming task send/resume.
 	OnSendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error)
 
-	// Handles streaming task send/resume. Returns a channel for updates.
-	OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (<-chan task.YieldUpdate, error)
+	// Handles streaming task send/resume. Returns the ID of the task, known before its first
+	// update, and a channel for updates, starting with the task's current status.
+	OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (string, <-chan task.YieldUpdate, error)
 
 	// Handles task retrieval.
 	OnGetTask(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error)
@@ -30,12 +42,36 @@ type TaskManager interface {
 	// Handles getting push notification config.
 	OnGetTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) (*a2a.PushNotificationConfig, error)
 
+	// Handles deleting push notification config. Deleting a config that does not exist is not an error.
+	OnDeleteTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) error
+
 	// Handles resubscribing to a task stream.
 	OnResubscribeToTask(ctx context.Context, params *a2a.TaskIdParams) (<-chan task.YieldUpdate, error)
 
 	// (Potentially other internal methods for state management)
 }
 
+// SessionCanceller is implemented by task managers that can cancel the tasks of a session.
+// The server handles sessions/cancel with it, and reports the method as not found for
+// task managers that do not implement it.
+type SessionCanceller interface {
+	// Handles cancelling every task in a session that has not finished. Returns the cancelled
+	// tasks, in the order they were created.
+	OnCancelSession(ctx context.Context, params *a2a.SessionIdParams) ([]*a2a.Task, error)
+}
+
+// TaskLister is implemented by task managers that can list their tasks. The server handles
+// tasks/list and tasks/listSubscribe with it, and reports them as not found for task
+// managers that do not implement it.
+type TaskLister interface {
+	// Handles listing tasks a page at a time, in the order they were created.
+	OnListTasks(ctx context.Context, params *a2a.ListTasksParams) (*a2a.ListTasksResult, error)
+
+	// Handles streaming a task list. Returns a channel of pages: the existing tasks, followed by
+	// new tasks as they are created. Every page has a NextCursor. The channel is closed when ctx is done.
+	OnListTasksSubscribe(ctx context.Context, params *a2a.ListTasksParams) (<-chan *a2a.ListTasksResult, error)
+}
+
 // InMemoryTaskManager is a basic implementation of TaskManager that stores tasks in memory.
 type InMemoryTaskManager struct {
 	tasks        map[string]*a2a.Task                   // Map of task ID to task
@@ -43,16 +79,98 @@ type InMemoryTaskManager struct {
 	taskHandler  task.Handler                           // Application-specific task handler
 	pushNotifier *PushNotifier                          // Push notification sender
 	expiry       time.Duration                          // Task expiry duration
+	maxHistory   int                                    // Maximum history length (0 = unbounded)
+	queue        TaskQueue                              // Tasks waiting for a worker to run their handlers
+	workers      int                                    // Number of workers running task handlers (0 = one per task)
+	workersOnce  sync.Once                              // Starts the workers when the first task is queued
+	busy         int                                    // Workers running a task handler
+	reserved     int                                    // Worker slots reserved for tasks not yet queued
+	overflow     TaskOverflowPolicy                     // What to do with tasks sent while all workers are busy
+	queued       map[string]*queuedRun                  // Tasks this task manager queued that no worker has started
+	stopCtx      context.Context                        // Done once the task manager is shut down, stopping its workers
+	stop         context.CancelFunc                     // Cancels stopCtx
+	running      map[string]context.CancelFunc          // Map of task ID to the function stopping its running handler
+	idempotency  map[string]idempotencyRecord           // Map of idempotency key to the task it created
+	idemExpiry   idempotencyQueue                       // Idempotency keys by expiry, used to forget expired keys
+	idemTTL      time.Duration                          // How long idempotency keys are remembered
+	artifacts    ArtifactStore                          // Where artifact content is offloaded (nil = kept inline)
+	maxOutput    int64                                  // Maximum artifact bytes a task may produce (0 = unlimited)
+	taskTimeout  time.Duration                          // Maximum time a task handler may run (0 = unlimited)
+	clock        Clock                                  // Source of timestamps and expiry checks
+	taskSeq      map[string]uint64                      // Map of task ID to its creation order, used for list cursors
+	nextTaskSeq  uint64                                 // Creation order of the next task stored
+	taskOrder    []taskOrderEntry                       // Tasks in creation order, including some deleted since, used for list pages
+	staleOrder   int                                    // Entries in taskOrder for deleted tasks
+	listWatchers map[chan struct{}]struct{}             // Task list streams, signalled when a task is stored
+	recordIDs    bool                                   // Whether tasks record the IDs of the request creating them
+	schemas      map[string]interface{}                 // Map of skill ID to the JSON Schema of its artifacts
+	schemaPolicy ArtifactValidationPolicy               // What to do with artifacts that do not match their schema
 	mu           sync.RWMutex                           // Mutex for thread safety
 }
 
+// ArtifactValidationPolicy controls what happens to a data artifact that does not match the
+// artifact schema of the skill its task was sent to.
+type ArtifactValidationPolicy int
+
+const (
+	// SkipArtifactValidation does not validate artifacts.
+	SkipArtifactValidation ArtifactValidationPolicy = iota
+	// LogInvalidArtifacts logs the schema violations and keeps the artifact.
+	LogInvalidArtifacts
+	// FailInvalidArtifacts fails the task, dropping the artifact.
+	FailInvalidArtifacts
+)
+
+// DefaultIdempotencyTTL is how long idempotency keys are remembered when no TTL is configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyRecord is the task created for an idempotency key.
+type idempotencyRecord struct {
+	taskID  string
+	expires time.Time
+}
+
+// idempotencyExpiry is when an idempotency key was due to expire when it was recorded.
+type idempotencyExpiry struct {
+	key     string
+	expires time.Time
+}
+
+// idempotencyQueue is a min-heap of idempotency keys ordered by expiry. A key recorded again
+// keeps its earlier entries, which are skipped once they no longer match its record.
+type idempotencyQueue []idempotencyExpiry
+
+func (q idempotencyQueue) Len() int           { return len(q) }
+func (q idempotencyQueue) Less(i, j int) bool { return q[i].expires.Before(q[j].expires) }
+func (q idempotencyQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *idempotencyQueue) Push(x interface{}) { *q = append(*q, x.(idempotencyExpiry)) }
+
+func (q *idempotencyQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// TaskOverflowPolicy controls what happens to a task sent while the maximum number of
+// concurrent tasks are already running.
+type TaskOverflowPolicy int
+
+const (
+	// QueueTasks queues the task in the submitted state until a running task finishes.
+	QueueTasks TaskOverflowPolicy = iota
+	// RejectTasks rejects the task with a rate limit error.
+	RejectTasks
+)
+
 // CreateTask creates a new task and returns its ID.
 func (tm *InMemoryTaskManager) CreateTask(ctx context.Context, taskType string, params interface{}) (string, error) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
 	id := generateTaskID()
-	now := time.Now()
+	now := tm.clock.Now()
 
 	task := &a2a.Task{
 		ID: id,
@@ -64,7 +182,8 @@ func (tm *InMemoryTaskManager) CreateTask(ctx context.Context, taskType string,
 		Artifacts: []a2a.Artifact{},
 	}
 
-	tm.tasks[id] = task
+	tm.evictExpiredTasks()
+	tm.storeTask(task)
 	return id, nil
 }
 
@@ -74,11 +193,11 @@ func (tm *InMemoryTaskManager) GetTask(ctx context.Context, id string) (*a2a.Tas
 	defer tm.mu.RUnlock()
 
 	task, exists := tm.tasks[id]
-	if !exists {
+	if !exists || tm.isExpired(task) {
 		return nil, fmt.Errorf("task not found: %s", id)
 	}
 
-	return task, nil
+	return copyTask(task), nil
 }
 
 // UpdateTask updates a task's status.
@@ -93,13 +212,12 @@ func (tm *InMemoryTaskManager) UpdateTask(ctx context.Context, id string, status
 
 	task.Status = a2a.TaskStatus{
 		State:     status,
-		Timestamp: time.Now(),
+		Timestamp: tm.clock.Now(),
 		Message:   message,
 	}
 
 	if message != nil {
-		var msg a2a.Message = *message
-		task.History = append(task.History, msg)
+		tm.appendHistory(task, *message)
 	}
 
 	return nil
@@ -114,7 +232,7 @@ func (tm *InMemoryTaskManager) DeleteTask(ctx context.Context, id string) error
 		return fmt.Errorf("task not found: %s", id)
 	}
 
-	delete(tm.tasks, id)
+	tm.deleteTask(id)
 	return nil
 }
 
@@ -125,22 +243,427 @@ func (tm *InMemoryTaskManager) ListTasks(ctx context.Context) ([]*a2a.Task, erro
 
 	tasks := make([]*a2a.Task, 0, len(tm.tasks))
 	for _, task := range tm.tasks {
-		// Check if task is expired
-		if !time.Now().After(task.Status.Timestamp.Add(tm.expiry)) {
-			tasks = append(tasks, task)
+		if !tm.isExpired(task) {
+			tasks = append(tasks, copyTask(task))
 		}
 	}
 
 	return tasks, nil
 }
 
-// SetTaskExpiry sets the expiry duration for tasks.
+// SetTaskExpiry sets how long a task is kept after it reaches a final state. Expired tasks
+// are no longer returned and are evicted when new tasks are created or EvictExpiredTasks
+// is called. Tasks still in progress never expire. A value of 0 keeps tasks forever.
 func (tm *InMemoryTaskManager) SetTaskExpiry(duration time.Duration) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 	tm.expiry = duration
 }
 
+// SetClock sets the clock used for task timestamps and expiry. It must be called before
+// any tasks are sent.
+func (tm *InMemoryTaskManager) SetClock(clock Clock) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.clock = clock
+}
+
+// EvictExpiredTasks removes tasks that have expired, along with their push notification
+// configs, and returns the number removed.
+func (tm *InMemoryTaskManager) EvictExpiredTasks() int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.evictExpiredTasks()
+}
+
+// evictExpiredTasks removes expired tasks and returns the number removed. The caller must
+// hold tm.mu for writing.
+func (tm *InMemoryTaskManager) evictExpiredTasks() int {
+	if tm.expiry <= 0 {
+		return 0
+	}
+	evicted := 0
+	for id, taskObj := range tm.tasks {
+		if tm.isExpired(taskObj) {
+			tm.deleteTask(id)
+			delete(tm.pushConfigs, id)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// storeTask stores a new task, recording its creation order and waking task list streams.
+// The caller must hold tm.mu for writing.
+func (tm *InMemoryTaskManager) storeTask(t *a2a.Task) {
+	tm.tasks[t.ID] = t
+	tm.nextTaskSeq++
+	tm.taskSeq[t.ID] = tm.nextTaskSeq
+	tm.taskOrder = append(tm.taskOrder, taskOrderEntry{seq: tm.nextTaskSeq, id: t.ID})
+	for watcher := range tm.listWatchers {
+		// A pending signal already covers this task
+		select {
+		case watcher <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// taskOrderEntry is a task in the creation order index of an InMemoryTaskManager.
+type taskOrderEntry struct {
+	seq uint64 // Creation order of the task
+	id  string // ID of the task
+}
+
+// deleteTask removes a task. Its entry in the creation order index is left in place and
+// skipped, until enough tasks have been deleted that compacting the index is worthwhile.
+// The caller must hold tm.mu for writing.
+func (tm *InMemoryTaskManager) deleteTask(id string) {
+	delete(tm.tasks, id)
+	delete(tm.taskSeq, id)
+	tm.staleOrder++
+	if tm.staleOrder*2 > len(tm.taskOrder) {
+		tm.taskOrder = slices.DeleteFunc(tm.taskOrder, func(entry taskOrderEntry) bool {
+			return tm.taskSeq[entry.id] != entry.seq
+		})
+		tm.staleOrder = 0
+	}
+}
+
+// isExpired reports whether a task is in a final state reached longer ago than the task
+// expiry. Tasks still in progress, including those waiting for input, never expire. The
+// caller must hold tm.mu.
+func (tm *InMemoryTaskManager) isExpired(t *a2a.Task) bool {
+	return tm.expiry > 0 && !isActiveState(t.Status.State) && tm.clock.Now().After(t.Status.Timestamp.Add(tm.expiry))
+}
+
+// SetTaskHandler replaces the task handler used for tasks started from now on.
+// Running tasks are not affected.
+func (tm *InMemoryTaskManager) SetTaskHandler(handler task.Handler) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.taskHandler = handler
+}
+
+// SetMaxConcurrentTasks sets the number of workers running task handlers, and so the number
+// of handlers running at once. Tasks sent while every worker is busy are queued or rejected
+// according to policy. A value of 0 removes the limit, starting each task's handler as soon
+// as it is queued. It must be called before any tasks are sent.
+func (tm *InMemoryTaskManager) SetMaxConcurrentTasks(n int, policy TaskOverflowPolicy) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.workers = max(n, 0)
+	tm.overflow = policy
+}
+
+// SetTaskQueue replaces the queue holding tasks waiting for a worker, e.g. with one kept in
+// an external system. It must be called before any tasks are sent.
+func (tm *InMemoryTaskManager) SetTaskQueue(queue TaskQueue) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.queue = queue
+}
+
+// taskSlot is a worker slot reserved for a task that has not been queued yet, so concurrent
+// sends cannot take more slots than there are workers when tasks are rejected. A nil slot
+// reserves nothing.
+type taskSlot struct {
+	tm   *InMemoryTaskManager
+	held bool
+}
+
+// reserveTaskSlot reserves a worker slot for a new task, or returns a rate limit error if new
+// tasks are rejected while all workers are busy. The slot is handed over when the task is
+// queued by runTaskHandler; otherwise the caller must release it.
+func (tm *InMemoryTaskManager) reserveTaskSlot() (*taskSlot, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.workers == 0 || tm.overflow != RejectTasks {
+		return nil, nil
+	}
+	if tm.busy+len(tm.queued)+tm.reserved >= tm.workers {
+		return nil, a2a.ErrRateLimitExceeded()
+	}
+	tm.reserved++
+	return &taskSlot{tm: tm, held: true}, nil
+}
+
+// release releases the slot if it is still held. It is safe to call more than once.
+func (s *taskSlot) release() {
+	if s == nil {
+		return
+	}
+	s.tm.mu.Lock()
+	defer s.tm.mu.Unlock()
+	s.releaseLocked()
+}
+
+// releaseLocked releases the slot if it is still held. The caller must hold tm.mu for writing.
+func (s *taskSlot) releaseLocked() {
+	if s != nil && s.held {
+		s.held = false
+		s.tm.reserved--
+	}
+}
+
+// SetMaxHistory sets the maximum number of messages retained in a task's history.
+// When the limit is exceeded, the oldest non-system messages are dropped, keeping
+// the initial user message. A value of 0 disables truncation.
+func (tm *InMemoryTaskManager) SetMaxHistory(n int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.maxHistory = n
+}
+
+// SetIdempotencyTTL sets how long idempotency keys are remembered. A tasks/send request
+// that repeats a key within the TTL returns the original task instead of running again.
+// A value of 0 uses DefaultIdempotencyTTL.
+func (tm *InMemoryTaskManager) SetIdempotencyTTL(ttl time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	tm.idemTTL = ttl
+}
+
+// SetArtifactStore sets the store that artifact content is offloaded to. When set, each
+// artifact a task produces is written to the store and kept on the task only as a FilePart
+// referencing its URI. A nil store keeps artifacts inline.
+func (tm *InMemoryTaskManager) SetArtifactStore(store ArtifactStore) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.artifacts = store
+}
+
+// SetMaxOutputBytes limits the total size of the artifacts a single task may produce.
+// A task that exceeds the limit is failed and its handler's context is cancelled.
+// A value of 0 removes the limit. It applies to tasks started from now on.
+func (tm *InMemoryTaskManager) SetMaxOutputBytes(n int64) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.maxOutput = n
+}
+
+// SetTaskTimeout sets the maximum time a task's handler may run. A task still running when
+// it passes is failed with a timeout message and its handler's context is cancelled.
+// A value of 0 removes the limit. It applies to tasks started from now on.
+func (tm *InMemoryTaskManager) SetTaskTimeout(timeout time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.taskTimeout = timeout
+}
+
+// SetPushBatchWindow sets how long push notifications for a task are coalesced into a single
+// batched request. Final status updates are sent at once. A value of 0 disables batching.
+func (tm *InMemoryTaskManager) SetPushBatchWindow(window time.Duration) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.pushNotifier != nil {
+		tm.pushNotifier.SetBatchWindow(window)
+	}
+}
+
+// SetRecordRequestIDs sets whether tasks record the IDs of the request that created them in
+// their metadata, under a2a.TaskMetadataRequestID and a2a.TaskMetadataHTTPRequestID, so a
+// stored task can be correlated with the request in audit logs.
+func (tm *InMemoryTaskManager) SetRecordRequestIDs(enabled bool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.recordIDs = enabled
+}
+
+// Stats returns the number of stored tasks in each state, and of tasks with a push
+// notification config, for the admin stats endpoint. Expired tasks are not counted.
+func (tm *InMemoryTaskManager) Stats() TaskStats {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	stats := TaskStats{Tasks: make(map[a2a.TaskState]int)}
+	for _, t := range tm.tasks {
+		if !tm.isExpired(t) {
+			stats.Tasks[t.Status.State]++
+		}
+	}
+	for taskID := range tm.pushConfigs {
+		if t, ok := tm.tasks[taskID]; ok && !tm.isExpired(t) {
+			stats.PushConfigs++
+		}
+	}
+	return stats
+}
+
+// SetArtifactValidation validates the DataPart artifacts of tasks sent to a skill against
+// the skill's artifact schema, keyed by skill ID in schemas, and handles artifacts that do
+// not match it according to policy. Tasks without a skill, or sent to a skill without a
+// schema, are not validated. It applies to tasks started from now on.
+func (tm *InMemoryTaskManager) SetArtifactValidation(schemas map[string]interface{}, policy ArtifactValidationPolicy) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.schemas = schemas
+	tm.schemaPolicy = policy
+}
+
+// requestMetadata returns the metadata of a task created by the request ctx belongs to:
+// the request's IDs if they are recorded, or nil.
+func (tm *InMemoryTaskManager) requestMetadata(ctx context.Context) map[string]interface{} {
+	tm.mu.RLock()
+	enabled := tm.recordIDs
+	tm.mu.RUnlock()
+	if !enabled {
+		return nil
+	}
+
+	metadata := make(map[string]interface{})
+	if id := jsonrpcIDFromContext(ctx); id != nil {
+		metadata[a2a.TaskMetadataRequestID] = id
+	}
+	if id := trace.RequestIDFromContext(ctx); id != "" {
+		metadata[a2a.TaskMetadataHTTPRequestID] = id
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// newArtifact creates an artifact for an update from a task handler, offloading its content
+// to the artifact store if one is set. If the content cannot be stored, it is kept inline.
+func (tm *InMemoryTaskManager) newArtifact(taskID string, u task.ArtifactUpdate) a2a.Artifact {
+	artifact := a2a.Artifact{
+		ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
+		TaskID:    taskID,
+		Timestamp: tm.clock.Now(),
+		Part:      u.Part,
+		Metadata:  u.Metadata,
+	}
+
+	tm.mu.RLock()
+	store := tm.artifacts
+	tm.mu.RUnlock()
+	if store != nil {
+		if err := offloadArtifact(context.Background(), store, &artifact); err != nil {
+			fmt.Printf("Failed to store artifact %s: %v\n", artifact.ID, err)
+		}
+	}
+	return artifact
+}
+
+// storedArtifactUpdate returns the update streamed to subscribers for a stored artifact,
+// so they receive its ID and its content as stored, e.g. offloaded to the artifact store.
+func storedArtifactUpdate(artifact a2a.Artifact) task.ArtifactUpdate {
+	return task.ArtifactUpdate{
+		Part:      artifact.Part,
+		Metadata:  artifact.Metadata,
+		ID:        artifact.ID,
+		Timestamp: artifact.Timestamp,
+	}
+}
+
+// idempotentTask returns the task created by an earlier request with the same idempotency
+// key, or nil if there is none or the key has expired. The caller must hold tm.mu.
+func (tm *InMemoryTaskManager) idempotentTask(key string) *a2a.Task {
+	if key == "" {
+		return nil
+	}
+	record, ok := tm.idempotency[key]
+	if !ok || tm.clock.Now().After(record.expires) {
+		return nil
+	}
+	return tm.tasks[record.taskID]
+}
+
+// recordIdempotencyKey remembers the task created for an idempotency key and forgets
+// expired keys. The caller must hold tm.mu for writing.
+func (tm *InMemoryTaskManager) recordIdempotencyKey(key string, taskID string) {
+	if key == "" {
+		return
+	}
+	now := tm.clock.Now()
+	for len(tm.idemExpiry) > 0 && now.After(tm.idemExpiry[0].expires) {
+		entry := heap.Pop(&tm.idemExpiry).(idempotencyExpiry)
+		if record, ok := tm.idempotency[entry.key]; ok && record.expires.Equal(entry.expires) {
+			delete(tm.idempotency, entry.key)
+		}
+	}
+	expires := now.Add(tm.idemTTL)
+	tm.idempotency[key] = idempotencyRecord{taskID: taskID, expires: expires}
+	heap.Push(&tm.idemExpiry, idempotencyExpiry{key: key, expires: expires})
+}
+
+// appendHistory appends a message to a task's history, truncating it if it exceeds
+// the configured maximum. The caller must hold tm.mu.
+func (tm *InMemoryTaskManager) appendHistory(t *a2a.Task, msg a2a.Message) {
+	t.History = append(t.History, msg)
+
+	var dropped int
+	t.History, dropped = truncateHistory(t.History, tm.maxHistory)
+	if dropped == 0 {
+		return
+	}
+
+	// Record the truncation so clients know the history is incomplete
+	if t.Metadata == nil {
+		t.Metadata = make(map[string]interface{})
+	}
+	total, _ := t.Metadata["historyDropped"].(int)
+	t.Metadata["historyTruncated"] = true
+	t.Metadata["historyDropped"] = total + dropped
+}
+
+// copyTask returns a deep copy of a task, so it can be used after tm.mu is released while
+// the task's handler keeps updating the original, and changes made to it do not reach the
+// stored task. Parts and metadata values are never modified in place, so they are shared.
+// The caller must hold tm.mu.
+func copyTask(t *a2a.Task) *a2a.Task {
+	c := *t
+	if t.SessionID != nil {
+		sessionID := *t.SessionID
+		c.SessionID = &sessionID
+	}
+	if t.Status.Message != nil {
+		msg := copyMessage(*t.Status.Message)
+		c.Status.Message = &msg
+	}
+	if t.History != nil {
+		c.History = make([]a2a.Message, len(t.History))
+		for i, msg := range t.History {
+			c.History[i] = copyMessage(msg)
+		}
+	}
+	c.Artifacts = slices.Clone(t.Artifacts)
+	c.Metadata = maps.Clone(t.Metadata)
+	return &c
+}
+
+// copyMessage returns a copy of a message with its own slice of parts.
+func copyMessage(m a2a.Message) a2a.Message {
+	m.Parts = slices.Clone(m.Parts)
+	return m
+}
+
+// truncateHistory drops the oldest non-system messages until the history fits within
+// max, always keeping the first message (the initial user message).
+// It returns the truncated history and the number of messages dropped.
+func truncateHistory(history []a2a.Message, max int) ([]a2a.Message, int) {
+	if max <= 0 || len(history) <= max {
+		return history, 0
+	}
+
+	excess := len(history) - max
+	dropped := 0
+	kept := make([]a2a.Message, 0, max)
+	for i, msg := range history {
+		if dropped < excess && i > 0 && msg.Role != a2a.RoleSystem {
+			dropped++
+			continue
+		}
+		kept = append(kept, msg)
+	}
+
+	return kept, dropped
+}
+
 // NewInMemoryTaskManager creates a new InMemoryTaskManager.
 func NewInMemoryTaskManager(handler task.Handler) *InMemoryTaskManager {
 	if handler == nil {
@@ -148,131 +671,395 @@ func NewInMemoryTaskManager(handler task.Handler) *InMemoryTaskManager {
 		panic("TaskHandler is required for InMemoryTaskManager")
 	}
 
+	stopCtx, stop := context.WithCancel(context.Background())
 	return &InMemoryTaskManager{
 		tasks:        make(map[string]*a2a.Task),
 		pushConfigs:  make(map[string]*a2a.PushNotificationConfig),
+		queue:        NewMemoryTaskQueue(),
+		queued:       make(map[string]*queuedRun),
+		stopCtx:      stopCtx,
+		stop:         stop,
+		running:      make(map[string]context.CancelFunc),
+		idempotency:  make(map[string]idempotencyRecord),
+		idemTTL:      DefaultIdempotencyTTL,
+		taskSeq:      make(map[string]uint64),
+		listWatchers: make(map[chan struct{}]struct{}),
 		taskHandler:  handler,
 		pushNotifier: NewPushNotifier(10 * time.Second), // Default 10 second timeout
+		clock:        realClock{},
 	}
 }
 
-// OnSendTask implements TaskManager.OnSendTask.
-func (tm *InMemoryTaskManager) OnSendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error) {
-	// Check if this is a resume (taskId provided)
-	if params.TaskID != nil {
+// runTaskHandler queues the task and waits for a worker to start its handler, returning the
+// handler's updates (see startTaskHandler). The task takes over slot once it is queued. If
+// the task is cancelled while queued, the handler is not called and the only update is the
+// cancellation.
+func (tm *InMemoryTaskManager) runTaskHandler(ctx context.Context, taskCtx task.Context, slot *taskSlot) (<-chan task.YieldUpdate, error) {
+	if tm.stopCtx.Err() != nil {
+		slot.release()
+		return nil, fmt.Errorf("task manager is shut down")
+	}
+
+	run := &queuedRun{
+		ctx:       ctx,
+		started:   make(chan startedRun, 1),
+		cancelled: make(chan struct{}),
+	}
+	tm.mu.Lock()
+	tm.queued[taskCtx.TaskID] = run
+	slot.releaseLocked()
+	queue := tm.queue
+	tm.mu.Unlock()
+
+	tm.startWorkers()
+	if err := queue.Enqueue(ctx, newQueuedTask(taskCtx)); err != nil {
+		tm.mu.Lock()
+		delete(tm.queued, taskCtx.TaskID)
+		tm.mu.Unlock()
+		return nil, fmt.Errorf("failed to queue task: %w", err)
+	}
+
+	// The task stays submitted while it waits for a worker
+	select {
+	case started := <-run.started:
+		return started.updates, started.err
+	case <-run.cancelled:
+		// Keep the reason the task was cancelled with, e.g. a shutdown
+		reason, retryable := a2a.TaskStatusReasonUser, false
 		tm.mu.RLock()
-		existingTask, exists := tm.tasks[*params.TaskID]
+		if taskObj, ok := tm.tasks[taskCtx.TaskID]; ok && taskObj.Status.State == a2a.TaskStateCancelled {
+			reason, retryable = taskObj.Status.Reason, taskObj.Status.Retryable
+		}
 		tm.mu.RUnlock()
 
-		if !exists {
-			return nil, a2a.ErrTaskNotFound(*params.TaskID)
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{
+			State:     a2a.TaskStateCancelled,
+			Reason:    reason,
+			Retryable: retryable,
+			Message: &a2a.Message{
+				Role:      a2a.RoleSystem,
+				Timestamp: tm.clock.Now(),
+				Parts: []a2a.Part{
+					a2a.TextPart{
+						Type: "text",
+						Text: "Task cancelled before it started",
+					},
+				},
+			},
 		}
+		close(updates)
+		return updates, nil
+	}
+}
 
-		// TODO: Validate session ID if provided
+// startTaskHandler calls the task handler within a span that ends once the handler's
+// updates are drained, then calls finished. The returned updates start with a working
+// status update. If the task is cancelled while the handler runs, the handler's context is
+// cancelled, aborting calls made with it such as model requests, and its remaining updates
+// are dropped so the task stays cancelled. If the task times out or exceeds the output
+// limit, the handler's context is cancelled and the last update is the failure.
+func (tm *InMemoryTaskManager) startTaskHandler(ctx context.Context, taskCtx task.Context, finished func()) (<-chan task.YieldUpdate, error) {
+	ctx, span := trace.StartSpan(ctx, "a2a.task", attribute.String("a2a.task_id", taskCtx.TaskID))
+	ctx, cancel := context.WithCancel(ctx)
 
-		// Create a task context
-		taskCtx := task.Context{
-			TaskID:      *params.TaskID,
-			UserMessage: params.Message,
-		}
+	tm.mu.RLock()
+	handler := tm.taskHandler
+	maxOutput := tm.maxOutput
+	timeout := tm.taskTimeout
+	artifactSchema, schemaPolicy := tm.schemas[taskCtx.SkillID], tm.schemaPolicy
+	tm.mu.RUnlock()
+	if artifactSchema == nil {
+		schemaPolicy = SkipArtifactValidation
+	}
 
-		// Start a goroutine to handle the task
+	// The client may ask for a shorter timeout than the server's, but not a longer one
+	if taskCtx.Timeout > 0 && (timeout == 0 || taskCtx.Timeout < timeout) {
+		timeout = taskCtx.Timeout
+	}
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		timedOut = timer.C
 		go func() {
-			// Call the task handler
-			handlerUpdateChan, err := tm.taskHandler(ctx, taskCtx)
-			if err != nil {
-				// Update task status to failed
-				tm.mu.Lock()
-				existingTask.Status = a2a.TaskStatus{
-					State:     a2a.TaskStateFailed,
-					Timestamp: time.Now(),
-					Message: &a2a.Message{
-						Role:      a2a.RoleSystem,
-						Timestamp: time.Now(),
-						Parts: []a2a.Part{
-							a2a.TextPart{
-								Type: "text",
-								Text: fmt.Sprintf("Task failed: %v", err),
-							},
-						},
-					},
-				}
+			<-ctx.Done()
+			timer.Stop()
+		}()
+		if deadline := time.Now().Add(timeout); !taskCtx.HasDeadline() || deadline.Before(taskCtx.Deadline) {
+			taskCtx.Deadline = deadline
+		}
+	}
 
-				// Get push notification config (if any)
-				config, hasPushConfig := tm.pushConfigs[*params.TaskID]
-				tm.mu.Unlock()
+	// cancelTask stops the handler, after which its updates are no longer forwarded
+	var stopped atomic.Bool
+	tm.mu.Lock()
+	tm.running[taskCtx.TaskID] = func() {
+		stopped.Store(true)
+		cancel()
+	}
+	tm.mu.Unlock()
+	stopRunning := func() {
+		tm.mu.Lock()
+		delete(tm.running, taskCtx.TaskID)
+		tm.mu.Unlock()
+	}
 
-				// Send push notification if configured
-				if hasPushConfig && tm.pushNotifier != nil {
-					if err := tm.pushNotifier.SendStatusUpdate(context.Background(), existingTask, config); err != nil {
-						// Just log the error for now
-						fmt.Printf("Failed to send push notification for task %s: %v\n", *params.TaskID, err)
-					}
+	updates, err := handler(ctx, taskCtx)
+	if err != nil {
+		cancel()
+		stopRunning()
+		finished()
+		trace.RecordError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	tracedUpdates := make(chan task.YieldUpdate)
+	go func() {
+		defer finished()
+		defer span.End()
+		defer stopRunning()
+		defer cancel()
+		defer close(tracedUpdates)
+		tracedUpdates <- task.StatusUpdate{State: a2a.TaskStateWorking}
+
+		// fail stops the handler and ends the task with a failure, instead of forwarding
+		// any more of the handler's updates
+		fail := func(text string, reason a2a.TaskStatusReason, retryable bool) {
+			cancel()
+			span.SetAttributes(attribute.String("a2a.task_state", string(a2a.TaskStateFailed)))
+			tracedUpdates <- task.StatusUpdate{
+				State:     a2a.TaskStateFailed,
+				Reason:    reason,
+				Retryable: retryable,
+				Message: &a2a.Message{
+					Role:      a2a.RoleSystem,
+					Timestamp: tm.clock.Now(),
+					Parts:     []a2a.Part{a2a.TextPart{Type: "text", Text: text}},
+				},
+			}
+
+			// Drain the handler's remaining updates so it can exit, without waiting for
+			// a handler that ignores its context
+			go func() {
+				for range updates {
 				}
+			}()
+		}
 
+		var outputBytes int64
+		for {
+			var update task.YieldUpdate
+			select {
+			case u, ok := <-updates:
+				if !ok {
+					return
+				}
+				update = u
+			case <-timedOut:
+				// A later attempt may finish in time
+				fail(fmt.Sprintf("Task timed out after %s", timeout), a2a.TaskStatusReasonTimeout, true)
 				return
 			}
-
-			// Process updates from the handler
-			for update := range handlerUpdateChan {
-				// Update task state in memory and send push notifications if configured
-				switch u := update.(type) {
-				case task.StatusUpdate:
-					tm.mu.Lock()
-					existingTask.Status = a2a.TaskStatus{
-						State:     u.State,
-						Timestamp: time.Now(),
-						Message:   u.Message,
-					}
-					if u.Message != nil {
-						existingTask.History = append(existingTask.History, *u.Message)
+			if stopped.Load() {
+				// The task was cancelled; the handler's last updates, e.g. a failure caused
+				// by its cancelled context, must not replace the cancelled status
+				span.SetAttributes(attribute.String("a2a.task_state", string(a2a.TaskStateCancelled)))
+				go func() {
+					for range updates {
 					}
+				}()
+				return
+			}
 
-					// Get push notification config (if any)
-					config, hasPushConfig := tm.pushConfigs[*params.TaskID]
-					tm.mu.Unlock()
-
-					// Send push notification if configured
-					if hasPushConfig && tm.pushNotifier != nil {
-						if err := tm.pushNotifier.SendStatusUpdate(context.Background(), existingTask, config); err != nil {
-							// Just log the error for now
-							fmt.Printf("Failed to send push notification for task %s: %v\n", *params.TaskID, err)
+			switch u := update.(type) {
+			case task.StatusUpdate:
+				span.SetAttributes(attribute.String("a2a.task_state", string(u.State)))
+			case task.ArtifactUpdate:
+				if u.Part != nil {
+					outputBytes += int64(u.Part.ContentSize())
+				}
+				if maxOutput > 0 && outputBytes > maxOutput {
+					fail(fmt.Sprintf("Task output exceeds the maximum of %d bytes", maxOutput), a2a.TaskStatusReasonError, false)
+					return
+				}
+				if schemaPolicy != SkipArtifactValidation {
+					if violations := artifactViolations(artifactSchema, u.Part); violations != "" {
+						if schemaPolicy == FailInvalidArtifacts {
+							fail(fmt.Sprintf("Task produced an artifact that does not match the artifact schema of skill %s: %s", taskCtx.SkillID, violations), a2a.TaskStatusReasonError, false)
+							return
 						}
+						fmt.Printf("Task %s produced an artifact that does not match the artifact schema of skill %s: %s\n", taskCtx.TaskID, taskCtx.SkillID, violations)
 					}
+				}
+			}
+			tracedUpdates <- update
+		}
+	}()
 
-				case task.ArtifactUpdate:
-					artifact := a2a.Artifact{
-						ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
-						TaskID:    *params.TaskID,
-						Timestamp: time.Now(),
-						Part:      u.Part,
-						Metadata:  u.Metadata,
-					}
+	return tracedUpdates, nil
+}
 
-					tm.mu.Lock()
-					existingTask.Artifacts = append(existingTask.Artifacts, artifact)
+// artifactViolations validates the data of a DataPart artifact against an artifact schema
+// and describes the violations, or returns "" if the data is valid or the part is not a
+// DataPart.
+func artifactViolations(artifactSchema interface{}, part a2a.Part) string {
+	dataPart, ok := part.(a2a.DataPart)
+	if !ok {
+		return ""
+	}
 
-					// Get push notification config (if any)
-					config, hasPushConfig := tm.pushConfigs[*params.TaskID]
-					tm.mu.Unlock()
+	fields, err := schema.Validate(artifactSchema, dataPart.Data)
+	if err != nil {
+		return err.Error()
+	}
+	violations := make([]string, len(fields))
+	for i, field := range fields {
+		violations[i] = field.Error()
+	}
+	return strings.Join(violations, "; ")
+}
+
+// statusFromUpdate returns the task status set by a status update at the given time.
+// Failures without a reason are reported as errors.
+func statusFromUpdate(u task.StatusUpdate, now time.Time) a2a.TaskStatus {
+	status := a2a.TaskStatus{
+		State:     u.State,
+		Timestamp: now,
+		Message:   u.Message,
+		Reason:    u.Reason,
+		Retryable: u.Retryable,
+	}
+	if status.State == a2a.TaskStateFailed && status.Reason == "" {
+		status.Reason = a2a.TaskStatusReasonError
+	}
+	return status
+}
+
+// updateFromStatus returns a status update replaying a task's current status.
+func updateFromStatus(status a2a.TaskStatus) task.StatusUpdate {
+	return task.StatusUpdate{
+		State:     status.State,
+		Message:   status.Message,
+		Reason:    status.Reason,
+		Retryable: status.Retryable,
+	}
+}
+
+// newTaskContext creates the context passed to the task handler for a task send request.
+// The deadline is taken from ctx, which should be the originating request's context.
+func newTaskContext(ctx context.Context, taskID string, params *a2a.TaskSendParams) task.Context {
+	taskCtx := task.Context{
+		TaskID:       taskID,
+		UserMessage:  params.Message,
+		Metadata:     params.Metadata,
+		TraceHeaders: trace.HeadersFromContext(ctx),
+		OutputModes:  params.AcceptedOutputModes,
+		Identity:     task.IdentityFromContext(ctx),
+	}
+	if params.SessionID != nil {
+		taskCtx.SessionID = *params.SessionID
+	}
+	if params.SkillID != nil {
+		taskCtx.SkillID = *params.SkillID
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		taskCtx.Deadline = deadline
+	}
+	if params.Timeout != nil && *params.Timeout > 0 {
+		taskCtx.Timeout = time.Duration(*params.Timeout * float64(time.Second))
+	}
+	return taskCtx
+}
+
+// OnSendTask implements TaskManager.OnSendTask.
+func (tm *InMemoryTaskManager) OnSendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error) {
+	// The task outlives the request, so detach it from the request's cancellation
+	// while keeping its values (e.g., trace headers). The request's deadline is
+	// still passed to the handler in the task context.
+	reqCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+
+	mode, err := taskSendMode(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// A retried request returns the task created by the original one, and a
+	// create-if-absent request the session's active task
+	var idemKey string
+	if params.IdempotencyKey != nil {
+		idemKey = *params.IdempotencyKey
+	}
+	tm.mu.RLock()
+	original := tm.idempotentTask(idemKey)
+	if original == nil && mode == a2a.TaskSendModeCreateIfAbsent {
+		original = tm.activeSessionTask(*params.SessionID)
+	}
+	if original != nil {
+		original = copyTask(original)
+	}
+	tm.mu.RUnlock()
+	if original != nil {
+		return original, nil
+	}
+
+	// Reject the task if all slots are taken and the overflow policy says so. The slot is
+	// released on return unless the task is handed to runTaskHandler.
+	slot, err := tm.reserveTaskSlot()
+	if err != nil {
+		return nil, err
+	}
+	handedOver := false
+	defer func() {
+		if !handedOver {
+			slot.release()
+		}
+	}()
+
+	// Check if this is a resume (taskId provided)
+	if mode == a2a.TaskSendModeResume {
+		tm.mu.Lock()
+		if original := tm.idempotentTask(idemKey); original != nil {
+			original = copyTask(original)
+			tm.mu.Unlock()
+			return original, nil
+		}
+		existingTask, exists := tm.tasks[*params.TaskID]
+		var result *a2a.Task
+		if exists {
+			tm.recordIdempotencyKey(idemKey, *params.TaskID)
+			result = copyTask(existingTask)
+		}
+		tm.mu.Unlock()
+
+		if !exists {
+			return nil, a2a.ErrTaskNotFound(*params.TaskID)
+		}
+
+		// TODO: Validate session ID if provided
+
+		// Create a task context
+		taskCtx := newTaskContext(reqCtx, *params.TaskID, params)
 
-					// Send push notification if configured
-					if hasPushConfig && tm.pushNotifier != nil {
-						if err := tm.pushNotifier.SendArtifactUpdate(context.Background(), existingTask, artifact, config); err != nil {
-							// Just log the error for now
-							fmt.Printf("Failed to send push notification for artifact %s: %v\n", artifact.ID, err)
-						}
-					}
-				}
+		// Start a goroutine to handle the task, which takes over the slot
+		handedOver = true
+		go func() {
+			// Call the task handler
+			handlerUpdateChan, err := tm.runTaskHandler(ctx, taskCtx, slot)
+			if err != nil {
+				tm.failTask(*params.TaskID, existingTask, err)
+				return
 			}
+			tm.processTaskUpdates(*params.TaskID, existingTask, handlerUpdateChan)
 		}()
 
-		return existingTask, nil
+		return result, nil
 	}
 
 	// Create a new task
 	taskID := generateTaskID()
-	now := time.Now()
+	now := tm.clock.Now()
 
 	newTask := &a2a.Task{
 		ID:        taskID,
@@ -283,165 +1070,203 @@ func (tm *InMemoryTaskManager) OnSendTask(ctx context.Context, params *a2a.TaskS
 		},
 		History:   []a2a.Message{params.Message}, // Start with the user message
 		Artifacts: []a2a.Artifact{},              // Empty initially
+		Metadata:  tm.requestMetadata(ctx),
 	}
 
-	// Store the task
+	// Store the task, unless a concurrent request with the same idempotency key (or, for
+	// create-if-absent, in the same session) got there first
 	tm.mu.Lock()
-	tm.tasks[taskID] = newTask
+	if original := tm.idempotentTask(idemKey); original != nil {
+		original = copyTask(original)
+		tm.mu.Unlock()
+		return original, nil
+	}
+	if mode == a2a.TaskSendModeCreateIfAbsent {
+		if active := tm.activeSessionTask(*params.SessionID); active != nil {
+			tm.recordIdempotencyKey(idemKey, active.ID)
+			active = copyTask(active)
+			tm.mu.Unlock()
+			return active, nil
+		}
+	}
+	tm.evictExpiredTasks()
+	tm.storeTask(newTask)
+	tm.recordIdempotencyKey(idemKey, taskID)
+	result := copyTask(newTask)
 	tm.mu.Unlock()
 
 	// Create a task context
-	taskCtx := task.Context{
-		TaskID:      taskID,
-		UserMessage: params.Message,
-	}
+	taskCtx := newTaskContext(reqCtx, taskID, params)
 
-	// Start a goroutine to handle the task
+	// Start a goroutine to handle the task, which takes over the slot
+	handedOver = true
 	go func() {
-		// Update task status to working
-		tm.mu.Lock()
-		newTask.Status = a2a.TaskStatus{
-			State:     a2a.TaskStateWorking,
-			Timestamp: time.Now(),
-		}
-		tm.mu.Unlock()
-
 		// Call the task handler
-		handlerUpdateChan, err := tm.taskHandler(ctx, taskCtx)
+		handlerUpdateChan, err := tm.runTaskHandler(ctx, taskCtx, slot)
 		if err != nil {
-			// Update task status to failed
-			tm.mu.Lock()
-			newTask.Status = a2a.TaskStatus{
-				State:     a2a.TaskStateFailed,
-				Timestamp: time.Now(),
-				Message: &a2a.Message{
-					Role:      a2a.RoleSystem,
-					Timestamp: time.Now(),
-					Parts: []a2a.Part{
-						a2a.TextPart{
-							Type: "text",
-							Text: fmt.Sprintf("Task failed: %v", err),
-						},
-					},
+			tm.failTask(taskID, newTask, err)
+			return
+		}
+		tm.processTaskUpdates(taskID, newTask, handlerUpdateChan)
+	}()
+
+	return result, nil
+}
+
+// failTask sets a task's status to failed because its handler could not be started, and
+// sends a push notification if one is configured.
+func (tm *InMemoryTaskManager) failTask(taskID string, taskObj *a2a.Task, err error) {
+	tm.mu.Lock()
+	taskObj.Status = a2a.TaskStatus{
+		State:     a2a.TaskStateFailed,
+		Timestamp: tm.clock.Now(),
+		Reason:    a2a.TaskStatusReasonError,
+		Message: &a2a.Message{
+			Role:      a2a.RoleSystem,
+			Timestamp: tm.clock.Now(),
+			Parts: []a2a.Part{
+				a2a.TextPart{
+					Type: "text",
+					Text: fmt.Sprintf("Task failed: %v", err),
 				},
+			},
+		},
+	}
+
+	// Get push notification config (if any)
+	config, hasPushConfig := tm.pushConfigs[taskID]
+	snapshot := copyTask(taskObj)
+	tm.mu.Unlock()
+
+	// Send push notification if configured
+	if hasPushConfig && tm.pushNotifier != nil {
+		if err := tm.pushNotifier.SendStatusUpdate(context.Background(), snapshot, config); err != nil {
+			// Just log the error for now
+			fmt.Printf("Failed to send push notification for task %s: %v\n", taskID, err)
+		}
+	}
+}
+
+// processTaskUpdates applies a task handler's updates to the task until the handler is
+// done, sending push notifications if they are configured.
+func (tm *InMemoryTaskManager) processTaskUpdates(taskID string, taskObj *a2a.Task, updates <-chan task.YieldUpdate) {
+	finished := false // Whether the handler has put the task in a final state
+	for update := range updates {
+		// Update task state in memory and send push notifications if configured
+		switch u := update.(type) {
+		case task.StatusUpdate:
+			tm.mu.Lock()
+			if finished || (taskObj.Status.State == a2a.TaskStateCancelled && u.State != a2a.TaskStateCancelled) {
+				// Drop updates sent after the handler finished the task, or before the task was cancelled
+				tm.mu.Unlock()
+				continue
+			}
+			finished = !isActiveState(u.State)
+			taskObj.Status = statusFromUpdate(u, tm.clock.Now())
+			if u.Message != nil {
+				tm.appendHistory(taskObj, *u.Message)
 			}
 
 			// Get push notification config (if any)
 			config, hasPushConfig := tm.pushConfigs[taskID]
+			snapshot := copyTask(taskObj)
 			tm.mu.Unlock()
 
 			// Send push notification if configured
 			if hasPushConfig && tm.pushNotifier != nil {
-				if err := tm.pushNotifier.SendStatusUpdate(context.Background(), newTask, config); err != nil {
+				if err := tm.pushNotifier.SendStatusUpdate(context.Background(), snapshot, config); err != nil {
 					// Just log the error for now
 					fmt.Printf("Failed to send push notification for task %s: %v\n", taskID, err)
 				}
 			}
 
-			return
-		}
-
-		// Process updates from the handler
-		for update := range handlerUpdateChan {
-			// Update task state in memory and send push notifications if configured
-			switch u := update.(type) {
-			case task.StatusUpdate:
-				tm.mu.Lock()
-				newTask.Status = a2a.TaskStatus{
-					State:     u.State,
-					Timestamp: time.Now(),
-					Message:   u.Message,
-				}
-				if u.Message != nil {
-					newTask.History = append(newTask.History, *u.Message)
-				}
-
-				// Get push notification config (if any)
-				config, hasPushConfig := tm.pushConfigs[taskID]
-				tm.mu.Unlock()
-
-				// Send push notification if configured
-				if hasPushConfig && tm.pushNotifier != nil {
-					if err := tm.pushNotifier.SendStatusUpdate(context.Background(), newTask, config); err != nil {
-						// Just log the error for now
-						fmt.Printf("Failed to send push notification for task %s: %v\n", taskID, err)
-					}
-				}
-
-			case task.ArtifactUpdate:
-				artifact := a2a.Artifact{
-					ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
-					TaskID:    taskID,
-					Timestamp: time.Now(),
-					Part:      u.Part,
-					Metadata:  u.Metadata,
-				}
+		case task.ArtifactUpdate:
+			artifact := tm.newArtifact(taskID, u)
 
-				tm.mu.Lock()
-				newTask.Artifacts = append(newTask.Artifacts, artifact)
+			tm.mu.Lock()
+			taskObj.Artifacts = append(taskObj.Artifacts, artifact)
 
-				// Get push notification config (if any)
-				config, hasPushConfig := tm.pushConfigs[taskID]
-				tm.mu.Unlock()
+			// Get push notification config (if any)
+			config, hasPushConfig := tm.pushConfigs[taskID]
+			snapshot := copyTask(taskObj)
+			tm.mu.Unlock()
 
-				// Send push notification if configured
-				if hasPushConfig && tm.pushNotifier != nil {
-					if err := tm.pushNotifier.SendArtifactUpdate(context.Background(), newTask, artifact, config); err != nil {
-						// Just log the error for now
-						fmt.Printf("Failed to send push notification for artifact %s: %v\n", artifact.ID, err)
-					}
+			// Send push notification if configured
+			if hasPushConfig && tm.pushNotifier != nil {
+				if err := tm.pushNotifier.SendArtifactUpdate(context.Background(), snapshot, artifact, config); err != nil {
+					// Just log the error for now
+					fmt.Printf("Failed to send push notification for artifact %s: %v\n", artifact.ID, err)
 				}
 			}
 		}
-	}()
-
-	return newTask, nil
+	}
 }
 
 // OnSendTaskSubscribe implements TaskManager.OnSendTaskSubscribe.
-func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (<-chan task.YieldUpdate, error) {
+func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (string, <-chan task.YieldUpdate, error) {
+	// Reject the task if all slots are taken and the overflow policy says so. The slot is
+	// released on return unless the task is handed to runTaskHandler.
+	slot, err := tm.reserveTaskSlot()
+	if err != nil {
+		return "", nil, err
+	}
+	handedOver := false
+	defer func() {
+		if !handedOver {
+			slot.release()
+		}
+	}()
+
+	mode, err := taskSendMode(params)
+	if err != nil {
+		return "", nil, err
+	}
+	if mode == a2a.TaskSendModeCreateIfAbsent {
+		// There is no stream to return for an existing task; tasks/resubscribe follows one
+		return "", nil, a2a.ErrValidation(a2a.FieldError{Field: "mode", Reason: "create-if-absent is not supported when streaming"})
+	}
+
 	// Create a channel for updates
 	updateChan := make(chan task.YieldUpdate)
 
 	// Check if this is a resume (taskId provided)
-	if params.TaskID != nil {
+	if mode == a2a.TaskSendModeResume {
 		tm.mu.RLock()
 		taskObj, exists := tm.tasks[*params.TaskID]
 		tm.mu.RUnlock()
 
 		if !exists {
-			return nil, a2a.ErrTaskNotFound(*params.TaskID)
+			return "", nil, a2a.ErrTaskNotFound(*params.TaskID)
 		}
 
 		// TODO: Validate session ID if provided
 
-		// Start a goroutine to handle the task
+		// Start a goroutine to handle the task, which takes over the slot
+		handedOver = true
 		go func() {
 			defer close(updateChan)
 
 			// Send the current status as the first update
-			updateChan <- task.StatusUpdate{
-				State:   taskObj.Status.State,
-				Message: taskObj.Status.Message,
-			}
+			tm.mu.RLock()
+			status := taskObj.Status
+			tm.mu.RUnlock()
+			updateChan <- updateFromStatus(status)
 
 			// Create a task context
-			taskCtx := task.Context{
-				TaskID:      *params.TaskID,
-				UserMessage: params.Message,
-			}
+			taskCtx := newTaskContext(ctx, *params.TaskID, params)
 
 			// Call the task handler
-			handlerUpdateChan, err := tm.taskHandler(ctx, taskCtx)
+			handlerUpdateChan, err := tm.runTaskHandler(ctx, taskCtx, slot)
 			if err != nil {
 				// Update task status to failed
 				tm.mu.Lock()
 				taskObj.Status = a2a.TaskStatus{
 					State:     a2a.TaskStateFailed,
-					Timestamp: time.Now(),
+					Timestamp: tm.clock.Now(),
+					Reason:    a2a.TaskStatusReasonError,
 					Message: &a2a.Message{
 						Role:      a2a.RoleSystem,
-						Timestamp: time.Now(),
+						Timestamp: tm.clock.Now(),
 						Parts: []a2a.Part{
 							a2a.TextPart{
 								Type: "text",
@@ -454,10 +1279,11 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 
 				// Send a failed status update
 				updateChan <- task.StatusUpdate{
-					State: a2a.TaskStateFailed,
+					State:  a2a.TaskStateFailed,
+					Reason: a2a.TaskStatusReasonError,
 					Message: &a2a.Message{
 						Role:      a2a.RoleSystem,
-						Timestamp: time.Now(),
+						Timestamp: tm.clock.Now(),
 						Parts: []a2a.Part{
 							a2a.TextPart{
 								Type: "text",
@@ -470,60 +1296,56 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 			}
 
 			// Forward updates from the handler
+			finished := false // Whether the handler has put the task in a final state
 			for update := range handlerUpdateChan {
 				// Update task state in memory and send push notifications if configured
 				switch u := update.(type) {
 				case task.StatusUpdate:
 					tm.mu.Lock()
-					taskObj.Status = a2a.TaskStatus{
-						State:     u.State,
-						Timestamp: time.Now(),
-						Message:   u.Message,
+					if finished || (taskObj.Status.State == a2a.TaskStateCancelled && u.State != a2a.TaskStateCancelled) {
+						// Drop updates sent after the handler finished the task, or before the task was cancelled
+						tm.mu.Unlock()
+						continue
 					}
+					finished = !isActiveState(u.State)
+					taskObj.Status = statusFromUpdate(u, tm.clock.Now())
 					if u.Message != nil {
-						taskObj.History = append(taskObj.History, *u.Message)
+						tm.appendHistory(taskObj, *u.Message)
 					}
 
 					// Get push notification config (if any)
 					config, hasPushConfig := tm.pushConfigs[*params.TaskID]
+					snapshot := copyTask(taskObj)
 					tm.mu.Unlock()
 
 					// Send push notification if configured
+					// Sent synchronously so notifications for the task keep their order
 					if hasPushConfig && tm.pushNotifier != nil {
-						// Send in a goroutine to avoid blocking
-						go func() {
-							if err := tm.pushNotifier.SendStatusUpdate(context.Background(), taskObj, config); err != nil {
-								// Just log the error for now
-								fmt.Printf("Failed to send push notification for task %s: %v\n", *params.TaskID, err)
-							}
-						}()
+						if err := tm.pushNotifier.SendStatusUpdate(context.Background(), snapshot, config); err != nil {
+							// Just log the error for now
+							fmt.Printf("Failed to send push notification for task %s: %v\n", *params.TaskID, err)
+						}
 					}
 
 				case task.ArtifactUpdate:
-					artifact := a2a.Artifact{
-						ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
-						TaskID:    *params.TaskID,
-						Timestamp: time.Now(),
-						Part:      u.Part,
-						Metadata:  u.Metadata,
-					}
+					artifact := tm.newArtifact(*params.TaskID, u)
+					update = storedArtifactUpdate(artifact)
 
 					tm.mu.Lock()
 					taskObj.Artifacts = append(taskObj.Artifacts, artifact)
 
 					// Get push notification config (if any)
 					config, hasPushConfig := tm.pushConfigs[*params.TaskID]
+					snapshot := copyTask(taskObj)
 					tm.mu.Unlock()
 
 					// Send push notification if configured
+					// Sent synchronously so notifications for the task keep their order
 					if hasPushConfig && tm.pushNotifier != nil {
-						// Send in a goroutine to avoid blocking
-						go func(a a2a.Artifact) {
-							if err := tm.pushNotifier.SendArtifactUpdate(context.Background(), taskObj, a, config); err != nil {
-								// Just log the error for now
-								fmt.Printf("Failed to send push notification for artifact %s: %v\n", a.ID, err)
-							}
-						}(artifact)
+						if err := tm.pushNotifier.SendArtifactUpdate(context.Background(), snapshot, artifact, config); err != nil {
+							// Just log the error for now
+							fmt.Printf("Failed to send push notification for artifact %s: %v\n", artifact.ID, err)
+						}
 					}
 				}
 
@@ -532,12 +1354,12 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 			}
 		}()
 
-		return updateChan, nil
+		return *params.TaskID, updateChan, nil
 	}
 
 	// Create a new task
 	taskID := generateTaskID()
-	now := time.Now()
+	now := tm.clock.Now()
 
 	taskObj := &a2a.Task{
 		ID:        taskID,
@@ -548,14 +1370,17 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 		},
 		History:   []a2a.Message{params.Message}, // Start with the user message
 		Artifacts: []a2a.Artifact{},              // Empty initially
+		Metadata:  tm.requestMetadata(ctx),
 	}
 
 	// Store the task
 	tm.mu.Lock()
-	tm.tasks[taskID] = taskObj
+	tm.evictExpiredTasks()
+	tm.storeTask(taskObj)
 	tm.mu.Unlock()
 
-	// Start a goroutine to handle the task
+	// Start a goroutine to handle the task, which takes over the slot
+	handedOver = true
 	go func() {
 		defer close(updateChan)
 
@@ -564,36 +1389,21 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 			State: a2a.TaskStateSubmitted,
 		}
 
-		// Update task status to working
-		tm.mu.Lock()
-		taskObj.Status = a2a.TaskStatus{
-			State:     a2a.TaskStateWorking,
-			Timestamp: time.Now(),
-		}
-		tm.mu.Unlock()
-
-		// Send a working status update
-		updateChan <- task.StatusUpdate{
-			State: a2a.TaskStateWorking,
-		}
-
 		// Create a task context
-		taskCtx := task.Context{
-			TaskID:      taskID,
-			UserMessage: params.Message,
-		}
+		taskCtx := newTaskContext(ctx, taskID, params)
 
 		// Call the task handler
-		handlerUpdateChan, err := tm.taskHandler(ctx, taskCtx)
+		handlerUpdateChan, err := tm.runTaskHandler(ctx, taskCtx, slot)
 		if err != nil {
 			// Update task status to failed
 			tm.mu.Lock()
 			taskObj.Status = a2a.TaskStatus{
 				State:     a2a.TaskStateFailed,
-				Timestamp: time.Now(),
+				Timestamp: tm.clock.Now(),
+				Reason:    a2a.TaskStatusReasonError,
 				Message: &a2a.Message{
 					Role:      a2a.RoleSystem,
-					Timestamp: time.Now(),
+					Timestamp: tm.clock.Now(),
 					Parts: []a2a.Part{
 						a2a.TextPart{
 							Type: "text",
@@ -606,10 +1416,11 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 
 			// Send a failed status update
 			updateChan <- task.StatusUpdate{
-				State: a2a.TaskStateFailed,
+				State:  a2a.TaskStateFailed,
+				Reason: a2a.TaskStatusReasonError,
 				Message: &a2a.Message{
 					Role:      a2a.RoleSystem,
-					Timestamp: time.Now(),
+					Timestamp: tm.clock.Now(),
 					Parts: []a2a.Part{
 						a2a.TextPart{
 							Type: "text",
@@ -622,28 +1433,26 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 		}
 
 		// Forward updates from the handler
+		finished := false // Whether the handler has put the task in a final state
 		for update := range handlerUpdateChan {
 			// Update task state in memory
 			switch u := update.(type) {
 			case task.StatusUpdate:
 				tm.mu.Lock()
-				taskObj.Status = a2a.TaskStatus{
-					State:     u.State,
-					Timestamp: time.Now(),
-					Message:   u.Message,
+				if finished || (taskObj.Status.State == a2a.TaskStateCancelled && u.State != a2a.TaskStateCancelled) {
+					// Drop updates sent after the handler finished the task, or before the task was cancelled
+					tm.mu.Unlock()
+					continue
 				}
+				finished = !isActiveState(u.State)
+				taskObj.Status = statusFromUpdate(u, tm.clock.Now())
 				if u.Message != nil {
-					taskObj.History = append(taskObj.History, *u.Message)
+					tm.appendHistory(taskObj, *u.Message)
 				}
 				tm.mu.Unlock()
 			case task.ArtifactUpdate:
-				artifact := a2a.Artifact{
-					ID:        fmt.Sprintf("artifact_%d", time.Now().UnixNano()),
-					TaskID:    taskID,
-					Timestamp: time.Now(),
-					Part:      u.Part,
-					Metadata:  u.Metadata,
-				}
+				artifact := tm.newArtifact(taskID, u)
+				update = storedArtifactUpdate(artifact)
 				tm.mu.Lock()
 				taskObj.Artifacts = append(taskObj.Artifacts, artifact)
 				tm.mu.Unlock()
@@ -654,7 +1463,7 @@ func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, params *
 		}
 	}()
 
-	return updateChan, nil
+	return taskID, updateChan, nil
 }
 
 // OnGetTask implements TaskManager.OnGetTask.
@@ -664,11 +1473,148 @@ func (tm *InMemoryTaskManager) OnGetTask(ctx context.Context, params *a2a.TaskQu
 	defer tm.mu.RUnlock()
 
 	taskObj, exists := tm.tasks[params.TaskID]
-	if !exists {
+	if !exists || tm.isExpired(taskObj) {
 		return nil, a2a.ErrTaskNotFound(params.TaskID)
 	}
 
-	return taskObj, nil
+	// Return a copy, as the task's handler may still be updating it
+	return copyTask(taskObj), nil
+}
+
+// DefaultListPageSize is the number of tasks per page when a task list request does not set one.
+const DefaultListPageSize = 50
+
+// MaxListPageSize is the largest page size a task list request may use. Larger page sizes are reduced to it.
+const MaxListPageSize = 1000
+
+// OnListTasks implements TaskLister.OnListTasks.
+func (tm *InMemoryTaskManager) OnListTasks(ctx context.Context, params *a2a.ListTasksParams) (*a2a.ListTasksResult, error) {
+	after, pageSize, err := parseListParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, last, more := tm.listTasksAfter(after, pageSize)
+	result := &a2a.ListTasksResult{Tasks: tasks}
+	if more {
+		result.NextCursor = encodeListCursor(last)
+	}
+	return result, nil
+}
+
+// OnListTasksSubscribe implements TaskLister.OnListTasksSubscribe.
+func (tm *InMemoryTaskManager) OnListTasksSubscribe(ctx context.Context, params *a2a.ListTasksParams) (<-chan *a2a.ListTasksResult, error) {
+	after, pageSize, err := parseListParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch for new tasks before listing, so none are missed in between
+	watcher := make(chan struct{}, 1)
+	tm.mu.Lock()
+	tm.listWatchers[watcher] = struct{}{}
+	tm.mu.Unlock()
+
+	pages := make(chan *a2a.ListTasksResult)
+	go func() {
+		defer close(pages)
+		defer func() {
+			tm.mu.Lock()
+			delete(tm.listWatchers, watcher)
+			tm.mu.Unlock()
+		}()
+
+		for {
+			tasks, last, more := tm.listTasksAfter(after, pageSize)
+			if len(tasks) > 0 {
+				after = last
+				select {
+				case pages <- &a2a.ListTasksResult{Tasks: tasks, NextCursor: encodeListCursor(last)}:
+				case <-ctx.Done():
+					return
+				}
+				if more {
+					continue
+				}
+			}
+
+			// Wait for new tasks
+			select {
+			case <-watcher:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return pages, nil
+}
+
+// listTasksAfter returns up to limit unexpired tasks created after the task with creation
+// order after, in creation order, along with the creation order of the last task returned
+// and whether more tasks follow it.
+func (tm *InMemoryTaskManager) listTasksAfter(after uint64, limit int) ([]a2a.Task, uint64, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	// The index is in creation order, so the page starts at the first task created after it
+	start, _ := slices.BinarySearchFunc(tm.taskOrder, after+1, func(entry taskOrderEntry, seq uint64) int {
+		return cmp.Compare(entry.seq, seq)
+	})
+
+	tasks := make([]a2a.Task, 0)
+	for _, entry := range tm.taskOrder[start:] {
+		taskObj, ok := tm.tasks[entry.id]
+		if !ok || tm.taskSeq[entry.id] != entry.seq || tm.isExpired(taskObj) {
+			continue
+		}
+		if len(tasks) == limit {
+			return tasks, after, true
+		}
+		tasks = append(tasks, *copyTask(taskObj))
+		after = entry.seq
+	}
+	return tasks, after, false
+}
+
+// parseListParams returns the creation order a task list starts after and its page size.
+func parseListParams(params *a2a.ListTasksParams) (uint64, int, error) {
+	if params.PageSize < 0 {
+		return 0, 0, a2a.ErrValidation(a2a.FieldError{Field: "pageSize", Reason: "must not be negative"})
+	}
+	pageSize := params.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultListPageSize
+	}
+	pageSize = min(pageSize, MaxListPageSize)
+
+	var after uint64
+	if params.Cursor != "" {
+		var ok bool
+		if after, ok = decodeListCursor(params.Cursor); !ok {
+			return 0, 0, a2a.ErrValidation(a2a.FieldError{Field: "cursor", Reason: "is not a valid cursor"})
+		}
+	}
+	return after, pageSize, nil
+}
+
+// encodeListCursor returns the cursor for the tasks created after the task with the given
+// creation order. Cursors are opaque to clients.
+func encodeListCursor(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(seq, 10)))
+}
+
+// decodeListCursor returns the creation order a cursor encodes.
+func decodeListCursor(cursor string) (uint64, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
 }
 
 // OnSetTaskPushNotification implements TaskManager.OnSetTaskPushNotification.
@@ -684,21 +1630,38 @@ func (tm *InMemoryTaskManager) OnSetTaskPushNotification(ctx context.Context, pa
 
 	// Create a push notification config from the params
 	config := &a2a.PushNotificationConfig{
-		TaskID:           params.TaskID,
-		URL:              params.URL,
-		Authentication:   params.Authentication,
-		IncludeTaskData:  params.IncludeTaskData,
-		IncludeArtifacts: params.IncludeArtifacts,
+		TaskID:            params.TaskID,
+		URL:               params.URL,
+		Authentication:    params.Authentication,
+		IncludeTaskData:   params.IncludeTaskData,
+		IncludeArtifacts:  params.IncludeArtifacts,
+		Headers:           params.Headers,
+		ReplayOnSubscribe: params.ReplayOnSubscribe,
 	}
 
 	// Store the push notification config
 	tm.mu.Lock()
 	tm.pushConfigs[params.TaskID] = config
+
+	// Replay the task's progress so a late subscriber can catch up on what it missed. The
+	// replay is queued before the lock is released, so it is sent ahead of later updates.
+	if config.ReplayOnSubscribe != nil && *config.ReplayOnSubscribe && tm.pushNotifier != nil {
+		if taskObj, ok := tm.tasks[params.TaskID]; ok && isActiveState(taskObj.Status.State) {
+			snapshot := copyTask(taskObj)
+			if start := len(snapshot.Artifacts) - maxReplayedArtifacts; start > 0 {
+				snapshot.Artifacts = snapshot.Artifacts[start:]
+			}
+			tm.pushNotifier.Replay(snapshot, config)
+		}
+	}
 	tm.mu.Unlock()
 
 	return config, nil
 }
 
+// maxReplayedArtifacts is the number of most recent artifacts replayed to a late push subscriber.
+const maxReplayedArtifacts = 10
+
 // OnGetTaskPushNotification implements TaskManager.OnGetTaskPushNotification.
 func (tm *InMemoryTaskManager) OnGetTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) (*a2a.PushNotificationConfig, error) {
 	// Check if the task exists
@@ -719,49 +1682,197 @@ func (tm *InMemoryTaskManager) OnGetTaskPushNotification(ctx context.Context, pa
 	return config, nil
 }
 
+// OnDeleteTaskPushNotification implements TaskManager.OnDeleteTaskPushNotification.
+func (tm *InMemoryTaskManager) OnDeleteTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	// Check if the task exists
+	if _, exists := tm.tasks[params.TaskID]; !exists {
+		return a2a.ErrTaskNotFound(params.TaskID)
+	}
+
+	// Remove the push notification config (a no-op if none is set)
+	delete(tm.pushConfigs, params.TaskID)
+
+	return nil
+}
+
 // OnCancelTask implements TaskManager.OnCancelTask.
 func (tm *InMemoryTaskManager) OnCancelTask(ctx context.Context, params *a2a.TaskIdParams) (*a2a.Task, error) {
+	return tm.cancelTask(params.TaskID, a2a.TaskStatusReasonUser, false, "Task cancelled by user")
+}
+
+// cancelTask sets a task's status to cancelled, with the given reason and message, cancels
+// the context of its handler if it is running, and sends a push notification if one is
+// configured.
+func (tm *InMemoryTaskManager) cancelTask(taskID string, reason a2a.TaskStatusReason, retryable bool, text string) (*a2a.Task, error) {
 	// Check if the task exists
 	tm.mu.RLock()
-	taskObj, exists := tm.tasks[params.TaskID]
+	taskObj, exists := tm.tasks[taskID]
 	tm.mu.RUnlock()
 
 	if !exists {
-		return nil, a2a.ErrTaskNotFound(params.TaskID)
+		return nil, a2a.ErrTaskNotFound(taskID)
 	}
 
 	// Update task status to cancelled
 	tm.mu.Lock()
+	if run, ok := tm.queued[taskID]; ok {
+		// Drop the task when a worker dequeues it, so its handler never runs
+		close(run.cancelled)
+		delete(tm.queued, taskID)
+	}
+	if stop, ok := tm.running[taskID]; ok {
+		// Stop the running handler, aborting its in-flight work
+		stop()
+		delete(tm.running, taskID)
+	}
 	taskObj.Status = a2a.TaskStatus{
 		State:     a2a.TaskStateCancelled,
-		Timestamp: time.Now(),
+		Timestamp: tm.clock.Now(),
+		Reason:    reason,
+		Retryable: retryable,
 		Message: &a2a.Message{
 			Role:      a2a.RoleSystem,
-			Timestamp: time.Now(),
+			Timestamp: tm.clock.Now(),
 			Parts: []a2a.Part{
 				a2a.TextPart{
 					Type: "text",
-					Text: "Task cancelled by user",
+					Text: text,
 				},
 			},
 		},
 	}
-	tm.mu.Unlock()
 
 	// Get push notification config (if any)
-	tm.mu.RLock()
-	config, hasPushConfig := tm.pushConfigs[params.TaskID]
-	tm.mu.RUnlock()
+	config, hasPushConfig := tm.pushConfigs[taskID]
+	snapshot := copyTask(taskObj)
+	tm.mu.Unlock()
 
 	// Send push notification if configured
 	if hasPushConfig && tm.pushNotifier != nil {
-		if err := tm.pushNotifier.SendStatusUpdate(context.Background(), taskObj, config); err != nil {
+		if err := tm.pushNotifier.SendStatusUpdate(context.Background(), snapshot, config); err != nil {
 			// Just log the error for now
-			fmt.Printf("Failed to send push notification for task %s: %v\n", params.TaskID, err)
+			fmt.Printf("Failed to send push notification for task %s: %v\n", taskID, err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Shutdown cancels every task that has not reached a final state, marking them as
+// retryable with the shutdown reason so clients know to send them again once the
+// server is back, and stops the workers. Tasks sent afterwards fail. It returns the
+// cancelled tasks.
+func (tm *InMemoryTaskManager) Shutdown() []*a2a.Task {
+	defer tm.stop()
+
+	tm.mu.RLock()
+	var taskIDs []string
+	for id, taskObj := range tm.tasks {
+		if isActiveState(taskObj.Status.State) {
+			taskIDs = append(taskIDs, id)
+		}
+	}
+	tm.mu.RUnlock()
+
+	cancelled := make([]*a2a.Task, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		taskObj, err := tm.cancelTask(id, a2a.TaskStatusReasonShutdown, true, "Task cancelled because the server is shutting down")
+		if err != nil {
+			continue
+		}
+		cancelled = append(cancelled, taskObj)
+	}
+	return cancelled
+}
+
+// taskSendMode returns the mode of a tasks/send request, defaulting to resume if it has a
+// task ID and to create otherwise, and checks that the params suit the mode.
+func taskSendMode(params *a2a.TaskSendParams) (a2a.TaskSendMode, error) {
+	hasTaskID := params.TaskID != nil
+	switch params.Mode {
+	case "":
+		if hasTaskID {
+			return a2a.TaskSendModeResume, nil
+		}
+		return a2a.TaskSendModeCreate, nil
+	case a2a.TaskSendModeCreate, a2a.TaskSendModeCreateIfAbsent:
+		if hasTaskID {
+			return "", a2a.ErrValidation(a2a.FieldError{Field: "taskId", Reason: fmt.Sprintf("must not be set when mode is %s", params.Mode)})
+		}
+		if params.Mode == a2a.TaskSendModeCreateIfAbsent && (params.SessionID == nil || *params.SessionID == "") {
+			return "", a2a.ErrValidation(a2a.FieldError{Field: "sessionId", Reason: "is required when mode is create-if-absent"})
+		}
+	case a2a.TaskSendModeResume:
+		if !hasTaskID {
+			return "", a2a.ErrValidation(a2a.FieldError{Field: "taskId", Reason: "is required when mode is resume"})
 		}
+	default:
+		return "", a2a.ErrValidation(a2a.FieldError{Field: "mode", Reason: fmt.Sprintf("unknown mode %q", params.Mode)})
+	}
+	return params.Mode, nil
+}
+
+// isActiveState reports whether a task in the given state has not reached a final state.
+func isActiveState(state a2a.TaskState) bool {
+	return state != a2a.TaskStateCompleted && state != a2a.TaskStateFailed && state != a2a.TaskStateCancelled
+}
+
+// activeSessionTask returns the most recently updated active, unexpired task in a session,
+// or nil if it has none. The caller must hold tm.mu.
+func (tm *InMemoryTaskManager) activeSessionTask(sessionID string) *a2a.Task {
+	var active *a2a.Task
+	for _, taskObj := range tm.tasks {
+		if taskObj.SessionID == nil || *taskObj.SessionID != sessionID || !isActiveState(taskObj.Status.State) {
+			continue
+		}
+		if tm.isExpired(taskObj) {
+			continue
+		}
+		if active == nil || taskObj.Status.Timestamp.After(active.Status.Timestamp) {
+			active = taskObj
+		}
+	}
+	return active
+}
+
+// OnCancelSession implements SessionCanceller.OnCancelSession.
+func (tm *InMemoryTaskManager) OnCancelSession(ctx context.Context, params *a2a.SessionIdParams) ([]*a2a.Task, error) {
+	if params.SessionID == "" {
+		return nil, a2a.ErrValidation(a2a.FieldError{Field: "sessionId", Reason: "is required"})
+	}
+
+	// Find the session's tasks that have not reached a final state
+	tm.mu.RLock()
+	var taskIDs []string
+	for id, taskObj := range tm.tasks {
+		if taskObj.SessionID == nil || *taskObj.SessionID != params.SessionID {
+			continue
+		}
+		if !isActiveState(taskObj.Status.State) {
+			continue
+		}
+		taskIDs = append(taskIDs, id)
+	}
+	slices.SortFunc(taskIDs, func(a, b string) int {
+		return cmp.Compare(tm.taskSeq[a], tm.taskSeq[b])
+	})
+	tm.mu.RUnlock()
+
+	// Cancel them one by one
+	cancelled := make([]*a2a.Task, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		taskObj, err := tm.OnCancelTask(ctx, &a2a.TaskIdParams{TaskID: id})
+		if err != nil {
+			// The task may have expired since it was found
+			continue
+		}
+		cancelled = append(cancelled, taskObj)
 	}
 
-	return taskObj, nil
+	return cancelled, nil
 }
 
 // OnResubscribeToTask implements TaskManager.OnResubscribeToTask.
@@ -769,9 +1880,13 @@ func (tm *InMemoryTaskManager) OnResubscribeToTask(ctx context.Context, params *
 	// Create a channel for updates
 	updateChan := make(chan task.YieldUpdate)
 
-	// Check if the task exists
+	// Check if the task exists, reading its status while the lock is held
 	tm.mu.RLock()
 	taskObj, exists := tm.tasks[params.TaskID]
+	var status a2a.TaskStatus
+	if exists {
+		status = taskObj.Status
+	}
 	tm.mu.RUnlock()
 
 	if !exists {
@@ -782,16 +1897,23 @@ func (tm *InMemoryTaskManager) OnResubscribeToTask(ctx context.Context, params *
 	go func() {
 		defer close(updateChan)
 
+		// send passes an update to the subscriber, returning false once it has gone away
+		send := func(update task.YieldUpdate) bool {
+			select {
+			case updateChan <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
 		// Send the current status as the first update
-		updateChan <- task.StatusUpdate{
-			State:   taskObj.Status.State,
-			Message: taskObj.Status.Message,
+		if !send(updateFromStatus(status)) {
+			return
 		}
 
 		// If the task is already completed, failed, or cancelled, just return
-		if taskObj.Status.State == a2a.TaskStateCompleted ||
-			taskObj.Status.State == a2a.TaskStateFailed ||
-			taskObj.Status.State == a2a.TaskStateCancelled {
+		if !isActiveState(status.State) {
 			return
 		}
 
@@ -813,15 +1935,12 @@ func (tm *InMemoryTaskManager) OnResubscribeToTask(ctx context.Context, params *
 				tm.mu.RUnlock()
 
 				// Send an update if the status has changed
-				updateChan <- task.StatusUpdate{
-					State:   currentStatus.State,
-					Message: currentStatus.Message,
+				if !send(updateFromStatus(currentStatus)) {
+					return
 				}
 
 				// If the task is now completed, failed, or cancelled, stop monitoring
-				if currentStatus.State == a2a.TaskStateCompleted ||
-					currentStatus.State == a2a.TaskStateFailed ||
-					currentStatus.State == a2a.TaskStateCancelled {
+				if !isActiveState(currentStatus.State) {
 					return
 				}
 			}
@@ -0,0 +1,1530 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// waitForState polls the task manager until the task reaches the given state.
+func waitForState(t *testing.T, tm TaskManager, taskID string, state a2a.TaskState) *a2a.Task {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		taskObj, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: taskID})
+		if err != nil {
+			t.Fatalf("Failed to get task: %v", err)
+		}
+		if taskObj.Status.State == state {
+			return taskObj
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Task %s did not reach state %s", taskID, state)
+	return nil
+}
+
+// newTextMessage creates a message with a single text part.
+func newTextMessage(role a2a.Role, text string) a2a.Message {
+	return a2a.Message{
+		Role:      role,
+		Timestamp: time.Now(),
+		Parts: []a2a.Part{
+			a2a.TextPart{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+func TestInMemoryTaskManager_MaxHistory(t *testing.T) {
+	const maxHistory = 4
+	const numMessages = 10
+
+	// Create a handler that emits more messages than the history limit
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			for i := 0; i < numMessages; i++ {
+				msg := newTextMessage(a2a.RoleAgent, fmt.Sprintf("message %d", i))
+				updates <- task.StatusUpdate{State: a2a.TaskStateWorking, Message: &msg}
+			}
+			updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		}()
+		return updates, nil
+	}
+
+	tm := NewInMemoryTaskManager(handler)
+	tm.SetMaxHistory(maxHistory)
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "initial"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	taskObj := waitForState(t, tm, created.ID, a2a.TaskStateCompleted)
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if len(taskObj.History) != maxHistory {
+		t.Fatalf("Expected history length %d, got %d", maxHistory, len(taskObj.History))
+	}
+
+	// The initial user message is always kept
+	if text := taskObj.History[0].Parts[0].(a2a.TextPart).Text; text != "initial" {
+		t.Errorf("Expected first message to be the initial user message, got %q", text)
+	}
+
+	// The remaining messages are the most recent ones, in order
+	for i, msg := range taskObj.History[1:] {
+		expected := fmt.Sprintf("message %d", numMessages-maxHistory+1+i)
+		if text := msg.Parts[0].(a2a.TextPart).Text; text != expected {
+			t.Errorf("Expected history[%d] to be %q, got %q", i+1, expected, text)
+		}
+	}
+
+	if truncated, _ := taskObj.Metadata["historyTruncated"].(bool); !truncated {
+		t.Error("Expected task metadata to indicate history truncation")
+	}
+	if dropped, _ := taskObj.Metadata["historyDropped"].(int); dropped != numMessages+1-maxHistory {
+		t.Errorf("Expected %d dropped messages, got %d", numMessages+1-maxHistory, dropped)
+	}
+}
+
+func TestTruncateHistory_KeepsSystemMessages(t *testing.T) {
+	history := []a2a.Message{
+		newTextMessage(a2a.RoleUser, "initial"),
+		newTextMessage(a2a.RoleSystem, "system"),
+		newTextMessage(a2a.RoleAgent, "a"),
+		newTextMessage(a2a.RoleAgent, "b"),
+	}
+
+	truncated, dropped := truncateHistory(history, 3)
+	if dropped != 1 {
+		t.Fatalf("Expected 1 dropped message, got %d", dropped)
+	}
+	if truncated[1].Role != a2a.RoleSystem {
+		t.Errorf("Expected system message to be kept, got role %s", truncated[1].Role)
+	}
+	if text := truncated[2].Parts[0].(a2a.TextPart).Text; text != "b" {
+		t.Errorf("Expected most recent agent message to be kept, got %q", text)
+	}
+}
+
+// newBlockingHandler returns a handler that records how many instances run at once
+// and blocks each one until release is closed.
+func newBlockingHandler(release <-chan struct{}, running, maxRunning, started *int32) task.Handler {
+	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		atomic.AddInt32(started, 1)
+		n := atomic.AddInt32(running, 1)
+		for {
+			max := atomic.LoadInt32(maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(maxRunning, max, n) {
+				break
+			}
+		}
+
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-release
+			atomic.AddInt32(running, -1)
+			updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		}()
+		return updates, nil
+	}
+}
+
+func TestInMemoryTaskManager_MaxConcurrentTasks(t *testing.T) {
+	const maxConcurrent = 2
+	const numTasks = 6
+
+	release := make(chan struct{})
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+	tm.SetMaxConcurrentTasks(maxConcurrent, QueueTasks)
+
+	ids := make([]string, numTasks)
+	for i := range ids {
+		created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+			Message: newTextMessage(a2a.RoleUser, "hello"),
+		})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		ids[i] = created.ID
+	}
+
+	// Wait for the first tasks to start, and give the rest a chance to (incorrectly) start
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&started) < maxConcurrent && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&started); n != maxConcurrent {
+		t.Fatalf("Expected %d handlers to start, got %d", maxConcurrent, n)
+	}
+
+	// Queued tasks stay submitted
+	submitted := 0
+	for _, id := range ids {
+		taskObj, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: id})
+		if err != nil {
+			t.Fatalf("OnGetTask failed: %v", err)
+		}
+		tm.mu.RLock()
+		if taskObj.Status.State == a2a.TaskStateSubmitted {
+			submitted++
+		}
+		tm.mu.RUnlock()
+	}
+	if submitted != numTasks-maxConcurrent {
+		t.Errorf("Expected %d queued tasks in the submitted state, got %d", numTasks-maxConcurrent, submitted)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForState(t, tm, id, a2a.TaskStateCompleted)
+	}
+
+	if n := atomic.LoadInt32(&maxRunning); n > maxConcurrent {
+		t.Errorf("Expected at most %d handlers to run at once, got %d", maxConcurrent, n)
+	}
+}
+
+func TestInMemoryTaskManager_MaxConcurrentTasksReject(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+	tm.SetMaxConcurrentTasks(1, RejectTasks)
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "first"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, created.ID, a2a.TaskStateWorking)
+
+	_, err = tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "second"),
+	})
+	var a2aErr *a2a.Error
+	if !errors.As(err, &a2aErr) || a2aErr.Code != a2a.CodeRateLimitExceeded {
+		t.Fatalf("Expected rate limit error, got %v", err)
+	}
+}
+
+func TestInMemoryTaskManager_MaxConcurrentTasksRejectConcurrentSends(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+	tm.SetMaxConcurrentTasks(1, RejectTasks)
+
+	// Sends racing for the only slot must not all see it free
+	var accepted int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+				Message: newTextMessage(a2a.RoleUser, "hello"),
+			})
+			if err == nil {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&accepted); n != 1 {
+		t.Errorf("Expected 1 task to be accepted, got %d", n)
+	}
+}
+
+func TestInMemoryTaskManager_IgnoresUpdatesAfterFinalState(t *testing.T) {
+	// A handler that reports a failure after completing the task
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 3)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		updates <- task.StatusUpdate{State: a2a.TaskStateFailed}
+		updates <- task.StatusUpdate{State: a2a.TaskStateWorking}
+		close(updates)
+		return updates, nil
+	}
+	tm := NewInMemoryTaskManager(handler)
+
+	taskID, updates, err := tm.OnSendTaskSubscribe(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTaskSubscribe failed: %v", err)
+	}
+	var last a2a.TaskState
+	for update := range updates {
+		if u, ok := update.(task.StatusUpdate); ok {
+			last = u.State
+		}
+	}
+	if last != a2a.TaskStateCompleted {
+		t.Errorf("Expected the last streamed state to be completed, got %s", last)
+	}
+
+	taskObj, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: taskID})
+	if err != nil {
+		t.Fatalf("OnGetTask failed: %v", err)
+	}
+	if taskObj.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected the task to stay completed, got %s", taskObj.Status.State)
+	}
+
+	// The same applies to tasks that are not streamed
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, created.ID, a2a.TaskStateCompleted)
+
+	// Give the later updates time to be applied
+	time.Sleep(50 * time.Millisecond)
+	taskObj, err = tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: created.ID})
+	if err != nil {
+		t.Fatalf("OnGetTask failed: %v", err)
+	}
+	if taskObj.Status.State != a2a.TaskStateCompleted {
+		t.Errorf("Expected the task to stay completed, got %s", taskObj.Status.State)
+	}
+}
+
+func TestInMemoryTaskManager_CancelQueuedTask(t *testing.T) {
+	release := make(chan struct{})
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+	tm.SetMaxConcurrentTasks(1, QueueTasks)
+
+	first, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "first"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, first.ID, a2a.TaskStateWorking)
+
+	queued, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "queued"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	// Wait for the task to join the queue, then cancel it
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		tm.mu.RLock()
+		_, ok := tm.queued[queued.ID]
+		tm.mu.RUnlock()
+		if ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := tm.OnCancelTask(context.Background(), &a2a.TaskIdParams{TaskID: queued.ID}); err != nil {
+		t.Fatalf("OnCancelTask failed: %v", err)
+	}
+
+	close(release)
+	waitForState(t, tm, first.ID, a2a.TaskStateCompleted)
+	time.Sleep(50 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&started); n != 1 {
+		t.Errorf("Expected the cancelled task's handler not to run, got %d handler runs", n)
+	}
+	waitForState(t, tm, queued.ID, a2a.TaskStateCancelled)
+}
+
+func TestInMemoryTaskManager_TaskContext(t *testing.T) {
+	received := make(chan task.Context, 2)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		received <- taskCtx
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+	tm := NewInMemoryTaskManager(handler)
+
+	sessionID := "session-1"
+	skillID := "summarise"
+	params := &a2a.TaskSendParams{
+		SessionID: &sessionID,
+		SkillID:   &skillID,
+		Message:   newTextMessage(a2a.RoleUser, "hello"),
+		Metadata:  map[string]interface{}{"priority": "high"},
+	}
+	deadline := time.Now().Add(time.Minute)
+
+	checkContext := func(name string, taskCtx task.Context) {
+		t.Helper()
+		if taskCtx.SessionID != sessionID {
+			t.Errorf("%s: expected session ID %q, got %q", name, sessionID, taskCtx.SessionID)
+		}
+		if taskCtx.SkillID != skillID {
+			t.Errorf("%s: expected skill ID %q, got %q", name, skillID, taskCtx.SkillID)
+		}
+		if metadata, _ := taskCtx.Metadata.(map[string]interface{}); metadata["priority"] != "high" {
+			t.Errorf("%s: expected request metadata, got %v", name, taskCtx.Metadata)
+		}
+		if !taskCtx.HasDeadline() || !taskCtx.Deadline.Equal(deadline) {
+			t.Errorf("%s: expected deadline %v, got %v", name, deadline, taskCtx.Deadline)
+		}
+	}
+
+	// The deadline is taken from the request even though the task outlives it
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	if _, err := tm.OnSendTask(ctx, params); err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	cancel()
+	select {
+	case taskCtx := <-received:
+		checkContext("OnSendTask", taskCtx)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler was not called for OnSendTask")
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	_, updates, err := tm.OnSendTaskSubscribe(ctx, params)
+	if err != nil {
+		t.Fatalf("OnSendTaskSubscribe failed: %v", err)
+	}
+	for range updates {
+	}
+	select {
+	case taskCtx := <-received:
+		checkContext("OnSendTaskSubscribe", taskCtx)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler was not called for OnSendTaskSubscribe")
+	}
+
+	// Without a deadline on the request, the task has none
+	params.SessionID, params.SkillID = nil, nil
+	if _, err := tm.OnSendTask(context.Background(), params); err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	select {
+	case taskCtx := <-received:
+		if taskCtx.HasDeadline() || taskCtx.SessionID != "" || taskCtx.SkillID != "" {
+			t.Errorf("Expected no deadline, session or skill, got %+v", taskCtx)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler was not called")
+	}
+}
+
+func TestInMemoryTaskManager_IdempotencyKeyExpires(t *testing.T) {
+	tm := NewInMemoryTaskManager(newMockHandler())
+	tm.SetIdempotencyTTL(50 * time.Millisecond)
+
+	key := "retry-key"
+	params := &a2a.TaskSendParams{
+		Message:        newTextMessage(a2a.RoleUser, "hello"),
+		IdempotencyKey: &key,
+	}
+
+	first, err := tm.OnSendTask(context.Background(), params)
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	second, err := tm.OnSendTask(context.Background(), params)
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected repeated key to return task %s, got %s", first.ID, second.ID)
+	}
+
+	// Once the TTL has passed, the key creates a new task
+	time.Sleep(100 * time.Millisecond)
+	third, err := tm.OnSendTask(context.Background(), params)
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	if third.ID == first.ID {
+		t.Error("Expected an expired key to create a new task")
+	}
+}
+
+func TestInMemoryTaskManager_ReplayOnSubscribe(t *testing.T) {
+	// Create a handler that emits an artifact, then waits to be released
+	release := make(chan struct{})
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			updates <- task.StatusUpdate{State: a2a.TaskStateWorking}
+			updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: "partial result"}}
+			<-release
+			updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		}()
+		return updates, nil
+	}
+
+	received := make(chan PushNotificationPayload, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PushNotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode push notification: %v", err)
+		}
+		received <- payload
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	tm := NewInMemoryTaskManager(handler)
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hello"),
+	})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	// Wait until the artifact has been emitted before subscribing
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		tm.mu.RLock()
+		emitted := len(tm.tasks[created.ID].Artifacts)
+		tm.mu.RUnlock()
+		if emitted == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Task did not emit its artifact")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	replay := true
+	if _, err := tm.OnSetTaskPushNotification(context.Background(), &a2a.TaskPushNotificationConfigParams{
+		TaskID:            created.ID,
+		URL:               receiver.URL,
+		ReplayOnSubscribe: &replay,
+	}); err != nil {
+		t.Fatalf("OnSetTaskPushNotification failed: %v", err)
+	}
+
+	var payloads []PushNotificationPayload
+	for len(payloads) < 2 {
+		select {
+		case payload := <-received:
+			payloads = append(payloads, payload)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected replayed notifications, got %d", len(payloads))
+		}
+	}
+
+	if payloads[0].EventType != "status" || payloads[0].Status.State != a2a.TaskStateWorking {
+		t.Errorf("Expected initial working status push, got %+v", payloads[0])
+	}
+	if payloads[1].EventType != "artifact" || payloads[1].Artifact.Part.(a2a.TextPart).Text != "partial result" {
+		t.Errorf("Expected replayed artifact push, got %+v", payloads[1])
+	}
+}
+
+func TestInMemoryTaskManager_NoReplayAfterFinalState(t *testing.T) {
+	received := make(chan PushNotificationPayload, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PushNotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	tm := NewInMemoryTaskManager(func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		close(updates)
+		return updates, nil
+	})
+	tm.tasks["done"] = &a2a.Task{ID: "done", Status: a2a.TaskStatus{State: a2a.TaskStateCompleted}}
+
+	replay := true
+	if _, err := tm.OnSetTaskPushNotification(context.Background(), &a2a.TaskPushNotificationConfigParams{
+		TaskID:            "done",
+		URL:               receiver.URL,
+		ReplayOnSubscribe: &replay,
+	}); err != nil {
+		t.Fatalf("OnSetTaskPushNotification failed: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		t.Errorf("Expected no replay for a completed task, got %+v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestInMemoryTaskManager_MaxOutputBytes(t *testing.T) {
+	handlerDone := make(chan error, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			for i := 0; i < 10; i++ {
+				select {
+				case updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: strings.Repeat("x", 60)}}:
+				case <-ctx.Done():
+					handlerDone <- ctx.Err()
+					return
+				}
+			}
+			handlerDone <- nil
+		}()
+		return updates, nil
+	}
+
+	tm := NewInMemoryTaskManager(handler)
+	tm.SetMaxOutputBytes(100)
+
+	taskObj, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "write a lot")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, taskObj.ID, a2a.TaskStateFailed)
+
+	select {
+	case err := <-handlerDone:
+		if err == nil {
+			t.Error("Expected the handler's context to be cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the handler to stop")
+	}
+
+	taskObj, err = tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: taskObj.ID})
+	if err != nil {
+		t.Fatalf("OnGetTask failed: %v", err)
+	}
+	if n := len(taskObj.Artifacts); n != 1 {
+		t.Errorf("Expected only the artifact within the limit to be kept, got %d", n)
+	}
+	if msg := taskObj.Status.Message; msg == nil || !strings.Contains(msg.Parts[0].(a2a.TextPart).Text, "100 bytes") {
+		t.Errorf("Expected the failure to mention the limit, got %+v", msg)
+	}
+}
+
+func TestInMemoryTaskManager_ArtifactValidation(t *testing.T) {
+	schemas := map[string]interface{}{
+		"summarise": map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"summary"},
+			"properties": map[string]interface{}{
+				"summary": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+	// The handler produces the artifact data sent as the message's data part
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 2)
+		updates <- task.ArtifactUpdate{Part: taskCtx.UserMessage.Parts[0]}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+
+	tests := []struct {
+		name      string
+		policy    ArtifactValidationPolicy
+		skillID   string
+		data      interface{}
+		wantState a2a.TaskState
+		artifacts int
+	}{
+		{"conforming artifact", FailInvalidArtifacts, "summarise", map[string]interface{}{"summary": "short"}, a2a.TaskStateCompleted, 1},
+		{"non-conforming artifact fails the task", FailInvalidArtifacts, "summarise", map[string]interface{}{"summary": 42}, a2a.TaskStateFailed, 0},
+		{"non-conforming artifact is logged", LogInvalidArtifacts, "summarise", map[string]interface{}{}, a2a.TaskStateCompleted, 1},
+		{"validation skipped", SkipArtifactValidation, "summarise", map[string]interface{}{"summary": 42}, a2a.TaskStateCompleted, 1},
+		{"skill without a schema", FailInvalidArtifacts, "translate", map[string]interface{}{"summary": 42}, a2a.TaskStateCompleted, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewInMemoryTaskManager(handler)
+			tm.SetArtifactValidation(schemas, tt.policy)
+
+			skillID := tt.skillID
+			taskObj, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+				SkillID: &skillID,
+				Message: a2a.Message{
+					Role:  a2a.RoleUser,
+					Parts: []a2a.Part{a2a.DataPart{Type: "data", MimeType: "application/json", Data: tt.data}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("OnSendTask failed: %v", err)
+			}
+			waitForState(t, tm, taskObj.ID, tt.wantState)
+
+			taskObj, err = tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: taskObj.ID})
+			if err != nil {
+				t.Fatalf("OnGetTask failed: %v", err)
+			}
+			if n := len(taskObj.Artifacts); n != tt.artifacts {
+				t.Errorf("Expected %d artifacts, got %d", tt.artifacts, n)
+			}
+			if tt.wantState == a2a.TaskStateFailed {
+				if msg := taskObj.Status.Message; msg == nil || !strings.Contains(msg.Parts[0].(a2a.TextPart).Text, "summary") {
+					t.Errorf("Expected the failure to name the invalid field, got %+v", msg)
+				}
+			}
+		})
+	}
+}
+
+// newHangingHandler returns a handler that never completes, reporting on cancelled when
+// its context is cancelled.
+func newHangingHandler(cancelled chan<- struct{}) task.Handler {
+	return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-ctx.Done()
+			close(cancelled)
+		}()
+		return updates, nil
+	}
+}
+
+func TestInMemoryTaskManager_TaskTimeout(t *testing.T) {
+	cancelled := make(chan struct{})
+	tm := NewInMemoryTaskManager(newHangingHandler(cancelled))
+	tm.SetTaskTimeout(50 * time.Millisecond)
+
+	taskObj, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hang")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	failed := waitForState(t, tm, taskObj.ID, a2a.TaskStateFailed)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the handler's context to be cancelled")
+	}
+
+	if msg := failed.Status.Message; msg == nil || !strings.Contains(msg.Parts[0].(a2a.TextPart).Text, "timed out after 50ms") {
+		t.Errorf("Expected a timeout failure message, got %+v", msg)
+	}
+}
+
+func TestInMemoryTaskManager_TaskTimeoutFromParams(t *testing.T) {
+	cancelled := make(chan struct{})
+	tm := NewInMemoryTaskManager(newHangingHandler(cancelled))
+	tm.SetTaskTimeout(time.Hour)
+
+	timeout := 0.05
+	_, updates, err := tm.OnSendTaskSubscribe(context.Background(), &a2a.TaskSendParams{
+		Message: newTextMessage(a2a.RoleUser, "hang"),
+		Timeout: &timeout,
+	})
+	if err != nil {
+		t.Fatalf("OnSendTaskSubscribe failed: %v", err)
+	}
+
+	var last task.StatusUpdate
+	deadline := time.After(2 * time.Second)
+	for last.State != a2a.TaskStateFailed {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				t.Fatalf("Updates closed before the task failed, last state %q", last.State)
+			}
+			if u, ok := update.(task.StatusUpdate); ok {
+				last = u
+			}
+		case <-deadline:
+			t.Fatal("Timed out waiting for the task to fail")
+		}
+	}
+	if text := last.Message.Parts[0].(a2a.TextPart).Text; !strings.Contains(text, "timed out after 50ms") {
+		t.Errorf("Expected the client's timeout in the failure message, got %q", text)
+	}
+}
+
+func TestInMemoryTaskManager_SendModes(t *testing.T) {
+	// Tasks stay active until they are cancelled
+	tm := NewInMemoryTaskManager(func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-ctx.Done()
+		}()
+		return updates, nil
+	})
+	ctx := context.Background()
+	session := "session-1"
+	otherSession := "session-2"
+
+	// An active task in the session
+	active, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{SessionID: &session, Message: newTextMessage(a2a.RoleUser, "first")})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		created, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeCreate, SessionID: &session, Message: newTextMessage(a2a.RoleUser, "new")})
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if created.ID == active.ID {
+			t.Error("Expected create to make a new task even though the session has an active one")
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		resumed, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeResume, TaskID: &active.ID, Message: newTextMessage(a2a.RoleUser, "more")})
+		if err != nil {
+			t.Fatalf("Failed to resume task: %v", err)
+		}
+		if resumed.ID != active.ID {
+			t.Errorf("Expected task %s to be resumed, got %s", active.ID, resumed.ID)
+		}
+	})
+
+	t.Run("create-if-absent hit", func(t *testing.T) {
+		got, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeCreateIfAbsent, SessionID: &session, Message: newTextMessage(a2a.RoleUser, "again")})
+		if err != nil {
+			t.Fatalf("Failed to send task: %v", err)
+		}
+		taskObj, err := tm.OnGetTask(ctx, &a2a.TaskQueryParams{TaskID: got.ID})
+		if err != nil {
+			t.Fatalf("Failed to get task: %v", err)
+		}
+		if taskObj.SessionID == nil || *taskObj.SessionID != session || !isActiveState(taskObj.Status.State) {
+			t.Errorf("Expected an active task in %s, got %+v", session, taskObj)
+		}
+		for _, message := range taskObj.History {
+			if message.Parts[0].(a2a.TextPart).Text == "again" {
+				t.Error("Expected the message not to be sent to the existing task")
+			}
+		}
+	})
+
+	t.Run("create-if-absent miss", func(t *testing.T) {
+		created, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeCreateIfAbsent, SessionID: &otherSession, Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("Failed to send task: %v", err)
+		}
+		if created.SessionID == nil || *created.SessionID != otherSession {
+			t.Errorf("Expected a new task in %s, got %+v", otherSession, created)
+		}
+
+		// The new task is now the session's active task
+		again, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeCreateIfAbsent, SessionID: &otherSession, Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("Failed to send task: %v", err)
+		}
+		if again.ID != created.ID {
+			t.Errorf("Expected the active task %s to be returned, got %s", created.ID, again.ID)
+		}
+	})
+
+	t.Run("create-if-absent after the task finished", func(t *testing.T) {
+		if _, err := tm.OnCancelSession(ctx, &a2a.SessionIdParams{SessionID: otherSession}); err != nil {
+			t.Fatalf("Failed to cancel session: %v", err)
+		}
+		created, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Mode: a2a.TaskSendModeCreateIfAbsent, SessionID: &otherSession, Message: newTextMessage(a2a.RoleUser, "hello")})
+		if err != nil {
+			t.Fatalf("Failed to send task: %v", err)
+		}
+		if created.Status.State != a2a.TaskStateSubmitted {
+			t.Errorf("Expected a new task once the session has no active task, got %+v", created.Status)
+		}
+	})
+
+	invalid := []struct {
+		name      string
+		params    a2a.TaskSendParams
+		wantField string
+	}{
+		{name: "create with task ID", params: a2a.TaskSendParams{Mode: a2a.TaskSendModeCreate, TaskID: &active.ID}, wantField: "taskId"},
+		{name: "resume without task ID", params: a2a.TaskSendParams{Mode: a2a.TaskSendModeResume}, wantField: "taskId"},
+		{name: "create-if-absent without session", params: a2a.TaskSendParams{Mode: a2a.TaskSendModeCreateIfAbsent}, wantField: "sessionId"},
+		{name: "unknown mode", params: a2a.TaskSendParams{Mode: "upsert"}, wantField: "mode"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Message = newTextMessage(a2a.RoleUser, "hello")
+			_, err := tm.OnSendTask(ctx, &tt.params)
+
+			var a2aErr *a2a.Error
+			if !errors.As(err, &a2aErr) {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+			if fields := a2aErr.ValidationErrors(); len(fields) != 1 || fields[0].Field != tt.wantField {
+				t.Errorf("Expected a validation error for %q, got %+v", tt.wantField, fields)
+			}
+		})
+	}
+}
+
+func TestInMemoryTaskManager_ConcurrentReads(t *testing.T) {
+	// Create a handler that streams status messages and artifacts once released
+	const artifactCount = 20
+	start := make(chan struct{})
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate)
+		go func() {
+			defer close(updates)
+			<-start
+			for i := 0; i < artifactCount; i++ {
+				msg := newTextMessage(a2a.RoleAgent, fmt.Sprintf("step %d", i))
+				updates <- task.StatusUpdate{State: a2a.TaskStateWorking, Message: &msg}
+				updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: fmt.Sprintf("%d", i)}}
+			}
+			updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		}()
+		return updates, nil
+	}
+
+	// The receiver gets the full task with every push, so the notifier reads it concurrently
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	tm := NewInMemoryTaskManager(handler)
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	includeTask := true
+	if _, err := tm.OnSetTaskPushNotification(context.Background(), &a2a.TaskPushNotificationConfigParams{
+		TaskID:          created.ID,
+		URL:             receiver.URL,
+		IncludeTaskData: &includeTask,
+	}); err != nil {
+		t.Fatalf("OnSetTaskPushNotification failed: %v", err)
+	}
+
+	// Read the task from several goroutines while the handler updates it
+	done := make(chan struct{})
+	readErrs := make(chan error, 2)
+	for i := 0; i < cap(readErrs); i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					readErrs <- nil
+					return
+				default:
+				}
+				taskObj, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: created.ID})
+				if err == nil {
+					_, err = json.Marshal(taskObj)
+				}
+				if err != nil {
+					readErrs <- err
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+	}
+
+	close(start)
+	final := waitForState(t, tm, created.ID, a2a.TaskStateCompleted)
+	close(done)
+	for i := 0; i < cap(readErrs); i++ {
+		if err := <-readErrs; err != nil {
+			t.Errorf("Failed to read task: %v", err)
+		}
+	}
+
+	if len(final.Artifacts) != artifactCount {
+		t.Fatalf("Expected %d artifacts, got %d", artifactCount, len(final.Artifacts))
+	}
+	for i, artifact := range final.Artifacts {
+		if text := artifact.Part.(a2a.TextPart).Text; text != fmt.Sprintf("%d", i) {
+			t.Errorf("Expected artifact %d in position %d, got %s", i, i, text)
+		}
+	}
+	if n := len(final.History); n != artifactCount+1 {
+		t.Errorf("Expected %d messages in history, got %d", artifactCount+1, n)
+	}
+
+	// The returned task is a copy, so appending to it does not change the stored task
+	final.Artifacts = append(final.Artifacts, a2a.Artifact{ID: "extra"})
+	if again, _ := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: created.ID}); len(again.Artifacts) != artifactCount {
+		t.Errorf("Expected the stored task to be unaffected, got %d artifacts", len(again.Artifacts))
+	}
+}
+
+func TestInMemoryTaskManager_ReturnsTaskCopies(t *testing.T) {
+	cancelled := make(chan struct{})
+	tm := NewInMemoryTaskManager(newHangingHandler(cancelled))
+
+	sent, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	got, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: sent.ID})
+	if err != nil {
+		t.Fatalf("OnGetTask failed: %v", err)
+	}
+
+	// Mutating the returned tasks does not change the stored task
+	sent.Status.State = a2a.TaskStateCompleted
+	sent.History[0].Parts[0] = a2a.TextPart{Type: "text", Text: "changed"}
+	got.History = append(got.History, newTextMessage(a2a.RoleAgent, "injected"))
+	got.Artifacts = append(got.Artifacts, a2a.Artifact{ID: "injected"})
+
+	stored, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: sent.ID})
+	if err != nil {
+		t.Fatalf("OnGetTask failed: %v", err)
+	}
+	if stored.Status.State == a2a.TaskStateCompleted {
+		t.Error("Expected the stored status to be unaffected")
+	}
+	if len(stored.History) != 1 || stored.History[0].Parts[0].(a2a.TextPart).Text != "hello" {
+		t.Errorf("Expected the stored history to be unaffected, got %+v", stored.History)
+	}
+	if len(stored.Artifacts) != 0 {
+		t.Errorf("Expected the stored artifacts to be unaffected, got %+v", stored.Artifacts)
+	}
+
+	// Updating the stored task does not change tasks already returned
+	cancelledTask, err := tm.OnCancelTask(context.Background(), &a2a.TaskIdParams{TaskID: sent.ID})
+	if err != nil {
+		t.Fatalf("OnCancelTask failed: %v", err)
+	}
+	if stored.Status.State == a2a.TaskStateCancelled {
+		t.Error("Expected the earlier copy to keep its status")
+	}
+	if cancelledTask.Status.State != a2a.TaskStateCancelled {
+		t.Errorf("Expected the cancelled task, got %s", cancelledTask.Status.State)
+	}
+	cancelledTask.Status.Message.Parts[0] = a2a.TextPart{Type: "text", Text: "changed"}
+	if again, _ := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: sent.ID}); again.Status.Message.Parts[0].(a2a.TextPart).Text == "changed" {
+		t.Error("Expected the stored status message to be unaffected")
+	}
+}
+
+func TestInMemoryTaskManager_TaskExpiry(t *testing.T) {
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	tm := NewInMemoryTaskManager(handler)
+	tm.SetClock(clock)
+	tm.SetTaskExpiry(time.Hour)
+
+	first, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "first")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	completed := waitForState(t, tm, first.ID, a2a.TaskStateCompleted)
+	if !completed.Status.Timestamp.Equal(start) {
+		t.Errorf("Expected the status timestamp to come from the clock, got %v", completed.Status.Timestamp)
+	}
+
+	// Just within the expiry, the task is kept
+	clock.Advance(time.Hour)
+	if n := tm.EvictExpiredTasks(); n != 0 {
+		t.Errorf("Expected no tasks to be evicted, got %d", n)
+	}
+	if _, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: first.ID}); err != nil {
+		t.Fatalf("Expected the task before it expires, got %v", err)
+	}
+
+	// Once expired, the task is hidden and then evicted when the next task is created
+	clock.Advance(time.Second)
+	if _, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: first.ID}); err == nil {
+		t.Error("Expected an expired task not to be found")
+	}
+	if tasks, _ := tm.ListTasks(context.Background()); len(tasks) != 0 {
+		t.Errorf("Expected no tasks to be listed, got %d", len(tasks))
+	}
+	second, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "second")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	tm.mu.RLock()
+	_, firstStored := tm.tasks[first.ID]
+	_, secondStored := tm.tasks[second.ID]
+	tm.mu.RUnlock()
+	if firstStored || !secondStored {
+		t.Errorf("Expected only the new task to be stored, got first=%v second=%v", firstStored, secondStored)
+	}
+}
+
+func TestInMemoryTaskManager_ActiveTasksDoNotExpire(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	tm := NewInMemoryTaskManager(newHangingHandler(make(chan struct{})))
+	tm.SetClock(clock)
+	tm.SetTaskExpiry(time.Hour)
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if n := tm.EvictExpiredTasks(); n != 0 {
+		t.Errorf("Expected a running task not to be evicted, got %d evicted", n)
+	}
+	if _, err := tm.OnGetTask(context.Background(), &a2a.TaskQueryParams{TaskID: created.ID}); err != nil {
+		t.Errorf("Expected a running task to be found, got %v", err)
+	}
+}
+
+func TestInMemoryTaskManager_NoExpiryByDefault(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	tm := NewInMemoryTaskManager(newHangingHandler(make(chan struct{})))
+	tm.SetClock(clock)
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	clock.Advance(365 * 24 * time.Hour)
+	if n := tm.EvictExpiredTasks(); n != 0 {
+		t.Errorf("Expected no tasks to be evicted, got %d", n)
+	}
+	if tasks, _ := tm.ListTasks(context.Background()); len(tasks) != 1 || tasks[0].ID != created.ID {
+		t.Errorf("Expected the task to be listed, got %+v", tasks)
+	}
+}
+
+func TestInMemoryTaskManager_CompletedWithMessage(t *testing.T) {
+	response := newTextMessage(a2a.RoleAgent, "All done")
+	tm := NewInMemoryTaskManager(func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted, Message: &response}
+		close(updates)
+		return updates, nil
+	})
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	// The task is never seen completed without its final message
+	completed := waitForState(t, tm, created.ID, a2a.TaskStateCompleted)
+	if completed.Status.Message == nil || !reflect.DeepEqual(completed.Status.Message.Parts, response.Parts) {
+		t.Errorf("Expected the completed status to carry the final message, got %+v", completed.Status.Message)
+	}
+	if n := len(completed.History); n != 2 || !reflect.DeepEqual(completed.History[n-1].Parts, response.Parts) {
+		t.Errorf("Expected the final message to be added to the history, got %+v", completed.History)
+	}
+}
+
+func TestInMemoryTaskManager_ListTasksPagination(t *testing.T) {
+	tm := NewInMemoryTaskManager(newMockHandler())
+	ctx := context.Background()
+
+	var created []string
+	for i := 0; i < 7; i++ {
+		id, err := tm.CreateTask(ctx, "test", nil)
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		created = append(created, id)
+	}
+
+	// Follow the pages until the last one
+	var listed []string
+	var pages int
+	params := &a2a.ListTasksParams{PageSize: 3}
+	for {
+		result, err := tm.OnListTasks(ctx, params)
+		if err != nil {
+			t.Fatalf("OnListTasks failed: %v", err)
+		}
+		pages++
+		if len(result.Tasks) > params.PageSize {
+			t.Errorf("Page %d has %d tasks, more than the page size", pages, len(result.Tasks))
+		}
+		for _, taskObj := range result.Tasks {
+			listed = append(listed, taskObj.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		params.Cursor = result.NextCursor
+	}
+
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if !reflect.DeepEqual(listed, created) {
+		t.Errorf("Expected tasks %v in creation order, got %v", created, listed)
+	}
+
+	// Errors identify the invalid param
+	for _, tt := range []struct {
+		params    a2a.ListTasksParams
+		wantField string
+	}{
+		{a2a.ListTasksParams{Cursor: "not a cursor"}, "cursor"},
+		{a2a.ListTasksParams{PageSize: -1}, "pageSize"},
+	} {
+		_, err := tm.OnListTasks(ctx, &tt.params)
+		var a2aErr *a2a.Error
+		if !errors.As(err, &a2aErr) {
+			t.Fatalf("Expected an a2a error for %+v, got %v", tt.params, err)
+		}
+		if fields := a2aErr.ValidationErrors(); len(fields) != 1 || fields[0].Field != tt.wantField {
+			t.Errorf("Expected an error for %q, got %+v", tt.wantField, fields)
+		}
+	}
+}
+
+func TestInMemoryTaskManager_ListTasksAfterDeletes(t *testing.T) {
+	tm := NewInMemoryTaskManager(newMockHandler())
+	ctx := context.Background()
+
+	var created []string
+	for i := 0; i < 10; i++ {
+		id, err := tm.CreateTask(ctx, "test", nil)
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		created = append(created, id)
+	}
+
+	first, err := tm.OnListTasks(ctx, &a2a.ListTasksParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("OnListTasks failed: %v", err)
+	}
+
+	// Delete enough tasks, including the last one listed, to compact the index
+	var kept []string
+	for i, id := range created {
+		if i%3 == 0 {
+			kept = append(kept, id)
+			continue
+		}
+		if err := tm.DeleteTask(ctx, id); err != nil {
+			t.Fatalf("DeleteTask failed: %v", err)
+		}
+	}
+
+	result, err := tm.OnListTasks(ctx, &a2a.ListTasksParams{PageSize: 10, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("OnListTasks failed: %v", err)
+	}
+	var listed []string
+	for _, taskObj := range result.Tasks {
+		listed = append(listed, taskObj.ID)
+	}
+	if want := kept[1:]; !reflect.DeepEqual(listed, want) {
+		t.Errorf("Expected tasks %v after the cursor, got %v", want, listed)
+	}
+	if result.NextCursor != "" {
+		t.Errorf("Expected no next cursor, got %q", result.NextCursor)
+	}
+}
+
+func TestInMemoryTaskManager_ListTasksSubscribe(t *testing.T) {
+	tm := NewInMemoryTaskManager(newMockHandler())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var existing []string
+	for i := 0; i < 5; i++ {
+		id, err := tm.CreateTask(ctx, "test", nil)
+		if err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		existing = append(existing, id)
+	}
+
+	pages, err := tm.OnListTasksSubscribe(ctx, &a2a.ListTasksParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("OnListTasksSubscribe failed: %v", err)
+	}
+
+	next := func() *a2a.ListTasksResult {
+		t.Helper()
+		select {
+		case page := <-pages:
+			if page.NextCursor == "" {
+				t.Errorf("Expected every streamed page to have a cursor")
+			}
+			return page
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a page")
+			return nil
+		}
+	}
+
+	// The existing tasks arrive a page at a time
+	var listed []string
+	for len(listed) < len(existing) {
+		for _, taskObj := range next().Tasks {
+			listed = append(listed, taskObj.ID)
+		}
+	}
+	if !reflect.DeepEqual(listed, existing) {
+		t.Errorf("Expected tasks %v, got %v", existing, listed)
+	}
+
+	// New tasks follow as they are created
+	id, err := tm.CreateTask(ctx, "test", nil)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if page := next(); len(page.Tasks) != 1 || page.Tasks[0].ID != id {
+		t.Errorf("Expected a page with new task %s, got %+v", id, page.Tasks)
+	}
+
+	// The stream ends when the context is cancelled
+	cancel()
+	select {
+	case _, ok := <-pages:
+		if ok {
+			t.Error("Expected no more pages after cancelling")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the stream to close")
+	}
+}
+
+func TestInMemoryTaskManager_StatusReasons(t *testing.T) {
+	// failWith returns a handler that ends the task with the given status update
+	failWith := func(update task.StatusUpdate) task.Handler {
+		return func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+			updates := make(chan task.YieldUpdate, 1)
+			updates <- update
+			close(updates)
+			return updates, nil
+		}
+	}
+
+	tests := []struct {
+		name          string
+		handler       task.Handler
+		setup         func(tm *InMemoryTaskManager)
+		end           func(t *testing.T, tm *InMemoryTaskManager, taskID string)
+		wantState     a2a.TaskState
+		wantReason    a2a.TaskStatusReason
+		wantRetryable bool
+	}{
+		{
+			name:    "cancelled by user",
+			handler: newHangingHandler(make(chan struct{})),
+			end: func(t *testing.T, tm *InMemoryTaskManager, taskID string) {
+				if _, err := tm.OnCancelTask(context.Background(), &a2a.TaskIdParams{TaskID: taskID}); err != nil {
+					t.Fatalf("OnCancelTask failed: %v", err)
+				}
+			},
+			wantState:  a2a.TaskStateCancelled,
+			wantReason: a2a.TaskStatusReasonUser,
+		},
+		{
+			name:    "cancelled by shutdown",
+			handler: newHangingHandler(make(chan struct{})),
+			end: func(t *testing.T, tm *InMemoryTaskManager, taskID string) {
+				if cancelled := tm.Shutdown(); len(cancelled) != 1 {
+					t.Fatalf("Expected Shutdown to cancel 1 task, got %d", len(cancelled))
+				}
+			},
+			wantState:     a2a.TaskStateCancelled,
+			wantReason:    a2a.TaskStatusReasonShutdown,
+			wantRetryable: true,
+		},
+		{
+			name:          "timed out",
+			handler:       newHangingHandler(make(chan struct{})),
+			setup:         func(tm *InMemoryTaskManager) { tm.SetTaskTimeout(50 * time.Millisecond) },
+			wantState:     a2a.TaskStateFailed,
+			wantReason:    a2a.TaskStatusReasonTimeout,
+			wantRetryable: true,
+		},
+		{
+			name: "handler error",
+			handler: func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+				return nil, errors.New("boom")
+			},
+			wantState:  a2a.TaskStateFailed,
+			wantReason: a2a.TaskStatusReasonError,
+		},
+		{
+			name:       "failure without a reason",
+			handler:    failWith(task.StatusUpdate{State: a2a.TaskStateFailed}),
+			wantState:  a2a.TaskStateFailed,
+			wantReason: a2a.TaskStatusReasonError,
+		},
+		{
+			name:          "retryable failure",
+			handler:       failWith(task.StatusUpdate{State: a2a.TaskStateFailed, Reason: a2a.TaskStatusReasonError, Retryable: true}),
+			wantState:     a2a.TaskStateFailed,
+			wantReason:    a2a.TaskStatusReasonError,
+			wantRetryable: true,
+		},
+		{
+			name: "output limit exceeded",
+			handler: func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+				updates := make(chan task.YieldUpdate, 1)
+				updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: strings.Repeat("x", 200)}}
+				close(updates)
+				return updates, nil
+			},
+			setup:      func(tm *InMemoryTaskManager) { tm.SetMaxOutputBytes(100) },
+			wantState:  a2a.TaskStateFailed,
+			wantReason: a2a.TaskStatusReasonError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewInMemoryTaskManager(tt.handler)
+			if tt.setup != nil {
+				tt.setup(tm)
+			}
+
+			taskObj, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+			if err != nil {
+				t.Fatalf("OnSendTask failed: %v", err)
+			}
+			if tt.end != nil {
+				waitForState(t, tm, taskObj.ID, a2a.TaskStateWorking)
+				tt.end(t, tm, taskObj.ID)
+			}
+
+			final := waitForState(t, tm, taskObj.ID, tt.wantState)
+			if final.Status.Reason != tt.wantReason || final.Status.Retryable != tt.wantRetryable {
+				t.Errorf("Expected reason %q (retryable %v), got %q (retryable %v)",
+					tt.wantReason, tt.wantRetryable, final.Status.Reason, final.Status.Retryable)
+			}
+		})
+	}
+}
+
+func TestInMemoryTaskManager_PushesStatusReason(t *testing.T) {
+	received := make(chan PushNotificationPayload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PushNotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode push notification: %v", err)
+		}
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	tm := NewInMemoryTaskManager(newHangingHandler(make(chan struct{})))
+	ctx := context.Background()
+	taskObj, err := tm.OnSendTask(ctx, &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, taskObj.ID, a2a.TaskStateWorking)
+	if _, err := tm.OnSetTaskPushNotification(ctx, &a2a.TaskPushNotificationConfigParams{TaskID: taskObj.ID, URL: receiver.URL}); err != nil {
+		t.Fatalf("OnSetTaskPushNotification failed: %v", err)
+	}
+
+	tm.Shutdown()
+
+	select {
+	case payload := <-received:
+		if payload.Status == nil || payload.Status.Reason != a2a.TaskStatusReasonShutdown || !payload.Status.Retryable {
+			t.Errorf("Expected a retryable shutdown status, got %+v", payload.Status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the push notification")
+	}
+}
+
+func TestInMemoryTaskManager_ResubscribeStopsWhenContextDone(t *testing.T) {
+	tm := NewInMemoryTaskManager(newHangingHandler(make(chan struct{})))
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := tm.OnResubscribeToTask(ctx, &a2a.TaskIdParams{TaskID: created.ID})
+	if err != nil {
+		t.Fatalf("OnResubscribeToTask failed: %v", err)
+	}
+	<-updates
+
+	// Once the subscriber has gone away, the next status check ends the stream instead of
+	// blocking on a send no one receives
+	cancel()
+	time.Sleep(700 * time.Millisecond)
+	if update, ok := <-updates; ok {
+		t.Errorf("Expected the stream to end, got %+v", update)
+	}
+}
+
+func TestInMemoryTaskManager_ForgetsExpiredIdempotencyKeys(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	tm := NewInMemoryTaskManager(newMockHandler())
+	tm.SetClock(clock)
+	tm.SetIdempotencyTTL(time.Hour)
+
+	send := func(key string) *a2a.Task {
+		t.Helper()
+		task, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+			Message:        newTextMessage(a2a.RoleUser, "hello"),
+			IdempotencyKey: &key,
+		})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		return task
+	}
+
+	for i := 0; i < 10; i++ {
+		send(fmt.Sprintf("key-%d", i))
+	}
+	// A key recorded with a longer TTL outlives keys recorded later with a shorter one
+	tm.SetIdempotencyTTL(3 * time.Hour)
+	long := send("long-key")
+	tm.SetIdempotencyTTL(time.Hour)
+
+	clock.Advance(2 * time.Hour)
+	send("new-key")
+
+	tm.mu.RLock()
+	keys, queued := len(tm.idempotency), len(tm.idemExpiry)
+	tm.mu.RUnlock()
+	if keys != 2 || queued != 2 {
+		t.Errorf("Expected 2 remembered keys, got %d keys and %d queued expiries", keys, queued)
+	}
+	if again := send("long-key"); again.ID != long.ID {
+		t.Errorf("Expected the unexpired key to return task %s, got %s", long.ID, again.ID)
+	}
+}
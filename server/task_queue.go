@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// TaskQueue holds the tasks waiting for a worker to run their handlers. The task manager
+// enqueues a task when it is sent or resumed, and its workers dequeue tasks in turn.
+// MemoryTaskQueue is the default; other implementations can keep the queue in an external
+// system such as a message broker.
+type TaskQueue interface {
+	// Enqueue adds a task to the back of the queue.
+	Enqueue(ctx context.Context, queued QueuedTask) error
+
+	// Dequeue removes the task at the front of the queue, waiting until there is one or ctx is done.
+	Dequeue(ctx context.Context) (QueuedTask, error)
+}
+
+// QueuedTask is a task waiting in a TaskQueue for its handler to run. It describes the task
+// on its own and can be encoded as JSON, so queues may keep it outside the process: the
+// worker that dequeues it looks the task up by ID and rebuilds the handler's context from
+// the stored task and the request fields kept here.
+type QueuedTask struct {
+	TaskID       string            `json:"taskId"`
+	SkillID      string            `json:"skillId,omitempty"`      // Skill requested by the client
+	UserMessage  a2a.Message       `json:"userMessage"`            // Message the task was sent or resumed with
+	Metadata     interface{}       `json:"metadata,omitempty"`     // Metadata sent with the task request
+	TraceHeaders map[string]string `json:"traceHeaders,omitempty"` // Trace headers of the originating request
+	OutputModes  []string          `json:"outputModes,omitempty"`  // Output MIME types the client accepts
+	Deadline     time.Time         `json:"deadline"`               // When the originating request times out (zero = none)
+	Timeout      time.Duration     `json:"timeout,omitempty"`      // Maximum time the task may run (0 = no limit)
+	Identity     *task.Identity    `json:"identity,omitempty"`     // Authenticated caller that sent the task
+}
+
+// newQueuedTask returns the queued task for a handler's context.
+func newQueuedTask(taskCtx task.Context) QueuedTask {
+	return QueuedTask{
+		TaskID:       taskCtx.TaskID,
+		SkillID:      taskCtx.SkillID,
+		UserMessage:  taskCtx.UserMessage,
+		Metadata:     taskCtx.Metadata,
+		TraceHeaders: taskCtx.TraceHeaders,
+		OutputModes:  taskCtx.OutputModes,
+		Deadline:     taskCtx.Deadline,
+		Timeout:      taskCtx.Timeout,
+		Identity:     taskCtx.Identity,
+	}
+}
+
+// taskContext returns the context the queued task's handler is called with, taking the
+// session from the stored task.
+func (q QueuedTask) taskContext(taskObj *a2a.Task) task.Context {
+	taskCtx := task.Context{
+		TaskID:       q.TaskID,
+		SkillID:      q.SkillID,
+		UserMessage:  q.UserMessage,
+		Metadata:     q.Metadata,
+		TraceHeaders: q.TraceHeaders,
+		OutputModes:  q.OutputModes,
+		Deadline:     q.Deadline,
+		Timeout:      q.Timeout,
+		Identity:     q.Identity,
+	}
+	if taskObj.SessionID != nil {
+		taskCtx.SessionID = *taskObj.SessionID
+	}
+	return taskCtx
+}
+
+// queueRetryDelay is how long a worker waits before dequeuing again after Dequeue fails.
+const queueRetryDelay = time.Second
+
+// MemoryTaskQueue is an unbounded, first-in first-out TaskQueue held in memory. Queued
+// tasks are lost if the process exits.
+type MemoryTaskQueue struct {
+	tasks []QueuedTask
+	ready chan struct{} // Signalled when tasks are waiting
+	mu    sync.Mutex
+}
+
+// NewMemoryTaskQueue creates an empty MemoryTaskQueue.
+func NewMemoryTaskQueue() *MemoryTaskQueue {
+	return &MemoryTaskQueue{ready: make(chan struct{}, 1)}
+}
+
+// Enqueue adds a task to the back of the queue.
+func (q *MemoryTaskQueue) Enqueue(ctx context.Context, queued QueuedTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tasks = append(q.tasks, queued)
+	q.signal()
+	return nil
+}
+
+// Dequeue removes the task at the front of the queue, waiting until there is one or ctx is done.
+func (q *MemoryTaskQueue) Dequeue(ctx context.Context) (QueuedTask, error) {
+	for {
+		q.mu.Lock()
+		if len(q.tasks) > 0 {
+			queued := q.tasks[0]
+			q.tasks[0] = QueuedTask{}
+			q.tasks = q.tasks[1:]
+			if len(q.tasks) > 0 {
+				// Wake the next waiting worker
+				q.signal()
+			}
+			q.mu.Unlock()
+			return queued, nil
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return QueuedTask{}, ctx.Err()
+		}
+	}
+}
+
+// Len returns the number of tasks in the queue.
+func (q *MemoryTaskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
+
+// signal wakes a worker waiting in Dequeue, if there is one. The caller must hold q.mu.
+func (q *MemoryTaskQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// queuedRun is a task this task manager enqueued and waits on to forward its handler's updates.
+type queuedRun struct {
+	ctx       context.Context // Context the handler is called with
+	started   chan startedRun // Receives the handler's updates once a worker starts it
+	cancelled chan struct{}   // Closed if the task is cancelled while queued
+}
+
+// startedRun is the result of starting a queued task's handler.
+type startedRun struct {
+	updates <-chan task.YieldUpdate
+	err     error
+}
+
+// startWorkers starts the workers running queued tasks, the first time it is called. With
+// a worker limit, that many workers each run one task at a time; without one, a single
+// worker starts every task as soon as it is dequeued.
+func (tm *InMemoryTaskManager) startWorkers() {
+	tm.workersOnce.Do(func() {
+		tm.mu.RLock()
+		workers, queue := tm.workers, tm.queue
+		tm.mu.RUnlock()
+
+		if workers == 0 {
+			go tm.work(queue, false)
+			return
+		}
+		for i := 0; i < workers; i++ {
+			go tm.work(queue, true)
+		}
+	})
+}
+
+// work runs the tasks dequeued from queue until the task manager is shut down. If wait is
+// set, it runs one task at a time, waiting for each task's handler to finish before
+// dequeuing the next task.
+func (tm *InMemoryTaskManager) work(queue TaskQueue, wait bool) {
+	for {
+		queued, err := queue.Dequeue(tm.stopCtx)
+		if err != nil {
+			if tm.stopCtx.Err() != nil {
+				return
+			}
+			fmt.Printf("Failed to dequeue task: %v\n", err)
+			select {
+			case <-time.After(queueRetryDelay):
+			case <-tm.stopCtx.Done():
+				return
+			}
+			continue
+		}
+
+		// Skip tasks cancelled or evicted while queued
+		tm.mu.Lock()
+		taskObj, ok := tm.tasks[queued.TaskID]
+		run := tm.queued[queued.TaskID]
+		delete(tm.queued, queued.TaskID)
+		if ok && isActiveState(taskObj.Status.State) {
+			tm.busy++
+		} else {
+			ok = false
+		}
+		tm.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		taskCtx := queued.taskContext(taskObj)
+		ctx := task.WithIdentity(context.Background(), taskCtx.Identity)
+		if run != nil {
+			ctx = run.ctx
+		}
+		done := make(chan struct{})
+		start := func() {
+			updates, err := tm.startTaskHandler(ctx, taskCtx, func() {
+				tm.mu.Lock()
+				tm.busy--
+				tm.mu.Unlock()
+				close(done)
+			})
+			if run != nil {
+				run.started <- startedRun{updates: updates, err: err}
+				return
+			}
+			// Nothing in this process waits on the task, e.g. another process queued it,
+			// so the worker records its updates
+			if err != nil {
+				tm.failTask(queued.TaskID, taskObj, err)
+				return
+			}
+			tm.processTaskUpdates(queued.TaskID, taskObj, updates)
+		}
+		if !wait {
+			// Handlers may do their work before returning, so each gets its own goroutine
+			go start()
+			continue
+		}
+		start()
+		<-done
+	}
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// recordingQueue is a MemoryTaskQueue that records the IDs of the tasks enqueued and dequeued.
+type recordingQueue struct {
+	*MemoryTaskQueue
+
+	mu       sync.Mutex
+	enqueued []string
+	dequeued []string
+}
+
+func (q *recordingQueue) Enqueue(ctx context.Context, queued QueuedTask) error {
+	q.mu.Lock()
+	q.enqueued = append(q.enqueued, queued.TaskID)
+	q.mu.Unlock()
+	return q.MemoryTaskQueue.Enqueue(ctx, queued)
+}
+
+func (q *recordingQueue) Dequeue(ctx context.Context) (QueuedTask, error) {
+	queued, err := q.MemoryTaskQueue.Dequeue(ctx)
+	if err == nil {
+		q.mu.Lock()
+		q.dequeued = append(q.dequeued, queued.TaskID)
+		q.mu.Unlock()
+	}
+	return queued, err
+}
+
+func TestMemoryTaskQueue(t *testing.T) {
+	q := NewMemoryTaskQueue()
+	for _, id := range []string{"task-1", "task-2", "task-3"} {
+		if err := q.Enqueue(context.Background(), QueuedTask{TaskID: id}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	if n := q.Len(); n != 3 {
+		t.Errorf("Expected 3 queued tasks, got %d", n)
+	}
+
+	// Tasks are dequeued in the order they were enqueued
+	for _, want := range []string{"task-1", "task-2", "task-3"} {
+		queued, err := q.Dequeue(context.Background())
+		if err != nil {
+			t.Fatalf("Dequeue failed: %v", err)
+		}
+		if queued.TaskID != want {
+			t.Errorf("Expected %s, got %s", want, queued.TaskID)
+		}
+	}
+
+	// Dequeue waits for a task
+	dequeued := make(chan string)
+	go func() {
+		queued, _ := q.Dequeue(context.Background())
+		dequeued <- queued.TaskID
+	}()
+	time.Sleep(20 * time.Millisecond)
+	q.Enqueue(context.Background(), QueuedTask{TaskID: "task-4"})
+	select {
+	case id := <-dequeued:
+		if id != "task-4" {
+			t.Errorf("Expected task-4, got %s", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Dequeue to return the task enqueued while it waited")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Dequeue(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected Dequeue on an empty queue to stop when ctx is done, got %v", err)
+	}
+}
+
+func TestInMemoryTaskManager_TaskWorkers(t *testing.T) {
+	const workers = 3
+	const numTasks = 9
+
+	release := make(chan struct{})
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+	queue := &recordingQueue{MemoryTaskQueue: NewMemoryTaskQueue()}
+	tm.SetTaskQueue(queue)
+	tm.SetMaxConcurrentTasks(workers, QueueTasks)
+
+	ids := make([]string, numTasks)
+	for i := range ids {
+		created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+			Message: newTextMessage(a2a.RoleUser, "hello"),
+		})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		ids[i] = created.ID
+	}
+
+	// Every worker picks up a task, and the rest wait in the queue
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&started) < workers && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&started); n != workers {
+		t.Fatalf("Expected %d handlers to start, one per worker, got %d", workers, n)
+	}
+	if n := queue.Len(); n != numTasks-workers {
+		t.Errorf("Expected %d tasks left in the queue, got %d", numTasks-workers, n)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForState(t, tm, id, a2a.TaskStateCompleted)
+	}
+
+	if n := atomic.LoadInt32(&maxRunning); n != workers {
+		t.Errorf("Expected the %d workers to run handlers at once, got at most %d", workers, n)
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if len(queue.enqueued) != numTasks || len(queue.dequeued) != numTasks {
+		t.Errorf("Expected all %d tasks to pass through the queue, got %d enqueued and %d dequeued", numTasks, len(queue.enqueued), len(queue.dequeued))
+	}
+}
+
+func TestInMemoryTaskManager_UnlimitedTaskWorkers(t *testing.T) {
+	const numTasks = 5
+
+	release := make(chan struct{})
+	var running, maxRunning, started int32
+	tm := NewInMemoryTaskManager(newBlockingHandler(release, &running, &maxRunning, &started))
+
+	ids := make([]string, numTasks)
+	for i := range ids {
+		created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{
+			Message: newTextMessage(a2a.RoleUser, "hello"),
+		})
+		if err != nil {
+			t.Fatalf("OnSendTask failed: %v", err)
+		}
+		ids[i] = created.ID
+	}
+
+	// Without a limit, every task's handler starts without waiting for another to finish
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&started) < numTasks && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&started); n != numTasks {
+		t.Errorf("Expected all %d handlers to start, got %d", numTasks, n)
+	}
+
+	close(release)
+	for _, id := range ids {
+		waitForState(t, tm, id, a2a.TaskStateCompleted)
+	}
+}
+
+func TestInMemoryTaskManager_QueuedTaskWithoutWaiter(t *testing.T) {
+	received := make(chan task.Context, 1)
+	tm := NewInMemoryTaskManager(func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		received <- taskCtx
+		updates := make(chan task.YieldUpdate, 2)
+		updates <- task.ArtifactUpdate{Part: a2a.TextPart{Type: "text", Text: "done"}}
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	})
+	tm.SetMaxConcurrentTasks(1, QueueTasks)
+
+	// A task stored and queued elsewhere, e.g. by another process sharing the queue, passes
+	// through the queue as JSON
+	sessionID := "session-1"
+	tm.mu.Lock()
+	tm.storeTask(&a2a.Task{
+		ID:        "task-1",
+		SessionID: &sessionID,
+		Status:    a2a.TaskStatus{State: a2a.TaskStateSubmitted, Timestamp: time.Now()},
+		History:   []a2a.Message{newTextMessage(a2a.RoleUser, "hello")},
+	})
+	tm.mu.Unlock()
+	data, err := json.Marshal(newQueuedTask(task.Context{
+		TaskID:      "task-1",
+		SkillID:     "greet",
+		UserMessage: newTextMessage(a2a.RoleUser, "hello"),
+		Timeout:     time.Minute,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to encode queued task: %v", err)
+	}
+	var queued QueuedTask
+	if err := json.Unmarshal(data, &queued); err != nil {
+		t.Fatalf("Failed to decode queued task: %v", err)
+	}
+	tm.queue.Enqueue(context.Background(), queued)
+	tm.startWorkers()
+
+	taskCtx := <-received
+	if taskCtx.SessionID != sessionID || taskCtx.SkillID != "greet" || taskCtx.Timeout != time.Minute || taskCtx.AllText() != "hello" {
+		t.Errorf("Expected the context to be rebuilt from the stored task and queued task, got %+v", taskCtx)
+	}
+
+	// The worker records the handler's updates itself
+	taskObj := waitForState(t, tm, "task-1", a2a.TaskStateCompleted)
+	if len(taskObj.Artifacts) != 1 {
+		t.Errorf("Expected the artifact to be stored, got %d", len(taskObj.Artifacts))
+	}
+}
+
+func TestInMemoryTaskManager_ShutdownStopsWorkers(t *testing.T) {
+	tm := NewInMemoryTaskManager(newMockHandler())
+	queue := &stoppableQueue{MemoryTaskQueue: NewMemoryTaskQueue(), stopped: make(chan struct{})}
+	tm.SetTaskQueue(queue)
+	tm.SetMaxConcurrentTasks(2, QueueTasks)
+
+	created, err := tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, created.ID, a2a.TaskStateCompleted)
+
+	tm.Shutdown()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-queue.stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the workers to stop dequeuing once the task manager shut down")
+		}
+	}
+
+	created, err = tm.OnSendTask(context.Background(), &a2a.TaskSendParams{Message: newTextMessage(a2a.RoleUser, "hello")})
+	if err != nil {
+		t.Fatalf("OnSendTask failed: %v", err)
+	}
+	waitForState(t, tm, created.ID, a2a.TaskStateFailed)
+}
+
+// stoppableQueue is a MemoryTaskQueue that signals each Dequeue stopped by its context.
+type stoppableQueue struct {
+	*MemoryTaskQueue
+	stopped chan struct{}
+}
+
+func (q *stoppableQueue) Dequeue(ctx context.Context) (QueuedTask, error) {
+	queued, err := q.MemoryTaskQueue.Dequeue(ctx)
+	if err != nil && ctx.Err() != nil {
+		q.stopped <- struct{}{}
+	}
+	return queued, err
+}
@@ -25,11 +25,12 @@ type AgentEngine interface {
 // TaskManager defines the interface for task management operations.
 type TaskManager interface {
 	OnSendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error)
-	OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (<-chan task.YieldUpdate, error)
+	OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (string, <-chan task.YieldUpdate, error)
 	OnGetTask(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error)
 	OnCancelTask(ctx context.Context, params *a2a.TaskIdParams) (*a2a.Task, error)
 	OnSetTaskPushNotification(ctx context.Context, params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error)
 	OnGetTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) (*a2a.PushNotificationConfig, error)
+	OnDeleteTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) error
 	OnResubscribeToTask(ctx context.Context, params *a2a.TaskIdParams) (<-chan task.YieldUpdate, error)
 }
 
@@ -123,10 +124,10 @@ func (m *MockTaskManager) OnSendTask(ctx context.Context, params *a2a.TaskSendPa
 	return &a2a.Task{ID: "test-task"}, nil
 }
 
-func (m *MockTaskManager) OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (<-chan task.YieldUpdate, error) {
+func (m *MockTaskManager) OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (string, <-chan task.YieldUpdate, error) {
 	updateChan := make(chan task.YieldUpdate)
 	close(updateChan)
-	return updateChan, nil
+	return "test-task", updateChan, nil
 }
 
 func (m *MockTaskManager) OnGetTask(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error) {
@@ -149,6 +150,18 @@ func (m *MockTaskManager) OnCancelTask(ctx context.Context, params *a2a.TaskIdPa
 	return task, nil
 }
 
+func (m *MockTaskManager) OnCancelSession(ctx context.Context, params *a2a.SessionIdParams) ([]*a2a.Task, error) {
+	m.Lock()
+	defer m.Unlock()
+	var tasks []*a2a.Task
+	for _, task := range m.Tasks {
+		if task.SessionID != nil && *task.SessionID == params.SessionID {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
 func (m *MockTaskManager) OnSetTaskPushNotification(ctx context.Context, params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error) {
 	config := &a2a.PushNotificationConfig{
 		TaskID: params.TaskID,
@@ -170,6 +183,13 @@ func (m *MockTaskManager) OnGetTaskPushNotification(ctx context.Context, params
 	return config, nil
 }
 
+func (m *MockTaskManager) OnDeleteTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.PushConfigs, params.TaskID)
+	return nil
+}
+
 func (m *MockTaskManager) OnResubscribeToTask(ctx context.Context, params *a2a.TaskIdParams) (<-chan task.YieldUpdate, error) {
 	updateChan := make(chan task.YieldUpdate)
 	close(updateChan)
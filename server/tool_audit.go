@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// ToolAuditArtifactType is the "type" in the metadata of tool audit artifacts, telling them
+// apart from tool result artifacts.
+const ToolAuditArtifactType = "tool_audit"
+
+// maxAuditResultLen is the maximum length in bytes of the result summary in a ToolAuditRecord.
+const maxAuditResultLen = 1024
+
+// ToolAuditRecord records a tool call made by an agent, for compliance. Agents with tool
+// auditing enabled send one for every call as a DataPart artifact, whether the call succeeds
+// or fails, in addition to the tool's result.
+type ToolAuditRecord struct {
+	Tool      string                 `json:"tool"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	StartedAt time.Time              `json:"startedAt"`
+	// DurationSeconds is how long the call took
+	DurationSeconds float64 `json:"durationSeconds"`
+	// Result is the JSON of the tool's result, truncated to 1 KiB (empty if the call failed)
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"` // Why the call failed (empty if it succeeded)
+}
+
+// newToolAuditRecord records a call to tool with params, started at startedAt, that returned
+// result and err.
+func newToolAuditRecord(tool string, params map[string]interface{}, startedAt time.Time, result interface{}, err error) ToolAuditRecord {
+	record := ToolAuditRecord{
+		Tool:            tool,
+		Params:          params,
+		StartedAt:       startedAt,
+		DurationSeconds: time.Since(startedAt).Seconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	summary, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		record.Error = "failed to marshal result: " + marshalErr.Error()
+		return record
+	}
+	record.Result = string(summary)
+	if len(record.Result) > maxAuditResultLen {
+		// Cut on a rune boundary so the summary stays valid UTF-8
+		cut := maxAuditResultLen
+		for cut > 0 && !utf8.RuneStart(record.Result[cut]) {
+			cut--
+		}
+		record.Result = record.Result[:cut] + "..."
+	}
+	return record
+}
+
+// artifact returns the artifact update carrying the record.
+func (r ToolAuditRecord) artifact() task.ArtifactUpdate {
+	return task.ArtifactUpdate{
+		Part: a2a.DataPart{
+			Type:     "data",
+			MimeType: "application/json",
+			Data:     r,
+		},
+		Metadata: map[string]interface{}{
+			"type": ToolAuditArtifactType,
+			"tool": r.Tool,
+		},
+	}
+}
+
+// toolAuditor is implemented by agent engines that can record the tool calls they make.
+type toolAuditor interface {
+	SetToolAudit(enabled bool)
+}
+
+// enableToolAudit turns on tool auditing for engine and skillEngines. It returns an error if
+// none of them can audit tool calls, so auditing is never silently missing.
+func enableToolAudit(engine AgentEngine, skillEngines map[string]AgentEngine) error {
+	audited := false
+	if auditor, ok := engine.(toolAuditor); ok {
+		auditor.SetToolAudit(true)
+		audited = true
+	}
+	for _, skillEngine := range skillEngines {
+		if auditor, ok := skillEngine.(toolAuditor); ok {
+			auditor.SetToolAudit(true)
+			audited = true
+		}
+	}
+	if !audited {
+		return errors.New("tool audit is enabled but no agent engine supports it")
+	}
+	return nil
+}
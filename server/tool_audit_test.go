@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// runToolCall processes a task with an MCPToolAugmentedAgent whose LLM asks for the weather
+// tool of client, and returns the artifacts and final state.
+func runToolCall(t *testing.T, client *fakeMCPClient, audit bool) ([]task.ArtifactUpdate, a2a.TaskState) {
+	t.Helper()
+
+	fake := &fakeLLM{stream: `{"tool": "weather", "params": {"city": "Melbourne"}}`, reply: "It is sunny."}
+	agent, err := NewMCPToolAugmentedAgent(fake, client)
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	agent.SetToolAudit(audit)
+
+	updates, err := agent.ProcessTask(context.Background(), task.Context{
+		TaskID:      "task-1",
+		UserMessage: newTextMessage(a2a.RoleUser, "What's the weather in Melbourne?"),
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask failed: %v", err)
+	}
+
+	var artifacts []task.ArtifactUpdate
+	var state a2a.TaskState
+	for update := range updates {
+		switch u := update.(type) {
+		case task.ArtifactUpdate:
+			artifacts = append(artifacts, u)
+		case task.StatusUpdate:
+			state = u.State
+		}
+	}
+	return artifacts, state
+}
+
+// auditRecords returns the tool audit records among artifacts.
+func auditRecords(t *testing.T, artifacts []task.ArtifactUpdate) []ToolAuditRecord {
+	t.Helper()
+
+	var records []ToolAuditRecord
+	for _, artifact := range artifacts {
+		if metadata, _ := artifact.Metadata.(map[string]interface{}); metadata["type"] != ToolAuditArtifactType {
+			continue
+		}
+		part, ok := artifact.Part.(a2a.DataPart)
+		if !ok {
+			t.Fatalf("Expected a DataPart audit artifact, got %T", artifact.Part)
+		}
+		record, ok := part.Data.(ToolAuditRecord)
+		if !ok {
+			t.Fatalf("Expected a ToolAuditRecord, got %T", part.Data)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestMCPToolAugmentedAgent_ToolAudit(t *testing.T) {
+	client := &fakeMCPClient{results: map[string]interface{}{
+		"weather": map[string]interface{}{"temperature": 21.5},
+	}}
+	artifacts, state := runToolCall(t, client, true)
+	if state != a2a.TaskStateCompleted {
+		t.Fatalf("Expected the task to complete, got %s", state)
+	}
+
+	records := auditRecords(t, artifacts)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Tool != "weather" || record.Params["city"] != "Melbourne" {
+		t.Errorf("Expected the audit record to name the tool and params, got %+v", record)
+	}
+	if !strings.Contains(record.Result, "21.5") || record.Error != "" {
+		t.Errorf("Expected the audit record to summarise the result, got %+v", record)
+	}
+	if record.StartedAt.IsZero() || record.DurationSeconds < 0 {
+		t.Errorf("Expected the audit record to time the call, got %+v", record)
+	}
+
+	// The result artifact is still sent
+	if len(artifacts) != 2 {
+		t.Errorf("Expected the audit and result artifacts, got %d artifacts", len(artifacts))
+	}
+}
+
+func TestMCPToolAugmentedAgent_ToolAuditError(t *testing.T) {
+	client := &fakeMCPClient{errs: map[string]error{"weather": errors.New("service unavailable")}}
+	artifacts, state := runToolCall(t, client, true)
+	if state != a2a.TaskStateFailed {
+		t.Fatalf("Expected the task to fail, got %s", state)
+	}
+
+	records := auditRecords(t, artifacts)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	if record := records[0]; record.Tool != "weather" || record.Error != "service unavailable" || record.Result != "" {
+		t.Errorf("Expected the audit record to hold the error, got %+v", record)
+	}
+}
+
+func TestMCPToolAugmentedAgent_ToolAuditDisabled(t *testing.T) {
+	client := &fakeMCPClient{results: map[string]interface{}{"weather": "sunny"}}
+	artifacts, _ := runToolCall(t, client, false)
+	if records := auditRecords(t, artifacts); len(records) != 0 {
+		t.Errorf("Expected no audit records, got %+v", records)
+	}
+	if len(artifacts) != 1 {
+		t.Errorf("Expected only the result artifact, got %d artifacts", len(artifacts))
+	}
+}
+
+func TestNewToolAuditRecord_TruncatesOnRuneBoundary(t *testing.T) {
+	// The marshalled result is a quote followed by three-byte runes, so the limit falls mid-rune
+	result := strings.Repeat("€", maxAuditResultLen)
+	record := newToolAuditRecord("echo", nil, time.Now(), result, nil)
+
+	if !strings.HasSuffix(record.Result, "...") {
+		t.Fatalf("Expected the result to be truncated, got %d bytes", len(record.Result))
+	}
+	if len(record.Result) > maxAuditResultLen+len("...") {
+		t.Errorf("Expected at most %d bytes, got %d", maxAuditResultLen+len("..."), len(record.Result))
+	}
+	if !utf8.ValidString(record.Result) {
+		t.Error("Expected the truncated result to be valid UTF-8")
+	}
+}
+
+func TestWithToolAudit(t *testing.T) {
+	agent, err := NewMCPToolAugmentedAgent(&fakeLLM{}, &fakeMCPClient{})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	card := WithAgentCard(&a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"})
+	if _, err := NewServer(card, WithToolAudit(true), WithAgentEngine(agent)); err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if !agent.auditTools {
+		t.Error("Expected WithToolAudit to enable auditing on the agent engine")
+	}
+
+	// An engine that makes no tool calls cannot be audited
+	basic := NewBasicLLMAgent(&fakeLLM{}, "You are a helpful assistant.")
+	if _, err := NewServer(card, WithAgentEngine(basic), WithToolAudit(true)); err == nil {
+		t.Error("Expected an error when no agent engine supports tool audit")
+	}
+}
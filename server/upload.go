@@ -0,0 +1,358 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+)
+
+// uploadTTL is how long an upload may go without receiving a chunk before it is discarded.
+const uploadTTL = time.Hour
+
+// completedUploadTTL is how long a completed upload is kept for tasks to reference.
+const completedUploadTTL = 24 * time.Hour
+
+// uploadURIPrefix starts the URIs of completed uploads, which tasks/send resolves.
+const uploadURIPrefix = "uploads/"
+
+// DefaultMaxUploadSize is the largest file that can be uploaded when no limit is configured.
+const DefaultMaxUploadSize = 100 << 20
+
+// DefaultMaxUploads is the number of uploads that may be in progress at once when no limit
+// is configured.
+const DefaultMaxUploads = 100
+
+// upload is a chunked file upload in progress. Its mutex serializes chunk writes and
+// completion; it is taken before uploadManager.mu when both are needed.
+type upload struct {
+	params  a2a.InitUploadParams
+	maxSize int64     // Largest size the upload may reach
+	updated time.Time // When the last chunk was received; guarded by uploadManager.mu
+
+	mu     sync.Mutex
+	chunks []string // URIs of the stored chunks, in order
+	sizes  []int64  // Sizes of the stored chunks
+	done   bool     // Completed or expired; no more chunks are accepted
+}
+
+// status returns the upload's progress. The caller must hold u.mu.
+func (u *upload) status(uploadID string) a2a.UploadStatus {
+	status := a2a.UploadStatus{UploadID: uploadID, NextChunk: len(u.chunks)}
+	for _, size := range u.sizes {
+		status.ReceivedBytes += size
+	}
+	return status
+}
+
+// completedUpload is an uploaded file, kept in the artifact store until it expires.
+type completedUpload struct {
+	uri     string // URI of the file in the artifact store
+	size    int64
+	expires time.Time
+}
+
+// uploadManager tracks chunked file uploads, keeping their chunks, and the completed files,
+// in an artifact store. Each upload is stored as if it were a task with the upload's ID.
+type uploadManager struct {
+	store      ArtifactStore
+	maxSize    int64 // Largest file that can be uploaded
+	maxUploads int   // Number of uploads that may be in progress at once
+
+	mu        sync.Mutex
+	uploads   map[string]*upload
+	completed map[string]completedUpload // Map of upload ID to the completed file
+}
+
+// newUploadManager creates an upload manager that stores uploads in store, with the given
+// limits (0 = the defaults).
+func newUploadManager(store ArtifactStore, maxSize int64, maxUploads int) *uploadManager {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxUploadSize
+	}
+	if maxUploads <= 0 {
+		maxUploads = DefaultMaxUploads
+	}
+	return &uploadManager{
+		store:      store,
+		maxSize:    maxSize,
+		maxUploads: maxUploads,
+		uploads:    make(map[string]*upload),
+		completed:  make(map[string]completedUpload),
+	}
+}
+
+// init starts an upload. The upload may not grow beyond the manager's size limit, nor
+// beyond maxInput if it is set, as the file becomes a task's input.
+func (m *uploadManager) init(params *a2a.InitUploadParams, maxInput int64) (a2a.UploadStatus, *a2a.Error) {
+	maxSize := m.maxSize
+	if maxInput > 0 && maxInput < maxSize {
+		maxSize = maxInput
+	}
+
+	var fields []a2a.FieldError
+	if params.Filename == "" {
+		fields = append(fields, a2a.FieldError{Field: "filename", Reason: "is required"})
+	}
+	if params.Size < 0 {
+		fields = append(fields, a2a.FieldError{Field: "size", Reason: "must not be negative"})
+	}
+	if params.Size > maxSize {
+		fields = append(fields, a2a.FieldError{Field: "size", Reason: fmt.Sprintf("exceeds the maximum upload size of %d bytes", maxSize)})
+	}
+	if len(fields) > 0 {
+		return a2a.UploadStatus{}, a2a.ErrValidation(fields...)
+	}
+
+	uploadID, genErr := generateUploadID()
+	if genErr != nil {
+		return a2a.UploadStatus{}, a2a.ErrInternalError(genErr)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneExpired()
+	if len(m.uploads) >= m.maxUploads {
+		return a2a.UploadStatus{}, a2a.ErrRateLimitExceeded()
+	}
+	m.uploads[uploadID] = &upload{params: *params, maxSize: maxSize, updated: time.Now()}
+	return a2a.UploadStatus{UploadID: uploadID}, nil
+}
+
+// putChunk stores a chunk of an upload. The chunk must be the next one expected, or one
+// already received, which is replaced.
+func (m *uploadManager) putChunk(ctx context.Context, params *a2a.UploadChunkParams) (a2a.UploadStatus, *a2a.Error) {
+	data, decodeErr := base64.StdEncoding.DecodeString(params.Data)
+	if decodeErr != nil {
+		return a2a.UploadStatus{}, a2a.ErrValidation(a2a.FieldError{Field: "data", Reason: "must be base64-encoded"})
+	}
+	if params.Index < 0 {
+		return a2a.UploadStatus{}, a2a.ErrValidation(a2a.FieldError{Field: "index", Reason: "must not be negative"})
+	}
+
+	u, a2aErr := m.get(params.UploadID)
+	if a2aErr != nil {
+		return a2a.UploadStatus{}, a2aErr
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return a2a.UploadStatus{}, a2a.ErrUploadNotFound(params.UploadID)
+	}
+
+	status := u.status(params.UploadID)
+	if params.Index > status.NextChunk {
+		return a2a.UploadStatus{}, a2a.ErrUploadChunkOutOfOrder(params.Index, status)
+	}
+
+	size := status.ReceivedBytes + int64(len(data))
+	if params.Index < status.NextChunk {
+		size -= u.sizes[params.Index]
+	}
+	if u.params.Size > 0 && size > u.params.Size {
+		return a2a.UploadStatus{}, a2a.ErrValidation(a2a.FieldError{Field: "data", Reason: fmt.Sprintf("exceeds the upload size of %d bytes", u.params.Size)})
+	}
+	if size > u.maxSize {
+		return a2a.UploadStatus{}, a2a.ErrValidation(a2a.FieldError{Field: "data", Reason: fmt.Sprintf("exceeds the maximum upload size of %d bytes", u.maxSize)})
+	}
+
+	uri, err := m.store.Put(ctx, params.UploadID, fmt.Sprintf("chunk_%06d", params.Index), data)
+	if err != nil {
+		return a2a.UploadStatus{}, a2a.ErrInternalError(fmt.Errorf("failed to store upload chunk: %w", err))
+	}
+	if params.Index == len(u.chunks) {
+		u.chunks = append(u.chunks, uri)
+		u.sizes = append(u.sizes, int64(len(data)))
+	} else {
+		u.chunks[params.Index] = uri
+		u.sizes[params.Index] = int64(len(data))
+	}
+	m.mu.Lock()
+	u.updated = time.Now()
+	m.mu.Unlock()
+
+	return u.status(params.UploadID), nil
+}
+
+// complete joins the chunks of an upload into a single file in the store and returns a
+// FilePart referencing it, whose URI tasks/send resolves to the file until it expires. The
+// chunks are read one at a time, and streamed into the file if the store supports it. The
+// chunks are deleted if the store supports it.
+func (m *uploadManager) complete(ctx context.Context, params *a2a.CompleteUploadParams) (a2a.FilePart, *a2a.Error) {
+	u, a2aErr := m.get(params.UploadID)
+	if a2aErr != nil {
+		return a2a.FilePart{}, a2aErr
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.done {
+		return a2a.FilePart{}, a2a.ErrUploadNotFound(params.UploadID)
+	}
+
+	status := u.status(params.UploadID)
+	if u.params.Size > 0 && status.ReceivedBytes != u.params.Size {
+		return a2a.FilePart{}, a2a.ErrValidation(a2a.FieldError{
+			Field:  "uploadId",
+			Reason: fmt.Sprintf("received %d of %d bytes", status.ReceivedBytes, u.params.Size),
+		})
+	}
+
+	var uri string
+	var err error
+	chunks := &chunkReader{ctx: ctx, store: m.store, uris: u.chunks}
+	if streamer, ok := m.store.(artifactStreamer); ok {
+		uri, err = streamer.PutStream(ctx, params.UploadID, "file", chunks)
+	} else {
+		var data []byte
+		if data, err = io.ReadAll(chunks); err == nil {
+			uri, err = m.store.Put(ctx, params.UploadID, "file", data)
+		}
+	}
+	if err != nil {
+		return a2a.FilePart{}, a2a.ErrInternalError(fmt.Errorf("failed to store upload: %w", err))
+	}
+
+	u.done = true
+	m.mu.Lock()
+	delete(m.uploads, params.UploadID)
+	m.completed[params.UploadID] = completedUpload{
+		uri:     uri,
+		size:    status.ReceivedBytes,
+		expires: time.Now().Add(completedUploadTTL),
+	}
+	m.mu.Unlock()
+	m.deleteChunks(ctx, u)
+
+	fileURI := uploadURIPrefix + params.UploadID
+	return a2a.FilePart{
+		Type:     "file",
+		Filename: u.params.Filename,
+		MimeType: u.params.MimeType,
+		URI:      &fileURI,
+	}, nil
+}
+
+// resolve returns the content of the completed upload a URI returned by complete refers
+// to. ok is false if the URI does not refer to an upload.
+func (m *uploadManager) resolve(ctx context.Context, uri string) (data []byte, ok bool, a2aErr *a2a.Error) {
+	uploadID, ok := strings.CutPrefix(uri, uploadURIPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+
+	m.mu.Lock()
+	m.pruneExpired()
+	file, found := m.completed[uploadID]
+	m.mu.Unlock()
+	if !found {
+		return nil, true, a2a.ErrUploadNotFound(uploadID)
+	}
+
+	data, err := m.store.Get(ctx, file.uri)
+	if err != nil {
+		return nil, true, a2a.ErrInternalError(fmt.Errorf("failed to read upload: %w", err))
+	}
+	return data, true, nil
+}
+
+// get returns an upload that has not expired.
+func (m *uploadManager) get(uploadID string) (*upload, *a2a.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.uploads[uploadID]
+	if !ok {
+		return nil, a2a.ErrUploadNotFound(uploadID)
+	}
+	return u, nil
+}
+
+// pruneExpired discards uploads that have not received a chunk within uploadTTL, and
+// deletes completed uploads that have expired. The caller must hold m.mu.
+func (m *uploadManager) pruneExpired() {
+	for uploadID, u := range m.uploads {
+		if time.Since(u.updated) <= uploadTTL {
+			continue
+		}
+		delete(m.uploads, uploadID)
+
+		// u.mu may be held by a chunk write waiting for m.mu, so it is not taken here
+		go func(u *upload) {
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			u.done = true
+			m.deleteChunks(context.Background(), u)
+		}(u)
+	}
+
+	now := time.Now()
+	for uploadID, file := range m.completed {
+		if now.Before(file.expires) {
+			continue
+		}
+		delete(m.completed, uploadID)
+		if deleter, ok := m.store.(artifactDeleter); ok {
+			go func(uri string) {
+				if err := deleter.Delete(context.Background(), uri); err != nil {
+					fmt.Printf("Failed to delete upload %s: %v\n", uri, err)
+				}
+			}(file.uri)
+		}
+	}
+}
+
+// deleteChunks deletes the stored chunks of an upload, if the store supports it.
+// The caller must hold u.mu.
+func (m *uploadManager) deleteChunks(ctx context.Context, u *upload) {
+	deleter, ok := m.store.(artifactDeleter)
+	if !ok {
+		return
+	}
+	for _, uri := range u.chunks {
+		if err := deleter.Delete(ctx, uri); err != nil {
+			fmt.Printf("Failed to delete upload chunk %s: %v\n", uri, err)
+		}
+	}
+}
+
+// chunkReader reads the stored chunks of an upload in order, getting one chunk from the
+// store at a time.
+type chunkReader struct {
+	ctx     context.Context
+	store   ArtifactStore
+	uris    []string // URIs of the chunks not yet read
+	current []byte   // Unread rest of the current chunk
+}
+
+// Read implements io.Reader.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if len(r.uris) == 0 {
+			return 0, io.EOF
+		}
+		chunk, err := r.store.Get(r.ctx, r.uris[0])
+		if err != nil {
+			return 0, fmt.Errorf("failed to read upload chunk: %w", err)
+		}
+		r.current, r.uris = chunk, r.uris[1:]
+	}
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// generateUploadID generates a random upload ID. Upload IDs are hard to guess, since
+// anyone who knows one can add to the upload.
+func generateUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return "upload_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/client"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+func TestUpload_Chunks(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileArtifactStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithArtifactStore(store))
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	chunks := [][]byte{[]byte("first "), []byte("second "), []byte("third")}
+	status, err := c.InitUpload(ctx, &a2a.InitUploadParams{Filename: "report.txt", MimeType: "text/plain", Size: 18})
+	if err != nil {
+		t.Fatalf("InitUpload failed: %v", err)
+	}
+	uploadID := status.UploadID
+
+	// Skipping a chunk is reported with the upload's status, so the client can resume
+	if _, err := c.UploadChunk(ctx, uploadID, 0, chunks[0]); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+	_, err = c.UploadChunk(ctx, uploadID, 2, chunks[2])
+	missing, ok := client.UploadStatusFromError(err)
+	if !ok {
+		t.Fatalf("Expected an out of order error, got %v", err)
+	}
+	if missing.NextChunk != 1 || missing.ReceivedBytes != int64(len(chunks[0])) {
+		t.Errorf("Expected the upload to resume from chunk 1, got %+v", missing)
+	}
+
+	// Completing before every byte has arrived fails
+	if _, err := c.CompleteUpload(ctx, uploadID); err == nil {
+		t.Error("Expected an error completing an incomplete upload")
+	}
+
+	for i := missing.NextChunk; i < len(chunks); i++ {
+		if status, err = c.UploadChunk(ctx, uploadID, i, chunks[i]); err != nil {
+			t.Fatalf("UploadChunk %d failed: %v", i, err)
+		}
+	}
+	if status.NextChunk != 3 || status.ReceivedBytes != 18 {
+		t.Errorf("Expected all chunks to be received, got %+v", status)
+	}
+
+	part, err := c.CompleteUpload(ctx, uploadID)
+	if err != nil {
+		t.Fatalf("CompleteUpload failed: %v", err)
+	}
+	if part.Filename != "report.txt" || part.MimeType != "text/plain" || part.URI == nil {
+		t.Fatalf("Expected a file part referencing the upload, got %+v", part)
+	}
+	if *part.URI != "uploads/"+uploadID {
+		t.Errorf("Expected the URI of the upload, got %q", *part.URI)
+	}
+	data, err := store.Get(ctx, "tasks/"+uploadID+"/artifacts/file")
+	if err != nil {
+		t.Fatalf("Failed to get the upload from the store: %v", err)
+	}
+	if string(data) != "first second third" {
+		t.Errorf("Expected the chunks joined in order, got %q", data)
+	}
+
+	// Only the completed file is left in the store
+	entries, err := os.ReadDir(filepath.Join(dir, uploadID))
+	if err != nil {
+		t.Fatalf("Failed to read the upload directory: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "file" {
+		t.Errorf("Expected the chunks to be deleted, got %v", entries)
+	}
+
+	// The upload cannot be added to once completed
+	_, err = c.UploadChunk(ctx, uploadID, 3, []byte("more"))
+	var a2aErr *a2a.Error
+	if !errors.As(err, &a2aErr) || a2aErr.Code != a2a.CodeUploadNotFound {
+		t.Errorf("Expected an upload not found error, got %v", err)
+	}
+}
+
+func TestUpload_File(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	received := make(chan task.Context, 1)
+	handler := func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+		received <- taskCtx
+		updates := make(chan task.YieldUpdate, 1)
+		updates <- task.StatusUpdate{State: a2a.TaskStateCompleted}
+		close(updates)
+		return updates, nil
+	}
+	_, baseURL := newTestServer(t, handler, WithArtifactStore(store))
+
+	c, err := client.NewClient(client.WithBaseURL(baseURL), client.WithUploadChunkSize(1000))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("0123456789"), 450)
+	part, err := c.UploadFile(ctx, bytes.NewReader(content), a2a.InitUploadParams{Filename: "digits.txt"})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	// tasks/send gives the handler the uploaded content
+	message := newTextMessage(a2a.RoleUser, "count the digits")
+	message.Parts = append(message.Parts, part)
+	if _, err := c.SendTask(ctx, &a2a.TaskSendParams{Message: message}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	files := (<-received).Files()
+	if len(files) != 1 || files[0].Content == nil {
+		t.Fatalf("Expected the handler to receive the uploaded file inline, got %+v", files)
+	}
+	data, err := base64.StdEncoding.DecodeString(files[0].Content.Data)
+	if err != nil {
+		t.Fatalf("Failed to decode the file: %v", err)
+	}
+	if !bytes.Equal(data, content) || files[0].Filename != "digits.txt" {
+		t.Errorf("Expected the uploaded content (%d bytes), got %d bytes", len(content), len(data))
+	}
+
+	// Unknown uploads are rejected
+	unknown := "uploads/upload_unknown"
+	message.Parts[1] = a2a.FilePart{Type: "file", URI: &unknown}
+	_, err = c.SendTask(ctx, &a2a.TaskSendParams{Message: message})
+	var a2aErr *a2a.Error
+	if !errors.As(err, &a2aErr) || a2aErr.Code != a2a.CodeInvalidParams || !strings.Contains(err.Error(), "message.parts[1].uri") {
+		t.Errorf("Expected a validation error for an unknown upload, got %v", err)
+	}
+}
+
+func TestUpload_Limits(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithArtifactStore(store), WithUploadLimits(100, 2))
+	c, err := client.NewClient(client.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.InitUpload(ctx, &a2a.InitUploadParams{Filename: "big.bin", Size: 101}); err == nil || !strings.Contains(err.Error(), "maximum upload size") {
+		t.Errorf("Expected an error for a declared size over the limit, got %v", err)
+	}
+
+	// Uploads of unknown size are stopped once they pass the limit
+	status, err := c.InitUpload(ctx, &a2a.InitUploadParams{Filename: "growing.bin"})
+	if err != nil {
+		t.Fatalf("InitUpload failed: %v", err)
+	}
+	if _, err := c.UploadChunk(ctx, status.UploadID, 0, make([]byte, 60)); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+	if _, err := c.UploadChunk(ctx, status.UploadID, 1, make([]byte, 60)); err == nil || !strings.Contains(err.Error(), "maximum upload size") {
+		t.Errorf("Expected an error for a chunk passing the limit, got %v", err)
+	}
+
+	// Only two uploads may be in progress
+	if _, err := c.InitUpload(ctx, &a2a.InitUploadParams{Filename: "second.bin"}); err != nil {
+		t.Fatalf("InitUpload failed: %v", err)
+	}
+	_, err = c.InitUpload(ctx, &a2a.InitUploadParams{Filename: "third.bin"})
+	var a2aErr *a2a.Error
+	if !errors.As(err, &a2aErr) || a2aErr.Code != a2a.CodeRateLimitExceeded {
+		t.Errorf("Expected a rate limit error for too many uploads, got %v", err)
+	}
+}
+
+func TestUpload_MaxInputBytes(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	_, baseURL := newTestServer(t, newMockHandler(), WithArtifactStore(store), WithAgentCard(&a2a.AgentCard{
+		A2AVersion:   "1.0",
+		ID:           "limited-agent",
+		Name:         "Limited Agent",
+		Capabilities: &a2a.AgentCapabilities{MaxInputBytes: 500},
+	}))
+	c, err := client.NewClient(client.WithBaseURL(baseURL), client.WithUploadChunkSize(100))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// The file becomes the task's input, so it may not exceed the agent's input limit
+	_, err = c.UploadFile(context.Background(), bytes.NewReader(make([]byte, 600)), a2a.InitUploadParams{Filename: "big.bin"})
+	if err == nil || !strings.Contains(err.Error(), "maximum upload size of 500 bytes") {
+		t.Errorf("Expected an error for an upload over the input limit, got %v", err)
+	}
+}
+
+func TestUpload_RequiresArtifactStore(t *testing.T) {
+	_, baseURL := newTestServer(t, newMockHandler())
+
+	_, body := postJSONRPC(t, baseURL, `{"jsonrpc":"2.0","method":"files/initUpload","params":{"filename":"a.txt"},"id":1}`)
+	if !strings.Contains(string(body), "file uploads") {
+		t.Errorf("Expected an unsupported operation error, got %s", body)
+	}
+}
+
+func TestUploadManager_CompletedUploadsExpire(t *testing.T) {
+	store, err := NewFileArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	m := newUploadManager(store, 0, 0)
+	ctx := context.Background()
+
+	status, _ := m.init(&a2a.InitUploadParams{Filename: "a.txt"}, 0)
+	if _, err := m.putChunk(ctx, &a2a.UploadChunkParams{UploadID: status.UploadID, Data: base64.StdEncoding.EncodeToString([]byte("hello"))}); err != nil {
+		t.Fatalf("putChunk failed: %v", err)
+	}
+	part, a2aErr := m.complete(ctx, &a2a.CompleteUploadParams{UploadID: status.UploadID})
+	if a2aErr != nil {
+		t.Fatalf("complete failed: %v", a2aErr)
+	}
+	if data, _, err := m.resolve(ctx, *part.URI); err != nil || string(data) != "hello" {
+		t.Fatalf("Expected the upload to resolve, got %q, %v", data, err)
+	}
+
+	// Once expired, the upload no longer resolves and its file is deleted
+	m.mu.Lock()
+	file := m.completed[status.UploadID]
+	file.expires = time.Now().Add(-time.Second)
+	m.completed[status.UploadID] = file
+	m.mu.Unlock()
+	if _, _, err := m.resolve(ctx, *part.URI); err == nil || err.Code != a2a.CodeUploadNotFound {
+		t.Errorf("Expected an expired upload not to be found, got %v", err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := store.Get(ctx, file.uri); err != nil {
+			return
+		}
+	}
+	t.Error("Expected the expired upload's file to be deleted")
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sammcj/go-a2a/a2a"
+	"github.com/sammcj/go-a2a/pkg/task"
+)
+
+// VirtualAgent is an additional agent served by a Server below its own path prefix, with its
+// own agent card, task handler and tasks.
+type VirtualAgent struct {
+	Prefix      string         // Path prefix of the agent's endpoints (e.g., "/agent-a")
+	AgentCard   *a2a.AgentCard // The agent card describing the agent
+	TaskHandler task.Handler   // Handler for the agent's tasks (nil = the server's agent engine)
+}
+
+// WithVirtualAgent serves another agent from the same server, below prefix. Its agent card
+// is served at the prefix joined with the agent card path, and its A2A and SSE endpoints at
+// the prefix joined with the A2A path prefix, e.g. /agent-a/.well-known/agent.json and
+// /agent-a/a2a. The agent keeps its own tasks, and shares the rest of the server's
+// configuration, including its middleware and agent engine.
+func WithVirtualAgent(prefix string, card *a2a.AgentCard, handler task.Handler) Option {
+	return func(c *Config) {
+		c.VirtualAgents = append(c.VirtualAgents, VirtualAgent{Prefix: prefix, AgentCard: card, TaskHandler: handler})
+	}
+}
+
+// newVirtualAgents creates the servers for the virtual agents configured in cfg, keyed by
+// their normalized prefix. cfg must be the parent server's completed configuration.
+func newVirtualAgents(cfg Config) (map[string]*Server, error) {
+	agents := make(map[string]*Server, len(cfg.VirtualAgents))
+	for _, va := range cfg.VirtualAgents {
+		prefix := strings.TrimSuffix(va.Prefix, "/")
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("virtual agent prefix %q must start with / and not be the root", va.Prefix)
+		}
+		if _, ok := agents[prefix]; ok {
+			return nil, fmt.Errorf("duplicate virtual agent prefix %q", va.Prefix)
+		}
+
+		agentCfg := cfg
+		agentCfg.AgentCard = va.AgentCard
+		agentCfg.AgentCardLoader = nil
+		agentCfg.TaskHandler = va.TaskHandler
+		agentCfg.TaskManager = nil
+		agentCfg.SkillAgentEngines = nil
+		agentCfg.VirtualAgents = nil
+		agentCfg.AdminAPI = false // The parent server's stats cover its virtual agents
+		agentCfg.A2APathPrefix = joinPath(prefix, cfg.A2APathPrefix)
+		agentCfg.AgentCardPath = joinPath(prefix, cfg.AgentCardPath)
+
+		// Middleware is applied once, by the parent server
+		agentCfg.AuthValidator = nil
+		agentCfg.RequestLogger = nil
+		agentCfg.Compression = false
+
+		agent, err := NewServer(func(c *Config) { *c = agentCfg })
+		if err != nil {
+			return nil, fmt.Errorf("virtual agent %q: %w", va.Prefix, err)
+		}
+		agents[prefix] = agent
+	}
+	return agents, nil
+}
+
+// agentFor returns the agent serving a request path: the virtual agent with the longest
+// prefix the path is below, or the server itself.
+func (s *Server) agentFor(path string) *Server {
+	agent, longest := s, 0
+	for prefix, va := range s.virtual {
+		if len(prefix) > longest && strings.HasPrefix(path, prefix+"/") {
+			agent, longest = va, len(prefix)
+		}
+	}
+	return agent
+}
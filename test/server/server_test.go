@@ -12,52 +12,86 @@ import (
 	"github.com/sammcj/go-a2a/a2a"
 	"github.com/sammcj/go-a2a/llm/gollm"
 	"github.com/sammcj/go-a2a/pkg/config"
+	"github.com/sammcj/go-a2a/pkg/task"
 	"github.com/sammcj/go-a2a/server"
 )
 
 // MockTaskManager is a mock implementation of the TaskManager interface for testing.
 type MockTaskManager struct {
-	StartTaskFunc func(ctx context.Context, agentID, skillID string, input map[string]interface{}, auth *a2a.AgentAuthentication, agentCapabilities *a2a.AgentCapabilities) (string, error)
-	GetTaskFunc   func(id string, auth *a2a.AgentAuthentication) (*a2a.AgentTask, error)
-	StopTaskFunc  func(id string) error
-	GetTasksFunc  func(auth *a2a.AgentAuthentication) ([]*a2a.AgentTask, error)
+	SendTaskFunc   func(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error)
+	GetTaskFunc    func(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error)
+	CancelTaskFunc func(ctx context.Context, params *a2a.TaskIdParams) (*a2a.Task, error)
 }
 
-func (m *MockTaskManager) StartTask(ctx context.Context, agentID, skillID string, input map[string]interface{}, auth *a2a.AgentAuthentication, agentCapabilities *a2a.AgentCapabilities) (string, error) {
-	if m.StartTaskFunc != nil {
-		return m.StartTaskFunc(ctx, agentID, skillID, input, auth, agentCapabilities)
+func (m *MockTaskManager) OnSendTask(ctx context.Context, params *a2a.TaskSendParams) (*a2a.Task, error) {
+	if m.SendTaskFunc != nil {
+		return m.SendTaskFunc(ctx, params)
 	}
-	return "", errors.New("StartTask not implemented")
+	return nil, errors.New("OnSendTask not implemented")
 }
 
-func (m *MockTaskManager) GetTask(id string, auth *a2a.AgentAuthentication) (*a2a.AgentTask, error) {
+func (m *MockTaskManager) OnSendTaskSubscribe(ctx context.Context, params *a2a.TaskSendParams) (string, <-chan task.YieldUpdate, error) {
+	return "", nil, errors.New("OnSendTaskSubscribe not implemented")
+}
+
+func (m *MockTaskManager) OnGetTask(ctx context.Context, params *a2a.TaskQueryParams) (*a2a.Task, error) {
 	if m.GetTaskFunc != nil {
-		return m.GetTaskFunc(id, auth)
+		return m.GetTaskFunc(ctx, params)
 	}
-	return nil, errors.New("GetTask not implemented")
+	return nil, errors.New("OnGetTask not implemented")
 }
 
-func (m *MockTaskManager) StopTask(id string) error {
-	if m.StopTaskFunc != nil {
-		return m.StopTaskFunc(id)
+func (m *MockTaskManager) OnCancelTask(ctx context.Context, params *a2a.TaskIdParams) (*a2a.Task, error) {
+	if m.CancelTaskFunc != nil {
+		return m.CancelTaskFunc(ctx, params)
 	}
-	return errors.New("StopTask not implemented")
+	return nil, errors.New("OnCancelTask not implemented")
 }
-func (m *MockTaskManager) GetTasks(auth *a2a.AgentAuthentication) ([]*a2a.AgentTask, error) {
-	if m.GetTasksFunc != nil {
-		return m.GetTasksFunc(auth)
-	}
-	return nil, errors.New("GetTasks not implemented")
+
+func (m *MockTaskManager) OnSetTaskPushNotification(ctx context.Context, params *a2a.TaskPushNotificationConfigParams) (*a2a.PushNotificationConfig, error) {
+	return nil, errors.New("OnSetTaskPushNotification not implemented")
+}
+
+func (m *MockTaskManager) OnGetTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) (*a2a.PushNotificationConfig, error) {
+	return nil, errors.New("OnGetTaskPushNotification not implemented")
 }
 
+func (m *MockTaskManager) OnDeleteTaskPushNotification(ctx context.Context, params *a2a.TaskIdParams) error {
+	return errors.New("OnDeleteTaskPushNotification not implemented")
+}
+
+func (m *MockTaskManager) OnResubscribeToTask(ctx context.Context, params *a2a.TaskIdParams) (<-chan task.YieldUpdate, error) {
+	return nil, errors.New("OnResubscribeToTask not implemented")
+}
+
+// MockAgentEngine is a mock implementation of the AgentEngine interface for testing.
 type MockAgentEngine struct {
-	HandleRequestFunc func(w http.ResponseWriter, r *http.Request)
+	ProcessTaskFunc func(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error)
 }
 
-func (m *MockAgentEngine) HandleRequest(w http.ResponseWriter, r *http.Request) {
-	if m.HandleRequestFunc != nil {
-		m.HandleRequestFunc(w, r)
+func (m *MockAgentEngine) ProcessTask(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+	if m.ProcessTaskFunc != nil {
+		return m.ProcessTaskFunc(ctx, taskCtx)
 	}
+	updates := make(chan task.YieldUpdate)
+	close(updates)
+	return updates, nil
+}
+
+func (m *MockAgentEngine) GetCapabilities() server.AgentCapabilities {
+	return server.AgentCapabilities{}
+}
+
+// testAgentCard returns a valid agent card for the tests.
+func testAgentCard() *a2a.AgentCard {
+	return &a2a.AgentCard{A2AVersion: "1.0", ID: "test-agent", Name: "Test Agent"}
+}
+
+// noopTaskHandler is a task handler that finishes without any updates.
+func noopTaskHandler(ctx context.Context, taskCtx task.Context) (<-chan task.YieldUpdate, error) {
+	updates := make(chan task.YieldUpdate)
+	close(updates)
+	return updates, nil
 }
 
 // TestNewServer tests the NewServer function.
@@ -68,71 +102,58 @@ func TestNewServer(t *testing.T) {
 		expectedError error
 	}{
 		{
-			name:          "NoAgentCard",
-			opts:          []server.Option{},
+			name: "NoAgentCard",
+			opts: []server.Option{
+				server.WithTaskHandler(noopTaskHandler),
+			},
 			expectedError: fmt.Errorf("agent card configuration is required"),
 		},
 		{
 			name: "ValidServer",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
+				server.WithAgentCard(testAgentCard()),
+				server.WithTaskHandler(noopTaskHandler),
 			},
 			expectedError: nil,
 		},
 		{
 			name: "ValidServerWithAgent",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
-				server.WithBasicGollmAgent("test", "test", "test", "test"),
+				server.WithAgentCard(testAgentCard()),
+				server.WithAgentEngine(&MockAgentEngine{}),
 			},
 			expectedError: nil,
 		},
 		{
 			name: "ValidServerWithGollmOptions",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
-				server.WithGollmOptions(&gollm.Options{Provider: "test"}),
+				server.WithAgentCard(testAgentCard()),
+				server.WithGollmOptions([]gollm.Option{gollm.WithProvider("openai"), gollm.WithModel("gpt-4o-mini"), gollm.WithAPIKey("sk-test-api-key-0123456789")}),
 			},
 			expectedError: nil,
 		},
 		{
 			name: "ValidServerWithGollmOptionsAndAgent",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
-				server.WithGollmOptions(&gollm.Options{Provider: "test"}),
-				server.WithBasicGollmAgent("test", "test", "test", "test"),
+				server.WithAgentCard(testAgentCard()),
+				server.WithGollmOptions([]gollm.Option{gollm.WithProvider("openai"), gollm.WithModel("gpt-4o-mini"), gollm.WithAPIKey("sk-test-api-key-0123456789")}),
+				server.WithAgentEngine(&MockAgentEngine{}),
 			},
 			expectedError: nil,
 		},
 		{
+			// Nothing processes tasks: no task handler, task manager, agent engine or gollm options
 			name: "InvalidServerNoAgentNoOptions",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
+				server.WithAgentCard(testAgentCard()),
 			},
 			expectedError: errors.New("gollm options must be set when agent engine not set"),
 		},
 		{
 			name: "ValidDefaultConfig",
 			opts: []server.Option{
-				server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-				server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-					return nil, nil
-				}),
+				server.WithAgentCard(testAgentCard()),
+				server.WithTaskManager(&MockTaskManager{}),
 			},
 			expectedError: nil,
 		},
@@ -163,13 +184,9 @@ func TestServerStartAndStop(t *testing.T) {
 
 	// Create a server with the mock task manager
 	srv, err := server.NewServer(
-		server.WithAgentCard(&a2a.AgentCard{ID: "test-agent"}),
-		server.WithListenAddress(":8082"),
+		server.WithAgentCard(testAgentCard()),
+		server.WithListenAddress("127.0.0.1:0"),
 		server.WithTaskManager(mockTaskManager),
-		server.WithTaskHandler(func(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
-			return nil, nil
-		}),
-		server.WithGollmOptions(&gollm.Options{}),
 	)
 	if err != nil {
 		t.Fatalf("Failed to create server: %v", err)
@@ -187,11 +204,6 @@ func TestServerStartAndStop(t *testing.T) {
 	// Wait for a short period to allow the server to start
 	time.Sleep(100 * time.Millisecond)
 
-	// Check if there was an error during start
-	if startErr != nil && startErr != http.ErrServerClosed {
-		t.Fatalf("Server start failed: %v", startErr)
-	}
-
 	// Stop the server
 	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -204,8 +216,8 @@ func TestServerStartAndStop(t *testing.T) {
 	wg.Wait()
 
 	// Check if there was an error during start
-	if startErr != nil && startErr != http.ErrServerClosed {
-		t.Fatalf
+	if startErr != nil && !errors.Is(startErr, http.ErrServerClosed) {
+		t.Fatalf("Server start failed: %v", startErr)
 	}
 }
 
@@ -214,118 +226,28 @@ func TestNewGollmOptionsFromConfig(t *testing.T) {
 	testCases := []struct {
 		name          string
 		llmConfig     config.LLMConfig
-		expectedError error
+		expectedCount int
 	}{
 		{
 			name:          "ValidConfig",
-			llmConfig:     config.LLMConfig{Provider: "openai", Model: "gpt-3.5-turbo", ApiKey: "test-api-key"},
-			expectedError: nil,
+			llmConfig:     config.LLMConfig{Provider: "openai", Model: "gpt-3.5-turbo", APIKey: "test-api-key"},
+			expectedCount: 3,
 		},
 		{
 			name:          "NoProvider",
 			llmConfig:     config.LLMConfig{},
-			expectedError: nil,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			_, err := server.NewGollmOptionsFromConfig(tc.llmConfig)
-			if tc.expectedError == nil && err != nil {
-				t.Fatalf("Expected no error, but got: %v", err)
-			}
-			if tc.expectedError != nil {
-				if err == nil {
-					t.Fatalf("Expected error: %v, but got no error", tc.expectedError)
-				}
-				if err.Error() != tc.expectedError.Error() {
-					t.Fatalf("Expected error: %v, but got: %v", tc.expectedError, err)
-				}
-			}
-		})
-	}
-}
-
-// TestDefaultGollmConfig tests the DefaultGollmConfig function.
-func TestDefaultGollmConfig(t *testing.T) {
-	defaultConfig := server.DefaultGollmConfig()
-
-	if defaultConfig.Provider != "openai" {
-		t.Errorf("Expected default provider to be 'openai', but got: %s", defaultConfig.Provider)
-	}
-	if defaultConfig.Options == nil {
-		t.Fatalf("Expected default options to not be nil")
-	}
-	if defaultConfig.Options["temperature"] != 0.7 {
-		t.Errorf("Expected default temperature to be 0.7, but got: %v", defaultConfig.Options["temperature"])
-	}
-}
-
-// TestNewGollmOptions tests the NewGollmOptions function.
-func TestNewGollmOptions(t *testing.T) {
-	testCases := []struct {
-		name          string
-		llmConfig     server.GollmConfig
-		expectedError error
-		checkOpts     func(opts *gollm.Options) error
-	}{
-		{
-			name:          "ValidConfig",
-			llmConfig:     server.GollmConfig{Provider: "openai", Model: "gpt-3.5-turbo", APIKey: "test-api-key", BaseUrl: "http://test.com", SystemPrompt: "test-prompt", Options: map[string]interface{}{"temperature": 0.8}},
-			expectedError: nil,
-			checkOpts: func(opts *gollm.Options) error {
-				if opts.Provider != "openai" {
-					return fmt.Errorf("expected provider to be 'openai', but got: %s", opts.Provider)
-				}
-				if opts.Model != "gpt-3.5-turbo" {
-					return fmt.Errorf("expected model to be 'gpt-3.5-turbo', but got: %s", opts.Model)
-				}
-				if opts.APIKey != "test-api-key" {
-					return fmt.Errorf("expected api key to be 'test-api-key', but got: %s", opts.APIKey)
-				}
-				if opts.BaseURL != "http://test.com" {
-					return fmt.Errorf("expected base url to be 'http://test.com', but got: %s", opts.BaseURL)
-				}
-				if opts.SystemPrompt != "test-prompt" {
-					return fmt.Errorf("expected system prompt to be 'test-prompt', but got: %s", opts.SystemPrompt)
-				}
-				if opts.Options["temperature"] != 0.8 {
-					return fmt.Errorf("expected temperature to be 0.8, but got: %v", opts.Options["temperature"])
-				}
-				return nil
-			},
-		},
-		{
-			name:          "NoProvider",
-			llmConfig:     server.GollmConfig{},
-			expectedError: nil,
-			checkOpts: func(opts *gollm.Options) error {
-				if opts.Provider != "" {
-					return fmt.Errorf("expected provider to be '', but got: %s", opts.Provider)
-				}
-				return nil
-			},
+			expectedCount: 0,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			opts, err := server.NewGollmOptions(tc.llmConfig)
-			if tc.expectedError == nil && err != nil {
+			opts, err := server.NewGollmOptionsFromConfig(tc.llmConfig)
+			if err != nil {
 				t.Fatalf("Expected no error, but got: %v", err)
 			}
-			if tc.expectedError != nil {
-				if err == nil {
-					t.Fatalf("Expected error: %v, but got no error", tc.expectedError)
-				}
-				if err.Error() != tc.expectedError.Error() {
-					t.Fatalf("Expected error: %v, but got: %v", tc.expectedError, err)
-				}
-			}
-			if tc.checkOpts != nil {
-				if err := tc.checkOpts(opts); err != nil {
-					t.Fatalf("Check options failed: %v", err)
-				}
+			if len(opts) != tc.expectedCount {
+				t.Fatalf("Expected %d options, but got: %d", tc.expectedCount, len(opts))
 			}
 		})
 	}